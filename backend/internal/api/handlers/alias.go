@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"url-manager-system/backend/internal/api/middleware"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// AliasHandler 别名处理器
+type AliasHandler struct {
+	aliasService *services.AliasService
+}
+
+// NewAliasHandler 创建别名处理器
+func NewAliasHandler(aliasService *services.AliasService) *AliasHandler {
+	return &AliasHandler{
+		aliasService: aliasService,
+	}
+}
+
+// CreateAlias 创建别名
+func (h *AliasHandler) CreateAlias(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	var req models.CreateURLAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alias, err := h.aliasService.CreateAlias(c.Request.Context(), projectID, userID, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create alias")
+		h.writeError(c, err, "Failed to create alias")
+		return
+	}
+
+	c.JSON(http.StatusCreated, alias)
+}
+
+// ListAliases 列出项目的别名
+func (h *AliasHandler) ListAliases(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	aliases, err := h.aliasService.ListAliases(c.Request.Context(), projectID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list aliases")
+		h.writeError(c, err, "Failed to list aliases")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListURLAliasesResponse{
+		Aliases: aliases,
+		Total:   len(aliases),
+	})
+}
+
+// UpdateAlias 将别名重新指向另一个URL
+func (h *AliasHandler) UpdateAlias(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alias ID"})
+		return
+	}
+
+	var req models.UpdateURLAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alias, err := h.aliasService.UpdateAlias(c.Request.Context(), id, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update alias")
+		h.writeError(c, err, "Failed to update alias")
+		return
+	}
+
+	c.JSON(http.StatusOK, alias)
+}
+
+// DeleteAlias 删除别名
+func (h *AliasHandler) DeleteAlias(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alias ID"})
+		return
+	}
+
+	if err := h.aliasService.DeleteAlias(c.Request.Context(), id); err != nil {
+		logrus.WithError(err).Error("Failed to delete alias")
+		h.writeError(c, err, "Failed to delete alias")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// writeError 根据错误类型返回不同的状态码
+func (h *AliasHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case err.Error() == "project not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case err.Error() == "alias not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alias not found"})
+	case err.Error() == "URL not found":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL not found in this project"})
+	case strings.HasPrefix(err.Error(), "invalid alias"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "already exists"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"url-manager-system/backend/internal/api/middleware"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// DomainHandler 自定义域名处理器
+type DomainHandler struct {
+	domainService *services.DomainService
+}
+
+// NewDomainHandler 创建自定义域名处理器
+func NewDomainHandler(domainService *services.DomainService) *DomainHandler {
+	return &DomainHandler{
+		domainService: domainService,
+	}
+}
+
+// CreateDomain 为项目登记自定义域名
+func (h *DomainHandler) CreateDomain(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	var req models.CreateCustomDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	domain, err := h.domainService.CreateDomain(c.Request.Context(), projectID, userID, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create domain")
+		h.writeError(c, err, "Failed to create domain")
+		return
+	}
+
+	c.JSON(http.StatusCreated, domain)
+}
+
+// ListDomains 列出项目的自定义域名
+func (h *DomainHandler) ListDomains(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	domains, err := h.domainService.ListDomains(c.Request.Context(), projectID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list domains")
+		h.writeError(c, err, "Failed to list domains")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListCustomDomainsResponse{
+		Domains: domains,
+		Total:   len(domains),
+	})
+}
+
+// VerifyDomain 立即检查域名的验证记录
+func (h *DomainHandler) VerifyDomain(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	domain, err := h.domainService.VerifyDomain(c.Request.Context(), id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to verify domain")
+		h.writeError(c, err, "Failed to verify domain")
+		return
+	}
+
+	c.JSON(http.StatusOK, domain)
+}
+
+// DeleteDomain 删除自定义域名
+func (h *DomainHandler) DeleteDomain(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	if err := h.domainService.DeleteDomain(c.Request.Context(), id); err != nil {
+		logrus.WithError(err).Error("Failed to delete domain")
+		h.writeError(c, err, "Failed to delete domain")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// writeError 根据错误类型返回不同的状态码
+func (h *DomainHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case err.Error() == "project not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case err.Error() == "domain not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+	case strings.HasPrefix(err.Error(), "invalid domain"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "already"), strings.Contains(err.Error(), "in use"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"url-manager-system/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// DriftHandler 配置漂移处理器
+type DriftHandler struct {
+	driftService *services.DriftService
+}
+
+// NewDriftHandler 创建配置漂移处理器
+func NewDriftHandler(driftService *services.DriftService) *DriftHandler {
+	return &DriftHandler{
+		driftService: driftService,
+	}
+}
+
+// GetURLDrift 比较URL的Kubernetes资源与URL记录，返回差异
+func (h *DriftHandler) GetURLDrift(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	report, err := h.driftService.GetURLDrift(c.Request.Context(), id)
+	if err != nil {
+		switch err.Error() {
+		case "URL not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+			return
+		case "drift detection is not supported for template URLs":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Drift detection is not supported for template URLs"})
+			return
+		case "URL is not deployed":
+			c.JSON(http.StatusConflict, gin.H{"error": "URL is not deployed"})
+			return
+		case "kubernetes not available":
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes not available"})
+			return
+		}
+		logrus.WithError(err).Error("Failed to detect drift")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to detect drift"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
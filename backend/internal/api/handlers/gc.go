@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"url-manager-system/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GCHandler 孤儿资源回收处理器
+type GCHandler struct {
+	gcService *services.GCService
+}
+
+// NewGCHandler 创建孤儿资源回收处理器
+func NewGCHandler(gcService *services.GCService) *GCHandler {
+	return &GCHandler{
+		gcService: gcService,
+	}
+}
+
+// RunGC 立即执行一次孤儿资源回收，dry_run=true时只返回将被删除的资源
+func (h *GCHandler) RunGC(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	report, err := h.gcService.Run(c.Request.Context(), dryRun)
+	if err != nil {
+		switch err.Error() {
+		case "garbage collection is already running":
+			c.JSON(http.StatusConflict, gin.H{"error": "Garbage collection is already running"})
+			return
+		case "kubernetes not available":
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes not available"})
+			return
+		}
+		logrus.WithError(err).Error("Failed to run garbage collection")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run garbage collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"url-manager-system/backend/internal/api/middleware"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// GitWebhookHandler Git webhook处理器
+type GitWebhookHandler struct {
+	gitWebhookService *services.GitWebhookService
+}
+
+// NewGitWebhookHandler 创建Git webhook处理器
+func NewGitWebhookHandler(gitWebhookService *services.GitWebhookService) *GitWebhookHandler {
+	return &GitWebhookHandler{
+		gitWebhookService: gitWebhookService,
+	}
+}
+
+// CreateGitWebhook 创建Git webhook
+func (h *GitWebhookHandler) CreateGitWebhook(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	var req models.CreateGitWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.gitWebhookService.CreateGitWebhook(c.Request.Context(), projectID, userID, middleware.IsAdmin(c), &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create git webhook")
+
+		// 根据错误类型返回不同的状态码
+		switch {
+		case err.Error() == "project not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case err.Error() == "template not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		case err.Error() == "template is not available in this project":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case strings.HasPrefix(err.Error(), "invalid git webhook"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "already exists"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create git webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// ListGitWebhooks 列出项目的Git webhook
+func (h *GitWebhookHandler) ListGitWebhooks(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	webhooks, err := h.gitWebhookService.ListGitWebhooks(c.Request.Context(), projectID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list git webhooks")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list git webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListGitWebhooksResponse{
+		Webhooks: webhooks,
+		Total:    len(webhooks),
+	})
+}
+
+// DeleteGitWebhook 删除Git webhook
+func (h *GitWebhookHandler) DeleteGitWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid git webhook ID"})
+		return
+	}
+
+	if err := h.gitWebhookService.DeleteGitWebhook(c.Request.Context(), id); err != nil {
+		if err.Error() == "git webhook not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Git webhook not found"})
+			return
+		}
+		logrus.WithError(err).Error("Failed to delete git webhook")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete git webhook"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ReceiveGitWebhook 接收GitHub/GitLab的webhook投递（不需要登录，通过签名或令牌认证）
+func (h *GitWebhookHandler) ReceiveGitWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Git webhook not found"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	// GitHub和GitLab使用不同的请求头传递事件类型和认证信息
+	eventType := c.GetHeader("X-GitHub-Event")
+	signature := c.GetHeader("X-Hub-Signature-256")
+	if eventType == "" {
+		eventType = c.GetHeader("X-Gitlab-Event")
+		signature = c.GetHeader("X-Gitlab-Token")
+	}
+
+	result, err := h.gitWebhookService.HandleDelivery(c.Request.Context(), id, eventType, signature, body)
+	if err != nil {
+		switch {
+		case err.Error() == "git webhook not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Git webhook not found"})
+		case err.Error() == "invalid webhook signature":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook signature"})
+		case strings.HasPrefix(err.Error(), "invalid webhook payload"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			// 投递接口无需登录，不向调用方返回内部错误信息
+			logrus.WithError(err).WithField("webhook_id", id).Error("Failed to handle git webhook")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to handle git webhook"})
+		}
+		return
+	}
+
+	if result.Action == models.GitWebhookIgnored {
+		c.JSON(http.StatusAccepted, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
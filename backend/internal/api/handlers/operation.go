@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"url-manager-system/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// OperationHandler 异步操作处理器
+type OperationHandler struct {
+	operationService *services.OperationService
+}
+
+// NewOperationHandler 创建异步操作处理器
+func NewOperationHandler(operationService *services.OperationService) *OperationHandler {
+	return &OperationHandler{
+		operationService: operationService,
+	}
+}
+
+// GetOperation 查询异步操作的进度和结果
+func (h *OperationHandler) GetOperation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid operation ID"})
+		return
+	}
+
+	op, err := h.operationService.GetOperation(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "operation not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Operation not found"})
+			return
+		}
+		logrus.WithError(err).Error("Failed to get operation")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get operation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, op)
+}
@@ -44,6 +44,7 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	var req struct {
 		Name        string `json:"name" binding:"required,min=1,max=100"`
 		Description string `json:"description"`
+		models.ProjectRoutingSettings
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -64,8 +65,13 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
-	project, err := h.projectService.CreateProject(c.Request.Context(), userID, req.Name, req.Description)
+	project, err := h.projectService.CreateProject(c.Request.Context(), userID, req.Name, req.Description, req.ProjectRoutingSettings)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid routing mode") || strings.HasPrefix(err.Error(), "invalid tls mode") ||
+			strings.HasPrefix(err.Error(), "invalid drift policy") || strings.HasPrefix(err.Error(), "invalid retry policy") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		logrus.WithError(err).Error("Failed to create project")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project"})
 		return
@@ -168,6 +174,7 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 	var req struct {
 		Name        string `json:"name" binding:"required,min=1,max=100"`
 		Description string `json:"description"`
+		models.ProjectRoutingSettings
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -181,12 +188,21 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 		return
 	}
 
-	project, err := h.projectService.UpdateProject(c.Request.Context(), id, req.Name, req.Description)
+	project, err := h.projectService.UpdateProject(c.Request.Context(), id, req.Name, req.Description, req.ProjectRoutingSettings)
 	if err != nil {
 		if err.Error() == "project not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 			return
 		}
+		if strings.HasPrefix(err.Error(), "invalid routing mode") || strings.HasPrefix(err.Error(), "invalid tls mode") ||
+			strings.HasPrefix(err.Error(), "invalid drift policy") || strings.HasPrefix(err.Error(), "invalid retry policy") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "cannot change routing mode of project with URLs" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		logrus.WithError(err).Error("Failed to update project")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
 		return
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"url-manager-system/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RegistryWebhookHandler 镜像仓库webhook处理器
+type RegistryWebhookHandler struct {
+	registryWebhookService *services.RegistryWebhookService
+}
+
+// NewRegistryWebhookHandler 创建镜像仓库webhook处理器
+func NewRegistryWebhookHandler(registryWebhookService *services.RegistryWebhookService) *RegistryWebhookHandler {
+	return &RegistryWebhookHandler{
+		registryWebhookService: registryWebhookService,
+	}
+}
+
+// ReceiveRegistryWebhook 接收Docker Registry v2或Harbor的推送通知（不需要登录，通过令牌认证）
+func (h *RegistryWebhookHandler) ReceiveRegistryWebhook(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	result, err := h.registryWebhookService.HandleNotification(c.Request.Context(), c.GetHeader("Authorization"), body)
+	if err != nil {
+		switch {
+		case err.Error() == "registry webhook is not enabled":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Registry webhook is not enabled"})
+		case err.Error() == "invalid webhook token":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook token"})
+		case strings.HasPrefix(err.Error(), "invalid webhook payload"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			logrus.WithError(err).Error("Failed to handle registry webhook")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to handle registry webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
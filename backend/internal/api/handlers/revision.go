@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"url-manager-system/backend/internal/db/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ListURLRevisions 获取URL的配置版本历史
+func (h *URLHandler) ListURLRevisions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	revisions, err := h.urlService.ListURLRevisions(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "URL not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+			return
+		}
+		logrus.WithError(err).Error("Failed to list URL revisions")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list URL revisions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListURLRevisionsResponse{
+		Revisions: revisions,
+		Total:     len(revisions),
+	})
+}
+
+// RollbackURL 将URL回滚到指定的配置版本
+func (h *URLHandler) RollbackURL(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	var req models.RollbackURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	op, err := h.urlService.RollbackURL(c.Request.Context(), id, req.Revision)
+	if err != nil {
+		switch {
+		case err.Error() == "URL not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		case err.Error() == "revision not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		case err.Error() == "rollback is not supported for template URLs":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Rollback is not supported for template URLs"})
+		case strings.HasPrefix(err.Error(), "URL cannot be rolled back"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			logrus.WithError(err).Error("Failed to roll back URL")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to roll back URL"})
+		}
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"url_id":       id,
+		"revision":     req.Revision,
+		"operation_id": op.ID,
+	}).Info("URL rollback initiated")
+	c.JSON(http.StatusAccepted, op)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"url-manager-system/backend/internal/api/middleware"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// StackHandler 环境处理器
+type StackHandler struct {
+	stackService *services.StackService
+}
+
+// NewStackHandler 创建环境处理器
+func NewStackHandler(stackService *services.StackService) *StackHandler {
+	return &StackHandler{
+		stackService: stackService,
+	}
+}
+
+// CreateStack 创建环境
+func (h *StackHandler) CreateStack(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	var req models.CreateStackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stack, err := h.stackService.CreateStack(c.Request.Context(), projectID, userID, middleware.IsAdmin(c), &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create stack")
+
+		// 根据错误类型返回不同的状态码
+		switch {
+		case err.Error() == "project not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case strings.HasPrefix(err.Error(), "invalid stack"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.HasSuffix(err.Error(), "template not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.HasSuffix(err.Error(), "template is not available in this project"):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "already exists"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create stack"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, stack)
+}
+
+// ListStacks 列出项目的环境
+func (h *StackHandler) ListStacks(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	// 解析分页参数
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	stacks, total, err := h.stackService.ListStacks(c.Request.Context(), projectID, limit, offset)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list stacks")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list stacks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListStacksResponse{
+		Stacks: stacks,
+		Total:  total,
+	})
+}
+
+// GetStack 获取环境
+func (h *StackHandler) GetStack(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stack ID"})
+		return
+	}
+
+	stack, err := h.stackService.GetStack(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "stack not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Stack not found"})
+			return
+		}
+		logrus.WithError(err).Error("Failed to get stack")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get stack"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stack)
+}
+
+// DeleteStack 删除环境及其所有服务
+func (h *StackHandler) DeleteStack(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stack ID"})
+		return
+	}
+
+	if err := h.stackService.DeleteStack(c.Request.Context(), id); err != nil {
+		if err.Error() == "stack not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Stack not found"})
+			return
+		}
+		logrus.WithError(err).Error("Failed to delete stack")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete stack"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
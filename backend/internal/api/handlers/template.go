@@ -1,26 +1,34 @@
 package handlers
 
 import (
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"url-manager-system/backend/internal/api/middleware"
 	"url-manager-system/backend/internal/db/models"
 	"url-manager-system/backend/internal/services"
+	"url-manager-system/backend/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+// maxTemplateBundleSize 导入模版包的大小上限
+const maxTemplateBundleSize = 5 << 20
+
 // TemplateHandler 模版处理器
 type TemplateHandler struct {
 	templateService *services.TemplateService
+	catalogService  *services.CatalogService
 }
 
 // NewTemplateHandler 创建模版处理器
-func NewTemplateHandler(templateService *services.TemplateService) *TemplateHandler {
+func NewTemplateHandler(templateService *services.TemplateService, catalogService *services.CatalogService) *TemplateHandler {
 	return &TemplateHandler{
 		templateService: templateService,
+		catalogService:  catalogService,
 	}
 }
 
@@ -44,9 +52,12 @@ func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
 		logrus.WithError(err).Error("Failed to create template")
 
 		// 根据错误类型返回不同的状态码
-		if err.Error() == "template name '"+req.Name+"' already exists" {
+		switch {
+		case err.Error() == "template name '"+req.Name+"' already exists":
 			c.JSON(http.StatusConflict, gin.H{"error": "Template name already exists"})
-		} else {
+		case strings.HasPrefix(err.Error(), "invalid"), strings.HasPrefix(err.Error(), "kustomize bundle is required"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
 		}
 		return
@@ -65,15 +76,14 @@ func (h *TemplateHandler) GetTemplate(c *gin.Context) {
 	}
 
 	// 获取当前用户ID（仍然需要认证）
-	_, err = middleware.GetCurrentUserID(c)
+	userID, err := middleware.GetCurrentUserID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
 		return
 	}
 
-	// 移除权限检查，所有用户都可以查看所有模版
-
-	template, err := h.templateService.GetTemplate(c.Request.Context(), id)
+	// 不可见的模版按不存在处理
+	template, err := h.templateService.GetTemplateForUser(c.Request.Context(), id, userID, middleware.IsAdmin(c))
 	if err != nil {
 		if err.Error() == "template not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
@@ -104,16 +114,46 @@ func (h *TemplateHandler) ListTemplates(c *gin.Context) {
 	}
 
 	// 获取当前用户ID（仍然需要认证）
-	_, err = middleware.GetCurrentUserID(c)
+	userID, err := middleware.GetCurrentUserID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
 		return
 	}
 
-	// 移除权限检查，所有用户都可以查看所有模版
-	isAdmin := true // 所有用户都被视为管理员
+	// 解析搜索和过滤参数
+	filter := &models.TemplateListFilter{
+		Search:       strings.TrimSpace(c.Query("search")),
+		Category:     c.Query("category"),
+		Visibility:   c.Query("visibility"),
+		TemplateType: c.Query("template_type"),
+		Sort:         c.Query("sort"),
+	}
+	if tagsParam := c.Query("tags"); tagsParam != "" {
+		filter.Tags, err = utils.NormalizeTemplateTags(strings.Split(tagsParam, ","))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if projectIDStr := c.Query("project_id"); projectIDStr != "" {
+		projectID, err := uuid.Parse(projectIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+			return
+		}
+		filter.ProjectID = &projectID
+	}
+	if featuredStr := c.Query("featured"); featuredStr != "" {
+		featured, err := strconv.ParseBool(featuredStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid featured filter"})
+			return
+		}
+		filter.Featured = &featured
+	}
 
-	templates, total, err := h.templateService.ListTemplates(c.Request.Context(), nil, isAdmin, limit, offset)
+	// 管理员可以查看所有模版，普通用户只能查看可见范围内的模版
+	templates, total, err := h.templateService.ListTemplates(c.Request.Context(), userID, middleware.IsAdmin(c), filter, limit, offset)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to list templates")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list templates"})
@@ -144,8 +184,7 @@ func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
 		return
 	}
 
-	// 移除权限检查，所有用户都被视为管理员
-	isAdmin := true
+	isAdmin := middleware.IsAdmin(c)
 
 	var req models.UpdateAppTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -163,8 +202,12 @@ func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
 		case err.Error() == "access denied: template belongs to another user":
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case err.Error() == "template is managed by catalog and read-only":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		case err.Error() == "template name '"+req.Name+"' already exists":
 			c.JSON(http.StatusConflict, gin.H{"error": "Template name already exists"})
+		case strings.HasPrefix(err.Error(), "invalid"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update template"})
 		}
@@ -190,8 +233,7 @@ func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
 		return
 	}
 
-	// 移除权限检查，所有用户都被视为管理员
-	isAdmin := true
+	isAdmin := middleware.IsAdmin(c)
 
 	err = h.templateService.DeleteTemplate(c.Request.Context(), id, userID, isAdmin)
 	if err != nil {
@@ -203,6 +245,8 @@ func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
 		case err.Error() == "access denied: template belongs to another user":
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case err.Error() == "template is managed by catalog and read-only":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		case err.Error()[:32] == "template is being used by":
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		default:
@@ -223,7 +267,13 @@ func (h *TemplateHandler) GetTemplateVariables(c *gin.Context) {
 		return
 	}
 
-	variables, err := h.templateService.GetTemplateVariables(c.Request.Context(), id)
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	variables, err := h.templateService.GetTemplateVariables(c.Request.Context(), id, userID, middleware.IsAdmin(c))
 	if err != nil {
 		if err.Error() == "template not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
@@ -248,25 +298,56 @@ func (h *TemplateHandler) PreviewTemplate(c *gin.Context) {
 		return
 	}
 
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
 	// 解析变量参数
 	var variables map[string]string
 	if err := c.ShouldBindJSON(&variables); err != nil {
 		// 如果没有传递变量，使用默认示例变量
-		variables = map[string]string{
-			"PATH":            "example-path",
-			"SERVICE_NAME":    "example-service",
-			"DEPLOYMENT_NAME": "example-deployment",
-			"PROJECT_NAME":    "example-project",
-			"UUID":            "abc12345",
+		variables = services.DefaultPreviewVariables()
+	}
+
+	// dry_run=true 时通过API Server校验渲染结果，不创建任何资源
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if dryRun {
+		processedYAML, result, err := h.templateService.DryRunTemplate(c.Request.Context(), id, userID, middleware.IsAdmin(c), variables)
+		if err != nil {
+			switch {
+			case err.Error() == "template not found":
+				c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			case strings.HasPrefix(err.Error(), "kustomize build failed"):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case err.Error() == "kubernetes resource manager not available":
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes cluster not available for dry-run"})
+			default:
+				logrus.WithError(err).Error("Failed to dry-run template")
+				c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			}
+			return
 		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"processed_yaml": processedYAML,
+			"variables":      variables,
+			"dry_run":        result,
+		})
+		return
 	}
 
-	processedYAML, err := h.templateService.ProcessTemplate(c.Request.Context(), id, variables)
+	processedYAML, err := h.templateService.ProcessTemplate(c.Request.Context(), id, userID, middleware.IsAdmin(c), variables)
 	if err != nil {
 		if err.Error() == "template not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
 			return
 		}
+		if strings.HasPrefix(err.Error(), "kustomize build failed") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		logrus.WithError(err).Error("Failed to process template")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process template"})
 		return
@@ -277,3 +358,146 @@ func (h *TemplateHandler) PreviewTemplate(c *gin.Context) {
 		"variables":      variables,
 	})
 }
+
+// SetTemplateFeatured 设置模版推荐标记（仅管理员）
+func (h *TemplateHandler) SetTemplateFeatured(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	var req models.SetTemplateFeaturedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.templateService.SetTemplateFeatured(c.Request.Context(), id, req.Featured)
+	if err != nil {
+		if err.Error() == "template not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			return
+		}
+		logrus.WithError(err).Error("Failed to set template featured flag")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set template featured flag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// ExportTemplates 导出模版包，ids为逗号分隔的模版ID，为空时导出全部模版
+func (h *TemplateHandler) ExportTemplates(c *gin.Context) {
+	var ids []uuid.UUID
+	if idsParam := c.Query("ids"); idsParam != "" {
+		for _, idStr := range strings.Split(idsParam, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(idStr))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+				return
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	h.exportTemplates(c, ids, "templates.yaml")
+}
+
+// ExportTemplate 导出单个模版
+func (h *TemplateHandler) ExportTemplate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	h.exportTemplates(c, []uuid.UUID{id}, "template-"+id.String()+".yaml")
+}
+
+// exportTemplates 以YAML附件形式返回模版包
+func (h *TemplateHandler) exportTemplates(c *gin.Context, ids []uuid.UUID, filename string) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	data, err := h.templateService.ExportTemplates(c.Request.Context(), userID, middleware.IsAdmin(c), ids)
+	if err != nil {
+		switch err.Error() {
+		case "template not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		case "no templates to export":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			logrus.WithError(err).Error("Failed to export templates")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export templates"})
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Data(http.StatusOK, "application/x-yaml", data)
+}
+
+// ImportTemplates 导入模版包，请求体为YAML或JSON格式的模版包（仅管理员，导入的模版对组织内所有用户可见）
+func (h *TemplateHandler) ImportTemplates(c *gin.Context) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	overwrite, _ := strconv.ParseBool(c.DefaultQuery("overwrite", "false"))
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxTemplateBundleSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	if len(body) > maxTemplateBundleSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Template bundle is too large"})
+		return
+	}
+
+	bundle, err := utils.DecodeTemplateBundle(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.templateService.ImportTemplates(c.Request.Context(), userID, bundle, overwrite)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to import templates")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SyncCatalog 手动触发Git模版目录同步（仅管理员）
+func (h *TemplateHandler) SyncCatalog(c *gin.Context) {
+	result, err := h.catalogService.SyncCatalog(c.Request.Context())
+	if err != nil {
+		switch {
+		case err.Error() == "template catalog is not configured":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case err.Error() == "template catalog sync already in progress":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case strings.HasPrefix(err.Error(), "failed to clone"), strings.HasPrefix(err.Error(), "failed to open"),
+			strings.HasPrefix(err.Error(), "failed to resolve"), strings.HasPrefix(err.Error(), "catalog path"):
+			logrus.WithError(err).Error("Failed to read template catalog")
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		default:
+			logrus.WithError(err).Error("Failed to sync template catalog")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync template catalog"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
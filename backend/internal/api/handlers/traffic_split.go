@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"url-manager-system/backend/internal/api/middleware"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// TrafficSplitHandler 流量切分处理器
+type TrafficSplitHandler struct {
+	trafficSplitService *services.TrafficSplitService
+}
+
+// NewTrafficSplitHandler 创建流量切分处理器
+func NewTrafficSplitHandler(trafficSplitService *services.TrafficSplitService) *TrafficSplitHandler {
+	return &TrafficSplitHandler{
+		trafficSplitService: trafficSplitService,
+	}
+}
+
+// CreateTrafficSplit 创建流量切分
+func (h *TrafficSplitHandler) CreateTrafficSplit(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	var req models.CreateTrafficSplitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	split, err := h.trafficSplitService.CreateSplit(c.Request.Context(), projectID, userID, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create traffic split")
+		h.writeError(c, err, "Failed to create traffic split")
+		return
+	}
+
+	c.JSON(http.StatusCreated, split)
+}
+
+// ListTrafficSplits 列出项目的流量切分
+func (h *TrafficSplitHandler) ListTrafficSplits(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	splits, err := h.trafficSplitService.ListSplits(c.Request.Context(), projectID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list traffic splits")
+		h.writeError(c, err, "Failed to list traffic splits")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListTrafficSplitsResponse{
+		TrafficSplits: splits,
+		Total:         len(splits),
+	})
+}
+
+// UpdateTrafficSplit 调整流量切分的权重
+func (h *TrafficSplitHandler) UpdateTrafficSplit(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid traffic split ID"})
+		return
+	}
+
+	var req models.UpdateTrafficSplitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	split, err := h.trafficSplitService.UpdateSplit(c.Request.Context(), id, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update traffic split")
+		h.writeError(c, err, "Failed to update traffic split")
+		return
+	}
+
+	c.JSON(http.StatusOK, split)
+}
+
+// DeleteTrafficSplit 删除流量切分，全部流量回到主URL
+func (h *TrafficSplitHandler) DeleteTrafficSplit(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid traffic split ID"})
+		return
+	}
+
+	if err := h.trafficSplitService.DeleteSplit(c.Request.Context(), id); err != nil {
+		logrus.WithError(err).Error("Failed to delete traffic split")
+		h.writeError(c, err, "Failed to delete traffic split")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// writeError 根据错误类型返回不同的状态码
+func (h *TrafficSplitHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case err.Error() == "project not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case err.Error() == "traffic split not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Traffic split not found"})
+	case err.Error() == "URL not found":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL not found in this project"})
+	case strings.HasPrefix(err.Error(), "invalid traffic split"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "already exists"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"url-manager-system/backend/internal/api/middleware"
 	"url-manager-system/backend/internal/db/models"
 	"url-manager-system/backend/internal/services"
 
@@ -51,13 +52,18 @@ func (h *URLHandler) CreateEphemeralURL(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 		case err.Error() == "validation failed: image not in allowed list":
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Image not allowed"})
+		case strings.HasPrefix(err.Error(), "invalid ingress host"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.HasSuffix(err.Error(), "is already used by another URL"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create ephemeral URL"})
 		}
 		return
 	}
 
-	c.JSON(http.StatusCreated, response)
+	// Kubernetes资源由异步操作创建，通过operation_id查询进度
+	c.JSON(http.StatusAccepted, response)
 }
 
 // GetEphemeralURL 获取临时URL
@@ -130,18 +136,22 @@ func (h *URLHandler) DeleteEphemeralURL(c *gin.Context) {
 		return
 	}
 
-	err = h.cleanupService.ForceCleanupURL(c.Request.Context(), id)
+	op, err := h.urlService.DeleteEphemeralURL(c.Request.Context(), id)
 	if err != nil {
 		if err.Error() == "URL not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
 			return
 		}
+		if err.Error() == "URL belongs to a stack and can only be deleted with the stack" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		logrus.WithError(err).Error("Failed to delete ephemeral URL")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete URL"})
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	c.JSON(http.StatusAccepted, op)
 }
 
 // GetURLByPath 根据路径获取URL（用于内部查询）
@@ -167,15 +177,26 @@ func (h *URLHandler) DeployURL(c *gin.Context) {
 		return
 	}
 
-	err = h.urlService.DeployURL(c.Request.Context(), urlID)
+	op, err := h.urlService.DeployURL(c.Request.Context(), urlID)
 	if err != nil {
 		logrus.WithError(err).WithField("url_id", urlID).Error("Failed to deploy URL")
+		if strings.HasPrefix(err.Error(), "invalid ingress host") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.HasSuffix(err.Error(), "is already used by another URL") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	logrus.WithField("url_id", urlID).Info("URL deployment initiated")
-	c.JSON(http.StatusOK, gin.H{"message": "URL deployment initiated successfully"})
+	logrus.WithFields(logrus.Fields{
+		"url_id":       urlID,
+		"operation_id": op.ID,
+	}).Info("URL deployment initiated")
+	c.JSON(http.StatusAccepted, op)
 }
 
 // ValidateAndCleanupData 校验并清理数据
@@ -200,13 +221,19 @@ func (h *URLHandler) CreateEphemeralURLFromTemplate(c *gin.Context) {
 		return
 	}
 
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
 	var req models.CreateEphemeralURLFromTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	response, err := h.urlService.CreateEphemeralURLFromTemplate(c.Request.Context(), projectID, &req)
+	response, err := h.urlService.CreateEphemeralURLFromTemplate(c.Request.Context(), projectID, userID, middleware.IsAdmin(c), &req)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to create ephemeral URL from template")
 
@@ -216,6 +243,8 @@ func (h *URLHandler) CreateEphemeralURLFromTemplate(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 		case err.Error() == "template not found":
 			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		case err.Error() == "template is not available in this project":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		case err.Error()[:4] == "path":
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		default:
@@ -224,7 +253,8 @@ func (h *URLHandler) CreateEphemeralURLFromTemplate(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, response)
+	// Kubernetes资源由异步操作创建，通过operation_id查询进度
+	c.JSON(http.StatusAccepted, response)
 }
 
 // UpdateEphemeralURL 更新临时URL
@@ -242,7 +272,7 @@ func (h *URLHandler) UpdateEphemeralURL(c *gin.Context) {
 		return
 	}
 
-	url, err := h.urlService.UpdateEphemeralURL(c.Request.Context(), id, &req)
+	op, err := h.urlService.UpdateEphemeralURL(c.Request.Context(), id, &req)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to update ephemeral URL")
 
@@ -250,15 +280,17 @@ func (h *URLHandler) UpdateEphemeralURL(c *gin.Context) {
 		switch {
 		case err.Error() == "URL not found":
 			c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
-		case strings.Contains(err.Error(), "validation failed"):
+		case strings.Contains(err.Error(), "validation failed"), strings.HasPrefix(err.Error(), "invalid ingress host"):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.HasSuffix(err.Error(), "is already used by another URL"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update ephemeral URL"})
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, url)
+	c.JSON(http.StatusAccepted, op)
 }
 
 // GetURLContainerStatus 获取URL容器状态
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"url-manager-system/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// idempotencyKeyHeader 客户端传入的幂等键请求头
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength 幂等键的最大长度
+const maxIdempotencyKeyLength = 255
+
+// idempotencyResponseWriter 记录响应体，请求完成后保存用于重放
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency 幂等键中间件，必须在认证中间件之后使用
+// 请求带有Idempotency-Key时，同一用户在保留时间内使用相同键和相同请求重复提交会收到第一次的响应，
+// 相同键用于不同请求返回409。服务端错误不保存响应，客户端可以使用同一个键重试。
+func Idempotency(idempotencyService *services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			c.Abort()
+			return
+		}
+
+		userID, err := GetCurrentUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+			c.Abort()
+			return
+		}
+		scope := userID.String()
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// 请求摘要包含方法和路径，同一个键不能用于不同的接口
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		ctx := c.Request.Context()
+		record, err := idempotencyService.Begin(ctx, scope, key, requestHash)
+		if err != nil {
+			switch err.Error() {
+			case "idempotency key already used with a different request", "idempotency key already used by a request in progress":
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				c.Abort()
+			default:
+				// Redis不可用时不阻塞请求，按没有幂等键处理
+				logrus.WithError(err).Warn("Idempotency check unavailable, processing request without it")
+				c.Next()
+			}
+			return
+		}
+
+		// 重放第一次请求的响应
+		if record != nil {
+			c.Header("Idempotent-Replayed", "true")
+			if len(record.Body) > 0 {
+				c.Data(record.StatusCode, "application/json; charset=utf-8", record.Body)
+			} else {
+				c.Status(record.StatusCode)
+			}
+			c.Abort()
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+
+		// 客户端断开后请求上下文会被取消，保存结果使用不随请求取消的上下文；处理器panic时同样释放幂等键
+		defer func() {
+			storeCtx := context.WithoutCancel(ctx)
+			if recovered := recover(); recovered != nil {
+				if err := idempotencyService.Release(storeCtx, scope, key); err != nil {
+					logrus.WithError(err).Warn("Failed to release idempotency key")
+				}
+				panic(recovered)
+			}
+
+			status := writer.Status()
+			if status >= http.StatusInternalServerError {
+				if err := idempotencyService.Release(storeCtx, scope, key); err != nil {
+					logrus.WithError(err).Warn("Failed to release idempotency key")
+				}
+				return
+			}
+			if err := idempotencyService.Complete(storeCtx, scope, key, requestHash, status, writer.body.Bytes()); err != nil {
+				logrus.WithError(err).Warn("Failed to store idempotent response")
+			}
+		}()
+
+		c.Next()
+	}
+}
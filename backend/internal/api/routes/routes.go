@@ -35,6 +35,7 @@ func SetupRoutes(serviceContainer *services.Container) *gin.Engine {
 	{
 		// 公开路由（不需要认证）
 		setupAuthRoutes(api, serviceContainer)
+		setupWebhookRoutes(api, serviceContainer)
 
 		// 需要认证的路由
 		authorized := api.Group("")
@@ -42,8 +43,15 @@ func SetupRoutes(serviceContainer *services.Container) *gin.Engine {
 		{
 			setupProjectRoutes(authorized, serviceContainer)
 			setupURLRoutes(authorized, serviceContainer)
+			setupStackRoutes(authorized, serviceContainer)
+			setupGitWebhookRoutes(authorized, serviceContainer)
+			setupAliasRoutes(authorized, serviceContainer)
+			setupDomainRoutes(authorized, serviceContainer)
+			setupTrafficSplitRoutes(authorized, serviceContainer)
+			setupOperationRoutes(authorized, serviceContainer)
 			setupTemplateRoutes(authorized, serviceContainer)
 			setupUserRoutes(authorized, serviceContainer)
+			setupAdminRoutes(authorized, serviceContainer)
 		}
 	}
 
@@ -62,12 +70,38 @@ func setupProjectRoutes(api *gin.RouterGroup, serviceContainer *services.Contain
 		projects.PUT("/:id", projectHandler.UpdateProject)
 		projects.DELETE("/:id", projectHandler.DeleteProject)
 
-		// 项目下的URL管理
+		// 项目下的URL管理，创建接口支持Idempotency-Key
 		urlHandler := handlers.NewURLHandler(serviceContainer.URLService, serviceContainer.CleanupService)
-		projects.POST("/:id/urls", urlHandler.CreateEphemeralURL)
-		projects.POST("/:id/urls/from-template", urlHandler.CreateEphemeralURLFromTemplate)
+		idempotency := middleware.Idempotency(serviceContainer.IdempotencyService)
+		projects.POST("/:id/urls", idempotency, urlHandler.CreateEphemeralURL)
+		projects.POST("/:id/urls/from-template", idempotency, urlHandler.CreateEphemeralURLFromTemplate)
 		projects.GET("/:id/urls", urlHandler.ListEphemeralURLs)
 
+		// 项目下的环境管理
+		stackHandler := handlers.NewStackHandler(serviceContainer.StackService)
+		projects.POST("/:id/stacks", stackHandler.CreateStack)
+		projects.GET("/:id/stacks", stackHandler.ListStacks)
+
+		// 项目下的Git webhook管理
+		gitWebhookHandler := handlers.NewGitWebhookHandler(serviceContainer.GitWebhookService)
+		projects.POST("/:id/git-webhooks", gitWebhookHandler.CreateGitWebhook)
+		projects.GET("/:id/git-webhooks", gitWebhookHandler.ListGitWebhooks)
+
+		// 项目下的别名管理
+		aliasHandler := handlers.NewAliasHandler(serviceContainer.AliasService)
+		projects.POST("/:id/aliases", aliasHandler.CreateAlias)
+		projects.GET("/:id/aliases", aliasHandler.ListAliases)
+
+		// 项目下的自定义域名管理
+		domainHandler := handlers.NewDomainHandler(serviceContainer.DomainService)
+		projects.POST("/:id/domains", domainHandler.CreateDomain)
+		projects.GET("/:id/domains", domainHandler.ListDomains)
+
+		// 项目下的流量切分管理
+		trafficSplitHandler := handlers.NewTrafficSplitHandler(serviceContainer.TrafficSplitService)
+		projects.POST("/:id/traffic-splits", trafficSplitHandler.CreateTrafficSplit)
+		projects.GET("/:id/traffic-splits", trafficSplitHandler.ListTrafficSplits)
+
 		// 项目统计
 		projects.GET("/stats", projectHandler.GetProjectStats)
 	}
@@ -76,13 +110,16 @@ func setupProjectRoutes(api *gin.RouterGroup, serviceContainer *services.Contain
 // setupURLRoutes 设置URL路由
 func setupURLRoutes(api *gin.RouterGroup, serviceContainer *services.Container) {
 	urlHandler := handlers.NewURLHandler(serviceContainer.URLService, serviceContainer.CleanupService)
+	idempotency := middleware.Idempotency(serviceContainer.IdempotencyService)
 
 	urls := api.Group("/urls")
 	{
 		urls.GET("/:id", urlHandler.GetEphemeralURL)
 		urls.PUT("/:id", urlHandler.UpdateEphemeralURL)
-		urls.DELETE("/:id", urlHandler.DeleteEphemeralURL)
-		urls.POST("/:id/deploy", urlHandler.DeployURL)
+		urls.DELETE("/:id", idempotency, urlHandler.DeleteEphemeralURL)
+		urls.POST("/:id/deploy", idempotency, urlHandler.DeployURL)
+		urls.GET("/:id/revisions", urlHandler.ListURLRevisions)
+		urls.POST("/:id/rollback", idempotency, urlHandler.RollbackURL)
 		urls.POST("/validate-cleanup", urlHandler.ValidateAndCleanupData)
 
 		// 容器状态、事件和日志相关API
@@ -90,23 +127,120 @@ func setupURLRoutes(api *gin.RouterGroup, serviceContainer *services.Container)
 		urls.GET("/:id/events", urlHandler.GetURLPodEvents)
 		urls.GET("/:id/logs", urlHandler.GetURLContainerLogs)
 
+		// 配置漂移检测
+		driftHandler := handlers.NewDriftHandler(serviceContainer.DriftService)
+		urls.GET("/:id/drift", driftHandler.GetURLDrift)
+
 		// urls.GET("/path/:path", urlHandler.GetURLByPath) // 可选：根据路径查询
 	}
 }
 
+// setupStackRoutes 设置环境路由
+func setupStackRoutes(api *gin.RouterGroup, serviceContainer *services.Container) {
+	stackHandler := handlers.NewStackHandler(serviceContainer.StackService)
+
+	stacks := api.Group("/stacks")
+	{
+		stacks.GET("/:id", stackHandler.GetStack)
+		stacks.DELETE("/:id", stackHandler.DeleteStack)
+	}
+}
+
+// setupGitWebhookRoutes 设置Git webhook管理路由
+func setupGitWebhookRoutes(api *gin.RouterGroup, serviceContainer *services.Container) {
+	gitWebhookHandler := handlers.NewGitWebhookHandler(serviceContainer.GitWebhookService)
+
+	webhooks := api.Group("/git-webhooks")
+	{
+		webhooks.DELETE("/:id", gitWebhookHandler.DeleteGitWebhook)
+	}
+}
+
+// setupAliasRoutes 设置别名路由
+func setupAliasRoutes(api *gin.RouterGroup, serviceContainer *services.Container) {
+	aliasHandler := handlers.NewAliasHandler(serviceContainer.AliasService)
+
+	aliases := api.Group("/aliases")
+	{
+		aliases.PUT("/:id", aliasHandler.UpdateAlias)
+		aliases.DELETE("/:id", aliasHandler.DeleteAlias)
+	}
+}
+
+// setupDomainRoutes 设置自定义域名路由
+func setupDomainRoutes(api *gin.RouterGroup, serviceContainer *services.Container) {
+	domainHandler := handlers.NewDomainHandler(serviceContainer.DomainService)
+
+	domains := api.Group("/domains")
+	{
+		domains.POST("/:id/verify", domainHandler.VerifyDomain)
+		domains.DELETE("/:id", domainHandler.DeleteDomain)
+	}
+}
+
+// setupTrafficSplitRoutes 设置流量切分路由
+func setupTrafficSplitRoutes(api *gin.RouterGroup, serviceContainer *services.Container) {
+	trafficSplitHandler := handlers.NewTrafficSplitHandler(serviceContainer.TrafficSplitService)
+
+	splits := api.Group("/traffic-splits")
+	{
+		splits.PUT("/:id", trafficSplitHandler.UpdateTrafficSplit)
+		splits.DELETE("/:id", trafficSplitHandler.DeleteTrafficSplit)
+	}
+}
+
+// setupOperationRoutes 设置异步操作路由
+func setupOperationRoutes(api *gin.RouterGroup, serviceContainer *services.Container) {
+	operationHandler := handlers.NewOperationHandler(serviceContainer.OperationService)
+
+	operations := api.Group("/operations")
+	{
+		operations.GET("/:id", operationHandler.GetOperation)
+	}
+}
+
+// setupAdminRoutes 设置管理员运维路由
+func setupAdminRoutes(api *gin.RouterGroup, serviceContainer *services.Container) {
+	gcHandler := handlers.NewGCHandler(serviceContainer.GCService)
+
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/gc", gcHandler.RunGC)
+	}
+}
+
+// setupWebhookRoutes 设置外部系统回调路由（不需要登录，由各处理器自行校验签名）
+func setupWebhookRoutes(api *gin.RouterGroup, serviceContainer *services.Container) {
+	gitWebhookHandler := handlers.NewGitWebhookHandler(serviceContainer.GitWebhookService)
+	registryWebhookHandler := handlers.NewRegistryWebhookHandler(serviceContainer.RegistryWebhookService)
+
+	webhooks := api.Group("/webhooks")
+	webhooks.Use(middleware.RequestSize(1 << 20)) // 1MB
+	{
+		webhooks.POST("/git/:id", gitWebhookHandler.ReceiveGitWebhook)
+		webhooks.POST("/registry", registryWebhookHandler.ReceiveRegistryWebhook)
+	}
+}
+
 // setupTemplateRoutes 设置模版路由
 func setupTemplateRoutes(api *gin.RouterGroup, serviceContainer *services.Container) {
-	templateHandler := handlers.NewTemplateHandler(serviceContainer.TemplateService)
+	templateHandler := handlers.NewTemplateHandler(serviceContainer.TemplateService, serviceContainer.CatalogService)
 
 	templates := api.Group("/templates")
 	{
 		templates.POST("", templateHandler.CreateTemplate)
 		templates.GET("", templateHandler.ListTemplates)
+		templates.GET("/export", templateHandler.ExportTemplates)
+		templates.POST("/import", middleware.AdminMiddleware(), templateHandler.ImportTemplates)
+		templates.POST("/catalog/sync", middleware.AdminMiddleware(), templateHandler.SyncCatalog)
 		templates.GET("/:id", templateHandler.GetTemplate)
 		templates.PUT("/:id", templateHandler.UpdateTemplate)
 		templates.DELETE("/:id", templateHandler.DeleteTemplate)
 		templates.GET("/:id/variables", templateHandler.GetTemplateVariables)
 		templates.POST("/:id/preview", templateHandler.PreviewTemplate)
+		templates.GET("/:id/export", templateHandler.ExportTemplate)
+		templates.PUT("/:id/featured", middleware.AdminMiddleware(), templateHandler.SetTemplateFeatured)
 	}
 }
 
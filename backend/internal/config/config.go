@@ -1,21 +1,30 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Debug       bool           `mapstructure:"debug"`
-	Environment string         `mapstructure:"environment"`
-	Server      ServerConfig   `mapstructure:"server"`
-	Database    DatabaseConfig `mapstructure:"database"`
-	Redis       RedisConfig    `mapstructure:"redis"`
-	K8s         K8sConfig      `mapstructure:"k8s"`
-	Security    SecurityConfig `mapstructure:"security"`
+	Debug       bool              `mapstructure:"debug"`
+	Environment string            `mapstructure:"environment"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Redis       RedisConfig       `mapstructure:"redis"`
+	K8s         K8sConfig         `mapstructure:"k8s"`
+	Security    SecurityConfig    `mapstructure:"security"`
+	Catalog     CatalogConfig     `mapstructure:"catalog"`
+	Operations  OperationsConfig  `mapstructure:"operations"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	GC          GCConfig          `mapstructure:"gc"`
+	Drift       DriftConfig       `mapstructure:"drift"`
+	Rollout     RolloutConfig     `mapstructure:"rollout"`
+	Readiness   ReadinessConfig   `mapstructure:"readiness"`
 }
 
 type ServerConfig struct {
@@ -48,6 +57,21 @@ type K8sConfig struct {
 	ConfigPath    string `mapstructure:"config_path"`
 	DefaultDomain string `mapstructure:"default_domain"`
 	IngressClass  string `mapstructure:"ingress_class"`
+	// IngressProvider 路由提供者：nginx、traefik或gateway-api，为空时根据ingress_class推断
+	IngressProvider string `mapstructure:"ingress_provider"`
+	// Gateway API模式下HTTPRoute挂载的Gateway，命名空间为空时与URL资源相同
+	GatewayName      string `mapstructure:"gateway_name"`
+	GatewayNamespace string `mapstructure:"gateway_namespace"`
+	// WildcardDomain 子域名路由模式使用的泛域名，URL访问地址为 <路径>.<项目>.<泛域名>
+	WildcardDomain string `mapstructure:"wildcard_domain"`
+	// TLSSecretName 证书模式为secret的项目默认引用的证书Secret（通常为泛域名证书）
+	TLSSecretName string `mapstructure:"tls_secret_name"`
+	// CertManagerIssuer 证书模式为cert-manager的项目使用的ClusterIssuer
+	CertManagerIssuer string `mapstructure:"cert_manager_issuer"`
+	// 别名目标URL被清理后的兜底页面：指定已有的Service，或由系统部署内置页面
+	AliasFallbackService string `mapstructure:"alias_fallback_service"`
+	AliasFallbackImage   string `mapstructure:"alias_fallback_image"`
+	AliasFallbackHTML    string `mapstructure:"alias_fallback_html"`
 }
 
 type SecurityConfig struct {
@@ -57,6 +81,52 @@ type SecurityConfig struct {
 	MaxTTLSeconds   int      `mapstructure:"max_ttl_seconds"`
 	DefaultCPULimit string   `mapstructure:"default_cpu_limit"`
 	DefaultMemLimit string   `mapstructure:"default_mem_limit"`
+	// RegistryWebhookToken 镜像仓库webhook的认证令牌，为空时不接收镜像仓库通知
+	RegistryWebhookToken string `mapstructure:"registry_webhook_token"`
+}
+
+// CatalogConfig Git模版目录配置
+type CatalogConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Repository   string        `mapstructure:"repository"`    // 本地仓库路径（支持bare仓库）或远程URL
+	Branch       string        `mapstructure:"branch"`        // 为空时使用HEAD
+	Path         string        `mapstructure:"path"`          // 仓库内模版包所在目录
+	Token        string        `mapstructure:"token"`         // 远程仓库访问令牌
+	SyncInterval time.Duration `mapstructure:"sync_interval"` // 为0时只能手动同步
+}
+
+// OperationsConfig 异步操作执行配置
+type OperationsConfig struct {
+	Workers     int `mapstructure:"workers"`      // 并发执行操作的工作线程数
+	MaxAttempts int `mapstructure:"max_attempts"` // 每个操作的最大尝试次数，失败后按指数退避重试
+}
+
+// IdempotencyConfig 幂等键配置
+type IdempotencyConfig struct {
+	TTL time.Duration `mapstructure:"ttl"` // 幂等键和缓存响应的保留时间
+}
+
+// GCConfig 孤儿Kubernetes资源回收配置
+type GCConfig struct {
+	Interval    time.Duration `mapstructure:"interval"`     // 自动回收间隔，为0时只能通过管理接口触发
+	GracePeriod time.Duration `mapstructure:"grace_period"` // 资源创建后超过该时间才会被判定为孤儿
+}
+
+// DriftConfig 配置漂移检测配置
+type DriftConfig struct {
+	Interval time.Duration `mapstructure:"interval"` // 检查自愈项目下URL的间隔，为0时关闭自愈，仍可通过接口查看漂移
+}
+
+// RolloutConfig 活跃URL更新的健康检查配置
+type RolloutConfig struct {
+	Timeout          time.Duration `mapstructure:"timeout"`           // 更新未在该时间内完成时自动回滚，为0时不监控更新
+	RestartThreshold int32         `mapstructure:"restart_threshold"` // 新Pod的容器重启达到该次数时自动回滚，为0时不按重启次数判断
+}
+
+// ReadinessConfig 新部署Pod就绪的等待配置
+type ReadinessConfig struct {
+	StartupTimeout    time.Duration `mapstructure:"startup_timeout"`     // URL未设置startup_timeout_seconds时等待Pod就绪的时间
+	MaxStartupTimeout time.Duration `mapstructure:"max_startup_timeout"` // 允许URL设置的最长启动超时
 }
 
 func Load() (*Config, error) {
@@ -95,9 +165,35 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	if err := config.K8s.resolveIngressProvider(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
+// resolveIngressProvider 校验路由提供者，未配置时根据ingress_class推断
+func (c *K8sConfig) resolveIngressProvider() error {
+	if c.IngressProvider == "" {
+		c.IngressProvider = "nginx"
+		if strings.Contains(c.IngressClass, "traefik") {
+			c.IngressProvider = "traefik"
+		}
+	}
+
+	switch c.IngressProvider {
+	case "nginx", "traefik":
+		return nil
+	case "gateway-api":
+		if c.GatewayName == "" {
+			return fmt.Errorf("k8s.gateway_name is required when ingress_provider is gateway-api")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid k8s.ingress_provider %q: must be nginx, traefik or gateway-api", c.IngressProvider)
+	}
+}
+
 func setDefaults() {
 	// Server配置
 	viper.SetDefault("debug", false)
@@ -127,6 +223,18 @@ func setDefaults() {
 	viper.SetDefault("k8s.config_path", "")
 	viper.SetDefault("k8s.default_domain", "example.com")
 	viper.SetDefault("k8s.ingress_class", "traefik")
+	viper.SetDefault("k8s.ingress_provider", "")
+	viper.SetDefault("k8s.gateway_name", "")
+	viper.SetDefault("k8s.gateway_namespace", "")
+	viper.SetDefault("k8s.wildcard_domain", "")
+	viper.SetDefault("k8s.tls_secret_name", "")
+	viper.SetDefault("k8s.cert_manager_issuer", "")
+	viper.SetDefault("k8s.alias_fallback_service", "")
+	viper.SetDefault("k8s.alias_fallback_image", "nginxinc/nginx-unprivileged:alpine")
+	viper.SetDefault("k8s.alias_fallback_html", `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>环境已清理</title></head>
+<body><h1>该环境已被清理</h1><p>此别名当前没有指向任何URL，请联系项目负责人重新指向新的环境。</p></body></html>
+`)
 
 	// Security配置
 	viper.SetDefault("security.jwt_secret", "")
@@ -135,6 +243,37 @@ func setDefaults() {
 	viper.SetDefault("security.max_ttl_seconds", 86400*7) // 7天
 	viper.SetDefault("security.default_cpu_limit", "500m")
 	viper.SetDefault("security.default_mem_limit", "512Mi")
+	viper.SetDefault("security.registry_webhook_token", "")
+
+	// Catalog配置
+	viper.SetDefault("catalog.enabled", false)
+	viper.SetDefault("catalog.repository", "")
+	viper.SetDefault("catalog.branch", "")
+	viper.SetDefault("catalog.path", "templates")
+	viper.SetDefault("catalog.token", "")
+	viper.SetDefault("catalog.sync_interval", 10*time.Minute)
+
+	// 异步操作配置
+	viper.SetDefault("operations.workers", 4)
+	viper.SetDefault("operations.max_attempts", 5)
+
+	// 幂等键配置
+	viper.SetDefault("idempotency.ttl", 24*time.Hour)
+
+	// 孤儿资源回收配置
+	viper.SetDefault("gc.interval", time.Hour)
+	viper.SetDefault("gc.grace_period", time.Hour)
+
+	// 配置漂移检测配置
+	viper.SetDefault("drift.interval", 10*time.Minute)
+
+	// 更新健康检查配置
+	viper.SetDefault("rollout.timeout", 10*time.Minute)
+	viper.SetDefault("rollout.restart_threshold", 3)
+
+	// 就绪等待配置
+	viper.SetDefault("readiness.startup_timeout", 15*time.Minute)
+	viper.SetDefault("readiness.max_startup_timeout", time.Hour)
 }
 
 func overrideWithEnv() {
@@ -198,7 +337,48 @@ func overrideWithEnv() {
 		viper.Set("k8s.default_domain", val)
 	}
 
+	if val := os.Getenv("INGRESS_PROVIDER"); val != "" {
+		viper.Set("k8s.ingress_provider", val)
+	}
+
+	if val := os.Getenv("GATEWAY_NAME"); val != "" {
+		viper.Set("k8s.gateway_name", val)
+	}
+
+	if val := os.Getenv("GATEWAY_NAMESPACE"); val != "" {
+		viper.Set("k8s.gateway_namespace", val)
+	}
+
+	if val := os.Getenv("WILDCARD_DOMAIN"); val != "" {
+		viper.Set("k8s.wildcard_domain", val)
+	}
+
+	if val := os.Getenv("TLS_SECRET_NAME"); val != "" {
+		viper.Set("k8s.tls_secret_name", val)
+	}
+
+	if val := os.Getenv("CERT_MANAGER_ISSUER"); val != "" {
+		viper.Set("k8s.cert_manager_issuer", val)
+	}
+
 	if val := os.Getenv("JWT_SECRET"); val != "" {
 		viper.Set("security.jwt_secret", val)
 	}
+
+	if val := os.Getenv("REGISTRY_WEBHOOK_TOKEN"); val != "" {
+		viper.Set("security.registry_webhook_token", val)
+	}
+
+	if val := os.Getenv("CATALOG_REPOSITORY"); val != "" {
+		viper.Set("catalog.enabled", true)
+		viper.Set("catalog.repository", val)
+	}
+
+	if val := os.Getenv("CATALOG_BRANCH"); val != "" {
+		viper.Set("catalog.branch", val)
+	}
+
+	if val := os.Getenv("CATALOG_TOKEN"); val != "" {
+		viper.Set("catalog.token", val)
+	}
 }
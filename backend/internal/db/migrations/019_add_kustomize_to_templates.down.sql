@@ -0,0 +1,7 @@
+-- 删除template_type字段的索引和约束
+DROP INDEX IF EXISTS idx_app_templates_template_type;
+ALTER TABLE app_templates DROP CONSTRAINT IF EXISTS chk_template_type;
+
+-- 删除模版类型和Kustomize包字段
+ALTER TABLE app_templates DROP COLUMN IF EXISTS kustomize_bundle;
+ALTER TABLE app_templates DROP COLUMN IF EXISTS template_type;
@@ -0,0 +1,10 @@
+-- 为app_templates表添加模版类型和Kustomize包字段
+ALTER TABLE app_templates ADD COLUMN template_type TEXT NOT NULL DEFAULT 'yaml';
+ALTER TABLE app_templates ADD COLUMN kustomize_bundle JSONB;
+
+-- 添加模版类型检查约束
+ALTER TABLE app_templates ADD CONSTRAINT chk_template_type
+    CHECK (template_type IN ('yaml', 'kustomize'));
+
+-- 创建template_type字段索引
+CREATE INDEX IF NOT EXISTS idx_app_templates_template_type ON app_templates(template_type);
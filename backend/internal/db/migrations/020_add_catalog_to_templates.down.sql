@@ -0,0 +1,8 @@
+-- 删除索引
+DROP INDEX IF EXISTS idx_app_templates_managed;
+
+-- 删除参数定义和Git模版目录管理字段
+ALTER TABLE app_templates DROP COLUMN IF EXISTS catalog_revision;
+ALTER TABLE app_templates DROP COLUMN IF EXISTS catalog_source;
+ALTER TABLE app_templates DROP COLUMN IF EXISTS managed;
+ALTER TABLE app_templates DROP COLUMN IF EXISTS parameters;
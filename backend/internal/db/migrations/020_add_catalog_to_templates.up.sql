@@ -0,0 +1,8 @@
+-- 为app_templates表添加参数定义和Git模版目录管理字段
+ALTER TABLE app_templates ADD COLUMN parameters JSONB;
+ALTER TABLE app_templates ADD COLUMN managed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE app_templates ADD COLUMN catalog_source TEXT;
+ALTER TABLE app_templates ADD COLUMN catalog_revision TEXT;
+
+-- 创建managed字段索引（目录同步时按managed查询）
+CREATE INDEX IF NOT EXISTS idx_app_templates_managed ON app_templates(managed);
@@ -0,0 +1,18 @@
+-- 删除索引
+DROP INDEX IF EXISTS idx_app_templates_tags;
+DROP INDEX IF EXISTS idx_app_templates_featured;
+DROP INDEX IF EXISTS idx_app_templates_category;
+DROP INDEX IF EXISTS idx_app_templates_project_id;
+DROP INDEX IF EXISTS idx_app_templates_visibility;
+
+-- 删除可见范围检查约束
+ALTER TABLE app_templates DROP CONSTRAINT IF EXISTS chk_template_visibility;
+
+-- 删除可见范围、分类标签、推荐标记和使用统计字段
+ALTER TABLE app_templates DROP COLUMN IF EXISTS last_used_at;
+ALTER TABLE app_templates DROP COLUMN IF EXISTS usage_count;
+ALTER TABLE app_templates DROP COLUMN IF EXISTS featured;
+ALTER TABLE app_templates DROP COLUMN IF EXISTS tags;
+ALTER TABLE app_templates DROP COLUMN IF EXISTS category;
+ALTER TABLE app_templates DROP COLUMN IF EXISTS project_id;
+ALTER TABLE app_templates DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,33 @@
+-- 为app_templates表添加可见范围、分类标签、推荐标记和使用统计字段
+ALTER TABLE app_templates ADD COLUMN visibility TEXT NOT NULL DEFAULT 'private';
+ALTER TABLE app_templates ADD COLUMN project_id UUID REFERENCES projects(id) ON DELETE SET NULL;
+ALTER TABLE app_templates ADD COLUMN category TEXT NOT NULL DEFAULT '';
+ALTER TABLE app_templates ADD COLUMN tags JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE app_templates ADD COLUMN featured BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE app_templates ADD COLUMN usage_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE app_templates ADD COLUMN last_used_at TIMESTAMP WITH TIME ZONE;
+
+-- 添加可见范围检查约束
+ALTER TABLE app_templates ADD CONSTRAINT chk_template_visibility
+    CHECK (visibility IN ('private', 'project', 'organization'));
+
+-- 已有模版保持仅创建者可见，目录管理的模版与目录同步时一致对所有用户可见
+UPDATE app_templates SET visibility = 'organization' WHERE managed = TRUE;
+
+-- 根据现有URL回填使用次数
+UPDATE app_templates t
+SET usage_count = u.count, last_used_at = u.last_used_at
+FROM (
+    SELECT template_id, COUNT(*) AS count, MAX(created_at) AS last_used_at
+    FROM ephemeral_urls
+    WHERE template_id IS NOT NULL
+    GROUP BY template_id
+) u
+WHERE t.id = u.template_id;
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_app_templates_visibility ON app_templates(visibility);
+CREATE INDEX IF NOT EXISTS idx_app_templates_project_id ON app_templates(project_id);
+CREATE INDEX IF NOT EXISTS idx_app_templates_category ON app_templates(category);
+CREATE INDEX IF NOT EXISTS idx_app_templates_featured ON app_templates(featured);
+CREATE INDEX IF NOT EXISTS idx_app_templates_tags ON app_templates USING GIN (tags);
@@ -0,0 +1,8 @@
+-- 删除ephemeral_urls表的环境字段
+DROP INDEX IF EXISTS idx_ephemeral_urls_stack_service;
+DROP INDEX IF EXISTS idx_ephemeral_urls_stack_id;
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS stack_service;
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS stack_id;
+
+-- 删除stacks表
+DROP TABLE IF EXISTS stacks;
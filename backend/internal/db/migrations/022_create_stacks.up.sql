@@ -0,0 +1,34 @@
+-- stacks表：项目下由多个URL组成的环境
+CREATE TABLE IF NOT EXISTS stacks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    services JSONB NOT NULL DEFAULT '[]',
+    env JSONB,
+    status TEXT NOT NULL DEFAULT 'deploying',
+    ttl_seconds INTEGER NOT NULL,
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE,
+    expire_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (project_id, name)
+);
+
+-- 为状态值添加检查约束
+ALTER TABLE stacks ADD CONSTRAINT chk_stack_status
+    CHECK (status IN ('draft', 'deploying', 'active', 'deleting', 'failed'));
+
+-- 为stacks表创建索引
+CREATE INDEX IF NOT EXISTS idx_stacks_project_id ON stacks(project_id);
+CREATE INDEX IF NOT EXISTS idx_stacks_status ON stacks(status);
+CREATE INDEX IF NOT EXISTS idx_stacks_expire_at ON stacks(expire_at);
+
+-- 为ephemeral_urls表添加所属环境及环境内服务名
+ALTER TABLE ephemeral_urls ADD COLUMN stack_id UUID REFERENCES stacks(id) ON DELETE CASCADE;
+ALTER TABLE ephemeral_urls ADD COLUMN stack_service TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_ephemeral_urls_stack_id ON ephemeral_urls(stack_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ephemeral_urls_stack_service ON ephemeral_urls(stack_id, stack_service) WHERE stack_id IS NOT NULL;
@@ -0,0 +1,10 @@
+-- 删除ephemeral_urls表的来源PR信息
+DROP INDEX IF EXISTS idx_ephemeral_urls_git_branch;
+DROP INDEX IF EXISTS idx_ephemeral_urls_git_pr;
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS git_commit;
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS git_branch;
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS git_pr_number;
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS git_repository;
+
+-- 删除git_webhooks表
+DROP TABLE IF EXISTS git_webhooks;
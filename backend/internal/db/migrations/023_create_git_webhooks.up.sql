@@ -0,0 +1,31 @@
+-- git_webhooks表：把Git仓库的PR事件映射到项目和模版
+CREATE TABLE IF NOT EXISTS git_webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    template_id UUID NOT NULL REFERENCES app_templates(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id),
+    provider TEXT NOT NULL,
+    repository TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    image_repository TEXT NOT NULL,
+    image_tag_format TEXT NOT NULL DEFAULT '{sha}',
+    ttl_seconds INTEGER NOT NULL DEFAULT 86400,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (project_id, repository)
+);
+
+-- 为提供方添加检查约束
+ALTER TABLE git_webhooks ADD CONSTRAINT chk_git_webhook_provider
+    CHECK (provider IN ('github', 'gitlab'));
+
+CREATE INDEX IF NOT EXISTS idx_git_webhooks_project_id ON git_webhooks(project_id);
+
+-- 为ephemeral_urls表添加来源PR信息
+ALTER TABLE ephemeral_urls ADD COLUMN git_repository TEXT;
+ALTER TABLE ephemeral_urls ADD COLUMN git_pr_number INTEGER;
+ALTER TABLE ephemeral_urls ADD COLUMN git_branch TEXT;
+ALTER TABLE ephemeral_urls ADD COLUMN git_commit TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_ephemeral_urls_git_pr ON ephemeral_urls(project_id, git_repository, git_pr_number);
+CREATE INDEX IF NOT EXISTS idx_ephemeral_urls_git_branch ON ephemeral_urls(project_id, git_repository, git_branch);
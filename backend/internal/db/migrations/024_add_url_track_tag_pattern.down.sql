@@ -0,0 +1,3 @@
+-- 删除ephemeral_urls表的镜像标签跟踪规则
+DROP INDEX IF EXISTS idx_ephemeral_urls_track_tag;
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS track_tag_pattern;
@@ -0,0 +1,4 @@
+-- 为ephemeral_urls表添加镜像标签跟踪规则，镜像仓库推送匹配的新标签时自动滚动更新
+ALTER TABLE ephemeral_urls ADD COLUMN track_tag_pattern TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_ephemeral_urls_track_tag ON ephemeral_urls(status) WHERE track_tag_pattern IS NOT NULL;
@@ -0,0 +1,2 @@
+-- 删除url_aliases表
+DROP TABLE IF EXISTS url_aliases;
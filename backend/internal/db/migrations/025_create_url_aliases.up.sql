@@ -0,0 +1,16 @@
+-- url_aliases表：项目内稳定的别名路径，可以重新指向不同的URL
+CREATE TABLE IF NOT EXISTS url_aliases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    path TEXT NOT NULL,
+    url_id UUID REFERENCES ephemeral_urls(id) ON DELETE SET NULL,
+    user_id UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (project_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_url_aliases_project_id ON url_aliases(project_id);
+CREATE INDEX IF NOT EXISTS idx_url_aliases_url_id ON url_aliases(url_id);
+
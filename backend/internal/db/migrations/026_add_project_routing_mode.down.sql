@@ -0,0 +1,3 @@
+-- 删除projects表的路由模式
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS chk_projects_routing_mode;
+ALTER TABLE projects DROP COLUMN IF EXISTS routing_mode;
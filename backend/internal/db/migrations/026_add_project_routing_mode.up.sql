@@ -0,0 +1,4 @@
+-- 为projects表添加路由模式：path（共享域名按路径区分）或subdomain（每个URL独立子域名）
+ALTER TABLE projects ADD COLUMN routing_mode VARCHAR(20) NOT NULL DEFAULT 'path';
+
+ALTER TABLE projects ADD CONSTRAINT chk_projects_routing_mode CHECK (routing_mode IN ('path', 'subdomain'));
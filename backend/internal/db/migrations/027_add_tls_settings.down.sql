@@ -0,0 +1,8 @@
+-- 删除TLS配置
+DROP INDEX IF EXISTS idx_ephemeral_urls_tls_pending;
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS tls_ready;
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS tls_secret_name;
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS chk_projects_tls_mode;
+ALTER TABLE projects DROP COLUMN IF EXISTS tls_secret_name;
+ALTER TABLE projects DROP COLUMN IF EXISTS tls_mode;
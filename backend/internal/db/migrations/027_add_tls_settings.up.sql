@@ -0,0 +1,11 @@
+-- 项目TLS配置：none（不配置证书）、secret（引用已有证书Secret）、cert-manager（通过cert-manager签发）
+ALTER TABLE projects ADD COLUMN tls_mode VARCHAR(20) NOT NULL DEFAULT 'none';
+ALTER TABLE projects ADD COLUMN tls_secret_name VARCHAR(253);
+
+ALTER TABLE projects ADD CONSTRAINT chk_projects_tls_mode CHECK (tls_mode IN ('none', 'secret', 'cert-manager'));
+
+-- URL使用的证书Secret及其是否已就绪，就绪前访问地址使用http
+ALTER TABLE ephemeral_urls ADD COLUMN tls_secret_name VARCHAR(253);
+ALTER TABLE ephemeral_urls ADD COLUMN tls_ready BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_ephemeral_urls_tls_pending ON ephemeral_urls(status) WHERE tls_secret_name IS NOT NULL AND tls_ready = false;
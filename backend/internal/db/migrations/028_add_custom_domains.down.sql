@@ -0,0 +1,2 @@
+-- 删除custom_domains表
+DROP TABLE IF EXISTS custom_domains;
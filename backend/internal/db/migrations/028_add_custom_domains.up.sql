@@ -0,0 +1,23 @@
+-- custom_domains表：项目登记的自定义域名，验证所有权后URL才能使用
+CREATE TABLE IF NOT EXISTS custom_domains (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    domain VARCHAR(253) NOT NULL,
+    verification_method VARCHAR(10) NOT NULL DEFAULT 'dns',
+    verification_token VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    last_checked_at TIMESTAMP WITH TIME ZONE,
+    user_id UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (project_id, domain),
+    CONSTRAINT chk_custom_domains_method CHECK (verification_method IN ('dns', 'http')),
+    CONSTRAINT chk_custom_domains_status CHECK (status IN ('pending', 'verified', 'failed'))
+);
+
+-- 同一域名只能被一个项目验证
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_domains_verified_domain ON custom_domains(domain) WHERE status = 'verified';
+CREATE INDEX IF NOT EXISTS idx_custom_domains_last_checked ON custom_domains(status, last_checked_at);
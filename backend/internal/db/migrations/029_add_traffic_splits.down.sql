@@ -0,0 +1,2 @@
+-- 删除traffic_splits表
+DROP TABLE IF EXISTS traffic_splits;
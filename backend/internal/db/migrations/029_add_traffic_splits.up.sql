@@ -0,0 +1,21 @@
+-- traffic_splits表：将主URL路由上的部分流量按权重转发给同项目的金丝雀URL
+CREATE TABLE IF NOT EXISTS traffic_splits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    primary_url_id UUID NOT NULL REFERENCES ephemeral_urls(id) ON DELETE CASCADE,
+    canary_url_id UUID NOT NULL REFERENCES ephemeral_urls(id) ON DELETE CASCADE,
+    canary_weight INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    reverted_at TIMESTAMP WITH TIME ZONE,
+    user_id UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_traffic_splits_weight CHECK (canary_weight BETWEEN 0 AND 100),
+    CONSTRAINT chk_traffic_splits_status CHECK (status IN ('active', 'reverted')),
+    CONSTRAINT chk_traffic_splits_urls CHECK (primary_url_id <> canary_url_id)
+);
+
+-- 每个URL的路由同时只能有一个生效的流量切分
+CREATE UNIQUE INDEX IF NOT EXISTS idx_traffic_splits_active_primary ON traffic_splits(primary_url_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_traffic_splits_canary ON traffic_splits(canary_url_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_traffic_splits_project_id ON traffic_splits(project_id);
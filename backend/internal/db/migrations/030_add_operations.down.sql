@@ -0,0 +1,2 @@
+-- 删除operations表
+DROP TABLE IF EXISTS operations;
@@ -0,0 +1,27 @@
+-- operations表：URL的异步操作，与URL记录在同一事务中写入，由后台工作线程执行Kubernetes部分并记录进度和结果
+-- url_id不设外键，删除操作完成后URL记录已被删除，操作结果仍可查询
+CREATE TABLE IF NOT EXISTS operations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(20) NOT NULL,
+    url_id UUID NOT NULL,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    progress VARCHAR(255),
+    payload JSONB NOT NULL DEFAULT '{}',
+    result JSONB,
+    error TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_operations_type CHECK (type IN ('create', 'update', 'delete', 'deploy')),
+    CONSTRAINT chk_operations_status CHECK (status IN ('pending', 'running', 'succeeded', 'failed'))
+);
+
+-- 工作线程按创建顺序领取未完成的操作，同一URL的操作依次执行
+CREATE INDEX IF NOT EXISTS idx_operations_unfinished ON operations(created_at) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_operations_url_id ON operations(url_id, created_at);
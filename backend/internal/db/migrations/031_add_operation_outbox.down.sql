@@ -0,0 +1,8 @@
+-- 移除操作来源和滚动更新操作类型
+DELETE FROM operations WHERE type = 'rollout';
+
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_source;
+ALTER TABLE operations DROP COLUMN IF EXISTS source;
+
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_type;
+ALTER TABLE operations ADD CONSTRAINT chk_operations_type CHECK (type IN ('create', 'update', 'delete', 'deploy'));
@@ -0,0 +1,9 @@
+-- operations表同时作为Kubernetes变更的outbox：清理任务和镜像webhook也在修改URL记录的同一事务中写入操作，
+-- 由工作线程幂等执行，进程在提交和执行之间退出时操作会在重启后继续执行
+ALTER TABLE operations ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'api';
+
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_type;
+ALTER TABLE operations ADD CONSTRAINT chk_operations_type CHECK (type IN ('create', 'update', 'delete', 'deploy', 'rollout'));
+
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_source;
+ALTER TABLE operations ADD CONSTRAINT chk_operations_source CHECK (source IN ('api', 'cleanup', 'webhook'));
@@ -0,0 +1,7 @@
+-- 删除配置漂移策略
+DELETE FROM operations WHERE source = 'drift';
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_source;
+ALTER TABLE operations ADD CONSTRAINT chk_operations_source CHECK (source IN ('api', 'cleanup', 'webhook'));
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS chk_projects_drift_policy;
+ALTER TABLE projects DROP COLUMN IF EXISTS drift_policy;
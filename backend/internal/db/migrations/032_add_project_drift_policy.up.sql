@@ -0,0 +1,9 @@
+-- 为projects表添加配置漂移策略：report（只报告）或heal（检测到漂移后按URL记录重新部署）
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS drift_policy VARCHAR(20) NOT NULL DEFAULT 'report';
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS chk_projects_drift_policy;
+ALTER TABLE projects ADD CONSTRAINT chk_projects_drift_policy CHECK (drift_policy IN ('report', 'heal'));
+
+-- 漂移自愈发起的重新部署操作
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_source;
+ALTER TABLE operations ADD CONSTRAINT chk_operations_source CHECK (source IN ('api', 'cleanup', 'webhook', 'drift'));
@@ -0,0 +1,7 @@
+-- 删除URL配置版本和回滚操作类型
+DELETE FROM operations WHERE type = 'rollback';
+
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_type;
+ALTER TABLE operations ADD CONSTRAINT chk_operations_type CHECK (type IN ('create', 'update', 'delete', 'deploy', 'rollout'));
+
+DROP TABLE IF EXISTS url_revisions;
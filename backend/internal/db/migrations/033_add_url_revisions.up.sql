@@ -0,0 +1,20 @@
+-- url_revisions表：URL每次应用到集群的配置，按URL从1开始编号，用于查看变更历史和回滚
+CREATE TABLE IF NOT EXISTS url_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url_id UUID NOT NULL REFERENCES ephemeral_urls(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    image TEXT NOT NULL,
+    env JSONB NOT NULL DEFAULT '[]',
+    replicas INTEGER NOT NULL,
+    resources JSONB NOT NULL DEFAULT '{}',
+    container_config JSONB NOT NULL DEFAULT '{}',
+    source VARCHAR(20) NOT NULL,
+    operation_id UUID,
+    rollback_of INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT uq_url_revisions_revision UNIQUE (url_id, revision)
+);
+
+-- 回滚操作
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_type;
+ALTER TABLE operations ADD CONSTRAINT chk_operations_type CHECK (type IN ('create', 'update', 'delete', 'deploy', 'rollout', 'rollback'));
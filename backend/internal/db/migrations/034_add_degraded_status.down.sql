@@ -0,0 +1,11 @@
+-- 移除degraded状态，处于该状态的URL恢复为active
+DELETE FROM operations WHERE source = 'health';
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_source;
+ALTER TABLE operations ADD CONSTRAINT chk_operations_source CHECK (source IN ('api', 'cleanup', 'webhook', 'drift'));
+
+UPDATE ephemeral_urls SET status = 'active' WHERE status = 'degraded';
+
+ALTER TABLE ephemeral_urls DROP CONSTRAINT IF EXISTS chk_status;
+
+ALTER TABLE ephemeral_urls ADD CONSTRAINT chk_status
+    CHECK (status IN ('draft', 'creating', 'waiting', 'active', 'deleting', 'deleted', 'failed'));
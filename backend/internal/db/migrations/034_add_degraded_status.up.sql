@@ -0,0 +1,9 @@
+-- 添加degraded状态：活跃URL更新过程中新Pod出现问题，旧版本仍在提供服务
+ALTER TABLE ephemeral_urls DROP CONSTRAINT IF EXISTS chk_status;
+
+ALTER TABLE ephemeral_urls ADD CONSTRAINT chk_status
+    CHECK (status IN ('draft', 'creating', 'waiting', 'active', 'degraded', 'deleting', 'deleted', 'failed'));
+
+-- 更新健康检查失败后自动发起的回滚操作
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_source;
+ALTER TABLE operations ADD CONSTRAINT chk_operations_source CHECK (source IN ('api', 'cleanup', 'webhook', 'drift', 'health'));
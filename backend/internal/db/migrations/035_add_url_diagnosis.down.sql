@@ -0,0 +1 @@
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS diagnosis;
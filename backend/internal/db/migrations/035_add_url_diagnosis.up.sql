@@ -0,0 +1,2 @@
+-- 部署失败原因分类
+ALTER TABLE ephemeral_urls ADD COLUMN IF NOT EXISTS diagnosis JSONB;
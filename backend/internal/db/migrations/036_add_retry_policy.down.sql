@@ -0,0 +1,9 @@
+DELETE FROM operations WHERE source = 'retry';
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_source;
+ALTER TABLE operations ADD CONSTRAINT chk_operations_source CHECK (source IN ('api', 'cleanup', 'webhook', 'drift', 'health'));
+
+DROP INDEX IF EXISTS idx_ephemeral_urls_next_retry_at;
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS next_retry_at;
+ALTER TABLE ephemeral_urls DROP COLUMN IF EXISTS retry_count;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS retry_policy;
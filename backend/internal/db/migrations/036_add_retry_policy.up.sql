@@ -0,0 +1,13 @@
+-- 项目的自动重试策略，默认不自动重试
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS retry_policy JSONB NOT NULL
+    DEFAULT '{"max_attempts": 0, "backoff_seconds": 60, "retryable_failures": ["image_pull_failed", "unschedulable", "quota_exceeded"]}';
+
+-- URL的自动重试次数和下一次重试时间
+ALTER TABLE ephemeral_urls ADD COLUMN IF NOT EXISTS retry_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE ephemeral_urls ADD COLUMN IF NOT EXISTS next_retry_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_ephemeral_urls_next_retry_at ON ephemeral_urls(next_retry_at) WHERE next_retry_at IS NOT NULL;
+
+-- 自动重试发起的部署操作
+ALTER TABLE operations DROP CONSTRAINT IF EXISTS chk_operations_source;
+ALTER TABLE operations ADD CONSTRAINT chk_operations_source CHECK (source IN ('api', 'cleanup', 'webhook', 'drift', 'health', 'retry'));
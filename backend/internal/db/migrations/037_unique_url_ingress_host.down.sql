@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_ephemeral_urls_project_ingress_host;
@@ -0,0 +1,22 @@
+-- 同一项目中未删除的URL不能使用相同的自定义域名
+-- 已有重复时保留最近更新的URL（其路由最后生效），其他URL取消自定义域名并记录日志
+UPDATE ephemeral_urls eu
+SET ingress_host = NULL, updated_at = NOW(),
+    logs = COALESCE(eu.logs, '[]'::jsonb) || jsonb_build_array(jsonb_build_object(
+        'timestamp', NOW(),
+        'level', 'warn',
+        'message', '自定义域名已被同项目中的其他URL使用，已取消绑定',
+        'details', format('自定义域名: %s', eu.ingress_host)))
+WHERE eu.ingress_host IS NOT NULL
+  AND eu.status NOT IN ('deleting', 'deleted')
+  AND EXISTS (
+    SELECT 1 FROM ephemeral_urls other
+    WHERE other.project_id = eu.project_id
+      AND other.ingress_host = eu.ingress_host
+      AND other.id <> eu.id
+      AND other.status NOT IN ('deleting', 'deleted')
+      AND (other.updated_at, other.id) > (eu.updated_at, eu.id)
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ephemeral_urls_project_ingress_host ON ephemeral_urls(project_id, ingress_host)
+    WHERE ingress_host IS NOT NULL AND status NOT IN ('deleting', 'deleted');
@@ -29,50 +29,305 @@ const (
 
 // AppTemplate 应用模版模型
 type AppTemplate struct {
-	ID          uuid.UUID    `json:"id" db:"id"`
-	UserID      uuid.UUID    `json:"user_id" db:"user_id"`
-	Name        string       `json:"name" db:"name" binding:"required,min=1,max=100"`
-	Description string       `json:"description" db:"description"`
-	YamlSpec    string       `json:"yaml_spec" db:"yaml_spec" binding:"required"`
-	ParsedSpec  TemplateSpec `json:"parsed_spec" db:"parsed_spec"` // 解析后的结构化数据
-	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+	ID              uuid.UUID          `json:"id" db:"id"`
+	UserID          uuid.UUID          `json:"user_id" db:"user_id"`
+	Name            string             `json:"name" db:"name" binding:"required,min=1,max=100"`
+	Description     string             `json:"description" db:"description"`
+	TemplateType    string             `json:"template_type" db:"template_type"` // 模版类型：yaml, kustomize
+	YamlSpec        string             `json:"yaml_spec" db:"yaml_spec" binding:"required"`
+	KustomizeBundle *KustomizeBundle   `json:"kustomize_bundle,omitempty" db:"kustomize_bundle"` // Kustomize类型模版的base与补丁
+	ParsedSpec      TemplateSpec       `json:"parsed_spec" db:"parsed_spec"`                     // 解析后的结构化数据
+	Parameters      TemplateParameters `json:"parameters,omitempty" db:"parameters"`             // 模版参数定义
+	Managed         bool               `json:"managed" db:"managed"`                             // 由Git模版目录同步管理，只读
+	CatalogSource   *string            `json:"catalog_source,omitempty" db:"catalog_source"`     // 模版目录中的来源文件
+	CatalogRevision *string            `json:"catalog_revision,omitempty" db:"catalog_revision"` // 模版内容对应的目录提交
+	Visibility      string             `json:"visibility" db:"visibility"`                       // 可见范围：private, project, organization
+	ProjectID       *uuid.UUID         `json:"project_id,omitempty" db:"project_id"`             // project可见范围时关联的项目
+	Category        string             `json:"category" db:"category"`
+	Tags            TemplateTags       `json:"tags" db:"tags"`
+	Featured        bool               `json:"featured" db:"featured"`                   // 管理员设置的推荐标记
+	UsageCount      int                `json:"usage_count" db:"usage_count"`             // 基于该模版创建的URL总数
+	LastUsedAt      *time.Time         `json:"last_used_at,omitempty" db:"last_used_at"` // 最近一次基于该模版创建URL的时间
+	CreatedAt       time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// TemplateType 模版类型常量
+const (
+	TemplateTypeYAML      = "yaml"
+	TemplateTypeKustomize = "kustomize"
+)
+
+// TemplateVisibility 模版可见范围常量
+const (
+	TemplateVisibilityPrivate      = "private"      // 仅创建者可见
+	TemplateVisibilityProject      = "project"      // 关联项目的所有者可见
+	TemplateVisibilityOrganization = "organization" // 所有用户可见
+)
+
+// TemplateTags 模版标签
+type TemplateTags []string
+
+// Value 实现driver.Valuer接口
+func (t TemplateTags) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan 实现sql.Scanner接口
+func (t *TemplateTags) Scan(value interface{}) error {
+	if value == nil {
+		*t = TemplateTags{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, t)
+}
+
+// TemplateListFilter 模版列表过滤条件
+type TemplateListFilter struct {
+	Search       string     // 按名称、描述模糊搜索
+	Category     string     // 分类
+	Tags         []string   // 必须包含全部标签
+	Visibility   string     // 可见范围
+	TemplateType string     // 模版类型
+	ProjectID    *uuid.UUID // 关联项目
+	Featured     *bool      // 是否推荐
+	Sort         string     // 排序：popular, name, recent（默认推荐优先、最新创建）
+}
+
+// KustomizeBundle Kustomize模版包
+type KustomizeBundle struct {
+	Base    map[string]string `json:"base" yaml:"base"`                           // base文件（相对路径 -> 内容），必须包含 kustomization.yaml
+	Patches []KustomizePatch  `json:"patches,omitempty" yaml:"patches,omitempty"` // 每个URL实例化时叠加的补丁，可包含 ${VAR} 占位符
+}
+
+// KustomizePatch Kustomize补丁
+type KustomizePatch struct {
+	Patch  string                `json:"patch" yaml:"patch" binding:"required"`    // 策略合并补丁或JSON6902补丁
+	Target *KustomizePatchTarget `json:"target,omitempty" yaml:"target,omitempty"` // 补丁目标，JSON6902补丁必填
+}
+
+// KustomizePatchTarget Kustomize补丁目标选择器
+type KustomizePatchTarget struct {
+	Group              string `json:"group,omitempty" yaml:"group,omitempty"`
+	Version            string `json:"version,omitempty" yaml:"version,omitempty"`
+	Kind               string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Name               string `json:"name,omitempty" yaml:"name,omitempty"`
+	Namespace          string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	LabelSelector      string `json:"label_selector,omitempty" yaml:"label_selector,omitempty"`
+	AnnotationSelector string `json:"annotation_selector,omitempty" yaml:"annotation_selector,omitempty"`
+}
+
+// Value 实现driver.Valuer接口
+func (k KustomizeBundle) Value() (driver.Value, error) {
+	return json.Marshal(k)
+}
+
+// Scan 实现sql.Scanner接口
+func (k *KustomizeBundle) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, k)
+}
+
+// TemplateParameter 模版参数定义
+type TemplateParameter struct {
+	Name        string `json:"name" yaml:"name" binding:"required"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Default     string `json:"default,omitempty" yaml:"default,omitempty"`   // 创建URL时未提供变量值时使用
+	Required    bool   `json:"required,omitempty" yaml:"required,omitempty"` // 是否必须提供
+}
+
+// TemplateParameters 模版参数定义列表
+type TemplateParameters []TemplateParameter
+
+// Value 实现driver.Valuer接口
+func (p TemplateParameters) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+// Scan 实现sql.Scanner接口
+func (p *TemplateParameters) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, p)
+}
+
+// TemplateBundleAPIVersion 模版包格式版本
+const (
+	TemplateBundleAPIVersion = "url-manager-system/v1"
+	TemplateBundleKind       = "TemplateBundle"
+)
+
+// TemplateBundle 可移植的模版包，用于导入/导出以及Git模版目录
+type TemplateBundle struct {
+	APIVersion string                `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string                `json:"kind" yaml:"kind"`
+	Templates  []TemplateBundleEntry `json:"templates" yaml:"templates"`
+}
+
+// TemplateBundleEntry 模版包中的单个模版
+type TemplateBundleEntry struct {
+	Name            string              `json:"name" yaml:"name"`
+	Description     string              `json:"description,omitempty" yaml:"description,omitempty"`
+	TemplateType    string              `json:"template_type,omitempty" yaml:"template_type,omitempty"` // 默认yaml
+	YamlSpec        string              `json:"yaml_spec,omitempty" yaml:"yaml_spec,omitempty"`
+	KustomizeBundle *KustomizeBundle    `json:"kustomize_bundle,omitempty" yaml:"kustomize_bundle,omitempty"`
+	Parameters      []TemplateParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Category        string              `json:"category,omitempty" yaml:"category,omitempty"`
+	Tags            []string            `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// TemplateImportResult 模版导入结果
+type TemplateImportResult struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Skipped []string `json:"skipped"`
+	Errors  []string `json:"errors"`
+}
+
+// CatalogSyncResult Git模版目录同步结果
+type CatalogSyncResult struct {
+	Revision  string    `json:"revision"`
+	Created   []string  `json:"created"`
+	Updated   []string  `json:"updated"`
+	Unchanged []string  `json:"unchanged"`
+	Removed   []string  `json:"removed"`
+	Errors    []string  `json:"errors"`
+	SyncedAt  time.Time `json:"synced_at"`
 }
 
 // Project 项目模型
 type Project struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	UserID      uuid.UUID `json:"user_id" db:"user_id"`
-	Name        string    `json:"name" db:"name" binding:"required,min=1,max=100"`
-	Description string    `json:"description" db:"description"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID            uuid.UUID   `json:"id" db:"id"`
+	UserID        uuid.UUID   `json:"user_id" db:"user_id"`
+	Name          string      `json:"name" db:"name" binding:"required,min=1,max=100"`
+	Description   string      `json:"description" db:"description"`
+	RoutingMode   string      `json:"routing_mode" db:"routing_mode"`                 // URL路由模式：path 或 subdomain
+	TLSMode       string      `json:"tls_mode" db:"tls_mode"`                         // 证书模式：none、secret 或 cert-manager
+	TLSSecretName *string     `json:"tls_secret_name,omitempty" db:"tls_secret_name"` // secret模式下引用的证书Secret，为空时使用配置的默认证书
+	DriftPolicy   string      `json:"drift_policy" db:"drift_policy"`                 // 配置漂移处理策略：report 或 heal
+	RetryPolicy   RetryPolicy `json:"retry_policy" db:"retry_policy"`                 // 部署失败后的自动重试策略
+	CreatedAt     time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// ProjectRoutingSettings 项目的路由、证书、漂移策略和重试策略设置，创建时为空的字段使用默认值，更新时保持不变
+type ProjectRoutingSettings struct {
+	RoutingMode   string       `json:"routing_mode"`
+	TLSMode       string       `json:"tls_mode"`
+	TLSSecretName *string      `json:"tls_secret_name"` // 更新时传空字符串清除
+	DriftPolicy   string       `json:"drift_policy"`
+	RetryPolicy   *RetryPolicy `json:"retry_policy"`
+}
+
+// RetryPolicy 项目下独立URL部署失败后的自动重试策略
+type RetryPolicy struct {
+	MaxAttempts       int      `json:"max_attempts"`       // 每次失败后最多自动重试的次数，0表示不自动重试
+	BackoffSeconds    int      `json:"backoff_seconds"`    // 第一次重试前等待的秒数，之后每次翻倍
+	RetryableFailures []string `json:"retryable_failures"` // 自动重试的失败分类，取值见FailureDiagnosis.Code
+}
+
+// Value 实现driver.Valuer接口
+func (r RetryPolicy) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan 实现sql.Scanner接口
+func (r *RetryPolicy) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, r)
 }
 
+// 项目路由模式常量
+const (
+	RoutingModePath      = "path"      // 所有URL共享默认域名，按路径前缀区分
+	RoutingModeSubdomain = "subdomain" // 每个URL使用独立的子域名，访问根路径
+)
+
+// 项目证书模式常量
+const (
+	TLSModeNone        = "none"         // 不配置证书，访问地址使用http
+	TLSModeSecret      = "secret"       // 引用已有的证书Secret（如泛域名证书）
+	TLSModeCertManager = "cert-manager" // 通过cert-manager的ClusterIssuer签发证书
+)
+
+// 项目配置漂移策略常量
+const (
+	DriftPolicyReport = "report" // 只检测和报告，不修改集群资源
+	DriftPolicyHeal   = "heal"   // 检测到漂移后按URL记录重新部署
+)
+
 // EphemeralURL 临时URL模型
 type EphemeralURL struct {
-	ID                uuid.UUID       `json:"id" db:"id"`
-	UserID            uuid.UUID       `json:"user_id" db:"user_id"`
-	ProjectID         uuid.UUID       `json:"project_id" db:"project_id"`
-	TemplateID        *uuid.UUID      `json:"template_id" db:"template_id"`
-	Path              string          `json:"path" db:"path"`
-	Image             string          `json:"image" db:"image" binding:"required"`
-	Env               EnvironmentVars `json:"env" db:"env"`
-	Replicas          int             `json:"replicas" db:"replicas" binding:"min=1,max=10"`
-	Resources         ResourceLimits  `json:"resources" db:"resources"`
-	ContainerConfig   ContainerConfig `json:"container_config" db:"container_config"`
-	Status            string          `json:"status" db:"status"`
-	TTLSeconds        int             `json:"ttl_seconds" db:"ttl_seconds"`
-	K8sDeploymentName *string         `json:"k8s_deployment_name" db:"k8s_deployment_name"`
-	K8sServiceName    *string         `json:"k8s_service_name" db:"k8s_service_name"`
-	K8sSecretName     *string         `json:"k8s_secret_name" db:"k8s_secret_name"`
-	ErrorMessage      *string         `json:"error_message" db:"error_message"`
-	Logs              []LogEntry      `json:"logs" db:"logs"`
-	IngressHost       *string         `json:"ingress_host" db:"ingress_host"`
-	StartedAt         *time.Time      `json:"started_at" db:"started_at"`
-	ExpireAt          time.Time       `json:"expire_at" db:"expire_at"`
-	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time       `json:"updated_at" db:"updated_at"`
+	ID                uuid.UUID         `json:"id" db:"id"`
+	UserID            uuid.UUID         `json:"user_id" db:"user_id"`
+	ProjectID         uuid.UUID         `json:"project_id" db:"project_id"`
+	TemplateID        *uuid.UUID        `json:"template_id" db:"template_id"`
+	Path              string            `json:"path" db:"path"`
+	Image             string            `json:"image" db:"image" binding:"required"`
+	Env               EnvironmentVars   `json:"env" db:"env"`
+	Replicas          int               `json:"replicas" db:"replicas" binding:"min=1,max=10"`
+	Resources         ResourceLimits    `json:"resources" db:"resources"`
+	ContainerConfig   ContainerConfig   `json:"container_config" db:"container_config"`
+	Status            string            `json:"status" db:"status"`
+	TTLSeconds        int               `json:"ttl_seconds" db:"ttl_seconds"`
+	K8sDeploymentName *string           `json:"k8s_deployment_name" db:"k8s_deployment_name"`
+	K8sServiceName    *string           `json:"k8s_service_name" db:"k8s_service_name"`
+	K8sSecretName     *string           `json:"k8s_secret_name" db:"k8s_secret_name"`
+	ErrorMessage      *string           `json:"error_message" db:"error_message"`
+	Diagnosis         *FailureDiagnosis `json:"diagnosis,omitempty" db:"diagnosis"`         // 失败原因分类，只有failed状态的URL有值
+	RetryCount        int               `json:"retry_count" db:"retry_count"`               // 最近一次部署成功或手动部署后已自动重试的次数
+	NextRetryAt       *time.Time        `json:"next_retry_at,omitempty" db:"next_retry_at"` // 下一次自动重试的时间，没有安排重试时为空
+	Logs              []LogEntry        `json:"logs" db:"logs"`
+	IngressHost       *string           `json:"ingress_host" db:"ingress_host"`
+	StackID           *uuid.UUID        `json:"stack_id,omitempty" db:"stack_id"`             // 所属环境，独立URL为空
+	StackService      *string           `json:"stack_service,omitempty" db:"stack_service"`   // 在所属环境中的服务名
+	GitRepository     *string           `json:"git_repository,omitempty" db:"git_repository"` // 由Git webhook创建时的来源仓库
+	GitPRNumber       *int              `json:"git_pr_number,omitempty" db:"git_pr_number"`
+	GitBranch         *string           `json:"git_branch,omitempty" db:"git_branch"`
+	GitCommit         *string           `json:"git_commit,omitempty" db:"git_commit"`               // 当前部署的提交
+	TrackTagPattern   *string           `json:"track_tag_pattern,omitempty" db:"track_tag_pattern"` // 镜像仓库推送匹配的标签时自动更新
+	TLSSecretName     *string           `json:"tls_secret_name,omitempty" db:"tls_secret_name"`     // 使用的证书Secret，未配置TLS时为空
+	TLSReady          bool              `json:"tls_ready" db:"tls_ready"`                           // 证书是否已就绪
+	URL               string            `json:"url,omitempty" db:"-"`                               // 完整访问地址，证书就绪前使用http
+	StartedAt         *time.Time        `json:"started_at" db:"started_at"`
+	ExpireAt          time.Time         `json:"expire_at" db:"expire_at"`
+	CreatedAt         time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at" db:"updated_at"`
 
 	// 关联项目信息(用于查询时连表获取)
 	Project *Project `json:"project,omitempty"`
@@ -80,6 +335,44 @@ type EphemeralURL struct {
 	Template *AppTemplate `json:"template,omitempty"`
 }
 
+// 部署失败原因分类，code保持稳定供调用方判断
+const (
+	FailureQuotaExceeded = "quota_exceeded"    // 命名空间资源配额不足
+	FailureUnschedulable = "unschedulable"     // 没有可调度的节点
+	FailureImagePull     = "image_pull_failed" // 镜像拉取失败
+	FailureConfigError   = "config_error"      // 容器配置错误（如引用的Secret不存在）
+	FailureOOMKilled     = "oom_killed"        // 容器内存超限被终止
+	FailureCrashLoop     = "crash_loop"        // 容器反复崩溃重启
+	FailureProbeFailed   = "probe_failed"      // 健康检查未通过
+	FailureUnknown       = "unknown"           // 未能确定原因
+)
+
+// FailureDiagnosis 部署失败的诊断结果
+type FailureDiagnosis struct {
+	Code   string `json:"code"`             // 失败原因分类
+	Reason string `json:"reason,omitempty"` // 判断依据，如容器等待原因或Pod事件信息
+	Hint   string `json:"hint"`             // 处理建议
+}
+
+// Value 实现driver.Valuer接口
+func (d FailureDiagnosis) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// Scan 实现sql.Scanner接口
+func (d *FailureDiagnosis) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, d)
+}
+
 // EnvironmentVar 环境变量
 type EnvironmentVar struct {
 	Name  string `json:"name" binding:"required"`
@@ -174,6 +467,29 @@ type ContainerConfig struct {
 	WorkingDir    string         `json:"working_dir,omitempty"`    // 工作目录
 	TTY           bool           `json:"tty"`                      // 是否分配TTY
 	Stdin         bool           `json:"stdin"`                    // 是否打开stdin
+
+	ReadinessProbe        *ContainerProbe `json:"readiness_probe,omitempty"`         // 就绪探针，未设置时容器启动即视为就绪
+	LivenessProbe         *ContainerProbe `json:"liveness_probe,omitempty"`          // 存活探针
+	StartupTimeoutSeconds int             `json:"startup_timeout_seconds,omitempty"` // Pod就绪的最长等待时间，为0时使用配置的默认值
+}
+
+// 探针类型
+const (
+	ProbeTypeHTTP = "http"
+	ProbeTypeTCP  = "tcp"
+	ProbeTypeExec = "exec"
+)
+
+// ContainerProbe 基于镜像创建的URL的容器健康检查探针
+type ContainerProbe struct {
+	Type                string   `json:"type"`                            // http, tcp, exec
+	Path                string   `json:"path,omitempty"`                  // HTTP探针的请求路径
+	Port                int32    `json:"port,omitempty"`                  // HTTP/TCP探针的端口，默认80
+	Command             []string `json:"command,omitempty"`               // exec探针执行的命令
+	InitialDelaySeconds int32    `json:"initial_delay_seconds,omitempty"` // 首次检查前的等待时间
+	PeriodSeconds       int32    `json:"period_seconds,omitempty"`        // 检查间隔
+	TimeoutSeconds      int32    `json:"timeout_seconds,omitempty"`       // 单次检查超时
+	FailureThreshold    int32    `json:"failure_threshold,omitempty"`     // 连续失败多少次视为失败
 }
 
 // Value 实现driver.Valuer接口
@@ -198,6 +514,7 @@ func (c *ContainerConfig) Scan(value interface{}) error {
 // TemplateSpec 模板规格（解析后的YAML结构）
 type TemplateSpec struct {
 	// Deployment 级别配置
+	WorkloadKind   string            `json:"workload_kind,omitempty"`   // 工作负载类型（Deployment/StatefulSet）
 	DeploymentName string            `json:"deployment_name,omitempty"` // Deployment名称
 	Namespace      string            `json:"namespace,omitempty"`       // 命名空间
 	Replicas       int32             `json:"replicas,omitempty"`        // 副本数
@@ -241,6 +558,15 @@ type TemplateSpec struct {
 	// 其他配置
 	ImagePullSecrets []string    `json:"image_pull_secrets,omitempty"` // 镜像拉取密钥
 	InitContainers   []Container `json:"init_containers,omitempty"`    // 初始化容器
+
+	// 模板中的其他资源（只读，结构化编辑时原样保留）
+	ExtraResources []ResourceRef `json:"extra_resources,omitempty"`
+}
+
+// ResourceRef 模板中的资源引用
+type ResourceRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name,omitempty"`
 }
 
 // ContainerPort 容器端口
@@ -543,6 +869,7 @@ const (
 	StatusCreating = "creating"
 	StatusWaiting  = "waiting"
 	StatusActive   = "active"
+	StatusDegraded = "degraded" // 活跃URL更新中Pod出现问题，旧版本仍在提供服务
 	StatusDeleting = "deleting"
 	StatusDeleted  = "deleted"
 	StatusFailed   = "failed"
@@ -612,7 +939,8 @@ type CreateEphemeralURLRequest struct {
 	Replicas        int             `json:"replicas" binding:"min=1,max=10"`
 	Resources       ResourceLimits  `json:"resources"`
 	ContainerConfig ContainerConfig `json:"container_config"`
-	IngressHost     *string         `json:"ingress_host,omitempty"` // 可选，自定义ingress host
+	IngressHost     *string         `json:"ingress_host,omitempty"`      // 可选，自定义ingress host
+	TrackTagPattern string          `json:"track_tag_pattern,omitempty"` // 可选，跟踪的镜像标签规则，例如 v1.* 或 main-*
 }
 
 // CreateEphemeralURLFromTemplateRequest 基于模版创建URL请求
@@ -631,28 +959,48 @@ type UpdateEphemeralURLRequest struct {
 	Resources       ResourceLimits  `json:"resources,omitempty"`
 	ContainerConfig ContainerConfig `json:"container_config,omitempty"`
 	IngressHost     *string         `json:"ingress_host,omitempty"`
+	TrackTagPattern *string         `json:"track_tag_pattern,omitempty"` // 为空字符串时取消跟踪
 }
 
 // CreateAppTemplateRequest 创建应用模版请求
 type CreateAppTemplateRequest struct {
-	Name        string       `json:"name" binding:"required,min=1,max=100"`
-	Description string       `json:"description"`
-	YamlSpec    string       `json:"yaml_spec" binding:"required"`
-	ParsedSpec  TemplateSpec `json:"parsed_spec,omitempty"` // 可选，解析后的规格
+	Name            string              `json:"name" binding:"required,min=1,max=100"`
+	Description     string              `json:"description"`
+	TemplateType    string              `json:"template_type,omitempty" binding:"omitempty,oneof=yaml kustomize"`            // 可选，默认yaml
+	YamlSpec        string              `json:"yaml_spec"`                                                                   // yaml类型模版必填
+	KustomizeBundle *KustomizeBundle    `json:"kustomize_bundle,omitempty"`                                                  // kustomize类型模版必填
+	ParsedSpec      TemplateSpec        `json:"parsed_spec,omitempty"`                                                       // 可选，解析后的规格
+	Parameters      []TemplateParameter `json:"parameters,omitempty"`                                                        // 可选，模版参数定义
+	Visibility      string              `json:"visibility,omitempty" binding:"omitempty,oneof=private project organization"` // 可选，默认private
+	ProjectID       *uuid.UUID          `json:"project_id,omitempty"`                                                        // project可见范围时必填
+	Category        string              `json:"category,omitempty" binding:"max=50"`
+	Tags            []string            `json:"tags,omitempty"`
 }
 
 // UpdateAppTemplateRequest 更新应用模版请求
 type UpdateAppTemplateRequest struct {
-	Name        string        `json:"name" binding:"required,min=1,max=100"`
-	Description string        `json:"description"`
-	YamlSpec    string        `json:"yaml_spec"`             // 可选，YAML编辑模式时使用
-	ParsedSpec  *TemplateSpec `json:"parsed_spec,omitempty"` // 可选，结构化编辑模式时使用
+	Name            string              `json:"name" binding:"required,min=1,max=100"`
+	Description     string              `json:"description"`
+	YamlSpec        string              `json:"yaml_spec"`                                                                   // 可选，YAML编辑模式时使用
+	KustomizeBundle *KustomizeBundle    `json:"kustomize_bundle,omitempty"`                                                  // 可选，kustomize类型模版更新base与补丁时使用
+	ParsedSpec      *TemplateSpec       `json:"parsed_spec,omitempty"`                                                       // 可选，结构化编辑模式时使用
+	Parameters      []TemplateParameter `json:"parameters,omitempty"`                                                        // 可选，为空时保持原有参数定义
+	Visibility      string              `json:"visibility,omitempty" binding:"omitempty,oneof=private project organization"` // 可选，为空时保持原有可见范围
+	ProjectID       *uuid.UUID          `json:"project_id,omitempty"`                                                        // 可选，project可见范围时使用
+	Category        *string             `json:"category,omitempty" binding:"omitempty,max=50"`                               // 可选，为空时保持原有分类
+	Tags            []string            `json:"tags,omitempty"`                                                              // 可选，为空时保持原有标签
+}
+
+// SetTemplateFeaturedRequest 设置模版推荐标记请求
+type SetTemplateFeaturedRequest struct {
+	Featured bool `json:"featured"`
 }
 
 // CreateEphemeralURLResponse 创建URL响应
 type CreateEphemeralURLResponse struct {
-	URL string    `json:"url"`
-	ID  uuid.UUID `json:"id"`
+	URL         string     `json:"url"`
+	ID          uuid.UUID  `json:"id"`
+	OperationID *uuid.UUID `json:"operation_id,omitempty"` // 部署URL的异步操作，Kubernetes不可用时为空
 }
 
 // ListProjectsResponse 项目列表响应
@@ -667,12 +1015,497 @@ type ListURLsResponse struct {
 	Total int            `json:"total"`
 }
 
+// DryRunStatus 服务端dry-run结果状态常量
+const (
+	DryRunStatusValid    = "valid"    // 通过校验
+	DryRunStatusInvalid  = "invalid"  // 解析失败或字段校验失败
+	DryRunStatusRejected = "rejected" // 被准入控制（webhook、策略等）拒绝
+	DryRunStatusConflict = "conflict" // 同名资源已存在
+	DryRunStatusError    = "error"    // 其他错误
+)
+
+// DryRunCause dry-run失败的具体原因
+type DryRunCause struct {
+	Field   string `json:"field,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message"`
+}
+
+// DryRunDocumentResult 单个YAML文档的dry-run结果
+type DryRunDocumentResult struct {
+	Index      int                    `json:"index"` // 非空文档的序号，从0开始
+	APIVersion string                 `json:"api_version,omitempty"`
+	Kind       string                 `json:"kind,omitempty"`
+	Name       string                 `json:"name,omitempty"`
+	Namespace  string                 `json:"namespace,omitempty"`
+	Status     string                 `json:"status"`
+	Message    string                 `json:"message,omitempty"`
+	Causes     []DryRunCause          `json:"causes,omitempty"`
+	Object     map[string]interface{} `json:"object,omitempty"` // 经过默认值填充和变更webhook处理后的对象
+}
+
+// TemplateDryRunResult 模版服务端dry-run结果
+type TemplateDryRunResult struct {
+	Valid     bool                   `json:"valid"`
+	Documents []DryRunDocumentResult `json:"documents"`
+}
+
 // ListAppTemplatesResponse 模版列表响应
 type ListAppTemplatesResponse struct {
 	Templates []AppTemplate `json:"templates"`
 	Total     int           `json:"total"`
 }
 
+// StackStatus 环境状态常量
+const (
+	StackStatusDraft     = "draft"     // Kubernetes不可用，仅保存记录
+	StackStatusDeploying = "deploying" // 按依赖顺序部署中
+	StackStatusActive    = "active"    // 所有服务已就绪，TTL开始计算
+	StackStatusDegraded  = "degraded"  // 已就绪后有服务不再处于active状态（仅由成员状态推导，不落库）
+	StackStatusDeleting  = "deleting"  // 删除中
+	StackStatusFailed    = "failed"    // 部署失败，已回滚全部服务
+)
+
+// StackServiceSpec 环境中单个服务的定义，template_id与image二选一
+type StackServiceSpec struct {
+	Name            string          `json:"name" binding:"required"` // 环境内唯一，用于依赖声明和注入的环境变量名
+	TemplateID      *uuid.UUID      `json:"template_id,omitempty"`
+	Image           string          `json:"image,omitempty"`
+	Env             EnvironmentVars `json:"env,omitempty"`
+	Replicas        int             `json:"replicas,omitempty"`
+	Resources       ResourceLimits  `json:"resources,omitempty"`
+	ContainerConfig ContainerConfig `json:"container_config,omitempty"`
+	Path            string          `json:"path,omitempty"`       // 可选，为空时系统生成
+	DependsOn       []string        `json:"depends_on,omitempty"` // 依赖的服务名，依赖就绪后才部署本服务
+}
+
+// StackServiceSpecs 环境服务定义列表
+type StackServiceSpecs []StackServiceSpec
+
+// Value 实现driver.Valuer接口
+func (s StackServiceSpecs) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	return json.Marshal(s)
+}
+
+// Scan 实现sql.Scanner接口
+func (s *StackServiceSpecs) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, s)
+}
+
+// Stack 环境：同一项目下按依赖顺序部署、共享TTL和环境变量的一组URL
+type Stack struct {
+	ID           uuid.UUID         `json:"id" db:"id"`
+	ProjectID    uuid.UUID         `json:"project_id" db:"project_id"`
+	UserID       *uuid.UUID        `json:"user_id" db:"user_id"`
+	Name         string            `json:"name" db:"name"`
+	Description  string            `json:"description" db:"description"`
+	Services     StackServiceSpecs `json:"services" db:"services"`
+	Env          EnvironmentVars   `json:"env" db:"env"` // 注入所有服务的共享环境变量
+	Status       string            `json:"status" db:"status"`
+	TTLSeconds   int               `json:"ttl_seconds" db:"ttl_seconds"`
+	ErrorMessage *string           `json:"error_message" db:"error_message"`
+	StartedAt    *time.Time        `json:"started_at" db:"started_at"`
+	ExpireAt     time.Time         `json:"expire_at" db:"expire_at"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
+
+	// 成员URL(用于查询时获取)
+	Members []EphemeralURL `json:"members,omitempty"`
+}
+
+// CreateStackRequest 创建环境请求
+type CreateStackRequest struct {
+	Name        string             `json:"name" binding:"required,min=1,max=100"`
+	Description string             `json:"description"`
+	Services    []StackServiceSpec `json:"services" binding:"required,min=1,max=20,dive"`
+	Env         EnvironmentVars    `json:"env"`
+	TTLSeconds  int                `json:"ttl_seconds" binding:"required,min=60,max=604800"` // 1分钟到7天
+}
+
+// ListStacksResponse 环境列表响应
+type ListStacksResponse struct {
+	Stacks []Stack `json:"stacks"`
+	Total  int     `json:"total"`
+}
+
+// URLAlias 项目内稳定的别名路径，指向某个URL的Service，可以重新指向新的URL
+type URLAlias struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	ProjectID uuid.UUID  `json:"project_id" db:"project_id"`
+	Name      string     `json:"name" db:"name"`
+	Path      string     `json:"path" db:"path"`
+	URLID     *uuid.UUID `json:"url_id" db:"url_id"` // 为空时指向兜底页面
+	UserID    *uuid.UUID `json:"user_id" db:"user_id"`
+	URL       string     `json:"url" db:"-"`      // 完整访问地址
+	Fallback  bool       `json:"fallback" db:"-"` // 当前是否指向兜底页面
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// CreateURLAliasRequest 创建别名请求
+type CreateURLAliasRequest struct {
+	Name  string     `json:"name" binding:"required,min=1,max=63"`
+	URLID *uuid.UUID `json:"url_id,omitempty"` // 可选，为空时先指向兜底页面
+}
+
+// UpdateURLAliasRequest 重新指向别名请求
+type UpdateURLAliasRequest struct {
+	URLID *uuid.UUID `json:"url_id"` // 为空时指向兜底页面
+}
+
+// ListURLAliasesResponse 别名列表响应
+type ListURLAliasesResponse struct {
+	Aliases []URLAlias `json:"aliases"`
+	Total   int        `json:"total"`
+}
+
+// 自定义域名验证方式
+const (
+	DomainVerificationDNS  = "dns"  // 在 _url-manager-challenge.<域名> 添加TXT记录
+	DomainVerificationHTTP = "http" // 在 http://<域名>/.well-known/url-manager-verification.txt 提供验证文件
+)
+
+// 自定义域名状态
+const (
+	DomainStatusPending  = "pending"  // 等待验证
+	DomainStatusVerified = "verified" // 已验证，URL可以使用
+	DomainStatusFailed   = "failed"   // 验证失败或重新验证连续失败
+)
+
+// CustomDomain 项目登记的自定义域名，验证所有权后URL才能将其作为ingress_host
+type CustomDomain struct {
+	ID                 uuid.UUID        `json:"id" db:"id"`
+	ProjectID          uuid.UUID        `json:"project_id" db:"project_id"`
+	Domain             string           `json:"domain" db:"domain"`
+	VerificationMethod string           `json:"verification_method" db:"verification_method"`
+	VerificationToken  string           `json:"verification_token" db:"verification_token"`
+	Status             string           `json:"status" db:"status"`
+	FailureCount       int              `json:"failure_count" db:"failure_count"`
+	LastError          *string          `json:"last_error" db:"last_error"`
+	VerifiedAt         *time.Time       `json:"verified_at" db:"verified_at"`
+	LastCheckedAt      *time.Time       `json:"last_checked_at" db:"last_checked_at"`
+	UserID             *uuid.UUID       `json:"user_id" db:"user_id"`
+	Challenge          *DomainChallenge `json:"challenge" db:"-"` // 完成验证需要配置的内容
+	CreatedAt          time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// DomainChallenge 域名验证需要配置的记录或文件
+type DomainChallenge struct {
+	Type  string `json:"type"`  // TXT 或 HTTP
+	Name  string `json:"name"`  // TXT记录名或验证文件地址
+	Value string `json:"value"` // 记录值或文件内容
+}
+
+// CreateCustomDomainRequest 登记自定义域名请求
+type CreateCustomDomainRequest struct {
+	Domain             string `json:"domain" binding:"required,max=253"`
+	VerificationMethod string `json:"verification_method,omitempty" binding:"omitempty,oneof=dns http"` // 可选，默认dns
+}
+
+// ListCustomDomainsResponse 自定义域名列表响应
+type ListCustomDomainsResponse struct {
+	Domains []CustomDomain `json:"domains"`
+	Total   int            `json:"total"`
+}
+
+// 流量切分状态
+const (
+	TrafficSplitStatusActive   = "active"   // 生效中
+	TrafficSplitStatusReverted = "reverted" // 主URL或金丝雀URL被删除或过期后已自动恢复
+)
+
+// TrafficSplit 将主URL路由上的部分流量按权重转发给同项目的金丝雀URL
+type TrafficSplit struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	ProjectID     uuid.UUID  `json:"project_id" db:"project_id"`
+	PrimaryURLID  uuid.UUID  `json:"primary_url_id" db:"primary_url_id"`
+	CanaryURLID   uuid.UUID  `json:"canary_url_id" db:"canary_url_id"`
+	CanaryWeight  int        `json:"canary_weight" db:"canary_weight"` // 转发给金丝雀URL的流量百分比
+	PrimaryWeight int        `json:"primary_weight" db:"-"`            // 100 - canary_weight
+	Status        string     `json:"status" db:"status"`
+	RevertedAt    *time.Time `json:"reverted_at" db:"reverted_at"`
+	UserID        *uuid.UUID `json:"user_id" db:"user_id"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// CreateTrafficSplitRequest 创建流量切分请求
+type CreateTrafficSplitRequest struct {
+	PrimaryURLID uuid.UUID `json:"primary_url_id" binding:"required"`
+	CanaryURLID  uuid.UUID `json:"canary_url_id" binding:"required"`
+	CanaryWeight *int      `json:"canary_weight" binding:"required,min=0,max=100"`
+}
+
+// UpdateTrafficSplitRequest 调整流量切分权重请求
+type UpdateTrafficSplitRequest struct {
+	CanaryWeight *int `json:"canary_weight" binding:"required,min=0,max=100"`
+}
+
+// ListTrafficSplitsResponse 流量切分列表响应
+type ListTrafficSplitsResponse struct {
+	TrafficSplits []TrafficSplit `json:"traffic_splits"`
+	Total         int            `json:"total"`
+}
+
+// 异步操作类型
+const (
+	OperationCreate   = "create"   // 创建URL的Kubernetes资源
+	OperationUpdate   = "update"   // 更新URL后清理不再使用的路由
+	OperationDelete   = "delete"   // 删除URL的Kubernetes资源
+	OperationDeploy   = "deploy"   // 重新部署URL
+	OperationRollout  = "rollout"  // 镜像webhook触发的镜像滚动更新
+	OperationRollback = "rollback" // 回滚到URL的历史配置版本
+)
+
+// 异步操作来源
+const (
+	OperationSourceAPI     = "api"     // 用户通过API发起
+	OperationSourceCleanup = "cleanup" // 过期清理任务发起
+	OperationSourceWebhook = "webhook" // Git或镜像仓库webhook发起
+	OperationSourceDrift   = "drift"   // 配置漂移自愈发起
+	OperationSourceHealth  = "health"  // 更新健康检查失败后自动回滚
+	OperationSourceRetry   = "retry"   // 部署失败后按项目重试策略自动重新部署
+)
+
+// 异步操作状态
+const (
+	OperationStatusPending   = "pending"   // 等待执行或等待重试
+	OperationStatusRunning   = "running"   // 执行中
+	OperationStatusSucceeded = "succeeded" // 执行成功
+	OperationStatusFailed    = "failed"    // 重试次数用完后仍失败
+)
+
+// OperationData 异步操作的参数或结果
+type OperationData map[string]interface{}
+
+// Value 实现driver.Valuer接口
+func (d OperationData) Value() (driver.Value, error) {
+	if d == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(d)
+}
+
+// Scan 实现sql.Scanner接口
+func (d *OperationData) Scan(value interface{}) error {
+	if value == nil {
+		*d = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, d)
+}
+
+// Operation URL的异步操作，与URL记录在同一事务中写入，由后台工作线程执行Kubernetes部分
+type Operation struct {
+	ID          uuid.UUID     `json:"id" db:"id"`
+	Type        string        `json:"type" db:"type"`
+	URLID       uuid.UUID     `json:"url_id" db:"url_id"`
+	ProjectID   uuid.UUID     `json:"project_id" db:"project_id"`
+	Source      string        `json:"source" db:"source"`
+	Status      string        `json:"status" db:"status"`
+	Progress    *string       `json:"progress" db:"progress"` // 当前执行到的步骤
+	Payload     OperationData `json:"-" db:"payload"`
+	Result      OperationData `json:"result,omitempty" db:"result"`
+	Error       *string       `json:"error" db:"error"` // 最近一次失败的原因
+	Attempts    int           `json:"attempts" db:"attempts"`
+	MaxAttempts int           `json:"max_attempts" db:"max_attempts"`
+	NextRunAt   time.Time     `json:"next_run_at" db:"next_run_at"`
+	StartedAt   *time.Time    `json:"started_at" db:"started_at"`
+	FinishedAt  *time.Time    `json:"finished_at" db:"finished_at"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// URLRevision URL应用到集群的一次配置，每次创建、部署、镜像滚动更新或回滚成功且配置有变化时记录
+type URLRevision struct {
+	ID              uuid.UUID        `json:"id" db:"id"`
+	URLID           uuid.UUID        `json:"url_id" db:"url_id"`
+	Revision        int              `json:"revision" db:"revision"`
+	Image           string           `json:"image" db:"image"`
+	Env             EnvironmentVars  `json:"env" db:"env"`
+	Replicas        int              `json:"replicas" db:"replicas"`
+	Resources       ResourceLimits   `json:"resources" db:"resources"`
+	ContainerConfig ContainerConfig  `json:"container_config" db:"container_config"`
+	Source          string           `json:"source" db:"source"` // 应用该配置的操作类型
+	OperationID     *uuid.UUID       `json:"operation_id,omitempty" db:"operation_id"`
+	RollbackOf      *int             `json:"rollback_of,omitempty" db:"rollback_of"` // 回滚产生的版本对应的历史版本
+	Changes         []RevisionChange `json:"changes" db:"-"`                         // 与上一版本相比的变化，第一个版本为空
+	CreatedAt       time.Time        `json:"created_at" db:"created_at"`
+}
+
+// RevisionChange 两个配置版本之间的一处变化
+type RevisionChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// ListURLRevisionsResponse URL配置版本列表响应，按版本号倒序
+type ListURLRevisionsResponse struct {
+	Revisions []URLRevision `json:"revisions"`
+	Total     int           `json:"total"`
+}
+
+// RollbackURLRequest 回滚URL请求
+type RollbackURLRequest struct {
+	Revision int `json:"revision" binding:"required,min=1"`
+}
+
+// DriftItem 集群中资源与URL记录期望状态的一处差异
+type DriftItem struct {
+	Kind    string `json:"kind"` // Deployment、Service或Route
+	Name    string `json:"name"`
+	Field   string `json:"field"` // 差异字段，资源缺失时为空
+	Desired string `json:"desired"`
+	Live    string `json:"live"` // 资源缺失时为空
+}
+
+// DriftReport URL的配置漂移检测结果
+type DriftReport struct {
+	URLID       uuid.UUID   `json:"url_id"`
+	DriftPolicy string      `json:"drift_policy"`
+	Drifted     bool        `json:"drifted"`
+	Items       []DriftItem `json:"items"`
+	CheckedAt   time.Time   `json:"checked_at"`
+}
+
+// IdempotencyRecord 幂等键记录，请求完成前只有请求摘要，完成后保存响应用于重放
+type IdempotencyRecord struct {
+	RequestHash string          `json:"request_hash"`
+	Completed   bool            `json:"completed"`
+	StatusCode  int             `json:"status_code,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+}
+
+// GCOrphan 回收时发现的孤儿资源
+type GCOrphan struct {
+	Kind    string  `json:"kind"` // Deployment、Service、Secret或路由资源类型
+	Name    string  `json:"name"`
+	URLID   *string `json:"url_id,omitempty"`  // 资源标签记录的URL
+	Project *string `json:"project,omitempty"` // 路由资源的项目标签
+	Reason  string  `json:"reason"`
+	Deleted bool    `json:"deleted"`
+	Error   *string `json:"error,omitempty"` // 删除失败的原因
+}
+
+// GCReport 一次孤儿资源回收的结果
+type GCReport struct {
+	DryRun      bool       `json:"dry_run"`
+	GracePeriod string     `json:"grace_period"`
+	Scanned     int        `json:"scanned"`  // 检查的资源数
+	InGrace     int        `json:"in_grace"` // 疑似孤儿但仍在宽限期内的资源数
+	Orphans     []GCOrphan `json:"orphans"`
+	Deleted     int        `json:"deleted"`
+	Failed      int        `json:"failed"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  time.Time  `json:"finished_at"`
+}
+
+// GitProvider Git托管平台常量
+const (
+	GitProviderGitHub = "github"
+	GitProviderGitLab = "gitlab"
+)
+
+// GitEventAction Git webhook事件归一化后的动作
+const (
+	GitEventOpen   = "open"   // PR创建或重新打开
+	GitEventUpdate = "update" // PR有新的提交，或分支被推送
+	GitEventClose  = "close"  // PR关闭或合并
+)
+
+// GitWebhookResult webhook处理结果的动作
+const (
+	GitWebhookCreated    = "created"
+	GitWebhookRedeployed = "redeployed"
+	GitWebhookDeleted    = "deleted"
+	GitWebhookIgnored    = "ignored"
+)
+
+// GitWebhook Git仓库到项目和模版的映射
+type GitWebhook struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	ProjectID       uuid.UUID  `json:"project_id" db:"project_id"`
+	TemplateID      uuid.UUID  `json:"template_id" db:"template_id"`
+	UserID          *uuid.UUID `json:"user_id" db:"user_id"`
+	Provider        string     `json:"provider" db:"provider"`
+	Repository      string     `json:"repository" db:"repository"`   // 例如 owner/repo 或 group/subgroup/repo
+	Secret          string     `json:"secret,omitempty" db:"secret"` // 仅在创建时返回
+	ImageRepository string     `json:"image_repository" db:"image_repository"`
+	ImageTagFormat  string     `json:"image_tag_format" db:"image_tag_format"` // 支持 {sha} {short_sha} {number} {branch}
+	TTLSeconds      int        `json:"ttl_seconds" db:"ttl_seconds"`
+	WebhookPath     string     `json:"webhook_path" db:"-"` // 在Git平台上配置的接收地址路径
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// CreateGitWebhookRequest 创建Git webhook请求
+type CreateGitWebhookRequest struct {
+	Provider        string    `json:"provider" binding:"required,oneof=github gitlab"`
+	Repository      string    `json:"repository" binding:"required,max=200"`
+	TemplateID      uuid.UUID `json:"template_id" binding:"required"`
+	ImageRepository string    `json:"image_repository" binding:"required"`
+	ImageTagFormat  string    `json:"image_tag_format,omitempty"`                                  // 可选，默认 {sha}
+	TTLSeconds      int       `json:"ttl_seconds,omitempty" binding:"omitempty,min=60,max=604800"` // 可选，默认1天
+	Secret          string    `json:"secret,omitempty" binding:"omitempty,min=16,max=256"`         // 可选，为空时系统生成
+}
+
+// ListGitWebhooksResponse Git webhook列表响应
+type ListGitWebhooksResponse struct {
+	Webhooks []GitWebhook `json:"webhooks"`
+	Total    int          `json:"total"`
+}
+
+// GitEvent 从GitHub/GitLab载荷中解析出的事件
+type GitEvent struct {
+	Action     string // GitEventOpen / GitEventUpdate / GitEventClose
+	Repository string
+	PRNumber   int // 推送事件为0
+	Branch     string
+	CommitSHA  string
+}
+
+// GitWebhookResult webhook处理结果
+type GitWebhookResult struct {
+	Action  string     `json:"action"`
+	URLID   *uuid.UUID `json:"url_id,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+// RegistryPushEvent 从镜像仓库通知中解析出的推送事件
+type RegistryPushEvent struct {
+	Host       string // 镜像仓库地址，例如 registry.example.com:5000
+	Repository string // 例如 acme/web
+	Tag        string
+	Digest     string
+}
+
+// RegistryWebhookResult 镜像仓库webhook处理结果
+type RegistryWebhookResult struct {
+	Events  int         `json:"events"`  // 通知中的推送事件数
+	Updated []uuid.UUID `json:"updated"` // 已滚动更新的URL
+}
+
 // 用户认证相关的请求和响应类型
 
 // LoginRequest 登录请求
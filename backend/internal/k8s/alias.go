@@ -0,0 +1,136 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// AliasFallbackName 内置别名兜底页面的Deployment、Service和ConfigMap名称
+const AliasFallbackName = "url-manager-alias-fallback"
+
+// aliasFallbackPort 兜底页面容器监听端口（非root的nginx镜像默认监听8080）
+const aliasFallbackPort = 8080
+
+// EnsureAliasFallback 确保内置的别名兜底页面已部署，页面内容变化时更新ConfigMap
+func (rm *ResourceManager) EnsureAliasFallback(ctx context.Context, image, html string) error {
+	if rm.client == nil {
+		return fmt.Errorf("Kubernetes client not available")
+	}
+
+	labels := map[string]string{
+		"app":        AliasFallbackName,
+		"managed-by": "url-manager-system",
+	}
+	clientset := rm.client.GetClientset()
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      AliasFallbackName,
+			Namespace: rm.namespace,
+			Labels:    labels,
+		},
+		Data: map[string]string{"index.html": html},
+	}
+	existing, err := clientset.CoreV1().ConfigMaps(rm.namespace).Get(ctx, AliasFallbackName, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		if _, err := clientset.CoreV1().ConfigMaps(rm.namespace).Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create fallback configmap: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get fallback configmap: %w", err)
+	case existing.Data["index.html"] != html:
+		existing.Data = configMap.Data
+		if _, err := clientset.CoreV1().ConfigMaps(rm.namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update fallback configmap: %w", err)
+		}
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      AliasFallbackName,
+			Namespace: rm.namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(1),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": AliasFallbackName},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot: boolPtr(true),
+						RunAsUser:    int64Ptr(101),
+					},
+					Containers: []corev1.Container{
+						{
+							Name:  "fallback",
+							Image: image,
+							Ports: []corev1.ContainerPort{{ContainerPort: aliasFallbackPort}},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resourceQuantity("10m"),
+									corev1.ResourceMemory: resourceQuantity("16Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:    resourceQuantity("100m"),
+									corev1.ResourceMemory: resourceQuantity("64Mi"),
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "html", MountPath: "/usr/share/nginx/html", ReadOnly: true},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "html",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: AliasFallbackName},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if _, err := clientset.AppsV1().Deployments(rm.namespace).Create(ctx, deployment, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create fallback deployment: %w", err)
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      AliasFallbackName,
+			Namespace: rm.namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": AliasFallbackName},
+			Ports: []corev1.ServicePort{
+				{
+					Port:       80,
+					TargetPort: intstr.FromInt(aliasFallbackPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+			Type: corev1.ServiceTypeClusterIP,
+		},
+	}
+	if _, err := clientset.CoreV1().Services(rm.namespace).Create(ctx, service, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create fallback service: %w", err)
+	}
+
+	logrus.WithField("namespace", rm.namespace).Debug("Alias fallback backend ensured")
+	return nil
+}
@@ -0,0 +1,157 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"url-manager-system/backend/internal/db/models"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// failurePriority 同时发现多种问题时按此顺序取最根本的原因
+// 例如配额不足或无法调度时Pod不会启动，镜像拉取失败时容器不会崩溃。
+var failurePriority = []string{
+	models.FailureQuotaExceeded,
+	models.FailureUnschedulable,
+	models.FailureImagePull,
+	models.FailureConfigError,
+	models.FailureOOMKilled,
+	models.FailureCrashLoop,
+	models.FailureProbeFailed,
+}
+
+// failureHints 各类失败的处理建议
+var failureHints = map[string]string{
+	models.FailureQuotaExceeded: "命名空间的资源配额不足，请降低副本数或资源配额，或联系管理员调整ResourceQuota",
+	models.FailureUnschedulable: "没有满足资源请求或调度约束的节点，请降低CPU、内存请求或检查节点资源",
+	models.FailureImagePull:     "镜像拉取失败，请检查镜像名称和标签是否存在，以及私有仓库的拉取凭证",
+	models.FailureConfigError:   "容器配置错误，请检查环境变量引用的Secret和容器配置",
+	models.FailureOOMKilled:     "容器内存超出限制被终止，请提高内存限制或排查内存占用",
+	models.FailureCrashLoop:     "容器启动后反复退出，请查看容器日志排查启动错误",
+	models.FailureProbeFailed:   "健康检查未通过，请检查探针配置以及应用是否在容器端口上正常响应",
+	models.FailureUnknown:       "未能确定失败原因，请查看容器日志和Pod事件",
+}
+
+// waitingFailures 容器等待原因对应的失败分类
+var waitingFailures = map[string]string{
+	"ErrImagePull":               models.FailureImagePull,
+	"ImagePullBackOff":           models.FailureImagePull,
+	"InvalidImageName":           models.FailureImagePull,
+	"CreateContainerConfigError": models.FailureConfigError,
+	"CreateContainerError":       models.FailureConfigError,
+	"CrashLoopBackOff":           models.FailureCrashLoop,
+}
+
+// DiagnoseFailure 根据Deployment状况、Pod状态和Pod事件判断部署失败的原因
+// Deployment不存在时返回nil。
+func (rm *ResourceManager) DiagnoseFailure(ctx context.Context, deploymentName string) (*models.FailureDiagnosis, error) {
+	if rm.client == nil {
+		return nil, fmt.Errorf("Kubernetes client not available")
+	}
+
+	deployment, err := rm.client.GetClientset().AppsV1().Deployments(rm.namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	pods, err := rm.client.GetClientset().CoreV1().Pods(rm.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=ephemeral-url,ephemeral-url-id=%s", deployment.Labels["ephemeral-url-id"]),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	events, err := rm.GetPodEvents(ctx, deploymentName)
+	if err != nil {
+		return nil, err
+	}
+
+	return diagnose(deployment, pods.Items, events), nil
+}
+
+// ClassifyError 按Kubernetes API返回的错误信息分类，无法分类时返回nil
+// 创建资源时的配额错误不会留下Pod，只能从错误信息判断。
+func ClassifyError(message string) *models.FailureDiagnosis {
+	if strings.Contains(message, "exceeded quota") {
+		return newDiagnosis(models.FailureQuotaExceeded, message)
+	}
+	return nil
+}
+
+// diagnose 汇总各处发现的问题，返回优先级最高的一项
+func diagnose(deployment *appsv1.Deployment, pods []corev1.Pod, events []*models.PodEvent) *models.FailureDiagnosis {
+	found := make(map[string]string)
+	record := func(code, reason string) {
+		if _, ok := found[code]; !ok {
+			found[code] = reason
+		}
+	}
+
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentReplicaFailure && cond.Status == corev1.ConditionTrue && strings.Contains(cond.Message, "exceeded quota") {
+			record(models.FailureQuotaExceeded, cond.Message)
+		}
+	}
+
+	for _, pod := range pods {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+				record(models.FailureUnschedulable, cond.Message)
+			}
+		}
+
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.LastTerminationState.Terminated != nil && cs.LastTerminationState.Terminated.Reason == "OOMKilled" ||
+				cs.State.Terminated != nil && cs.State.Terminated.Reason == "OOMKilled" {
+				record(models.FailureOOMKilled, fmt.Sprintf("container %s: OOMKilled", cs.Name))
+			}
+			if w := cs.State.Waiting; w != nil {
+				if code, ok := waitingFailures[w.Reason]; ok {
+					record(code, containerReason(cs.Name, w.Reason, w.Message))
+				}
+			}
+			if t := cs.State.Terminated; t != nil && t.ExitCode != 0 && cs.RestartCount > 0 {
+				record(models.FailureCrashLoop, containerReason(cs.Name, fmt.Sprintf("exit code %d", t.ExitCode), t.Message))
+			}
+		}
+	}
+
+	for _, event := range events {
+		switch {
+		case event.Reason == "FailedScheduling":
+			record(models.FailureUnschedulable, event.Message)
+		case event.Reason == "FailedCreate" && strings.Contains(event.Message, "exceeded quota"):
+			record(models.FailureQuotaExceeded, event.Message)
+		case event.Reason == "Unhealthy":
+			record(models.FailureProbeFailed, event.Message)
+		case event.Reason == "Failed" && strings.Contains(event.Message, "pull"):
+			record(models.FailureImagePull, event.Message)
+		}
+	}
+
+	for _, code := range failurePriority {
+		if reason, ok := found[code]; ok {
+			return newDiagnosis(code, reason)
+		}
+	}
+	return newDiagnosis(models.FailureUnknown, "")
+}
+
+func newDiagnosis(code, reason string) *models.FailureDiagnosis {
+	return &models.FailureDiagnosis{Code: code, Reason: reason, Hint: failureHints[code]}
+}
+
+func containerReason(container, reason, message string) string {
+	if message == "" {
+		return fmt.Sprintf("container %s: %s", container, reason)
+	}
+	return fmt.Sprintf("container %s: %s: %s", container, reason, message)
+}
@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"testing"
+	"url-manager-system/backend/internal/db/models"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func diagnosisTestPod(statuses ...corev1.ContainerStatus) corev1.Pod {
+	return corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: statuses}}
+}
+
+func waitingStatus(reason string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name:  "app",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	oomKilled := waitingStatus("CrashLoopBackOff")
+	oomKilled.RestartCount = 2
+	oomKilled.LastTerminationState.Terminated = &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}
+
+	exited := corev1.ContainerStatus{
+		Name:         "app",
+		RestartCount: 1,
+		State:        corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}},
+	}
+
+	unschedulable := corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+		Type:    corev1.PodScheduled,
+		Status:  corev1.ConditionFalse,
+		Reason:  corev1.PodReasonUnschedulable,
+		Message: "0/3 nodes are available: 3 Insufficient cpu.",
+	}}}}
+
+	quota := &appsv1.Deployment{Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{{
+		Type:    appsv1.DeploymentReplicaFailure,
+		Status:  corev1.ConditionTrue,
+		Reason:  "FailedCreate",
+		Message: `pods "app-1" is forbidden: exceeded quota: compute`,
+	}}}}
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		pods       []corev1.Pod
+		events     []*models.PodEvent
+		want       string
+	}{
+		{"no signal", &appsv1.Deployment{}, nil, nil, models.FailureUnknown},
+		{"image pull", &appsv1.Deployment{}, []corev1.Pod{diagnosisTestPod(waitingStatus("ImagePullBackOff"))}, nil, models.FailureImagePull},
+		{"crash loop", &appsv1.Deployment{}, []corev1.Pod{diagnosisTestPod(waitingStatus("CrashLoopBackOff"))}, nil, models.FailureCrashLoop},
+		{"exited with restarts", &appsv1.Deployment{}, []corev1.Pod{diagnosisTestPod(exited)}, nil, models.FailureCrashLoop},
+		{"oom before crash loop", &appsv1.Deployment{}, []corev1.Pod{diagnosisTestPod(oomKilled)}, nil, models.FailureOOMKilled},
+		{"config error", &appsv1.Deployment{}, []corev1.Pod{diagnosisTestPod(waitingStatus("CreateContainerConfigError"))}, nil, models.FailureConfigError},
+		{"unschedulable", &appsv1.Deployment{}, []corev1.Pod{unschedulable}, nil, models.FailureUnschedulable},
+		{"quota", quota, nil, nil, models.FailureQuotaExceeded},
+		{"probe event", &appsv1.Deployment{}, nil, []*models.PodEvent{{Reason: "Unhealthy", Message: "Readiness probe failed: connection refused"}}, models.FailureProbeFailed},
+		{"scheduling event", &appsv1.Deployment{}, nil, []*models.PodEvent{{Reason: "FailedScheduling"}}, models.FailureUnschedulable},
+		{"image pull before probe", &appsv1.Deployment{}, []corev1.Pod{diagnosisTestPod(waitingStatus("ErrImagePull"))},
+			[]*models.PodEvent{{Reason: "Unhealthy"}}, models.FailureImagePull},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diagnose(tt.deployment, tt.pods, tt.events)
+			if got.Code != tt.want {
+				t.Errorf("diagnose() code = %s, want %s (reason %q)", got.Code, tt.want, got.Reason)
+			}
+			if got.Hint == "" {
+				t.Errorf("diagnose() returned no hint for %s", got.Code)
+			}
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	if d := ClassifyError(`failed to create service: services "x" is forbidden: exceeded quota: services`); d == nil || d.Code != models.FailureQuotaExceeded {
+		t.Errorf("ClassifyError() = %v, want %s", d, models.FailureQuotaExceeded)
+	}
+	if d := ClassifyError("deployment verification timeout"); d != nil {
+		t.Errorf("ClassifyError() = %v, want nil", d)
+	}
+}
@@ -0,0 +1,216 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"url-manager-system/backend/internal/db/models"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DetectDrift 比较集群中URL的Deployment和Service与按URL记录生成的期望规格
+// 只比较系统设置的字段，Kubernetes填充的默认值不视为漂移。
+func (rm *ResourceManager) DetectDrift(ctx context.Context, url *models.EphemeralURL) ([]models.DriftItem, error) {
+	if rm.client == nil {
+		return nil, fmt.Errorf("Kubernetes client not available")
+	}
+
+	var items []models.DriftItem
+
+	desiredDeployment := rm.buildDeploymentSpec(url)
+	liveDeployment, err := rm.client.GetClientset().AppsV1().Deployments(rm.namespace).Get(ctx, desiredDeployment.Name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		items = append(items, missingDrift("Deployment", desiredDeployment.Name))
+	case err != nil:
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
+	default:
+		items = append(items, diffDeployment(desiredDeployment, liveDeployment)...)
+	}
+
+	desiredService := rm.buildServiceSpec(url)
+	liveService, err := rm.client.GetClientset().CoreV1().Services(rm.namespace).Get(ctx, desiredService.Name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		items = append(items, missingDrift("Service", desiredService.Name))
+	case err != nil:
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	default:
+		items = append(items, diffService(desiredService, liveService)...)
+	}
+
+	return items, nil
+}
+
+// RouteDrift 检查URL的路由（按项目路由模式生成的路由和自定义域名路由）是否存在并转发到URL的Service
+// 流量切分会在路由上增加金丝雀Service，只要求路由包含URL的Service。
+func (im *IngressManager) RouteDrift(ctx context.Context, url *models.EphemeralURL, project *models.Project) ([]models.DriftItem, error) {
+	names := []string{routeObjectName(project.Name, "path", url.Path)}
+	if project.RoutingMode == models.RoutingModeSubdomain {
+		names[0] = routeObjectName(project.Name, "host", im.Host(project, url.Path))
+	}
+	if url.IngressHost != nil && *url.IngressHost != "" {
+		names = append(names, routeObjectName(project.Name, "host", *url.IngressHost))
+	}
+
+	var items []models.DriftItem
+	for _, name := range names {
+		route, err := im.provider.GetRoute(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get route: %w", err)
+		}
+		if route == nil {
+			items = append(items, missingDrift("Route", name))
+			continue
+		}
+		if !containsString(route.Services, *url.K8sServiceName) {
+			items = appendDrift(items, route.Kind, name, "backend", *url.K8sServiceName, strings.Join(route.Services, ","))
+		}
+	}
+	return items, nil
+}
+
+// diffDeployment 比较Deployment的副本数和主容器配置
+func diffDeployment(desired, live *appsv1.Deployment) []models.DriftItem {
+	var items []models.DriftItem
+	kind, name := "Deployment", desired.Name
+
+	items = appendDrift(items, kind, name, "replicas", formatReplicas(desired.Spec.Replicas), formatReplicas(live.Spec.Replicas))
+
+	want := desired.Spec.Template.Spec.Containers[0]
+	var got *corev1.Container
+	liveNames := make([]string, 0, len(live.Spec.Template.Spec.Containers))
+	for i := range live.Spec.Template.Spec.Containers {
+		c := &live.Spec.Template.Spec.Containers[i]
+		liveNames = append(liveNames, c.Name)
+		if c.Name == want.Name {
+			got = c
+		}
+	}
+	if got == nil {
+		return appendDrift(items, kind, name, "containers", want.Name, strings.Join(liveNames, ","))
+	}
+
+	field := func(f string) string { return fmt.Sprintf("containers[%s].%s", want.Name, f) }
+	items = appendDrift(items, kind, name, field("image"), want.Image, got.Image)
+	items = appendDrift(items, kind, name, field("command"), strings.Join(want.Command, " "), strings.Join(got.Command, " "))
+	items = appendDrift(items, kind, name, field("args"), strings.Join(want.Args, " "), strings.Join(got.Args, " "))
+	items = appendDrift(items, kind, name, field("workingDir"), want.WorkingDir, got.WorkingDir)
+	items = appendDrift(items, kind, name, field("env"), formatEnv(want.Env), formatEnv(got.Env))
+	items = appendDrift(items, kind, name, field("ports"), formatContainerPorts(want.Ports), formatContainerPorts(got.Ports))
+	for _, res := range []struct {
+		label string
+		want  corev1.ResourceList
+		got   corev1.ResourceList
+		name  corev1.ResourceName
+	}{
+		{"resources.requests.cpu", want.Resources.Requests, got.Resources.Requests, corev1.ResourceCPU},
+		{"resources.requests.memory", want.Resources.Requests, got.Resources.Requests, corev1.ResourceMemory},
+		{"resources.limits.cpu", want.Resources.Limits, got.Resources.Limits, corev1.ResourceCPU},
+		{"resources.limits.memory", want.Resources.Limits, got.Resources.Limits, corev1.ResourceMemory},
+	} {
+		w, g := res.want[res.name], res.got[res.name]
+		if !w.Equal(g) {
+			items = append(items, models.DriftItem{Kind: kind, Name: name, Field: field(res.label), Desired: w.String(), Live: g.String()})
+		}
+	}
+
+	return items
+}
+
+// diffService 比较Service的类型、选择器和端口
+func diffService(desired, live *corev1.Service) []models.DriftItem {
+	var items []models.DriftItem
+	kind, name := "Service", desired.Name
+
+	items = appendDrift(items, kind, name, "type", string(desired.Spec.Type), string(live.Spec.Type))
+	items = appendDrift(items, kind, name, "selector", formatLabels(desired.Spec.Selector), formatLabels(live.Spec.Selector))
+	items = appendDrift(items, kind, name, "ports", formatServicePorts(desired.Spec.Ports), formatServicePorts(live.Spec.Ports))
+	return items
+}
+
+// appendDrift 期望值和实际值不同时追加一条差异
+func appendDrift(items []models.DriftItem, kind, name, field, desired, live string) []models.DriftItem {
+	if desired == live {
+		return items
+	}
+	return append(items, models.DriftItem{Kind: kind, Name: name, Field: field, Desired: desired, Live: live})
+}
+
+// missingDrift 资源不存在的差异
+func missingDrift(kind, name string) models.DriftItem {
+	return models.DriftItem{Kind: kind, Name: name, Desired: "present"}
+}
+
+func formatReplicas(replicas *int32) string {
+	if replicas == nil {
+		return "1"
+	}
+	return fmt.Sprintf("%d", *replicas)
+}
+
+// formatEnv 环境变量按名称排序后格式化，引用Secret的变量不展示值
+func formatEnv(env []corev1.EnvVar) string {
+	parts := make([]string, 0, len(env))
+	for _, e := range env {
+		switch {
+		case e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil:
+			parts = append(parts, fmt.Sprintf("%s=secret:%s/%s", e.Name, e.ValueFrom.SecretKeyRef.Name, e.ValueFrom.SecretKeyRef.Key))
+		case e.ValueFrom != nil:
+			parts = append(parts, e.Name+"=<ref>")
+		default:
+			parts = append(parts, e.Name+"="+e.Value)
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func formatContainerPorts(ports []corev1.ContainerPort) string {
+	parts := make([]string, 0, len(ports))
+	for _, p := range ports {
+		parts = append(parts, fmt.Sprintf("%d/%s", p.ContainerPort, protocolOrTCP(p.Protocol)))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func formatServicePorts(ports []corev1.ServicePort) string {
+	parts := make([]string, 0, len(ports))
+	for _, p := range ports {
+		parts = append(parts, fmt.Sprintf("%d->%s/%s", p.Port, p.TargetPort.String(), protocolOrTCP(p.Protocol)))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func formatLabels(labels map[string]string) string {
+	parts := make([]string, 0, len(labels))
+	for k, v := range labels {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// protocolOrTCP 未设置的协议由Kubernetes默认为TCP
+func protocolOrTCP(protocol corev1.Protocol) corev1.Protocol {
+	if protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return protocol
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"testing"
+	"url-manager-system/backend/internal/db/models"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func driftTestURL() *models.EphemeralURL {
+	deploymentName, serviceName := "ephemeral-a1b2c3d4", "svc-ephemeral-a1b2c3d4"
+	return &models.EphemeralURL{
+		ID:                uuid.New(),
+		ProjectID:         uuid.New(),
+		Image:             "nginx:1.25",
+		Env:               models.EnvironmentVars{{Name: "MODE", Value: "preview"}},
+		Replicas:          2,
+		K8sDeploymentName: &deploymentName,
+		K8sServiceName:    &serviceName,
+	}
+}
+
+func TestDiffDeployment(t *testing.T) {
+	rm := &ResourceManager{namespace: "default"}
+	url := driftTestURL()
+	desired := rm.buildDeploymentSpec(url)
+
+	// Kubernetes填充的默认值不视为漂移
+	live := desired.DeepCopy()
+	live.Spec.Template.Spec.Containers[0].TerminationMessagePath = "/dev/termination-log"
+	live.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
+	if items := diffDeployment(desired, live); len(items) != 0 {
+		t.Fatalf("diffDeployment() with defaults = %+v, want no drift", items)
+	}
+
+	live.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+	live.Spec.Template.Spec.Containers[0].Env = append(live.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "DEBUG", Value: "1"})
+	replicas := int32(0)
+	live.Spec.Replicas = &replicas
+
+	fields := map[string]models.DriftItem{}
+	for _, item := range diffDeployment(desired, live) {
+		fields[item.Field] = item
+	}
+	if len(fields) != 3 {
+		t.Fatalf("diffDeployment() = %+v, want 3 items", fields)
+	}
+	if got := fields["containers[app].image"]; got.Desired != "nginx:1.25" || got.Live != "nginx:latest" {
+		t.Errorf("image drift = %+v", got)
+	}
+	if got := fields["replicas"]; got.Desired != "2" || got.Live != "0" {
+		t.Errorf("replicas drift = %+v", got)
+	}
+	if got := fields["containers[app].env"]; got.Desired != "MODE=preview" || got.Live != "DEBUG=1,MODE=preview" {
+		t.Errorf("env drift = %+v", got)
+	}
+
+	// 主容器被改名时只报告容器差异
+	live = desired.DeepCopy()
+	live.Spec.Template.Spec.Containers[0].Name = "main"
+	items := diffDeployment(desired, live)
+	if len(items) != 1 || items[0].Field != "containers" || items[0].Live != "main" {
+		t.Errorf("diffDeployment() with renamed container = %+v", items)
+	}
+}
+
+func TestDiffService(t *testing.T) {
+	rm := &ResourceManager{namespace: "default"}
+	desired := rm.buildServiceSpec(driftTestURL())
+
+	live := desired.DeepCopy()
+	live.Spec.ClusterIP = "10.0.0.12"
+	if items := diffService(desired, live); len(items) != 0 {
+		t.Fatalf("diffService() with cluster IP = %+v, want no drift", items)
+	}
+
+	live.Spec.Type = corev1.ServiceTypeNodePort
+	live.Spec.Selector = map[string]string{"app": "other"}
+	items := diffService(desired, live)
+	if len(items) != 2 || items[0].Field != "type" || items[1].Field != "selector" {
+		t.Errorf("diffService() = %+v, want type and selector drift", items)
+	}
+}
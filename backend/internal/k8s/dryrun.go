@@ -0,0 +1,120 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"url-manager-system/backend/internal/db/models"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DryRunResourcesFromYAML 以服务端dry-run方式提交YAML中的每个文档
+// 所有文档都会被提交，单个文档失败不影响其他文档；API Server会执行默认值填充、字段校验和准入webhook，但不会持久化任何对象。
+func (rm *ResourceManager) DryRunResourcesFromYAML(ctx context.Context, yamlSpec string) (*models.TemplateDryRunResult, error) {
+	if rm.dynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not available")
+	}
+
+	mapper, err := rm.restMapper()
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := decodeYAMLDocuments(yamlSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.TemplateDryRunResult{
+		Valid:     true,
+		Documents: make([]models.DryRunDocumentResult, 0, len(documents)),
+	}
+
+	for i, doc := range documents {
+		docResult := models.DryRunDocumentResult{Index: i}
+
+		if doc.err != nil {
+			docResult.Status = models.DryRunStatusInvalid
+			docResult.Message = fmt.Sprintf("failed to unmarshal YAML: %v", doc.err)
+			result.Valid = false
+			result.Documents = append(result.Documents, docResult)
+			continue
+		}
+
+		obj := doc.object
+		docResult.APIVersion = obj.GetAPIVersion()
+		docResult.Kind = obj.GetKind()
+		docResult.Name = obj.GetName()
+
+		dr, namespace, err := rm.resourceInterface(mapper, obj)
+		if err != nil {
+			docResult.Status = models.DryRunStatusInvalid
+			docResult.Message = err.Error()
+			result.Valid = false
+			result.Documents = append(result.Documents, docResult)
+			continue
+		}
+		docResult.Namespace = namespace
+
+		created, err := dr.Create(ctx, obj, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+		if err != nil {
+			docResult.Status, docResult.Message, docResult.Causes = classifyDryRunError(err)
+			result.Valid = false
+			result.Documents = append(result.Documents, docResult)
+			continue
+		}
+
+		docResult.Status = models.DryRunStatusValid
+		docResult.Name = created.GetName()
+		docResult.Object = sanitizeDryRunObject(created)
+		result.Documents = append(result.Documents, docResult)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"documents": len(result.Documents),
+		"valid":     result.Valid,
+	}).Debug("Template dry-run completed")
+
+	return result, nil
+}
+
+// classifyDryRunError 将API Server返回的错误转换为dry-run状态、消息和字段级原因
+func classifyDryRunError(err error) (string, string, []models.DryRunCause) {
+	status := models.DryRunStatusError
+	switch {
+	case errors.IsInvalid(err):
+		status = models.DryRunStatusInvalid
+	case errors.IsAlreadyExists(err):
+		status = models.DryRunStatusConflict
+	case errors.IsForbidden(err), strings.Contains(err.Error(), "admission webhook"):
+		status = models.DryRunStatusRejected
+	case errors.IsBadRequest(err):
+		status = models.DryRunStatusInvalid
+	}
+
+	var causes []models.DryRunCause
+	if statusErr, ok := err.(errors.APIStatus); ok {
+		if details := statusErr.Status().Details; details != nil {
+			for _, cause := range details.Causes {
+				causes = append(causes, models.DryRunCause{
+					Field:   cause.Field,
+					Reason:  string(cause.Type),
+					Message: cause.Message,
+				})
+			}
+		}
+	}
+
+	return status, err.Error(), causes
+}
+
+// sanitizeDryRunObject 移除对预览无意义的服务端字段
+func sanitizeDryRunObject(obj *unstructured.Unstructured) map[string]interface{} {
+	object := obj.DeepCopy()
+	unstructured.RemoveNestedField(object.Object, "metadata", "managedFields")
+	return object.Object
+}
@@ -0,0 +1,114 @@
+package k8s
+
+import (
+	"fmt"
+	"testing"
+	"url-manager-system/backend/internal/db/models"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestClassifyDryRunError(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus string
+		wantCauses int
+	}{
+		{
+			name: "invalid",
+			err: errors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "web", field.ErrorList{
+				field.Required(field.NewPath("spec", "selector"), ""),
+				field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+			}),
+			wantStatus: models.DryRunStatusInvalid,
+			wantCauses: 2,
+		},
+		{
+			name:       "already exists",
+			err:        errors.NewAlreadyExists(deployments, "web"),
+			wantStatus: models.DryRunStatusConflict,
+		},
+		{
+			name:       "forbidden",
+			err:        errors.NewForbidden(deployments, "web", fmt.Errorf("exceeded quota")),
+			wantStatus: models.DryRunStatusRejected,
+		},
+		{
+			name:       "admission webhook",
+			err:        errors.NewBadRequest(`admission webhook "policy.example.com" denied the request: image tag latest is not allowed`),
+			wantStatus: models.DryRunStatusRejected,
+		},
+		{
+			name:       "other",
+			err:        fmt.Errorf("connection refused"),
+			wantStatus: models.DryRunStatusError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message, causes := classifyDryRunError(tt.err)
+			if status != tt.wantStatus {
+				t.Errorf("status = %s, expected %s", status, tt.wantStatus)
+			}
+			if message != tt.err.Error() {
+				t.Errorf("message = %q, expected %q", message, tt.err.Error())
+			}
+			if len(causes) != tt.wantCauses {
+				t.Errorf("causes = %+v, expected %d causes", causes, tt.wantCauses)
+			}
+		})
+	}
+}
+
+func TestDecodeYAMLDocuments(t *testing.T) {
+	yamlSpec := `# leading comment
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+---
+metadata:
+  name: missing-kind
+`
+
+	documents, err := decodeYAMLDocuments(yamlSpec)
+	if err != nil {
+		t.Fatalf("decodeYAMLDocuments() error = %v", err)
+	}
+	if len(documents) != 3 {
+		t.Fatalf("documents = %d, expected 3", len(documents))
+	}
+
+	if documents[0].err != nil || documents[0].object.GetKind() != "Service" {
+		t.Errorf("document 0 = %+v", documents[0])
+	}
+	if documents[1].err != nil || documents[1].object.GetName() != "web" {
+		t.Errorf("document 1 = %+v", documents[1])
+	}
+	replicas, _, _ := unstructured.NestedInt64(documents[1].object.Object, "spec", "replicas")
+	if replicas != 2 {
+		t.Errorf("replicas = %d, expected 2", replicas)
+	}
+	if documents[2].err == nil {
+		t.Errorf("document without kind should fail to decode")
+	}
+}
@@ -2,207 +2,254 @@ package k8s
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"strings"
 	"url-manager-system/backend/internal/db/models"
 	"url-manager-system/backend/internal/utils"
 
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 )
 
 // IngressManager Ingress管理器
+// 负责按项目的路由模式和证书配置决定URL的路由，具体资源由配置的路由提供者创建。
 type IngressManager struct {
-	client       *Client
-	namespace    string
-	ingressClass string
-	domain       string
+	client         *Client
+	namespace      string
+	provider       RouteProvider
+	domain         string
+	wildcardDomain string
+	certIssuer     string
+}
+
+// IngressOptions 路由相关配置
+type IngressOptions struct {
+	// Provider 路由提供者：nginx、traefik或gateway-api
+	Provider       string
+	IngressClass   string
+	Domain         string
+	WildcardDomain string
+	CertIssuer     string
+	// Gateway API模式下HTTPRoute挂载的Gateway
+	GatewayName      string
+	GatewayNamespace string
 }
 
 // NewIngressManager 创建Ingress管理器
-func NewIngressManager(client *Client, namespace, ingressClass, domain string) *IngressManager {
+func NewIngressManager(client *Client, namespace string, opts IngressOptions) *IngressManager {
 	return &IngressManager{
-		client:       client,
-		namespace:    namespace,
-		ingressClass: ingressClass,
-		domain:       domain,
+		client:         client,
+		namespace:      namespace,
+		provider:       NewRouteProvider(client, namespace, opts),
+		domain:         opts.Domain,
+		wildcardDomain: opts.WildcardDomain,
+		certIssuer:     opts.CertIssuer,
 	}
 }
 
-// AddPath 向项目的Ingress添加路径
-func (im *IngressManager) AddPath(ctx context.Context, url *models.EphemeralURL, projectName string) error {
-	// 清理项目名称以符合Kubernetes命名规范
-	sanitizedProjectName := utils.SanitizeKubernetesName(projectName)
-	ingressName := fmt.Sprintf("project-%s-ingress", sanitizedProjectName)
-
-	// 首先尝试获取现有的Ingress
-	ingress, err := im.client.GetClientset().NetworkingV1().Ingresses(im.namespace).Get(ctx, ingressName, metav1.GetOptions{})
-	if err != nil {
-		// 如果Ingress不存在，创建新的
-		if errors.IsNotFound(err) {
-			return im.createProjectIngress(ctx, ingressName, url, sanitizedProjectName)
-		}
-		return err
+// Host 返回项目下URL的访问域名，子域名模式下为独立子域名，否则为默认域名
+func (im *IngressManager) Host(project *models.Project, path string) string {
+	if project.RoutingMode == models.RoutingModeSubdomain {
+		return utils.SubdomainHost(path, project.Name, im.wildcardDomain)
 	}
+	return im.domain
+}
 
-	// 如果Ingress存在，添加新路径
-	return im.addPathToExistingIngress(ctx, ingress, url)
+// TLS 返回项目使用该证书Secret时的路由证书配置，secretName为空表示不配置TLS
+func (im *IngressManager) TLS(project *models.Project, secretName *string) *RouteTLS {
+	if secretName == nil {
+		return nil
+	}
+	tls := &RouteTLS{SecretName: *secretName}
+	if project.TLSMode == models.TLSModeCertManager {
+		tls.Issuer = im.certIssuer
+	}
+	return tls
 }
 
-// RemovePath 从项目的Ingress中移除路径
-func (im *IngressManager) RemovePath(ctx context.Context, projectName, path string) error {
-	// 清理项目名称以符合Kubernetes命名规范
-	sanitizedProjectName := utils.SanitizeKubernetesName(projectName)
-	ingressName := fmt.Sprintf("project-%s-ingress", sanitizedProjectName)
+// AddRoute 按项目的路由模式为URL添加路由，URL指定了证书Secret时同时配置TLS
+// URL设置了自定义域名时额外添加该域名的路由。重复调用（重新部署）时替换已有路由的后端。
+func (im *IngressManager) AddRoute(ctx context.Context, url *models.EphemeralURL, project *models.Project) error {
+	tls := im.TLS(project, url.TLSSecretName)
+	if tls != nil && project.TLSMode == models.TLSModeCertManager && tls.Issuer == "" {
+		return fmt.Errorf("cert-manager issuer is not configured")
+	}
 
-	ingress, err := im.client.GetClientset().NetworkingV1().Ingresses(im.namespace).Get(ctx, ingressName, metav1.GetOptions{})
-	if err != nil {
-		if errors.IsNotFound(err) {
-			// Ingress不存在，忽略错误
-			return nil
+	var err error
+	if project.RoutingMode == models.RoutingModeSubdomain {
+		if im.wildcardDomain == "" {
+			return fmt.Errorf("subdomain routing is not configured")
 		}
+		err = im.provider.SetHost(ctx, project.Name, im.Host(project, url.Path), *url.K8sServiceName, 80, tls)
+	} else {
+		err = im.provider.SetPath(ctx, project.Name, im.domain, url.Path, *url.K8sServiceName, 80, tls)
+	}
+	if err != nil || url.IngressHost == nil || *url.IngressHost == "" {
 		return err
 	}
 
-	return im.removePathFromIngress(ctx, ingress, path)
-}
-
-// createProjectIngress 创建项目的Ingress
-func (im *IngressManager) createProjectIngress(ctx context.Context, ingressName string, url *models.EphemeralURL, projectName string) error {
-	pathType := networkingv1.PathTypePrefix
-
-	ingress := &networkingv1.Ingress{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      ingressName,
-			Namespace: im.namespace,
-			Labels: map[string]string{
-				"app":        "url-manager-system",
-				"project":    utils.SanitizeKubernetesLabel(projectName),
-				"managed-by": "url-manager-system",
-			},
-			Annotations: map[string]string{
-				"kubernetes.io/ingress.class":                    im.ingressClass,
-				"nginx.ingress.kubernetes.io/rewrite-target":     "/",
-				"nginx.ingress.kubernetes.io/ssl-redirect":       "false",
-				"nginx.ingress.kubernetes.io/force-ssl-redirect": "false",
-			},
-		},
-		Spec: networkingv1.IngressSpec{
-			Rules: []networkingv1.IngressRule{
-				{
-					Host: im.domain,
-					IngressRuleValue: networkingv1.IngressRuleValue{
-						HTTP: &networkingv1.HTTPIngressRuleValue{
-							Paths: []networkingv1.HTTPIngressPath{
-								{
-									Path:     url.Path,
-									PathType: &pathType,
-									Backend: networkingv1.IngressBackend{
-										Service: &networkingv1.IngressServiceBackend{
-											Name: *url.K8sServiceName,
-											Port: networkingv1.ServiceBackendPort{
-												Number: 80,
-											},
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-
-	_, err := im.client.GetClientset().NetworkingV1().Ingresses(im.namespace).Create(ctx, ingress, metav1.CreateOptions{})
-	return err
-}
-
-// addPathToExistingIngress 向现有Ingress添加路径
-func (im *IngressManager) addPathToExistingIngress(ctx context.Context, ingress *networkingv1.Ingress, url *models.EphemeralURL) error {
-	pathType := networkingv1.PathTypePrefix
-
-	newPath := networkingv1.HTTPIngressPath{
-		Path:     url.Path,
-		PathType: &pathType,
-		Backend: networkingv1.IngressBackend{
-			Service: &networkingv1.IngressServiceBackend{
-				Name: *url.K8sServiceName,
-				Port: networkingv1.ServiceBackendPort{
-					Number: 80,
-				},
-			},
-		},
-	}
-
-	// 构建JSON Patch
-	patch := []map[string]interface{}{
-		{
-			"op":    "add",
-			"path":  "/spec/rules/0/http/paths/-",
-			"value": newPath,
-		},
-	}
-
-	patchBytes, err := json.Marshal(patch)
-	if err != nil {
+	return im.provider.SetHost(ctx, project.Name, *url.IngressHost, *url.K8sServiceName, 80, im.customHostTLS(project, *url.IngressHost))
+}
+
+// customHostTLS 自定义域名的证书配置，项目的证书Secret不覆盖自定义域名，仅cert-manager模式下为其单独签发证书
+func (im *IngressManager) customHostTLS(project *models.Project, host string) *RouteTLS {
+	if project.TLSMode != models.TLSModeCertManager {
+		return nil
+	}
+	return &RouteTLS{SecretName: host + "-tls", Issuer: im.certIssuer}
+}
+
+// RemoveRoute 移除URL的路由
+// 同时清理路径路由、子域名路由和自定义域名路由，不依赖项目当前的路由模式，不存在时都会被忽略。
+func (im *IngressManager) RemoveRoute(ctx context.Context, url *models.EphemeralURL, projectName string) error {
+	if err := im.RemovePath(ctx, projectName, url.Path); err != nil {
 		return err
 	}
+	if im.wildcardDomain != "" {
+		if err := im.provider.RemoveHost(ctx, projectName, utils.SubdomainHost(url.Path, projectName, im.wildcardDomain)); err != nil {
+			return err
+		}
+	}
+	if url.IngressHost == nil || *url.IngressHost == "" {
+		return nil
+	}
+	return im.RemoveHost(ctx, projectName, *url.IngressHost)
+}
+
+// RemoveHost 移除自定义域名的路由及其证书配置
+func (im *IngressManager) RemoveHost(ctx context.Context, projectName, host string) error {
+	return im.provider.RemoveHost(ctx, projectName, host)
+}
 
-	_, err = im.client.GetClientset().NetworkingV1().Ingresses(im.namespace).Patch(
-		ctx,
-		ingress.Name,
-		types.JSONPatchType,
-		patchBytes,
-		metav1.PatchOptions{},
-	)
+// ListRoutes 列出系统管理的全部路由资源
+func (im *IngressManager) ListRoutes(ctx context.Context) ([]RouteObject, error) {
+	return im.provider.ListRoutes(ctx)
+}
 
-	return err
+// DeleteRoute 删除路由资源及其附属资源
+func (im *IngressManager) DeleteRoute(ctx context.Context, route RouteObject) error {
+	return im.provider.DeleteRoute(ctx, route)
 }
 
-// removePathFromIngress 从Ingress中移除路径
-func (im *IngressManager) removePathFromIngress(ctx context.Context, ingress *networkingv1.Ingress, path string) error {
-	if len(ingress.Spec.Rules) == 0 || ingress.Spec.Rules[0].HTTP == nil {
-		return nil
+// SetSplit 将主URL路由上canaryWeight%的流量转发给金丝雀URL的Service，其余流量仍由主URL处理
+// 只切分按项目路由模式生成的路由，自定义域名的路由不受影响。
+func (im *IngressManager) SetSplit(ctx context.Context, project *models.Project, primary, canary *models.EphemeralURL, canaryWeight int) error {
+	host, path := im.splitRoute(project, primary.Path)
+	return im.provider.SetSplit(ctx, project.Name, host, path, *primary.K8sServiceName, *canary.K8sServiceName, 80, canaryWeight)
+}
+
+// RemoveSplit 移除主URL路由上的流量切分，全部流量回到主URL
+func (im *IngressManager) RemoveSplit(ctx context.Context, project *models.Project, primary *models.EphemeralURL) error {
+	host, path := im.splitRoute(project, primary.Path)
+	return im.provider.RemoveSplit(ctx, project.Name, host, path, *primary.K8sServiceName, 80)
+}
+
+// splitRoute 返回URL路由的域名和路径，子域名模式下路径为空
+func (im *IngressManager) splitRoute(project *models.Project, path string) (string, string) {
+	if project.RoutingMode == models.RoutingModeSubdomain {
+		return im.Host(project, path), ""
 	}
+	return im.domain, path
+}
+
+// SetAliasPath 将默认域名下的别名路径指向指定的Service，路径不存在时添加，存在时原子替换后端
+// tls为nil时保留路由现有的TLS配置。
+func (im *IngressManager) SetAliasPath(ctx context.Context, projectName, path, serviceName string, port int32, tls *RouteTLS) error {
+	return im.provider.SetPath(ctx, projectName, im.domain, path, serviceName, port, tls)
+}
+
+// RemovePath 移除默认域名下的路径路由
+func (im *IngressManager) RemovePath(ctx context.Context, projectName, path string) error {
+	return im.provider.RemovePath(ctx, projectName, im.domain, path)
+}
+
+// certManagerIssuerAnnotation 旧版本共用Ingress上由cert-manager签发证书使用的注解
+const certManagerIssuerAnnotation = "cert-manager.io/cluster-issuer"
 
-	paths := ingress.Spec.Rules[0].HTTP.Paths
-	pathIndex := -1
+// SplitSharedIngresses 将旧版本每个项目共用的Ingress拆分为每条路由独立的路由资源，返回迁移的路由数
+// 先通过当前的路由提供者创建所有路由，全部成功后再按resourceVersion删除共用Ingress，
+// 期间共用Ingress被修改时删除失败，保留原Ingress等待下次迁移，不会丢失路由。
+func (im *IngressManager) SplitSharedIngresses(ctx context.Context, projectName string) (int, error) {
+	sanitizedProjectName := utils.SanitizeKubernetesName(projectName)
+	ingresses := im.client.GetClientset().NetworkingV1().Ingresses(im.namespace)
+
+	migrated := 0
+	for _, name := range []string{
+		fmt.Sprintf("project-%s-ingress", sanitizedProjectName),
+		fmt.Sprintf("project-%s-hosts-ingress", sanitizedProjectName),
+	} {
+		ingress, err := ingresses.Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return migrated, err
+		}
 
-	// 找到要删除的路径索引
-	for i, p := range paths {
-		if p.Path == path {
-			pathIndex = i
-			break
+		hostRoutes := strings.HasSuffix(name, "-hosts-ingress")
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			tls := legacyIngressTLS(ingress, rule.Host)
+			for _, p := range rule.HTTP.Paths {
+				if p.Backend.Service == nil {
+					continue
+				}
+				serviceName, port := p.Backend.Service.Name, p.Backend.Service.Port.Number
+				if hostRoutes {
+					err = im.provider.SetHost(ctx, projectName, rule.Host, serviceName, port, tls)
+				} else {
+					err = im.provider.SetPath(ctx, projectName, rule.Host, legacyRoutePath(p.Path), serviceName, port, tls)
+				}
+				if err != nil {
+					return migrated, fmt.Errorf("failed to migrate route %s%s: %w", rule.Host, p.Path, err)
+				}
+				migrated++
+			}
 		}
-	}
 
-	if pathIndex == -1 {
-		return nil // 路径不存在
+		err = ingresses.Delete(ctx, name, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{ResourceVersion: &ingress.ResourceVersion},
+		})
+		if err != nil && !errors.IsNotFound(err) {
+			return migrated, fmt.Errorf("failed to delete shared ingress %s: %w", name, err)
+		}
 	}
 
-	// 构建JSON Patch来删除路径
-	patch := []map[string]interface{}{
-		{
-			"op":   "remove",
-			"path": fmt.Sprintf("/spec/rules/0/http/paths/%d", pathIndex),
-		},
-	}
+	return migrated, nil
+}
 
-	patchBytes, err := json.Marshal(patch)
-	if err != nil {
-		return err
+// legacyRoutePath 还原共用Ingress中的URL路径，兼容普通前缀路径和nginx正则路径
+func legacyRoutePath(ingressPath string) string {
+	path := strings.TrimSuffix(ingressPath, "(/|$)(.*)")
+	if path == ingressPath {
+		return path
 	}
 
-	_, err = im.client.GetClientset().NetworkingV1().Ingresses(im.namespace).Patch(
-		ctx,
-		ingress.Name,
-		types.JSONPatchType,
-		patchBytes,
-		metav1.PatchOptions{},
-	)
+	// 去掉regexp.QuoteMeta添加的转义
+	var b strings.Builder
+	escaped := false
+	for _, r := range path {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
 
-	return err
+// legacyIngressTLS 共用Ingress中覆盖该域名的证书配置
+func legacyIngressTLS(ingress *networkingv1.Ingress, host string) *RouteTLS {
+	for _, entry := range ingress.Spec.TLS {
+		for _, h := range entry.Hosts {
+			if h == host {
+				return &RouteTLS{SecretName: entry.SecretName, Issuer: ingress.Annotations[certManagerIssuerAnnotation]}
+			}
+		}
+	}
+	return nil
 }
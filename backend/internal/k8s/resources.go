@@ -1,23 +1,27 @@
 package k8s
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 	"url-manager-system/backend/internal/db/models"
 
 	"github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v2"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/restmapper"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 // ResourceManager Kubernetes资源管理器
@@ -90,6 +94,33 @@ func (rm *ResourceManager) UpdateDeployment(ctx context.Context, url *models.Eph
 	return nil
 }
 
+// SetDeploymentImage 只替换Deployment主容器的镜像，用于模版创建的URL滚动更新
+func (rm *ResourceManager) SetDeploymentImage(ctx context.Context, name, image string) error {
+	if rm.client == nil {
+		return fmt.Errorf("Kubernetes client not available")
+	}
+
+	deployments := rm.client.GetClientset().AppsV1().Deployments(rm.namespace)
+	deployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get existing deployment: %w", err)
+	}
+	if len(deployment.Spec.Template.Spec.Containers) == 0 {
+		return fmt.Errorf("deployment %s has no containers", name)
+	}
+
+	deployment.Spec.Template.Spec.Containers[0].Image = image
+	if _, err := deployments.Update(ctx, deployment, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update deployment: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"deployment": name,
+		"image":      image,
+	}).Info("Deployment image updated")
+	return nil
+}
+
 // CreateOrUpdateDeployment 创建或更新Deployment
 func (rm *ResourceManager) CreateOrUpdateDeployment(ctx context.Context, url *models.EphemeralURL) error {
 	if rm.client == nil {
@@ -341,7 +372,19 @@ func (rm *ResourceManager) CreateSecret(ctx context.Context, url *models.Ephemer
 		Data: secretData,
 	}
 
-	_, err := rm.client.GetClientset().CoreV1().Secrets(rm.namespace).Create(ctx, secret, metav1.CreateOptions{})
+	secrets := rm.client.GetClientset().CoreV1().Secrets(rm.namespace)
+	_, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+	if !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	// 重新部署或重试时Secret已存在，更新为当前的环境变量
+	existing, err := secrets.Get(ctx, secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	existing.Data = secretData
+	_, err = secrets.Update(ctx, existing, metav1.UpdateOptions{})
 	return err
 }
 
@@ -378,6 +421,76 @@ func (rm *ResourceManager) DeleteSecret(ctx context.Context, name string) error
 	return err
 }
 
+// ManagedResource 系统为URL创建的Kubernetes资源
+type ManagedResource struct {
+	Kind      string // Deployment、Service或Secret
+	Name      string
+	URLID     string // ephemeral-url-id标签
+	CreatedAt time.Time
+}
+
+// managedResourceSelector 系统为URL创建的资源的标签选择器
+const managedResourceSelector = "app=ephemeral-url,managed-by=url-manager-system"
+
+// ListManagedResources 列出系统为URL创建的Deployment、Service和Secret
+func (rm *ResourceManager) ListManagedResources(ctx context.Context) ([]ManagedResource, error) {
+	opts := metav1.ListOptions{LabelSelector: managedResourceSelector}
+	clientset := rm.client.GetClientset()
+	var resources []ManagedResource
+
+	deployments, err := clientset.AppsV1().Deployments(rm.namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		resources = append(resources, ManagedResource{Kind: "Deployment", Name: d.Name, URLID: d.Labels["ephemeral-url-id"], CreatedAt: d.CreationTimestamp.Time})
+	}
+
+	services, err := clientset.CoreV1().Services(rm.namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	for _, svc := range services.Items {
+		resources = append(resources, ManagedResource{Kind: "Service", Name: svc.Name, URLID: svc.Labels["ephemeral-url-id"], CreatedAt: svc.CreationTimestamp.Time})
+	}
+
+	secrets, err := clientset.CoreV1().Secrets(rm.namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for _, secret := range secrets.Items {
+		resources = append(resources, ManagedResource{Kind: "Secret", Name: secret.Name, URLID: secret.Labels["ephemeral-url-id"], CreatedAt: secret.CreationTimestamp.Time})
+	}
+
+	return resources, nil
+}
+
+// DeleteManagedResource 删除ListManagedResources返回的资源
+func (rm *ResourceManager) DeleteManagedResource(ctx context.Context, res ManagedResource) error {
+	switch res.Kind {
+	case "Deployment":
+		return rm.DeleteDeployment(ctx, res.Name)
+	case "Service":
+		return rm.DeleteService(ctx, res.Name)
+	case "Secret":
+		return rm.DeleteSecret(ctx, res.Name)
+	default:
+		return fmt.Errorf("unsupported resource kind: %s", res.Kind)
+	}
+}
+
+// CheckTLSSecretReady 检查证书Secret是否已包含证书，Secret不存在时返回未就绪
+func (rm *ResourceManager) CheckTLSSecretReady(ctx context.Context, name string) (bool, error) {
+	secret, err := rm.client.GetClientset().CoreV1().Secrets(rm.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return len(secret.Data[corev1.TLSCertKey]) > 0 && len(secret.Data[corev1.TLSPrivateKeyKey]) > 0, nil
+}
+
 // CheckDeploymentReady 检查Deployment是否就绪
 func (rm *ResourceManager) CheckDeploymentReady(ctx context.Context, name string) (bool, error) {
 	deployment, err := rm.client.GetClientset().AppsV1().Deployments(rm.namespace).Get(ctx, name, metav1.GetOptions{})
@@ -505,9 +618,48 @@ func (rm *ResourceManager) buildContainerSpec(url *models.EphemeralURL) corev1.C
 		container.VolumeDevices = rm.buildVolumeDevices(url.ContainerConfig.Devices)
 	}
 
+	// 设置健康检查探针
+	container.ReadinessProbe = buildProbe(url.ContainerConfig.ReadinessProbe)
+	container.LivenessProbe = buildProbe(url.ContainerConfig.LivenessProbe)
+
 	return container
 }
 
+// buildProbe 将探针配置转换为Kubernetes探针，未配置时返回nil
+// 未指定端口时使用容器端口80，时间和次数为0时使用Kubernetes默认值。
+func buildProbe(probe *models.ContainerProbe) *corev1.Probe {
+	if probe == nil {
+		return nil
+	}
+
+	port := probe.Port
+	if port == 0 {
+		port = 80
+	}
+
+	result := &corev1.Probe{
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		FailureThreshold:    probe.FailureThreshold,
+	}
+	switch probe.Type {
+	case models.ProbeTypeHTTP:
+		path := probe.Path
+		if path == "" {
+			path = "/"
+		}
+		result.HTTPGet = &corev1.HTTPGetAction{Path: path, Port: intstr.FromInt(int(port))}
+	case models.ProbeTypeTCP:
+		result.TCPSocket = &corev1.TCPSocketAction{Port: intstr.FromInt(int(port))}
+	case models.ProbeTypeExec:
+		result.Exec = &corev1.ExecAction{Command: probe.Command}
+	default:
+		return nil
+	}
+	return result
+}
+
 // buildVolumeDevices 构建设备映射
 func (rm *ResourceManager) buildVolumeDevices(devices models.DeviceMappings) []corev1.VolumeDevice {
 	var volumeDevices []corev1.VolumeDevice
@@ -539,56 +691,102 @@ func (rm *ResourceManager) CreateResourcesFromYAML(ctx context.Context, yamlSpec
 		return fmt.Errorf("dynamic client not available")
 	}
 
-	// 使用restmapper获取资源映射
-	groupResources, err := restmapper.GetAPIGroupResources(rm.client.GetClientset().Discovery())
+	mapper, err := rm.restMapper()
 	if err != nil {
-		return fmt.Errorf("failed to get API group resources: %w", err)
+		return err
 	}
 
-	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
-
 	// 按文档分割YAML（支持多个资源）
-	documents := strings.Split(yamlSpec, "\n---")
+	documents, err := decodeYAMLDocuments(yamlSpec)
+	if err != nil {
+		return err
+	}
+
 	for _, doc := range documents {
-		doc = strings.TrimSpace(doc)
-		if doc == "" {
-			continue
+		if doc.err != nil {
+			return fmt.Errorf("failed to unmarshal YAML: %w", doc.err)
 		}
+		obj := doc.object
 
-		// 解析YAML到通用对象
-		obj := &unstructured.Unstructured{}
-		if err := yaml.Unmarshal([]byte(doc), obj); err != nil {
-			return fmt.Errorf("failed to unmarshal YAML: %w", err)
+		// 获取资源接口
+		gvk := obj.GroupVersionKind()
+		dr, _, err := rm.resourceInterface(mapper, obj)
+		if err != nil {
+			return err
 		}
 
-		// 如果对象为空，跳过
-		if obj.Object == nil {
-			continue
+		// 创建资源，已存在时视为之前的尝试已创建（资源名称按URL生成）
+		_, err = dr.Create(ctx, obj, metav1.CreateOptions{})
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create resource %s: %w", gvk.String(), err)
 		}
+	}
 
-		// 获取资源映射
-		gvk := obj.GroupVersionKind()
-		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	return nil
+}
+
+// yamlDocument 多文档YAML中的单个非空文档
+type yamlDocument struct {
+	object *unstructured.Unstructured
+	err    error // 文档解析失败的原因
+}
+
+// decodeYAMLDocuments 将多文档YAML解析为通用对象，空文档会被跳过
+func decodeYAMLDocuments(yamlSpec string) ([]yamlDocument, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(yamlSpec)))
+
+	var documents []yamlDocument
+	for {
+		raw, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			return fmt.Errorf("failed to get REST mapping for %s: %w", gvk.String(), err)
+			return nil, fmt.Errorf("failed to read YAML documents: %w", err)
 		}
 
-		// 获取资源接口
-		var dr dynamic.ResourceInterface
-		if mapping.Scope.Name() == "namespace" {
-			dr = rm.dynamicClient.Resource(mapping.Resource).Namespace(rm.namespace)
-		} else {
-			dr = rm.dynamicClient.Resource(mapping.Resource)
+		jsonData, err := sigsyaml.YAMLToJSON(raw)
+		if err != nil {
+			documents = append(documents, yamlDocument{err: err})
+			continue
+		}
+		// 空文档或只有注释的文档
+		if trimmed := strings.TrimSpace(string(jsonData)); trimmed == "null" || trimmed == "{}" {
+			continue
 		}
 
-		// 创建资源
-		_, err = dr.Create(ctx, obj, metav1.CreateOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to create resource %s: %w", gvk.String(), err)
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(jsonData); err != nil {
+			documents = append(documents, yamlDocument{err: err})
+			continue
 		}
+		documents = append(documents, yamlDocument{object: obj})
 	}
 
-	return nil
+	return documents, nil
+}
+
+// restMapper 通过discovery构建资源映射
+func (rm *ResourceManager) restMapper() (meta.RESTMapper, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(rm.client.GetClientset().Discovery())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API group resources: %w", err)
+	}
+	return restmapper.NewDiscoveryRESTMapper(groupResources), nil
+}
+
+// resourceInterface 获取对象对应的动态客户端接口，并返回对象实际所在的命名空间
+func (rm *ResourceManager) resourceInterface(mapper meta.RESTMapper, obj *unstructured.Unstructured) (dynamic.ResourceInterface, string, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get REST mapping for %s: %w", gvk.String(), err)
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return rm.dynamicClient.Resource(mapping.Resource).Namespace(rm.namespace), rm.namespace, nil
+	}
+	return rm.dynamicClient.Resource(mapping.Resource), "", nil
 }
 
 // GetContainerStatus 获取容器状态
@@ -0,0 +1,31 @@
+package k8s
+
+import (
+	"testing"
+	"url-manager-system/backend/internal/db/models"
+)
+
+func TestBuildProbe(t *testing.T) {
+	if probe := buildProbe(nil); probe != nil {
+		t.Errorf("buildProbe(nil) = %v, want nil", probe)
+	}
+
+	http := buildProbe(&models.ContainerProbe{Type: models.ProbeTypeHTTP, FailureThreshold: 3})
+	if http == nil || http.HTTPGet == nil {
+		t.Fatalf("buildProbe(http) = %v, want HTTPGet action", http)
+	}
+	if http.HTTPGet.Path != "/" || http.HTTPGet.Port.IntValue() != 80 || http.FailureThreshold != 3 {
+		t.Errorf("buildProbe(http) = path %q port %d threshold %d, want / 80 3",
+			http.HTTPGet.Path, http.HTTPGet.Port.IntValue(), http.FailureThreshold)
+	}
+
+	tcp := buildProbe(&models.ContainerProbe{Type: models.ProbeTypeTCP, Port: 5432})
+	if tcp == nil || tcp.TCPSocket == nil || tcp.TCPSocket.Port.IntValue() != 5432 {
+		t.Errorf("buildProbe(tcp) = %v, want TCPSocket on 5432", tcp)
+	}
+
+	exec := buildProbe(&models.ContainerProbe{Type: models.ProbeTypeExec, Command: []string{"cat", "/tmp/ready"}})
+	if exec == nil || exec.Exec == nil || len(exec.Exec.Command) != 2 {
+		t.Errorf("buildProbe(exec) = %v, want Exec action", exec)
+	}
+}
@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RolloutStatus Deployment更新的进展
+type RolloutStatus struct {
+	Complete bool   // 新版本的Pod全部可用且旧版本已缩容
+	Failed   bool   // 更新已失败（超过进度截止时间或容器反复重启），需要回滚
+	Problem  string // 更新失败的原因，或新Pod当前存在的问题（崩溃、镜像拉取失败），为空表示正常
+}
+
+// podProblemReasons 容器处于这些等待原因时视为更新出现问题
+var podProblemReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// GetRolloutStatus 获取Deployment更新的进展
+// 只检查since之后创建的Pod，更新前已存在的Pod的重启次数不计入。
+func (rm *ResourceManager) GetRolloutStatus(ctx context.Context, name string, since time.Time, restartThreshold int32) (*RolloutStatus, error) {
+	if rm.client == nil {
+		return nil, fmt.Errorf("Kubernetes client not available")
+	}
+
+	deployment, err := rm.client.GetClientset().AppsV1().Deployments(rm.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	pods, err := rm.client.GetClientset().CoreV1().Pods(rm.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=ephemeral-url,ephemeral-url-id=%s", deployment.Labels["ephemeral-url-id"]),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	status := evaluateRollout(deployment, pods.Items, since, restartThreshold)
+	return &status, nil
+}
+
+// evaluateRollout 根据Deployment状态和Pod状态判断更新进展
+func evaluateRollout(deployment *appsv1.Deployment, pods []corev1.Pod, since time.Time, restartThreshold int32) RolloutStatus {
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == "ProgressDeadlineExceeded" {
+			return RolloutStatus{Failed: true, Problem: "progress deadline exceeded"}
+		}
+	}
+
+	var status RolloutStatus
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || pod.CreationTimestamp.Time.Before(since) {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if restartThreshold > 0 && cs.RestartCount >= restartThreshold {
+				return RolloutStatus{Failed: true, Problem: fmt.Sprintf("container %s restarted %d times", cs.Name, cs.RestartCount)}
+			}
+			if cs.State.Waiting != nil && podProblemReasons[cs.State.Waiting.Reason] && status.Problem == "" {
+				status.Problem = fmt.Sprintf("container %s: %s", cs.Name, cs.State.Waiting.Reason)
+			}
+		}
+	}
+
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	s := deployment.Status
+	status.Complete = status.Problem == "" &&
+		s.ObservedGeneration >= deployment.Generation &&
+		s.UpdatedReplicas == desired &&
+		s.Replicas == desired &&
+		s.AvailableReplicas == desired
+	return status
+}
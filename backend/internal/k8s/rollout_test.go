@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func rolloutTestPod(created time.Time, restarts int32, waitingReason string) corev1.Pod {
+	cs := corev1.ContainerStatus{Name: "app", RestartCount: restarts}
+	if waitingReason != "" {
+		cs.State.Waiting = &corev1.ContainerStateWaiting{Reason: waitingReason}
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+		Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{cs}},
+	}
+}
+
+func TestEvaluateRollout(t *testing.T) {
+	since := time.Now()
+	before, after := since.Add(-time.Hour), since.Add(time.Second)
+	replicas := int32(2)
+
+	deployment := func(updated, available int32, conditions ...appsv1.DeploymentCondition) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: 3},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: 3,
+				Replicas:           replicas,
+				UpdatedReplicas:    updated,
+				AvailableReplicas:  available,
+				Conditions:         conditions,
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		pods       []corev1.Pod
+		want       RolloutStatus
+	}{
+		{
+			name:       "complete",
+			deployment: deployment(2, 2),
+			pods:       []corev1.Pod{rolloutTestPod(after, 0, ""), rolloutTestPod(after, 0, "")},
+			want:       RolloutStatus{Complete: true},
+		},
+		{
+			name:       "in progress",
+			deployment: deployment(1, 2),
+			pods:       []corev1.Pod{rolloutTestPod(before, 0, ""), rolloutTestPod(after, 0, "ContainerCreating")},
+			want:       RolloutStatus{},
+		},
+		{
+			name:       "crash loop below threshold",
+			deployment: deployment(1, 2),
+			pods:       []corev1.Pod{rolloutTestPod(after, 1, "CrashLoopBackOff")},
+			want:       RolloutStatus{Problem: "container app: CrashLoopBackOff"},
+		},
+		{
+			name:       "restart threshold reached",
+			deployment: deployment(1, 2),
+			pods:       []corev1.Pod{rolloutTestPod(after, 3, "CrashLoopBackOff")},
+			want:       RolloutStatus{Failed: true, Problem: "container app restarted 3 times"},
+		},
+		{
+			name:       "restarts of pods before the update are ignored",
+			deployment: deployment(2, 2),
+			pods:       []corev1.Pod{rolloutTestPod(before, 7, ""), rolloutTestPod(after, 0, "")},
+			want:       RolloutStatus{Complete: true},
+		},
+		{
+			name: "progress deadline exceeded",
+			deployment: deployment(1, 2, appsv1.DeploymentCondition{
+				Type:   appsv1.DeploymentProgressing,
+				Status: corev1.ConditionFalse,
+				Reason: "ProgressDeadlineExceeded",
+			}),
+			want: RolloutStatus{Failed: true, Problem: "progress deadline exceeded"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluateRollout(tt.deployment, tt.pods, since, 3); got != tt.want {
+				t.Errorf("evaluateRollout() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
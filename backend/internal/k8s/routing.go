@@ -0,0 +1,314 @@
+package k8s
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"time"
+	"url-manager-system/backend/internal/utils"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+// 支持的路由提供者
+const (
+	ProviderNginx      = "nginx"
+	ProviderTraefik    = "traefik"
+	ProviderGatewayAPI = "gateway-api"
+)
+
+// RouteProvider 路由提供者，屏蔽不同入口控制器的资源和注解差异
+// 路径路由转发到后端时去掉路径前缀，子域名路由原样转发。
+type RouteProvider interface {
+	// SetPath 将域名下的路径前缀指向Service，路径不存在时添加，存在时替换后端
+	SetPath(ctx context.Context, projectName, host, path, serviceName string, port int32, tls *RouteTLS) error
+	// RemovePath 移除路径路由，不存在时忽略
+	RemovePath(ctx context.Context, projectName, host, path string) error
+	// SetHost 将独立域名的所有请求指向Service
+	SetHost(ctx context.Context, projectName, host, serviceName string, port int32, tls *RouteTLS) error
+	// RemoveHost 移除域名路由及其证书配置，不存在时忽略
+	RemoveHost(ctx context.Context, projectName, host string) error
+	// SetSplit 将路由上canaryWeight%（0-100）的流量转发给金丝雀Service，其余仍转发给主Service
+	// path为空时切分独立域名的路由，否则切分该路径的路由。
+	SetSplit(ctx context.Context, projectName, host, path, primaryService, canaryService string, port int32, canaryWeight int) error
+	// RemoveSplit 移除流量切分，路由的全部流量回到主Service
+	RemoveSplit(ctx context.Context, projectName, host, path, primaryService string, port int32) error
+	// ListRoutes 列出系统管理的全部路由资源
+	ListRoutes(ctx context.Context) ([]RouteObject, error)
+	// DeleteRoute 删除ListRoutes返回的路由资源及其附属资源（中间件、为其签发的证书）
+	DeleteRoute(ctx context.Context, route RouteObject) error
+	// GetRoute 获取指定名称的路由资源，不存在时返回nil
+	GetRoute(ctx context.Context, name string) (*RouteObject, error)
+}
+
+// RouteObject 集群中由系统管理的一条路由资源
+type RouteObject struct {
+	Kind      string
+	Name      string
+	Project   string   // 资源的project标签
+	Services  []string // 路由转发到的Service
+	CreatedAt time.Time
+
+	annotations map[string]string
+}
+
+// routeListSelector 系统创建的路由资源的标签选择器
+const routeListSelector = "app=url-manager-system,managed-by=url-manager-system"
+
+// RouteTLS 路由的证书配置，为nil时保持路由现有的TLS配置不变
+type RouteTLS struct {
+	SecretName string
+	// Issuer 非空时由cert-manager通过该ClusterIssuer签发证书到SecretName
+	Issuer string
+}
+
+// NewRouteProvider 根据名称创建路由提供者，未知名称使用nginx
+func NewRouteProvider(client *Client, namespace string, opts IngressOptions) RouteProvider {
+	switch opts.Provider {
+	case ProviderTraefik:
+		return newTraefikProvider(client, namespace)
+	case ProviderGatewayAPI:
+		return newGatewayProvider(client, namespace, opts.GatewayName, opts.GatewayNamespace)
+	default:
+		return newNginxProvider(client, namespace, opts.IngressClass)
+	}
+}
+
+// routeLabels 路由资源的公共标签
+func routeLabels(projectName string) map[string]string {
+	return map[string]string{
+		"app":        "url-manager-system",
+		"project":    utils.SanitizeKubernetesLabel(projectName),
+		"managed-by": "url-manager-system",
+	}
+}
+
+// routeObjectName 为项目下的一条路由生成稳定的资源名称
+// 可读部分可能因截断或字符替换而重复，末尾附加原始值的哈希保证唯一。
+func routeObjectName(projectName, kind, key string) string {
+	sum := sha1.Sum([]byte(projectName + "|" + kind + "|" + key))
+	base := utils.SanitizeKubernetesName(fmt.Sprintf("%s-%s", projectName, strings.Trim(key, "/")))
+	if len(base) > 40 {
+		base = strings.TrimRight(base[:40], "-")
+	}
+	return fmt.Sprintf("%s-%s-%x", base, kind, sum[:4])
+}
+
+// routeCertificateAnnotation 记录为域名路由签发的cert-manager证书，移除路由时一并删除
+const routeCertificateAnnotation = "url-manager-system/certificate"
+
+var certificateGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// newDynamicClient 创建路由提供者使用的动态客户端
+func newDynamicClient(client *Client) *dynamic.DynamicClient {
+	dynamicClient, _ := dynamic.NewForConfig(client.GetConfig())
+	return dynamicClient
+}
+
+// newRouteObject 构建路由自定义资源
+func newRouteObject(apiVersion, kind, name, projectName string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"spec":       spec,
+	}}
+	obj.SetName(name)
+	obj.SetLabels(routeLabels(projectName))
+	return obj
+}
+
+// upsertObject 创建或更新自定义资源
+// 已存在时由mutate在最新版本上修改，返回false表示无需更新；更新携带resourceVersion，冲突时重新读取后重试。
+func upsertObject(ctx context.Context, ri dynamic.ResourceInterface, desired *unstructured.Unstructured, mutate func(existing *unstructured.Unstructured) bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := ri.Get(ctx, desired.GetName(), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			_, err = ri.Create(ctx, desired, metav1.CreateOptions{})
+			if errors.IsAlreadyExists(err) {
+				// 并发创建，按冲突处理以便重新读取后更新
+				return errors.NewConflict(schema.GroupResource{}, desired.GetName(), err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if !mutate(existing) {
+			return nil
+		}
+		_, err = ri.Update(ctx, existing, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// replaceRouteSpec 用期望的spec和注解更新已有路由资源，返回false表示无需更新
+// keep中的字段在期望的spec里缺失时保留已有值（例如未指定证书时保留现有TLS配置）。
+func replaceRouteSpec(existing, desired *unstructured.Unstructured, keep ...string) bool {
+	spec, _, _ := unstructured.NestedMap(desired.Object, "spec")
+	current, _, _ := unstructured.NestedMap(existing.Object, "spec")
+	for _, field := range keep {
+		if _, ok := spec[field]; ok {
+			continue
+		}
+		if value, ok := current[field]; ok {
+			spec[field] = value
+		}
+	}
+
+	changed := !equality.Semantic.DeepEqual(current, spec)
+	annotations := existing.GetAnnotations()
+	for key, value := range desired.GetAnnotations() {
+		if annotations[key] == value {
+			continue
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[key] = value
+		changed = true
+	}
+	if !changed {
+		return false
+	}
+
+	existing.SetAnnotations(annotations)
+	existing.Object["spec"] = spec
+	return true
+}
+
+// listRouteObjects 列出系统管理的路由自定义资源，services返回资源转发到的Service
+func listRouteObjects(ctx context.Context, ri dynamic.ResourceInterface, services func(obj *unstructured.Unstructured) []string) ([]RouteObject, error) {
+	list, err := ri.List(ctx, metav1.ListOptions{LabelSelector: routeListSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]RouteObject, 0, len(list.Items))
+	for i := range list.Items {
+		obj := &list.Items[i]
+		routes = append(routes, toRouteObject(obj, services(obj)))
+	}
+	return routes, nil
+}
+
+// getRouteObject 获取指定名称的路由自定义资源，不存在时返回nil
+func getRouteObject(ctx context.Context, ri dynamic.ResourceInterface, name string, services func(obj *unstructured.Unstructured) []string) (*RouteObject, error) {
+	obj, err := ri.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	route := toRouteObject(obj, services(obj))
+	return &route, nil
+}
+
+// toRouteObject 将路由自定义资源转换为RouteObject
+func toRouteObject(obj *unstructured.Unstructured, services []string) RouteObject {
+	return RouteObject{
+		Kind:        obj.GetKind(),
+		Name:        obj.GetName(),
+		Project:     obj.GetLabels()["project"],
+		Services:    services,
+		CreatedAt:   obj.GetCreationTimestamp().Time,
+		annotations: obj.GetAnnotations(),
+	}
+}
+
+// nestedServiceNames 从规则列表中收集后端Service名称
+// rulesPath为规则列表在资源中的位置，backendsField为规则中后端列表的字段名。
+func nestedServiceNames(obj *unstructured.Unstructured, backendsField string, rulesPath ...string) []string {
+	rules, _, _ := unstructured.NestedSlice(obj.Object, rulesPath...)
+	var names []string
+	for _, rule := range rules {
+		ruleMap, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		backends, _, _ := unstructured.NestedSlice(ruleMap, backendsField)
+		for _, backend := range backends {
+			backendMap, ok := backend.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, _, _ := unstructured.NestedString(backendMap, "name"); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// deleteObject 删除自定义资源，不存在时忽略
+func deleteObject(ctx context.Context, ri dynamic.ResourceInterface, name string) error {
+	err := ri.Delete(ctx, name, metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// ensureCertificate 确保cert-manager的Certificate资源为域名签发证书到tls.SecretName
+// 同一Secret被多个路由共用（默认域名）时只追加缺少的域名。
+func ensureCertificate(ctx context.Context, dynamicClient dynamic.Interface, namespace, projectName, host string, tls *RouteTLS) error {
+	cert := newRouteObject("cert-manager.io/v1", "Certificate", tls.SecretName, projectName, map[string]interface{}{
+		"secretName": tls.SecretName,
+		"dnsNames":   []interface{}{host},
+		"issuerRef": map[string]interface{}{
+			"name": tls.Issuer,
+			"kind": "ClusterIssuer",
+		},
+	})
+
+	return upsertObject(ctx, dynamicClient.Resource(certificateGVR).Namespace(namespace), cert, func(existing *unstructured.Unstructured) bool {
+		changed := false
+		// 旧版本由cert-manager根据Ingress注解创建的证书属于该Ingress，接管后不再随其删除
+		if len(existing.GetOwnerReferences()) > 0 {
+			existing.SetOwnerReferences(nil)
+			changed = true
+		}
+		dnsNames, _, _ := unstructured.NestedStringSlice(existing.Object, "spec", "dnsNames")
+		for _, name := range dnsNames {
+			if name == host {
+				return changed
+			}
+		}
+		_ = unstructured.SetNestedStringSlice(existing.Object, append(dnsNames, host), "spec", "dnsNames")
+		return true
+	})
+}
+
+// releaseCertificate 删除路由注解中记录的证书
+func releaseCertificate(ctx context.Context, dynamicClient dynamic.Interface, namespace string, annotations map[string]string) error {
+	name := annotations[routeCertificateAnnotation]
+	if name == "" {
+		return nil
+	}
+	return deleteObject(ctx, dynamicClient.Resource(certificateGVR).Namespace(namespace), name)
+}
+
+// ensureRouteCertificate 指定了ClusterIssuer时为路由签发证书，返回需要添加到路由资源上的注解
+// 只有独立域名的证书（owned）记录在路由上并在移除路由时释放，默认域名的证书由多条路由共用。
+func ensureRouteCertificate(ctx context.Context, dynamicClient *dynamic.DynamicClient, namespace, projectName, host string, tls *RouteTLS, owned bool) (map[string]string, error) {
+	if tls == nil || tls.Issuer == "" {
+		return nil, nil
+	}
+	if dynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not available")
+	}
+	if err := ensureCertificate(ctx, dynamicClient, namespace, projectName, host, tls); err != nil {
+		return nil, fmt.Errorf("failed to ensure certificate: %w", err)
+	}
+	if !owned {
+		return nil, nil
+	}
+	return map[string]string{routeCertificateAnnotation: tls.SecretName}, nil
+}
@@ -0,0 +1,220 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var httpRouteGVR = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+
+// gatewayProvider 基于Gateway API HTTPRoute的路由提供者
+// 每条路由一个挂载到指定Gateway的HTTPRoute，路径前缀通过URLRewrite过滤器替换为/。
+// Gateway API中TLS在Gateway的监听器上终止，这里只负责通过cert-manager签发证书，
+// 监听器需要由管理员配置为引用这些证书Secret。
+type gatewayProvider struct {
+	dynamicClient    *dynamic.DynamicClient
+	namespace        string
+	gatewayName      string
+	gatewayNamespace string
+}
+
+func newGatewayProvider(client *Client, namespace, gatewayName, gatewayNamespace string) *gatewayProvider {
+	return &gatewayProvider{
+		dynamicClient:    newDynamicClient(client),
+		namespace:        namespace,
+		gatewayName:      gatewayName,
+		gatewayNamespace: gatewayNamespace,
+	}
+}
+
+// SetPath 创建或更新路径的HTTPRoute
+func (p *gatewayProvider) SetPath(ctx context.Context, projectName, host, path, serviceName string, port int32, tls *RouteTLS) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+
+	route := p.httpRoute(routeObjectName(projectName, "path", path), projectName, host, pathRouteRule(path, serviceName, port))
+	annotations, err := ensureRouteCertificate(ctx, p.dynamicClient, p.namespace, projectName, host, tls, false)
+	if err != nil {
+		return err
+	}
+	route.SetAnnotations(annotations)
+	return p.apply(ctx, route)
+}
+
+// RemovePath 删除路径的HTTPRoute
+func (p *gatewayProvider) RemovePath(ctx context.Context, projectName, host, path string) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+	return deleteObject(ctx, p.resource(), routeObjectName(projectName, "path", path))
+}
+
+// SetHost 创建或更新子域名的HTTPRoute
+func (p *gatewayProvider) SetHost(ctx context.Context, projectName, host, serviceName string, port int32, tls *RouteTLS) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+
+	route := p.httpRoute(routeObjectName(projectName, "host", host), projectName, host, httpRouteRule("/", serviceName, port))
+	annotations, err := ensureRouteCertificate(ctx, p.dynamicClient, p.namespace, projectName, host, tls, true)
+	if err != nil {
+		return err
+	}
+	route.SetAnnotations(annotations)
+	return p.apply(ctx, route)
+}
+
+// RemoveHost 删除子域名的HTTPRoute及为其签发的证书
+func (p *gatewayProvider) RemoveHost(ctx context.Context, projectName, host string) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+
+	name := routeObjectName(projectName, "host", host)
+	route, err := p.resource().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := releaseCertificate(ctx, p.dynamicClient, p.namespace, route.GetAnnotations()); err != nil {
+		return fmt.Errorf("failed to delete certificate: %w", err)
+	}
+	return deleteObject(ctx, p.resource(), name)
+}
+
+// SetSplit 将路由的后端替换为按权重分配的主Service和金丝雀Service
+func (p *gatewayProvider) SetSplit(ctx context.Context, projectName, host, path, primaryService, canaryService string, port int32, canaryWeight int) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+
+	name, rule := routeObjectName(projectName, "host", host), httpRouteRule("/", primaryService, port)
+	if path != "" {
+		name, rule = routeObjectName(projectName, "path", path), pathRouteRule(path, primaryService, port)
+	}
+	rule["backendRefs"] = []interface{}{
+		map[string]interface{}{"name": primaryService, "port": int64(port), "weight": int64(100 - canaryWeight)},
+		map[string]interface{}{"name": canaryService, "port": int64(port), "weight": int64(canaryWeight)},
+	}
+	return p.apply(ctx, p.httpRoute(name, projectName, host, rule))
+}
+
+// RemoveSplit 将路由的后端恢复为主Service
+func (p *gatewayProvider) RemoveSplit(ctx context.Context, projectName, host, path, primaryService string, port int32) error {
+	if path == "" {
+		return p.SetHost(ctx, projectName, host, primaryService, port, nil)
+	}
+	return p.SetPath(ctx, projectName, host, path, primaryService, port, nil)
+}
+
+// pathRouteRule 构建路径路由的规则，转发前将路径前缀替换为/
+func pathRouteRule(path, serviceName string, port int32) map[string]interface{} {
+	rule := httpRouteRule(path, serviceName, port)
+	rule["filters"] = []interface{}{
+		// 与Traefik的StripPrefix一致，通过X-Forwarded-Prefix告知后端原始前缀
+		map[string]interface{}{
+			"type": "RequestHeaderModifier",
+			"requestHeaderModifier": map[string]interface{}{
+				"set": []interface{}{
+					map[string]interface{}{"name": "X-Forwarded-Prefix", "value": path},
+				},
+			},
+		},
+		map[string]interface{}{
+			"type": "URLRewrite",
+			"urlRewrite": map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":               "ReplacePrefixMatch",
+					"replacePrefixMatch": "/",
+				},
+			},
+		},
+	}
+	return rule
+}
+
+// httpRouteRule 构建按路径前缀转发到Service的规则
+func httpRouteRule(path, serviceName string, port int32) map[string]interface{} {
+	return map[string]interface{}{
+		"matches": []interface{}{
+			map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":  "PathPrefix",
+					"value": path,
+				},
+			},
+		},
+		"backendRefs": []interface{}{
+			map[string]interface{}{
+				"name": serviceName,
+				"port": int64(port),
+			},
+		},
+	}
+}
+
+// ListRoutes 列出系统管理的HTTPRoute
+func (p *gatewayProvider) ListRoutes(ctx context.Context) ([]RouteObject, error) {
+	if p.dynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not available")
+	}
+	return listRouteObjects(ctx, p.resource(), httpRouteServices)
+}
+
+// GetRoute 获取指定名称的HTTPRoute
+func (p *gatewayProvider) GetRoute(ctx context.Context, name string) (*RouteObject, error) {
+	if p.dynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not available")
+	}
+	return getRouteObject(ctx, p.resource(), name, httpRouteServices)
+}
+
+// httpRouteServices HTTPRoute转发到的Service
+func httpRouteServices(obj *unstructured.Unstructured) []string {
+	return nestedServiceNames(obj, "backendRefs", "spec", "rules")
+}
+
+// DeleteRoute 删除HTTPRoute及为其签发的证书
+func (p *gatewayProvider) DeleteRoute(ctx context.Context, route RouteObject) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+	if err := releaseCertificate(ctx, p.dynamicClient, p.namespace, route.annotations); err != nil {
+		return fmt.Errorf("failed to delete certificate: %w", err)
+	}
+	return deleteObject(ctx, p.resource(), route.Name)
+}
+
+// httpRoute 构建挂载到Gateway的HTTPRoute
+func (p *gatewayProvider) httpRoute(name, projectName, host string, rule map[string]interface{}) *unstructured.Unstructured {
+	parentRef := map[string]interface{}{"name": p.gatewayName}
+	if p.gatewayNamespace != "" {
+		parentRef["namespace"] = p.gatewayNamespace
+	}
+
+	return newRouteObject("gateway.networking.k8s.io/v1", "HTTPRoute", name, projectName, map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"hostnames":  []interface{}{host},
+		"rules":      []interface{}{rule},
+	})
+}
+
+// apply 创建或更新HTTPRoute
+func (p *gatewayProvider) apply(ctx context.Context, route *unstructured.Unstructured) error {
+	return upsertObject(ctx, p.resource(), route, func(existing *unstructured.Unstructured) bool {
+		return replaceRouteSpec(existing, route)
+	})
+}
+
+func (p *gatewayProvider) resource() dynamic.ResourceInterface {
+	return p.dynamicClient.Resource(httpRouteGVR).Namespace(p.namespace)
+}
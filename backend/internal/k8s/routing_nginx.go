@@ -0,0 +1,267 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+// nginxProvider 基于ingress-nginx的路由提供者
+// 每条路由一个Ingress，不同URL的写入互不影响；路径前缀通过正则捕获和rewrite-target去掉。
+type nginxProvider struct {
+	client        *Client
+	dynamicClient *dynamic.DynamicClient
+	namespace     string
+	ingressClass  string
+}
+
+func newNginxProvider(client *Client, namespace, ingressClass string) *nginxProvider {
+	return &nginxProvider{
+		client:        client,
+		dynamicClient: newDynamicClient(client),
+		namespace:     namespace,
+		ingressClass:  ingressClass,
+	}
+}
+
+// nginxPathPattern 路径前缀对应的正则路径，第二个捕获组为转发给后端的部分
+// /abc 匹配 /abc、/abc/ 和 /abc/x，不匹配 /abcd。
+func nginxPathPattern(path string) string {
+	return regexp.QuoteMeta(path) + "(/|$)(.*)"
+}
+
+// SetPath 创建或更新路径的Ingress
+// 原始前缀通过X-Forwarded-Prefix请求头告知后端，后端可据此生成正确的重定向地址。
+func (p *nginxProvider) SetPath(ctx context.Context, projectName, host, path, serviceName string, port int32, tls *RouteTLS) error {
+	annotations := map[string]string{
+		"nginx.ingress.kubernetes.io/use-regex":          "true",
+		"nginx.ingress.kubernetes.io/rewrite-target":     "/$2",
+		"nginx.ingress.kubernetes.io/x-forwarded-prefix": path,
+	}
+	ingressPath := newIngressPath(nginxPathPattern(path), networkingv1.PathTypeImplementationSpecific, serviceName, port)
+	return p.apply(ctx, routeObjectName(projectName, "path", path), projectName, host, ingressPath, annotations, tls, false)
+}
+
+// RemovePath 删除路径的Ingress
+func (p *nginxProvider) RemovePath(ctx context.Context, projectName, host, path string) error {
+	return p.delete(ctx, routeObjectName(projectName, "path", path))
+}
+
+// SetHost 创建或更新子域名的Ingress，直接转发根路径
+func (p *nginxProvider) SetHost(ctx context.Context, projectName, host, serviceName string, port int32, tls *RouteTLS) error {
+	ingressPath := newIngressPath("/", networkingv1.PathTypePrefix, serviceName, port)
+	return p.apply(ctx, routeObjectName(projectName, "host", host), projectName, host, ingressPath, nil, tls, true)
+}
+
+// RemoveHost 删除子域名的Ingress及为其签发的证书
+func (p *nginxProvider) RemoveHost(ctx context.Context, projectName, host string) error {
+	name := routeObjectName(projectName, "host", host)
+	ingress, err := p.client.GetClientset().NetworkingV1().Ingresses(p.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := releaseCertificate(ctx, p.dynamicClient, p.namespace, ingress.Annotations); err != nil {
+		return fmt.Errorf("failed to delete certificate: %w", err)
+	}
+	return p.delete(ctx, name)
+}
+
+// SetSplit 创建或更新金丝雀Ingress
+// ingress-nginx按canary-weight把同一域名和路径上的部分请求转发给金丝雀Ingress的后端，主Ingress保持不变。
+func (p *nginxProvider) SetSplit(ctx context.Context, projectName, host, path, primaryService, canaryService string, port int32, canaryWeight int) error {
+	annotations := map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": strconv.Itoa(canaryWeight),
+	}
+	ingressPath := newIngressPath("/", networkingv1.PathTypePrefix, canaryService, port)
+	if path != "" {
+		annotations["nginx.ingress.kubernetes.io/use-regex"] = "true"
+		annotations["nginx.ingress.kubernetes.io/rewrite-target"] = "/$2"
+		annotations["nginx.ingress.kubernetes.io/x-forwarded-prefix"] = path
+		ingressPath = newIngressPath(nginxPathPattern(path), networkingv1.PathTypeImplementationSpecific, canaryService, port)
+	}
+	return p.apply(ctx, routeObjectName(projectName, "canary", host+path), projectName, host, ingressPath, annotations, nil, false)
+}
+
+// RemoveSplit 删除金丝雀Ingress
+func (p *nginxProvider) RemoveSplit(ctx context.Context, projectName, host, path, primaryService string, port int32) error {
+	return p.delete(ctx, routeObjectName(projectName, "canary", host+path))
+}
+
+// ListRoutes 列出系统管理的Ingress，包括金丝雀Ingress
+func (p *nginxProvider) ListRoutes(ctx context.Context) ([]RouteObject, error) {
+	list, err := p.client.GetClientset().NetworkingV1().Ingresses(p.namespace).List(ctx, metav1.ListOptions{LabelSelector: routeListSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]RouteObject, 0, len(list.Items))
+	for i := range list.Items {
+		routes = append(routes, ingressRouteObject(&list.Items[i]))
+	}
+	return routes, nil
+}
+
+// GetRoute 获取指定名称的Ingress
+func (p *nginxProvider) GetRoute(ctx context.Context, name string) (*RouteObject, error) {
+	ingress, err := p.client.GetClientset().NetworkingV1().Ingresses(p.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	route := ingressRouteObject(ingress)
+	return &route, nil
+}
+
+// ingressRouteObject 将Ingress转换为RouteObject
+func ingressRouteObject(ingress *networkingv1.Ingress) RouteObject {
+	var services []string
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				services = append(services, path.Backend.Service.Name)
+			}
+		}
+	}
+	return RouteObject{
+		Kind:        "Ingress",
+		Name:        ingress.Name,
+		Project:     ingress.Labels["project"],
+		Services:    services,
+		CreatedAt:   ingress.CreationTimestamp.Time,
+		annotations: ingress.Annotations,
+	}
+}
+
+// DeleteRoute 删除Ingress及为其签发的证书
+func (p *nginxProvider) DeleteRoute(ctx context.Context, route RouteObject) error {
+	if err := releaseCertificate(ctx, p.dynamicClient, p.namespace, route.annotations); err != nil {
+		return fmt.Errorf("failed to delete certificate: %w", err)
+	}
+	return p.delete(ctx, route.Name)
+}
+
+// apply 创建或更新只包含一条规则的Ingress
+// 更新携带resourceVersion，冲突时重新读取后重试；tls为nil时保留现有TLS配置。
+func (p *nginxProvider) apply(ctx context.Context, name, projectName, host string, ingressPath networkingv1.HTTPIngressPath, extra map[string]string, tls *RouteTLS, ownsCertificate bool) error {
+	annotations := map[string]string{
+		"kubernetes.io/ingress.class":                    p.ingressClass,
+		"nginx.ingress.kubernetes.io/ssl-redirect":       "false",
+		"nginx.ingress.kubernetes.io/force-ssl-redirect": "false",
+	}
+	for key, value := range extra {
+		annotations[key] = value
+	}
+
+	spec := networkingv1.IngressSpec{
+		Rules: []networkingv1.IngressRule{
+			{
+				Host: host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{ingressPath},
+					},
+				},
+			},
+		},
+	}
+	if tls != nil {
+		spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{host}, SecretName: tls.SecretName}}
+		certAnnotations, err := ensureRouteCertificate(ctx, p.dynamicClient, p.namespace, projectName, host, tls, ownsCertificate)
+		if err != nil {
+			return err
+		}
+		for key, value := range certAnnotations {
+			annotations[key] = value
+		}
+	}
+
+	ingresses := p.client.GetClientset().NetworkingV1().Ingresses(p.namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := ingresses.Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        name,
+					Namespace:   p.namespace,
+					Labels:      routeLabels(projectName),
+					Annotations: annotations,
+				},
+				Spec: spec,
+			}
+			_, err = ingresses.Create(ctx, ingress, metav1.CreateOptions{})
+			if errors.IsAlreadyExists(err) {
+				// 并发创建，按冲突处理以便重新读取后更新
+				return errors.NewConflict(schema.GroupResource{Group: "networking.k8s.io", Resource: "ingresses"}, name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		desired := spec
+		if tls == nil {
+			desired.TLS = existing.Spec.TLS
+		}
+		changed := !equality.Semantic.DeepEqual(existing.Spec, desired)
+		if existing.Annotations == nil {
+			existing.Annotations = map[string]string{}
+		}
+		for key, value := range annotations {
+			if existing.Annotations[key] != value {
+				existing.Annotations[key] = value
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+
+		existing.Spec = desired
+		_, err = ingresses.Update(ctx, existing, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// delete 删除Ingress，不存在时忽略
+func (p *nginxProvider) delete(ctx context.Context, name string) error {
+	err := p.client.GetClientset().NetworkingV1().Ingresses(p.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// newIngressPath 构建指向Service的路径
+func newIngressPath(path string, pathType networkingv1.PathType, serviceName string, port int32) networkingv1.HTTPIngressPath {
+	return networkingv1.HTTPIngressPath{
+		Path:     path,
+		PathType: &pathType,
+		Backend: networkingv1.IngressBackend{
+			Service: &networkingv1.IngressServiceBackend{
+				Name: serviceName,
+				Port: networkingv1.ServiceBackendPort{
+					Number: port,
+				},
+			},
+		},
+	}
+}
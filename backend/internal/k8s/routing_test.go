@@ -0,0 +1,153 @@
+package k8s
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestRouteObjectName(t *testing.T) {
+	long := strings.Repeat("very-long-project-name-", 5)
+
+	tests := []struct {
+		name    string
+		project string
+		kind    string
+		key     string
+	}{
+		{name: "path", project: "demo", kind: "path", key: "/a1b2c3d4"},
+		{name: "host", project: "demo", kind: "host", key: "a1b2c3d4.demo.preview.example.com"},
+		{name: "long project", project: long, kind: "path", key: "/pr/42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := routeObjectName(tt.project, tt.kind, tt.key)
+			if errs := validation.IsDNS1123Label(got); len(errs) > 0 {
+				t.Errorf("routeObjectName() = %q is not a valid name: %v", got, errs)
+			}
+			if again := routeObjectName(tt.project, tt.kind, tt.key); again != got {
+				t.Errorf("routeObjectName() is not stable: %q != %q", again, got)
+			}
+		})
+	}
+
+	// 清理后相同的路径仍需得到不同的名称
+	if routeObjectName("demo", "path", "/pr/42") == routeObjectName("demo", "path", "/pr-42") {
+		t.Error("routeObjectName() collides for different paths")
+	}
+}
+
+func TestNginxPathPattern(t *testing.T) {
+	re := regexp.MustCompile("^" + nginxPathPattern("/abc"))
+
+	tests := []struct {
+		path    string
+		matches bool
+		rest    string
+	}{
+		{path: "/abc", matches: true, rest: ""},
+		{path: "/abc/", matches: true, rest: ""},
+		{path: "/abc/static/app.js", matches: true, rest: "static/app.js"},
+		{path: "/abcd", matches: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			m := re.FindStringSubmatch(tt.path)
+			if (m != nil) != tt.matches {
+				t.Fatalf("match %q = %v, want %v", tt.path, m != nil, tt.matches)
+			}
+			if m != nil && m[2] != tt.rest {
+				t.Errorf("rewritten path for %q = /%s, want /%s", tt.path, m[2], tt.rest)
+			}
+		})
+	}
+}
+
+func TestLegacyRoutePath(t *testing.T) {
+	tests := []struct {
+		ingressPath string
+		expected    string
+	}{
+		{ingressPath: "/abc", expected: "/abc"},
+		{ingressPath: nginxPathPattern("/abc"), expected: "/abc"},
+		{ingressPath: nginxPathPattern("/v1.2/app"), expected: "/v1.2/app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ingressPath, func(t *testing.T) {
+			if got := legacyRoutePath(tt.ingressPath); got != tt.expected {
+				t.Errorf("legacyRoutePath() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTraefikPathMatch(t *testing.T) {
+	want := "Host(`example.com`) && (Path(`/abc`) || PathPrefix(`/abc/`))"
+	if got := traefikPathMatch("example.com", "/abc"); got != want {
+		t.Errorf("traefikPathMatch() = %q, want %q", got, want)
+	}
+}
+
+func TestReplaceRouteSpec(t *testing.T) {
+	tls := &RouteTLS{SecretName: "demo-tls"}
+	p := &traefikProvider{}
+	existing := p.ingressRoute("demo", "demo", traefikHostMatch("a.example.com"), traefikServices("svc-a", 80), "", tls)
+
+	// 未指定证书时保留现有TLS配置，只替换后端
+	desired := p.ingressRoute("demo", "demo", traefikHostMatch("a.example.com"), traefikServices("svc-b", 80), "", nil)
+	if !replaceRouteSpec(existing, desired, "tls") {
+		t.Fatal("replaceRouteSpec() = false, want true")
+	}
+	if name, _, _ := unstructured.NestedString(existing.Object, "spec", "tls", "secretName"); name != "demo-tls" {
+		t.Errorf("tls secret = %q, want demo-tls", name)
+	}
+	routes, _, _ := unstructured.NestedSlice(existing.Object, "spec", "routes")
+	services := routes[0].(map[string]interface{})["services"].([]interface{})
+	if got := services[0].(map[string]interface{})["name"]; got != "svc-b" {
+		t.Errorf("service = %v, want svc-b", got)
+	}
+
+	if replaceRouteSpec(existing, desired, "tls") {
+		t.Error("replaceRouteSpec() = true for unchanged route, want false")
+	}
+}
+
+func TestTraefikWeightedServices(t *testing.T) {
+	services := traefikWeightedServices("svc-primary", "svc-canary", 80, 20)
+	if len(services) != 2 {
+		t.Fatalf("len(services) = %d, want 2", len(services))
+	}
+
+	want := map[string]int64{"svc-primary": 80, "svc-canary": 20}
+	for _, s := range services {
+		service := s.(map[string]interface{})
+		name := service["name"].(string)
+		if got := service["weight"]; got != want[name] {
+			t.Errorf("weight of %s = %v, want %d", name, got, want[name])
+		}
+	}
+}
+
+func TestNestedServiceNames(t *testing.T) {
+	p := &traefikProvider{}
+	route := p.ingressRoute("demo-path-1", "demo", traefikPathMatch("example.com", "/abc"),
+		traefikWeightedServices("svc-primary", "svc-canary", 80, 20), "", nil)
+
+	got := nestedServiceNames(route, "services", "spec", "routes")
+	if len(got) != 2 || got[0] != "svc-primary" || got[1] != "svc-canary" {
+		t.Errorf("traefik services = %v, want [svc-primary svc-canary]", got)
+	}
+
+	g := &gatewayProvider{gatewayName: "gw"}
+	httpRoute := g.httpRoute("demo-host-1", "demo", "demo.example.com", httpRouteRule("/", "svc-primary", 80))
+	got = nestedServiceNames(httpRoute, "backendRefs", "spec", "rules")
+	if len(got) != 1 || got[0] != "svc-primary" {
+		t.Errorf("gateway services = %v, want [svc-primary]", got)
+	}
+}
@@ -0,0 +1,234 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	traefikIngressRouteGVR = schema.GroupVersionResource{Group: "traefik.io", Version: "v1alpha1", Resource: "ingressroutes"}
+	traefikMiddlewareGVR   = schema.GroupVersionResource{Group: "traefik.io", Version: "v1alpha1", Resource: "middlewares"}
+)
+
+// traefikProvider 基于Traefik IngressRoute的路由提供者
+// 每条路由一个IngressRoute，路径路由额外使用一个StripPrefix中间件去掉路径前缀，
+// Traefik会把去掉的前缀放在X-Forwarded-Prefix请求头中，后端可据此生成正确的重定向地址。
+type traefikProvider struct {
+	dynamicClient *dynamic.DynamicClient
+	namespace     string
+}
+
+func newTraefikProvider(client *Client, namespace string) *traefikProvider {
+	return &traefikProvider{
+		dynamicClient: newDynamicClient(client),
+		namespace:     namespace,
+	}
+}
+
+// traefikPathMatch 路径路由的匹配规则，/abc 匹配 /abc 和 /abc/ 下的请求，不匹配 /abcd
+func traefikPathMatch(host, path string) string {
+	return fmt.Sprintf("Host(`%s`) && (Path(`%s`) || PathPrefix(`%s/`))", host, path, path)
+}
+
+// traefikHostMatch 子域名路由的匹配规则
+func traefikHostMatch(host string) string {
+	return fmt.Sprintf("Host(`%s`)", host)
+}
+
+// SetPath 创建或更新路径的IngressRoute和StripPrefix中间件
+func (p *traefikProvider) SetPath(ctx context.Context, projectName, host, path, serviceName string, port int32, tls *RouteTLS) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+
+	name := routeObjectName(projectName, "path", path)
+	middlewareName := name + "-strip"
+	middleware := newRouteObject("traefik.io/v1alpha1", "Middleware", middlewareName, projectName, map[string]interface{}{
+		"stripPrefix": map[string]interface{}{
+			"prefixes": []interface{}{path},
+		},
+	})
+	if err := upsertObject(ctx, p.resource(traefikMiddlewareGVR), middleware, func(existing *unstructured.Unstructured) bool {
+		return replaceRouteSpec(existing, middleware)
+	}); err != nil {
+		return fmt.Errorf("failed to apply strip prefix middleware: %w", err)
+	}
+
+	route := p.ingressRoute(name, projectName, traefikPathMatch(host, path), traefikServices(serviceName, port), middlewareName, tls)
+	annotations, err := ensureRouteCertificate(ctx, p.dynamicClient, p.namespace, projectName, host, tls, false)
+	if err != nil {
+		return err
+	}
+	route.SetAnnotations(annotations)
+	return p.apply(ctx, route)
+}
+
+// RemovePath 删除路径的IngressRoute和中间件
+func (p *traefikProvider) RemovePath(ctx context.Context, projectName, host, path string) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+
+	name := routeObjectName(projectName, "path", path)
+	if err := deleteObject(ctx, p.resource(traefikIngressRouteGVR), name); err != nil {
+		return err
+	}
+	return deleteObject(ctx, p.resource(traefikMiddlewareGVR), name+"-strip")
+}
+
+// SetHost 创建或更新子域名的IngressRoute
+func (p *traefikProvider) SetHost(ctx context.Context, projectName, host, serviceName string, port int32, tls *RouteTLS) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+
+	route := p.ingressRoute(routeObjectName(projectName, "host", host), projectName, traefikHostMatch(host), traefikServices(serviceName, port), "", tls)
+	annotations, err := ensureRouteCertificate(ctx, p.dynamicClient, p.namespace, projectName, host, tls, true)
+	if err != nil {
+		return err
+	}
+	route.SetAnnotations(annotations)
+	return p.apply(ctx, route)
+}
+
+// RemoveHost 删除子域名的IngressRoute及为其签发的证书
+func (p *traefikProvider) RemoveHost(ctx context.Context, projectName, host string) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+
+	name := routeObjectName(projectName, "host", host)
+	route, err := p.resource(traefikIngressRouteGVR).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := releaseCertificate(ctx, p.dynamicClient, p.namespace, route.GetAnnotations()); err != nil {
+		return fmt.Errorf("failed to delete certificate: %w", err)
+	}
+	return deleteObject(ctx, p.resource(traefikIngressRouteGVR), name)
+}
+
+// SetSplit 将路由的后端替换为按权重分配的主Service和金丝雀Service，保留路由现有的TLS配置
+func (p *traefikProvider) SetSplit(ctx context.Context, projectName, host, path, primaryService, canaryService string, port int32, canaryWeight int) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+
+	services := traefikWeightedServices(primaryService, canaryService, port, canaryWeight)
+	if path == "" {
+		return p.apply(ctx, p.ingressRoute(routeObjectName(projectName, "host", host), projectName, traefikHostMatch(host), services, "", nil))
+	}
+	name := routeObjectName(projectName, "path", path)
+	return p.apply(ctx, p.ingressRoute(name, projectName, traefikPathMatch(host, path), services, name+"-strip", nil))
+}
+
+// RemoveSplit 将路由的后端恢复为主Service
+func (p *traefikProvider) RemoveSplit(ctx context.Context, projectName, host, path, primaryService string, port int32) error {
+	if path == "" {
+		return p.SetHost(ctx, projectName, host, primaryService, port, nil)
+	}
+	return p.SetPath(ctx, projectName, host, path, primaryService, port, nil)
+}
+
+// ListRoutes 列出系统管理的IngressRoute
+func (p *traefikProvider) ListRoutes(ctx context.Context) ([]RouteObject, error) {
+	if p.dynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not available")
+	}
+	return listRouteObjects(ctx, p.resource(traefikIngressRouteGVR), traefikRouteServices)
+}
+
+// GetRoute 获取指定名称的IngressRoute
+func (p *traefikProvider) GetRoute(ctx context.Context, name string) (*RouteObject, error) {
+	if p.dynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not available")
+	}
+	return getRouteObject(ctx, p.resource(traefikIngressRouteGVR), name, traefikRouteServices)
+}
+
+// traefikRouteServices IngressRoute转发到的Service
+func traefikRouteServices(obj *unstructured.Unstructured) []string {
+	return nestedServiceNames(obj, "services", "spec", "routes")
+}
+
+// DeleteRoute 删除IngressRoute、路径路由的中间件及为其签发的证书
+func (p *traefikProvider) DeleteRoute(ctx context.Context, route RouteObject) error {
+	if p.dynamicClient == nil {
+		return fmt.Errorf("dynamic client not available")
+	}
+	if err := releaseCertificate(ctx, p.dynamicClient, p.namespace, route.annotations); err != nil {
+		return fmt.Errorf("failed to delete certificate: %w", err)
+	}
+	if err := deleteObject(ctx, p.resource(traefikIngressRouteGVR), route.Name); err != nil {
+		return err
+	}
+	return deleteObject(ctx, p.resource(traefikMiddlewareGVR), route.Name+"-strip")
+}
+
+// traefikServices 只有一个Service的后端列表
+func traefikServices(serviceName string, port int32) []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"name": serviceName,
+			"port": int64(port),
+		},
+	}
+}
+
+// traefikWeightedServices 按权重分配流量的后端列表，Traefik对同一路由的多个Service做加权轮询
+func traefikWeightedServices(primaryService, canaryService string, port int32, canaryWeight int) []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"name":   primaryService,
+			"port":   int64(port),
+			"weight": int64(100 - canaryWeight),
+		},
+		map[string]interface{}{
+			"name":   canaryService,
+			"port":   int64(port),
+			"weight": int64(canaryWeight),
+		},
+	}
+}
+
+// ingressRoute 构建只有一条规则的IngressRoute，middlewareName为空时不使用中间件
+func (p *traefikProvider) ingressRoute(name, projectName, match string, services []interface{}, middlewareName string, tls *RouteTLS) *unstructured.Unstructured {
+	rule := map[string]interface{}{
+		"kind":     "Rule",
+		"match":    match,
+		"services": services,
+	}
+	if middlewareName != "" {
+		rule["middlewares"] = []interface{}{
+			map[string]interface{}{"name": middlewareName},
+		}
+	}
+
+	spec := map[string]interface{}{
+		"routes": []interface{}{rule},
+	}
+	if tls != nil {
+		spec["tls"] = map[string]interface{}{"secretName": tls.SecretName}
+	}
+	return newRouteObject("traefik.io/v1alpha1", "IngressRoute", name, projectName, spec)
+}
+
+// apply 创建或更新IngressRoute，未指定证书时保留现有TLS配置
+func (p *traefikProvider) apply(ctx context.Context, route *unstructured.Unstructured) error {
+	return upsertObject(ctx, p.resource(traefikIngressRouteGVR), route, func(existing *unstructured.Unstructured) bool {
+		return replaceRouteSpec(existing, route, "tls")
+	})
+}
+
+func (p *traefikProvider) resource(gvr schema.GroupVersionResource) dynamic.ResourceInterface {
+	return p.dynamicClient.Resource(gvr).Namespace(p.namespace)
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+	"url-manager-system/backend/internal/config"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/k8s"
+	"url-manager-system/backend/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// aliasNamePattern 别名格式（DNS标签），作为路径 /<name> 使用
+var aliasNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// aliasColumns url_aliases表查询列，与scanAlias保持一致
+const aliasColumns = `id, project_id, name, path, url_id, user_id, created_at, updated_at`
+
+// AliasService 别名服务
+type AliasService struct {
+	db              *sql.DB
+	urlService      *URLService
+	resourceManager *k8s.ResourceManager
+	ingressManager  *k8s.IngressManager
+	config          *config.Config
+}
+
+// NewAliasService 创建别名服务
+func NewAliasService(db *sql.DB, urlService *URLService, resourceManager *k8s.ResourceManager, ingressManager *k8s.IngressManager, cfg *config.Config) *AliasService {
+	return &AliasService{
+		db:              db,
+		urlService:      urlService,
+		resourceManager: resourceManager,
+		ingressManager:  ingressManager,
+		config:          cfg,
+	}
+}
+
+// CreateAlias 创建别名，未指定URL时先指向兜底页面
+func (s *AliasService) CreateAlias(ctx context.Context, projectID, userID uuid.UUID, req *models.CreateURLAliasRequest) (*models.URLAlias, error) {
+	if !aliasNamePattern.MatchString(req.Name) {
+		return nil, fmt.Errorf("invalid alias name %q: must be lowercase alphanumeric or '-' and at most 63 characters", req.Name)
+	}
+
+	project, err := s.urlService.getProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	path := "/" + req.Name
+	var aliasCount, urlCount int
+	err = s.db.QueryRowContext(ctx, `
+		SELECT (SELECT COUNT(*) FROM url_aliases WHERE project_id = $1 AND name = $2),
+		       (SELECT COUNT(*) FROM ephemeral_urls WHERE project_id = $1 AND path = $3)
+	`, projectID, req.Name, path).Scan(&aliasCount, &urlCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check alias name: %w", err)
+	}
+	if aliasCount > 0 {
+		return nil, fmt.Errorf("alias '%s' already exists in this project", req.Name)
+	}
+	if urlCount > 0 {
+		return nil, fmt.Errorf("path '%s' already exists in this project", path)
+	}
+
+	var target *models.EphemeralURL
+	if req.URLID != nil {
+		target, err = s.resolveTarget(ctx, projectID, *req.URLID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.route(ctx, project, path, target); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	alias := &models.URLAlias{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		Name:      req.Name,
+		Path:      path,
+		URLID:     req.URLID,
+		UserID:    &userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	query := `
+		INSERT INTO url_aliases (id, project_id, name, path, url_id, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = s.db.ExecContext(ctx, query,
+		alias.ID, alias.ProjectID, alias.Name, alias.Path, alias.URLID, alias.UserID, alias.CreatedAt, alias.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create alias: %w", err)
+	}
+	s.decorate(alias, s.tlsReady(ctx, project))
+
+	logrus.WithFields(logrus.Fields{
+		"alias_id":   alias.ID,
+		"project_id": projectID,
+		"path":       path,
+		"url_id":     req.URLID,
+	}).Info("Alias created successfully")
+
+	return alias, nil
+}
+
+// UpdateAlias 将别名重新指向另一个URL，URL为空时指向兜底页面
+func (s *AliasService) UpdateAlias(ctx context.Context, id uuid.UUID, req *models.UpdateURLAliasRequest) (*models.URLAlias, error) {
+	alias, err := s.GetAlias(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.urlService.getProject(ctx, alias.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *models.EphemeralURL
+	if req.URLID != nil {
+		target, err = s.resolveTarget(ctx, alias.ProjectID, *req.URLID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.route(ctx, project, alias.Path, target); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.ExecContext(ctx, "UPDATE url_aliases SET url_id = $2, updated_at = NOW() WHERE id = $1", id, req.URLID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update alias: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"alias_id": id,
+		"path":     alias.Path,
+		"from":     alias.URLID,
+		"to":       req.URLID,
+	}).Info("Alias re-pointed")
+
+	return s.GetAlias(ctx, id)
+}
+
+// GetAlias 获取别名
+func (s *AliasService) GetAlias(ctx context.Context, id uuid.UUID) (*models.URLAlias, error) {
+	query := `SELECT ` + aliasColumns + ` FROM url_aliases WHERE id = $1`
+	alias, err := scanAlias(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("alias not found")
+		}
+		return nil, fmt.Errorf("failed to get alias: %w", err)
+	}
+
+	project, err := s.urlService.getProject(ctx, alias.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	s.decorate(alias, s.tlsReady(ctx, project))
+	return alias, nil
+}
+
+// ListAliases 列出项目的别名
+func (s *AliasService) ListAliases(ctx context.Context, projectID uuid.UUID) ([]models.URLAlias, error) {
+	project, err := s.urlService.getProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	tlsReady := s.tlsReady(ctx, project)
+
+	query := `SELECT ` + aliasColumns + ` FROM url_aliases WHERE project_id = $1 ORDER BY name`
+	rows, err := s.db.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []models.URLAlias{}
+	for rows.Next() {
+		alias, err := scanAlias(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan alias")
+			continue
+		}
+		s.decorate(alias, tlsReady)
+		aliases = append(aliases, *alias)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// DeleteAlias 删除别名及其Ingress路径，不影响指向的URL
+func (s *AliasService) DeleteAlias(ctx context.Context, id uuid.UUID) error {
+	alias, err := s.GetAlias(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.urlService.getProject(ctx, alias.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if s.ingressManager != nil {
+		if err := s.ingressManager.RemovePath(ctx, project.Name, alias.Path); err != nil {
+			return fmt.Errorf("failed to remove alias path: %w", err)
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM url_aliases WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete alias: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"alias_id": id,
+		"path":     alias.Path,
+	}).Info("Alias deleted successfully")
+	return nil
+}
+
+// resolveTarget 校验别名要指向的URL：必须属于同一项目、未被删除且有Service
+func (s *AliasService) resolveTarget(ctx context.Context, projectID, urlID uuid.UUID) (*models.EphemeralURL, error) {
+	url, err := s.urlService.GetEphemeralURL(ctx, urlID)
+	if err != nil {
+		return nil, err
+	}
+	if url.ProjectID != projectID {
+		return nil, fmt.Errorf("URL not found")
+	}
+	if url.Status == models.StatusDeleting || url.Status == models.StatusDeleted || url.K8sServiceName == nil {
+		return nil, fmt.Errorf("invalid alias target: URL is %s and cannot be routed", url.Status)
+	}
+	return url, nil
+}
+
+// route 将别名路径指向URL的Service，target为空时指向兜底页面
+// 别名始终使用默认域名的路径路由，项目配置了证书时同时为默认域名配置TLS。
+func (s *AliasService) route(ctx context.Context, project *models.Project, path string, target *models.EphemeralURL) error {
+	if s.ingressManager == nil {
+		logrus.WithField("path", path).Warn("Kubernetes not available, alias saved without routing")
+		return nil
+	}
+
+	var serviceName string
+	if target != nil {
+		serviceName = *target.K8sServiceName
+	} else {
+		var err error
+		if serviceName, err = fallbackService(ctx, s.resourceManager, s.config); err != nil {
+			return err
+		}
+	}
+
+	tls := s.ingressManager.TLS(project, s.urlService.tlsSecretName(project, s.config.K8s.DefaultDomain))
+	if err := s.ingressManager.SetAliasPath(ctx, project.Name, path, serviceName, 80, tls); err != nil {
+		return fmt.Errorf("failed to route alias: %w", err)
+	}
+	return nil
+}
+
+// tlsReady 检查项目默认域名的证书是否已就绪
+func (s *AliasService) tlsReady(ctx context.Context, project *models.Project) bool {
+	secretName := s.urlService.tlsSecretName(project, s.config.K8s.DefaultDomain)
+	return secretName != nil && s.urlService.tlsSecretReady(ctx, *secretName)
+}
+
+// decorate 补全别名的访问地址和兜底状态，证书就绪前使用http
+func (s *AliasService) decorate(alias *models.URLAlias, tlsReady bool) {
+	alias.URL = utils.RoutedURL(s.config.K8s.DefaultDomain, alias.Path, false, tlsReady)
+	alias.Fallback = alias.URLID == nil
+}
+
+// scanAlias 扫描一行别名记录
+func scanAlias(row rowScanner) (*models.URLAlias, error) {
+	alias := &models.URLAlias{}
+	err := row.Scan(&alias.ID, &alias.ProjectID, &alias.Name, &alias.Path, &alias.URLID, &alias.UserID, &alias.CreatedAt, &alias.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return alias, nil
+}
+
+// fallbackService 返回兜底页面的Service，未配置外部Service时确保内置页面已部署
+func fallbackService(ctx context.Context, rm *k8s.ResourceManager, cfg *config.Config) (string, error) {
+	if cfg.K8s.AliasFallbackService != "" {
+		return cfg.K8s.AliasFallbackService, nil
+	}
+	if err := rm.EnsureAliasFallback(ctx, cfg.K8s.AliasFallbackImage, cfg.K8s.AliasFallbackHTML); err != nil {
+		return "", fmt.Errorf("failed to deploy alias fallback: %w", err)
+	}
+	return k8s.AliasFallbackName, nil
+}
+
+// routeToFallback 将路径指向兜底页面，保留路由现有的TLS配置
+func routeToFallback(ctx context.Context, rm *k8s.ResourceManager, im *k8s.IngressManager, cfg *config.Config, projectName, path string) error {
+	serviceName, err := fallbackService(ctx, rm, cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := im.SetAliasPath(ctx, projectName, path, serviceName, 80, nil); err != nil {
+		return fmt.Errorf("failed to route alias to fallback: %w", err)
+	}
+	return nil
+}
+
+// detachURLAliases 在URL的资源被删除前将指向它的别名切换到兜底页面
+// 由URL删除和清理流程调用，失败只记录日志，不阻塞URL的删除。
+func detachURLAliases(ctx context.Context, db *sql.DB, rm *k8s.ResourceManager, im *k8s.IngressManager, cfg *config.Config, url *models.EphemeralURL, projectName string) {
+	rows, err := db.QueryContext(ctx, "SELECT id, path FROM url_aliases WHERE url_id = $1", url.ID)
+	if err != nil {
+		logrus.WithError(err).WithField("url_id", url.ID).Error("Failed to list aliases of URL")
+		return
+	}
+	defer rows.Close()
+
+	type aliasRef struct {
+		id   uuid.UUID
+		path string
+	}
+	var aliases []aliasRef
+	for rows.Next() {
+		var ref aliasRef
+		if err := rows.Scan(&ref.id, &ref.path); err != nil {
+			logrus.WithError(err).Error("Failed to scan alias")
+			continue
+		}
+		aliases = append(aliases, ref)
+	}
+	rows.Close()
+
+	for _, alias := range aliases {
+		if im != nil {
+			if err := routeToFallback(ctx, rm, im, cfg, projectName, alias.path); err != nil {
+				logrus.WithError(err).WithField("alias_id", alias.id).Error("Failed to route alias to fallback")
+			}
+		}
+		if _, err := db.ExecContext(ctx, "UPDATE url_aliases SET url_id = NULL, updated_at = NOW() WHERE id = $1", alias.id); err != nil {
+			logrus.WithError(err).WithField("alias_id", alias.id).Error("Failed to detach alias")
+			continue
+		}
+		logrus.WithFields(logrus.Fields{
+			"alias_id": alias.id,
+			"path":     alias.path,
+			"url_id":   url.ID,
+		}).Info("Alias switched to fallback page")
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+	"url-manager-system/backend/internal/config"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	catalogLockKey = "url_manager:catalog_sync_lock"
+	catalogLockTTL = 5 * time.Minute
+)
+
+// CatalogService Git模版目录同步服务
+type CatalogService struct {
+	db              *sqlx.DB
+	redis           *redis.Client
+	templateService *TemplateService
+	config          config.CatalogConfig
+}
+
+// NewCatalogService 创建模版目录同步服务
+func NewCatalogService(db *sqlx.DB, redis *redis.Client, templateService *TemplateService, cfg config.CatalogConfig) *CatalogService {
+	return &CatalogService{
+		db:              db,
+		redis:           redis,
+		templateService: templateService,
+		config:          cfg,
+	}
+}
+
+// StartWorker 启动模版目录定时同步
+func (s *CatalogService) StartWorker() {
+	if !s.config.Enabled || s.config.SyncInterval <= 0 {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"repository": s.config.Repository,
+		"interval":   s.config.SyncInterval,
+	}).Info("Starting template catalog worker")
+
+	ticker := time.NewTicker(s.config.SyncInterval)
+	defer ticker.Stop()
+
+	// 立即执行一次同步
+	s.runSync()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runSync()
+		}
+	}
+}
+
+// runSync 执行一次定时同步
+func (s *CatalogService) runSync() {
+	result, err := s.SyncCatalog(context.Background())
+	if err != nil {
+		logrus.WithError(err).Warn("Template catalog sync failed")
+		return
+	}
+	if len(result.Errors) > 0 {
+		logrus.WithField("errors", result.Errors).Warn("Template catalog synced with errors")
+	}
+}
+
+// SyncCatalog 从Git仓库同步模版目录
+// 目录中的模版以名称为键写入数据库并标记为只读；目录中已删除的模版在未被URL使用时一并删除。
+func (s *CatalogService) SyncCatalog(ctx context.Context) (*models.CatalogSyncResult, error) {
+	if !s.config.Enabled || s.config.Repository == "" {
+		return nil, fmt.Errorf("template catalog is not configured")
+	}
+
+	// 多实例部署时只允许一个实例同步
+	token, lock, err := acquireLock(ctx, s.redis, catalogLockKey, catalogLockTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire catalog lock: %w", err)
+	}
+	if !lock {
+		return nil, fmt.Errorf("template catalog sync already in progress")
+	}
+	defer func() {
+		if err := releaseLock(context.WithoutCancel(ctx), s.redis, catalogLockKey, token); err != nil {
+			logrus.WithError(err).Error("Failed to release catalog lock")
+		}
+	}()
+
+	snapshot, err := utils.ReadGitCatalog(ctx, utils.GitCatalogOptions{
+		Repository: s.config.Repository,
+		Branch:     s.config.Branch,
+		Path:       s.config.Path,
+		Token:      s.config.Token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ownerID, err := s.catalogOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CatalogSyncResult{
+		Revision:  snapshot.Revision,
+		Created:   []string{},
+		Updated:   []string{},
+		Unchanged: []string{},
+		Removed:   []string{},
+		Errors:    []string{},
+	}
+
+	files := make([]string, 0, len(snapshot.Files))
+	for name := range snapshot.Files {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+
+	// 任何文件解析失败时不删除模版，避免误删
+	complete := true
+	seen := make(map[string]bool)
+	for _, file := range files {
+		source := path.Join(s.config.Path, file)
+
+		bundle, err := utils.DecodeTemplateBundle(snapshot.Files[file])
+		if err != nil {
+			complete = false
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", source, err))
+			continue
+		}
+
+		for i := range bundle.Templates {
+			entry := &bundle.Templates[i]
+			if seen[entry.Name] {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: duplicate template name '%s'", source, entry.Name))
+				continue
+			}
+			seen[entry.Name] = true
+
+			action, err := s.templateService.SyncCatalogTemplate(ctx, ownerID, entry, source, snapshot.Revision)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %s: %v", source, entry.Name, err))
+				continue
+			}
+
+			switch action {
+			case "created":
+				result.Created = append(result.Created, entry.Name)
+			case "updated":
+				result.Updated = append(result.Updated, entry.Name)
+			default:
+				result.Unchanged = append(result.Unchanged, entry.Name)
+			}
+		}
+	}
+
+	if complete {
+		managed, err := s.templateService.ListCatalogTemplates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, template := range managed {
+			if seen[template.Name] {
+				continue
+			}
+			if err := s.templateService.RemoveCatalogTemplate(ctx, template.ID); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", template.Name, err))
+				continue
+			}
+			result.Removed = append(result.Removed, template.Name)
+		}
+	}
+
+	result.SyncedAt = time.Now()
+
+	logrus.WithFields(logrus.Fields{
+		"revision":  result.Revision,
+		"created":   len(result.Created),
+		"updated":   len(result.Updated),
+		"unchanged": len(result.Unchanged),
+		"removed":   len(result.Removed),
+		"errors":    len(result.Errors),
+	}).Info("Template catalog synced")
+
+	return result, nil
+}
+
+// catalogOwner 模版目录中的模版归属于最早创建的管理员
+func (s *CatalogService) catalogOwner(ctx context.Context) (uuid.UUID, error) {
+	var ownerID uuid.UUID
+	err := s.db.GetContext(ctx, &ownerID, "SELECT id FROM users WHERE role = 'admin' ORDER BY created_at LIMIT 1")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to find catalog owner")
+		return uuid.Nil, fmt.Errorf("failed to find admin user for template catalog: %w", err)
+	}
+	return ownerID, nil
+}
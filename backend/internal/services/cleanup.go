@@ -126,14 +126,15 @@ func (s *CleanupService) releaseLock(ctx context.Context) {
 func (s *CleanupService) getExpiredURLs(ctx context.Context) ([]models.EphemeralURL, error) {
 	query := `
 		SELECT eu.id, eu.project_id, eu.template_id, eu.path, eu.image, eu.env, eu.replicas, eu.resources,
-		       eu.container_config, eu.status, eu.ttl_seconds, eu.k8s_deployment_name, eu.k8s_service_name, eu.k8s_secret_name,
+		       eu.container_config, eu.status, eu.ttl_seconds, eu.k8s_deployment_name, eu.k8s_service_name, eu.k8s_secret_name, eu.ingress_host,
 		       eu.error_message, eu.started_at, eu.expire_at, eu.created_at, eu.updated_at,
 		       p.id, p.name, p.description, p.created_at, p.updated_at
 		FROM ephemeral_urls eu
 		INNER JOIN projects p ON eu.project_id = p.id
-		WHERE (
-			  (eu.status = 'active' AND eu.expire_at <= NOW())
-		   OR (eu.status = 'failed' AND eu.created_at <= NOW() - INTERVAL '1 hour')
+		WHERE eu.stack_id IS NULL
+		  AND (
+			  (eu.status IN ('active', 'degraded') AND eu.expire_at <= NOW())
+		   OR (eu.status = 'failed' AND eu.created_at <= NOW() - INTERVAL '1 hour' AND eu.next_retry_at IS NULL)
 		)
 		ORDER BY eu.expire_at ASC
 		LIMIT 50
@@ -152,7 +153,7 @@ func (s *CleanupService) getExpiredURLs(ctx context.Context) ([]models.Ephemeral
 
 		err := rows.Scan(
 			&url.ID, &url.ProjectID, &url.TemplateID, &url.Path, &url.Image, &url.Env, &url.Replicas, &url.Resources,
-			&url.ContainerConfig, &url.Status, &url.TTLSeconds, &url.K8sDeploymentName, &url.K8sServiceName, &url.K8sSecretName,
+			&url.ContainerConfig, &url.Status, &url.TTLSeconds, &url.K8sDeploymentName, &url.K8sServiceName, &url.K8sSecretName, &url.IngressHost,
 			&url.ErrorMessage, &url.StartedAt, &url.ExpireAt, &url.CreatedAt, &url.UpdatedAt,
 			&url.Project.ID, &url.Project.Name, &url.Project.Description, &url.Project.CreatedAt, &url.Project.UpdatedAt,
 		)
@@ -170,7 +171,7 @@ func (s *CleanupService) getExpiredURLs(ctx context.Context) ([]models.Ephemeral
 	return urls, nil
 }
 
-// cleanupURL 清理单个URL
+// cleanupURL 清理单个URL，Kubernetes资源由异步删除操作清理，完成后URL标记为已删除
 func (s *CleanupService) cleanupURL(ctx context.Context, url *models.EphemeralURL) error {
 	logrus.WithFields(logrus.Fields{
 		"url_id": url.ID,
@@ -178,25 +179,40 @@ func (s *CleanupService) cleanupURL(ctx context.Context, url *models.EphemeralUR
 		"status": url.Status,
 	}).Info("Cleaning up expired URL")
 
-	// 更新状态为删除中
-	if err := s.updateURLStatus(ctx, url.ID, models.StatusDeleting, ""); err != nil {
-		return fmt.Errorf("failed to update status to deleting: %w", err)
+	op, err := s.enqueueDelete(ctx, url, models.OperationSourceCleanup, true)
+	if err != nil {
+		return err
 	}
 
-	// 删除Kubernetes资源
-	if err := s.deleteKubernetesResources(ctx, url); err != nil {
-		logrus.WithError(err).Error("Failed to delete Kubernetes resources")
-		// 更新状态为失败，但继续清理数据库记录
-		s.updateURLStatus(ctx, url.ID, models.StatusFailed, err.Error())
+	logrus.WithFields(logrus.Fields{
+		"url_id":       url.ID,
+		"operation_id": op.ID,
+	}).Info("URL cleanup enqueued")
+	return nil
+}
+
+// enqueueDelete 在同一事务中把URL标记为删除中并写入删除操作，keepRecord为true时删除完成后保留URL记录
+func (s *CleanupService) enqueueDelete(ctx context.Context, url *models.EphemeralURL, source string, keepRecord bool) (*models.Operation, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// 更新状态为已删除
-	if err := s.updateURLStatus(ctx, url.ID, models.StatusDeleted, ""); err != nil {
-		return fmt.Errorf("failed to update status to deleted: %w", err)
+	if err := setURLStatusTx(ctx, tx, url.ID, models.StatusDeleting); err != nil {
+		return nil, fmt.Errorf("failed to update status to deleting: %w", err)
 	}
 
-	logrus.WithField("url_id", url.ID).Info("URL cleanup completed")
-	return nil
+	op := newOperation(s.config, models.OperationDelete, url, models.OperationData{"keep_record": keepRecord})
+	op.Source = source
+	if err := enqueueOperation(ctx, tx, op); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return op, nil
 }
 
 // deleteKubernetesResources 删除Kubernetes资源
@@ -209,11 +225,19 @@ func (s *CleanupService) deleteKubernetesResources(ctx context.Context, url *mod
 
 	var errors []error
 
-	// 从Ingress移除路径
+	// 指向该URL的别名切换到兜底页面
+	if url.Project != nil {
+		detachURLAliases(ctx, s.db, s.resourceManager, s.ingressManager, s.config, url, url.Project.Name)
+	}
+
+	// 该URL参与的流量切分恢复为全部流量转发给主URL
+	revertURLTrafficSplits(ctx, s.db, s.ingressManager, url)
+
+	// 从Ingress移除路径，仍被其他URL使用的自定义域名路由保留
 	if url.Project != nil {
-		if err := s.ingressManager.RemovePath(ctx, url.Project.Name, url.Path); err != nil {
-			logrus.WithError(err).Warn("Failed to remove ingress path")
-			errors = append(errors, fmt.Errorf("failed to remove ingress path: %w", err))
+		if err := s.ingressManager.RemoveRoute(ctx, routeOwnedURL(ctx, s.db, url), url.Project.Name); err != nil {
+			logrus.WithError(err).Warn("Failed to remove ingress route")
+			errors = append(errors, fmt.Errorf("failed to remove ingress route: %w", err))
 		}
 	}
 
@@ -253,7 +277,7 @@ func (s *CleanupService) deleteKubernetesResources(ctx context.Context, url *mod
 func (s *CleanupService) updateURLStatus(ctx context.Context, id uuid.UUID, status, errorMessage string) error {
 	query := `
 		UPDATE ephemeral_urls 
-		SET status = $2, error_message = $3, updated_at = $4
+		SET status = $2, error_message = $3, diagnosis = NULL, updated_at = $4
 		WHERE id = $1
 	`
 
@@ -270,7 +294,7 @@ func (s *CleanupService) updateURLStatus(ctx context.Context, id uuid.UUID, stat
 	return nil
 }
 
-// ForceCleanupURL 强制清理指定URL（用于手动删除）
+// ForceCleanupURL 强制清理指定URL（用于PR关闭时删除预览URL）
 func (s *CleanupService) ForceCleanupURL(ctx context.Context, id uuid.UUID) error {
 	// 获取URL信息
 	url, err := s.getURLWithProject(ctx, id)
@@ -283,56 +307,32 @@ func (s *CleanupService) ForceCleanupURL(ctx context.Context, id uuid.UUID) erro
 		return nil
 	}
 
+	// 环境成员只能随环境整体删除
+	if url.StackID != nil {
+		return fmt.Errorf("URL belongs to a stack and can only be deleted with the stack")
+	}
+
 	// 执行清理和删除
-	return s.forceDeleteURL(ctx, url)
+	return s.forceDeleteURL(ctx, url, models.OperationSourceWebhook)
 }
 
-// forceDeleteURL 强制删除URL（清理资源并从数据库删除）
-func (s *CleanupService) forceDeleteURL(ctx context.Context, url *models.EphemeralURL) error {
+// forceDeleteURL 强制删除URL，由异步删除操作清理资源并从数据库删除
+func (s *CleanupService) forceDeleteURL(ctx context.Context, url *models.EphemeralURL, source string) error {
 	logrus.WithFields(logrus.Fields{
 		"url_id": url.ID,
 		"path":   url.Path,
 		"status": url.Status,
 	}).Info("Force deleting URL")
 
-	// 更新状态为删除中
-	if err := s.updateURLStatus(ctx, url.ID, models.StatusDeleting, ""); err != nil {
-		return fmt.Errorf("failed to update status to deleting: %w", err)
-	}
-
-	// 删除Kubernetes资源
-	if err := s.deleteKubernetesResources(ctx, url); err != nil {
-		logrus.WithError(err).Error("Failed to delete Kubernetes resources")
-		// 即使K8s资源删除失败，也继续删除数据库记录
-	}
-
-	// 从数据库中删除URL记录
-	if err := s.deleteURLFromDatabase(ctx, url.ID); err != nil {
-		return fmt.Errorf("failed to delete URL from database: %w", err)
-	}
-
-	logrus.WithField("url_id", url.ID).Info("URL force deletion completed")
-	return nil
-}
-
-// deleteURLFromDatabase 从数据库中删除URL记录
-func (s *CleanupService) deleteURLFromDatabase(ctx context.Context, id uuid.UUID) error {
-	query := "DELETE FROM ephemeral_urls WHERE id = $1"
-	result, err := s.db.ExecContext(ctx, query, id)
+	op, err := s.enqueueDelete(ctx, url, source, false)
 	if err != nil {
-		return fmt.Errorf("failed to delete URL from database: %w", err)
+		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("URL not found")
-	}
-
-	logrus.WithField("url_id", id).Info("URL deleted from database")
+	logrus.WithFields(logrus.Fields{
+		"url_id":       url.ID,
+		"operation_id": op.ID,
+	}).Info("URL force deletion enqueued")
 	return nil
 }
 
@@ -340,8 +340,8 @@ func (s *CleanupService) deleteURLFromDatabase(ctx context.Context, id uuid.UUID
 func (s *CleanupService) getURLWithProject(ctx context.Context, id uuid.UUID) (*models.EphemeralURL, error) {
 	query := `
 		SELECT eu.id, eu.project_id, eu.path, eu.image, eu.env, eu.replicas, eu.resources,
-		       eu.status, eu.k8s_deployment_name, eu.k8s_service_name, eu.k8s_secret_name,
-		       eu.error_message, eu.expire_at, eu.created_at, eu.updated_at,
+		       eu.status, eu.k8s_deployment_name, eu.k8s_service_name, eu.k8s_secret_name, eu.ingress_host,
+		       eu.error_message, eu.stack_id, eu.expire_at, eu.created_at, eu.updated_at,
 		       p.id, p.name, p.description, p.created_at, p.updated_at
 		FROM ephemeral_urls eu
 		INNER JOIN projects p ON eu.project_id = p.id
@@ -351,8 +351,8 @@ func (s *CleanupService) getURLWithProject(ctx context.Context, id uuid.UUID) (*
 	url := &models.EphemeralURL{Project: &models.Project{}}
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&url.ID, &url.ProjectID, &url.Path, &url.Image, &url.Env, &url.Replicas, &url.Resources,
-		&url.Status, &url.K8sDeploymentName, &url.K8sServiceName, &url.K8sSecretName,
-		&url.ErrorMessage, &url.ExpireAt, &url.CreatedAt, &url.UpdatedAt,
+		&url.Status, &url.K8sDeploymentName, &url.K8sServiceName, &url.K8sSecretName, &url.IngressHost,
+		&url.ErrorMessage, &url.StackID, &url.ExpireAt, &url.CreatedAt, &url.UpdatedAt,
 		&url.Project.ID, &url.Project.Name, &url.Project.Description, &url.Project.CreatedAt, &url.Project.UpdatedAt,
 	)
 
@@ -394,7 +394,7 @@ func (s *CleanupService) ValidateAndCleanupData(ctx context.Context) error {
 	return nil
 }
 
-// cleanupOrphanURLs 清理孤儿URL
+// cleanupOrphanURLs 清理孤儿URL，记录删除后遗留的Kubernetes资源由GCService回收
 func (s *CleanupService) cleanupOrphanURLs(ctx context.Context) error {
 	query := `
 		DELETE FROM ephemeral_urls 
@@ -415,13 +415,17 @@ func (s *CleanupService) cleanupOrphanURLs(ctx context.Context) error {
 
 // validateAndFixURLStatus 校验并修复URL状态
 func (s *CleanupService) validateAndFixURLStatus(ctx context.Context) error {
-	// 获取所有非deleted状态的URL
+	// 获取所有非deleted状态的URL，异步操作尚未完成的URL状态由操作更新
 	query := `
 		SELECT eu.id, eu.status, eu.k8s_deployment_name, eu.created_at,
 		       p.name as project_name
 		FROM ephemeral_urls eu
 		INNER JOIN projects p ON eu.project_id = p.id
 		WHERE eu.status IN ('creating', 'active', 'draft', 'failed')
+		  AND NOT EXISTS (
+			SELECT 1 FROM operations o
+			WHERE o.url_id = eu.id AND o.status IN ('pending', 'running')
+		  )
 		ORDER BY eu.created_at DESC
 	`
 
@@ -504,19 +508,23 @@ func (s *CleanupService) validateAndFixURLStatus(ctx context.Context) error {
 
 // cleanupStuckURLs 清理长时间处于中间状态的URL
 func (s *CleanupService) cleanupStuckURLs(ctx context.Context) error {
-	// 清理超过30分钟还在creating状态的URL
-	stuckThreshold := time.Now().Add(-30 * time.Minute)
-
+	// 清理部署后超过启动超时还在creating状态的URL，仍有异步操作在重试的除外
+	// 启动超时按startupTimeout的规则取URL的startup_timeout_seconds或配置的默认值
 	query := `
 		UPDATE ephemeral_urls 
 		SET status = 'failed', 
 		    error_message = 'Stuck in creating state for too long',
 		    updated_at = NOW()
 		WHERE status = 'creating' 
-		  AND created_at < $1
+		  AND COALESCE(deployment_requested_at, created_at) < NOW() - make_interval(
+			secs => COALESCE(NULLIF((container_config->>'startup_timeout_seconds')::int, 0), $1))
+		  AND NOT EXISTS (
+			SELECT 1 FROM operations o
+			WHERE o.url_id = ephemeral_urls.id AND o.status IN ('pending', 'running')
+		  )
 	`
 
-	result, err := s.db.ExecContext(ctx, query, stuckThreshold)
+	result, err := s.db.ExecContext(ctx, query, int(startupTimeout(s.config.Readiness, models.ContainerConfig{}).Seconds()))
 	if err != nil {
 		return fmt.Errorf("failed to cleanup stuck URLs: %w", err)
 	}
@@ -534,13 +542,18 @@ func (s *CleanupService) forceCleanupExpiredURLs(ctx context.Context) error {
 	// 获取所有过期但状态不是deleted的URL
 	query := `
 		SELECT eu.id, eu.project_id, eu.path, eu.image, eu.env, eu.replicas, eu.resources,
-		       eu.status, eu.k8s_deployment_name, eu.k8s_service_name, eu.k8s_secret_name,
+		       eu.status, eu.k8s_deployment_name, eu.k8s_service_name, eu.k8s_secret_name, eu.ingress_host,
 		       eu.error_message, eu.expire_at, eu.created_at, eu.updated_at,
 		       p.id, p.name, p.description, p.created_at, p.updated_at
 		FROM ephemeral_urls eu
 		INNER JOIN projects p ON eu.project_id = p.id
 		WHERE eu.expire_at <= NOW() 
 		  AND eu.status != 'deleted'
+		  AND eu.stack_id IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM operations o
+			WHERE o.url_id = eu.id AND o.type = 'delete' AND o.status IN ('pending', 'running')
+		  )
 		ORDER BY eu.expire_at ASC
 	`
 
@@ -557,7 +570,7 @@ func (s *CleanupService) forceCleanupExpiredURLs(ctx context.Context) error {
 
 		err := rows.Scan(
 			&url.ID, &url.ProjectID, &url.Path, &url.Image, &url.Env, &url.Replicas, &url.Resources,
-			&url.Status, &url.K8sDeploymentName, &url.K8sServiceName, &url.K8sSecretName,
+			&url.Status, &url.K8sDeploymentName, &url.K8sServiceName, &url.K8sSecretName, &url.IngressHost,
 			&url.ErrorMessage, &url.ExpireAt, &url.CreatedAt, &url.UpdatedAt,
 			&url.Project.ID, &url.Project.Name, &url.Project.Description, &url.Project.CreatedAt, &url.Project.UpdatedAt,
 		)
@@ -567,7 +580,7 @@ func (s *CleanupService) forceCleanupExpiredURLs(ctx context.Context) error {
 		}
 
 		// 强制删除
-		if err := s.forceDeleteURL(ctx, &url); err != nil {
+		if err := s.forceDeleteURL(ctx, &url, models.OperationSourceCleanup); err != nil {
 			logrus.WithError(err).WithField("url_id", url.ID).Error("Failed to force delete expired URL")
 		} else {
 			cleanedCount++
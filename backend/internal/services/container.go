@@ -11,11 +11,23 @@ import (
 
 // Container 服务容器
 type Container struct {
-	AuthService     *AuthService
-	ProjectService  *ProjectService
-	URLService      *URLService
-	TemplateService *TemplateService
-	CleanupService  *CleanupService
+	AuthService            *AuthService
+	ProjectService         *ProjectService
+	URLService             *URLService
+	TemplateService        *TemplateService
+	CleanupService         *CleanupService
+	CatalogService         *CatalogService
+	StackService           *StackService
+	GitWebhookService      *GitWebhookService
+	RegistryWebhookService *RegistryWebhookService
+	AliasService           *AliasService
+	DomainService          *DomainService
+	TrafficSplitService    *TrafficSplitService
+	OperationService       *OperationService
+	IdempotencyService     *IdempotencyService
+	GCService              *GCService
+	DriftService           *DriftService
+	RetryService           *RetryService
 }
 
 // StartWorkers 启动所有后台工作线程
@@ -23,8 +35,32 @@ func (c *Container) StartWorkers() {
 	// 启动清理工作线程
 	go c.CleanupService.StartWorker()
 
+	// 拆分旧版本的项目共用Ingress
+	go c.URLService.MigrateSharedIngresses()
+
 	// 启动Pod状态监控工作线程
 	go c.URLService.StartPodMonitor()
+
+	// 启动模版目录同步工作线程（未启用时立即返回）
+	go c.CatalogService.StartWorker()
+
+	// 启动环境过期清理工作线程
+	go c.StackService.StartWorker()
+
+	// 启动自定义域名验证工作线程
+	go c.DomainService.StartWorker()
+
+	// 启动异步操作工作线程
+	c.OperationService.StartWorkers()
+
+	// 启动孤儿资源回收工作线程（未启用时立即返回）
+	go c.GCService.StartWorker()
+
+	// 启动配置漂移自愈工作线程（未启用时立即返回）
+	go c.DriftService.StartWorker()
+
+	// 启动失败URL自动重试工作线程
+	go c.RetryService.StartWorker()
 }
 
 // NewContainer 创建服务容器
@@ -35,7 +71,15 @@ func NewContainer(db *sql.DB, redis *redis.Client, k8sClient *k8s.Client, cfg *c
 	// 只有在k8sClient不为nil时才创建资源管理器
 	if k8sClient != nil {
 		resourceManager = k8s.NewResourceManager(k8sClient, cfg.K8s.Namespace)
-		ingressManager = k8s.NewIngressManager(k8sClient, cfg.K8s.Namespace, cfg.K8s.IngressClass, cfg.K8s.DefaultDomain)
+		ingressManager = k8s.NewIngressManager(k8sClient, cfg.K8s.Namespace, k8s.IngressOptions{
+			Provider:         cfg.K8s.IngressProvider,
+			IngressClass:     cfg.K8s.IngressClass,
+			Domain:           cfg.K8s.DefaultDomain,
+			WildcardDomain:   cfg.K8s.WildcardDomain,
+			CertIssuer:       cfg.K8s.CertManagerIssuer,
+			GatewayName:      cfg.K8s.GatewayName,
+			GatewayNamespace: cfg.K8s.GatewayNamespace,
+		})
 	}
 
 	// 为 TemplateService 创建 sqlx.DB 实例
@@ -43,16 +87,40 @@ func NewContainer(db *sql.DB, redis *redis.Client, k8sClient *k8s.Client, cfg *c
 
 	// 创建服务实例
 	authService := NewAuthService(sqlxDB, cfg.Security.JWTSecret)
-	projectService := NewProjectService(db)
-	templateService := NewTemplateService(sqlxDB)
+	projectService := NewProjectService(db, cfg)
+	templateService := NewTemplateService(sqlxDB, resourceManager)
 	urlService := NewURLService(db, resourceManager, ingressManager, templateService, cfg)
 	cleanupService := NewCleanupService(db, redis, resourceManager, ingressManager, cfg)
+	catalogService := NewCatalogService(sqlxDB, redis, templateService, cfg.Catalog)
+	stackService := NewStackService(db, redis, urlService, cleanupService, cfg)
+	gitWebhookService := NewGitWebhookService(db, urlService, cleanupService)
+	registryWebhookService := NewRegistryWebhookService(db, urlService, cfg)
+	aliasService := NewAliasService(db, urlService, resourceManager, ingressManager, cfg)
+	domainService := NewDomainService(db, redis, urlService, cfg)
+	trafficSplitService := NewTrafficSplitService(db, urlService, ingressManager)
+	operationService := NewOperationService(db, urlService, cfg)
+	idempotencyService := NewIdempotencyService(redis, cfg.Idempotency)
+	gcService := NewGCService(db, redis, resourceManager, ingressManager, cfg.GC)
+	driftService := NewDriftService(db, redis, urlService, resourceManager, ingressManager, cfg)
+	retryService := NewRetryService(db, redis, urlService, cfg)
 
 	return &Container{
-		AuthService:     authService,
-		ProjectService:  projectService,
-		URLService:      urlService,
-		TemplateService: templateService,
-		CleanupService:  cleanupService,
+		AuthService:            authService,
+		ProjectService:         projectService,
+		URLService:             urlService,
+		TemplateService:        templateService,
+		CleanupService:         cleanupService,
+		CatalogService:         catalogService,
+		StackService:           stackService,
+		GitWebhookService:      gitWebhookService,
+		RegistryWebhookService: registryWebhookService,
+		AliasService:           aliasService,
+		DomainService:          domainService,
+		TrafficSplitService:    trafficSplitService,
+		OperationService:       operationService,
+		IdempotencyService:     idempotencyService,
+		GCService:              gcService,
+		DriftService:           driftService,
+		RetryService:           retryService,
 	}
 }
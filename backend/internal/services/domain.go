@@ -0,0 +1,488 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+	"url-manager-system/backend/internal/config"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	domainLockKey = "url_manager:domain_verification_lock"
+	// domainCheckInterval 后台检查间隔，等待验证的域名每次都会检查
+	domainCheckInterval = time.Hour
+	// domainRecheckInterval 已验证域名的重新验证间隔
+	domainRecheckInterval = 24 * time.Hour
+	// domainPendingWindow 超过该时间仍未验证的域名不再自动检查，可手动触发验证
+	domainPendingWindow = 7 * 24 * time.Hour
+	// domainMaxFailures 已验证域名连续验证失败达到该次数后标记为失败
+	domainMaxFailures = 3
+)
+
+// domainColumns custom_domains表查询列，与scanDomain保持一致
+const domainColumns = `id, project_id, domain, verification_method, verification_token, status, failure_count,
+	last_error, verified_at, last_checked_at, user_id, created_at, updated_at`
+
+// DomainService 自定义域名服务
+type DomainService struct {
+	db         *sql.DB
+	redis      *redis.Client
+	urlService *URLService
+	config     *config.Config
+	resolver   *net.Resolver
+	httpClient *http.Client
+}
+
+// NewDomainService 创建自定义域名服务
+func NewDomainService(db *sql.DB, redis *redis.Client, urlService *URLService, cfg *config.Config) *DomainService {
+	return &DomainService{
+		db:         db,
+		redis:      redis,
+		urlService: urlService,
+		config:     cfg,
+		resolver:   net.DefaultResolver,
+		httpClient: newVerificationClient(),
+	}
+}
+
+// CreateDomain 为项目登记自定义域名，返回完成验证需要配置的记录
+func (s *DomainService) CreateDomain(ctx context.Context, projectID, userID uuid.UUID, req *models.CreateCustomDomainRequest) (*models.CustomDomain, error) {
+	domain, err := utils.NormalizeDomain(req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if utils.IsDomainWithin(domain, s.config.K8s.DefaultDomain) || utils.IsDomainWithin(domain, s.config.K8s.WildcardDomain) {
+		return nil, fmt.Errorf("invalid domain: %s is managed by the system", domain)
+	}
+
+	method := req.VerificationMethod
+	if method == "" {
+		method = models.DomainVerificationDNS
+	}
+
+	if _, err := s.urlService.getProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	var existing, verifiedElsewhere int
+	err = s.db.QueryRowContext(ctx, `
+		SELECT (SELECT COUNT(*) FROM custom_domains WHERE project_id = $1 AND domain = $2),
+		       (SELECT COUNT(*) FROM custom_domains WHERE project_id <> $1 AND domain = $2 AND status = $3)
+	`, projectID, domain, models.DomainStatusVerified).Scan(&existing, &verifiedElsewhere)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check domain: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("domain '%s' already exists in this project", domain)
+	}
+	if verifiedElsewhere > 0 {
+		return nil, fmt.Errorf("domain '%s' is already verified by another project", domain)
+	}
+
+	token, err := utils.GenerateRandomString(32, "abcdefghijklmnopqrstuvwxyz0123456789")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	now := time.Now()
+	d := &models.CustomDomain{
+		ID:                 uuid.New(),
+		ProjectID:          projectID,
+		Domain:             domain,
+		VerificationMethod: method,
+		VerificationToken:  token,
+		Status:             models.DomainStatusPending,
+		UserID:             &userID,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	query := `
+		INSERT INTO custom_domains (id, project_id, domain, verification_method, verification_token, status, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = s.db.ExecContext(ctx, query,
+		d.ID, d.ProjectID, d.Domain, d.VerificationMethod, d.VerificationToken, d.Status, d.UserID, d.CreatedAt, d.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create domain: %w", err)
+	}
+	decorateDomain(d)
+
+	logrus.WithFields(logrus.Fields{
+		"domain_id":  d.ID,
+		"project_id": projectID,
+		"domain":     domain,
+		"method":     method,
+	}).Info("Custom domain registered")
+
+	return d, nil
+}
+
+// GetDomain 获取自定义域名
+func (s *DomainService) GetDomain(ctx context.Context, id uuid.UUID) (*models.CustomDomain, error) {
+	query := fmt.Sprintf("SELECT %s FROM custom_domains WHERE id = $1", domainColumns)
+	d, err := scanDomain(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("domain not found")
+		}
+		return nil, fmt.Errorf("failed to get domain: %w", err)
+	}
+	decorateDomain(d)
+	return d, nil
+}
+
+// ListDomains 列出项目的自定义域名
+func (s *DomainService) ListDomains(ctx context.Context, projectID uuid.UUID) ([]models.CustomDomain, error) {
+	if _, err := s.urlService.getProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM custom_domains WHERE project_id = $1 ORDER BY domain", domainColumns)
+	rows, err := s.db.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	defer rows.Close()
+
+	var domains []models.CustomDomain
+	for rows.Next() {
+		d, err := scanDomain(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan domain")
+			continue
+		}
+		decorateDomain(d)
+		domains = append(domains, *d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating domains: %w", err)
+	}
+
+	return domains, nil
+}
+
+// VerifyDomain 立即检查域名的验证记录并返回最新状态，检查失败的原因记录在last_error中
+func (s *DomainService) VerifyDomain(ctx context.Context, id uuid.UUID) (*models.CustomDomain, error) {
+	d, err := s.GetDomain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordCheck(ctx, d, s.check(ctx, d)); err != nil {
+		return nil, err
+	}
+	return s.GetDomain(ctx, id)
+}
+
+// DeleteDomain 删除自定义域名，仍有URL使用时拒绝删除
+func (s *DomainService) DeleteDomain(ctx context.Context, id uuid.UUID) error {
+	d, err := s.GetDomain(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var inUse int
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM ephemeral_urls
+		WHERE project_id = $1 AND ingress_host = $2 AND status NOT IN ($3, $4)
+	`, d.ProjectID, d.Domain, models.StatusDeleting, models.StatusDeleted).Scan(&inUse)
+	if err != nil {
+		return fmt.Errorf("failed to check domain usage: %w", err)
+	}
+	if inUse > 0 {
+		return fmt.Errorf("domain is in use by %d URLs", inUse)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM custom_domains WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete domain: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"domain_id": id,
+		"domain":    d.Domain,
+	}).Info("Custom domain deleted")
+	return nil
+}
+
+// StartWorker 启动域名验证工作线程：重试等待验证的域名，并定期重新验证已验证的域名
+func (s *DomainService) StartWorker() {
+	logrus.Info("Starting custom domain verification worker")
+
+	ticker := time.NewTicker(domainCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runChecks()
+		}
+	}
+}
+
+// runChecks 检查到期的域名
+func (s *DomainService) runChecks() {
+	ctx := context.Background()
+
+	lock, err := s.redis.SetNX(ctx, domainLockKey, "locked", lockTTL).Result()
+	if err != nil || !lock {
+		logrus.Debug("Another instance is verifying domains, skipping")
+		return
+	}
+	defer func() {
+		if err := s.redis.Del(ctx, domainLockKey).Err(); err != nil {
+			logrus.WithError(err).Error("Failed to release domain verification lock")
+		}
+	}()
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM custom_domains
+		WHERE (status = $1 AND (last_checked_at IS NULL OR last_checked_at <= $2))
+		   OR (status = $3 AND created_at > $4)
+		ORDER BY last_checked_at NULLS FIRST
+		LIMIT 100
+	`, domainColumns)
+	now := time.Now()
+	rows, err := s.db.QueryContext(ctx, query,
+		models.DomainStatusVerified, now.Add(-domainRecheckInterval),
+		models.DomainStatusPending, now.Add(-domainPendingWindow))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query domains to verify")
+		return
+	}
+
+	var domains []*models.CustomDomain
+	for rows.Next() {
+		d, err := scanDomain(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan domain")
+			continue
+		}
+		domains = append(domains, d)
+	}
+	rows.Close()
+
+	for _, d := range domains {
+		if err := s.recordCheck(ctx, d, s.check(ctx, d)); err != nil {
+			logrus.WithError(err).WithField("domain", d.Domain).Error("Failed to record domain verification")
+		}
+	}
+}
+
+// check 检查域名的验证记录
+func (s *DomainService) check(ctx context.Context, d *models.CustomDomain) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	expected := utils.DomainChallengeValue(d.VerificationToken)
+
+	if d.VerificationMethod == models.DomainVerificationHTTP {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+d.Domain+utils.DomainChallengePath, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to fetch verification file: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("verification file returned status %d", resp.StatusCode)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if err != nil {
+			return fmt.Errorf("failed to read verification file: %w", err)
+		}
+		if strings.TrimSpace(string(body)) != expected {
+			return fmt.Errorf("verification file content does not match")
+		}
+		return nil
+	}
+
+	records, err := s.resolver.LookupTXT(ctx, utils.DomainChallengeRecord(d.Domain))
+	if err != nil {
+		return fmt.Errorf("failed to lookup TXT record: %w", err)
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == expected {
+			return nil
+		}
+	}
+	return fmt.Errorf("TXT record %s does not contain the verification value", utils.DomainChallengeRecord(d.Domain))
+}
+
+// recordCheck 保存验证结果
+// 验证成功时标记为已验证；已验证的域名连续失败达到上限才标记为失败，避免DNS的短暂故障影响使用。
+func (s *DomainService) recordCheck(ctx context.Context, d *models.CustomDomain, checkErr error) error {
+	if checkErr == nil {
+		_, err := s.db.ExecContext(ctx, `
+			UPDATE custom_domains
+			SET status = $2, failure_count = 0, last_error = NULL,
+			    verified_at = COALESCE(verified_at, NOW()), last_checked_at = NOW(), updated_at = NOW()
+			WHERE id = $1
+		`, d.ID, models.DomainStatusVerified)
+		if err != nil {
+			// 唯一索引保证同一域名只能被一个项目验证
+			if strings.Contains(err.Error(), "idx_custom_domains_verified_domain") {
+				return fmt.Errorf("domain '%s' is already verified by another project", d.Domain)
+			}
+			return fmt.Errorf("failed to update domain: %w", err)
+		}
+		if d.Status != models.DomainStatusVerified {
+			logrus.WithFields(logrus.Fields{
+				"domain_id": d.ID,
+				"domain":    d.Domain,
+			}).Info("Custom domain verified")
+		}
+		return nil
+	}
+
+	status := d.Status
+	failures := d.FailureCount + 1
+	if status == models.DomainStatusVerified && failures >= domainMaxFailures {
+		status = models.DomainStatusFailed
+		logrus.WithError(checkErr).WithField("domain", d.Domain).Warn("Custom domain failed re-verification")
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE custom_domains
+		SET status = $2, failure_count = $3, last_error = $4, last_checked_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, d.ID, status, failures, checkErr.Error())
+	if err != nil {
+		return fmt.Errorf("failed to update domain: %w", err)
+	}
+	return nil
+}
+
+// decorateDomain 补全域名验证需要配置的记录
+func decorateDomain(d *models.CustomDomain) {
+	value := utils.DomainChallengeValue(d.VerificationToken)
+	if d.VerificationMethod == models.DomainVerificationHTTP {
+		d.Challenge = &models.DomainChallenge{Type: "HTTP", Name: "http://" + d.Domain + utils.DomainChallengePath, Value: value}
+		return
+	}
+	d.Challenge = &models.DomainChallenge{Type: "TXT", Name: utils.DomainChallengeRecord(d.Domain), Value: value}
+}
+
+// scanDomain 扫描一行自定义域名记录
+func scanDomain(row rowScanner) (*models.CustomDomain, error) {
+	d := &models.CustomDomain{}
+	err := row.Scan(&d.ID, &d.ProjectID, &d.Domain, &d.VerificationMethod, &d.VerificationToken, &d.Status, &d.FailureCount,
+		&d.LastError, &d.VerifiedAt, &d.LastCheckedAt, &d.UserID, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// checkIngressHost 校验URL使用的自定义域名已被项目验证且未被项目中其他URL使用，返回规范化后的域名，空字符串表示不使用自定义域名
+// urlID为使用该域名的URL，创建URL时传uuid.Nil。
+func checkIngressHost(ctx context.Context, db *sql.DB, projectID, urlID uuid.UUID, host string) (string, error) {
+	if strings.TrimSpace(host) == "" {
+		return "", nil
+	}
+	domain, err := utils.NormalizeDomain(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid ingress host: %w", err)
+	}
+
+	var verified bool
+	err = db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM custom_domains WHERE project_id = $1 AND domain = $2 AND status = $3)",
+		projectID, domain, models.DomainStatusVerified).Scan(&verified)
+	if err != nil {
+		return "", fmt.Errorf("failed to check ingress host: %w", err)
+	}
+	if !verified {
+		return "", fmt.Errorf("invalid ingress host: %s is not a verified domain of this project", domain)
+	}
+
+	inUse, err := ingressHostInUse(ctx, db, projectID, urlID, domain)
+	if err != nil {
+		return "", err
+	}
+	if inUse {
+		return "", fmt.Errorf("ingress host %s is already used by another URL", domain)
+	}
+	return domain, nil
+}
+
+// ingressHostInUse 判断项目中除urlID外是否还有未删除的URL使用该自定义域名
+// 域名路由按项目和域名命名，仍有其他URL使用时不能移除。
+func ingressHostInUse(ctx context.Context, db *sql.DB, projectID, urlID uuid.UUID, host string) (bool, error) {
+	var inUse bool
+	err := db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM ephemeral_urls
+			WHERE project_id = $1 AND ingress_host = $2 AND id <> $3 AND status NOT IN ($4, $5)
+		)
+	`, projectID, host, urlID, models.StatusDeleting, models.StatusDeleted).Scan(&inUse)
+	if err != nil {
+		return false, fmt.Errorf("failed to check ingress host usage: %w", err)
+	}
+	return inUse, nil
+}
+
+// routeOwnedURL 返回移除路由时使用的URL，自定义域名仍被项目中其他URL使用时不移除该域名的路由
+func routeOwnedURL(ctx context.Context, db *sql.DB, url *models.EphemeralURL) *models.EphemeralURL {
+	if url.IngressHost == nil || *url.IngressHost == "" {
+		return url
+	}
+	inUse, err := ingressHostInUse(ctx, db, url.ProjectID, url.ID, *url.IngressHost)
+	if err != nil {
+		// 无法确认时保留域名路由，避免误删其他URL的路由
+		logrus.WithError(err).WithField("url_id", url.ID).Warn("Failed to check ingress host usage, keeping host route")
+	}
+	if err != nil || inUse {
+		owned := *url
+		owned.IngressHost = nil
+		return &owned
+	}
+	return url
+}
+
+// newVerificationClient 创建获取验证文件的HTTP客户端
+// 域名由用户填写，只允许连接公网地址，避免借验证请求访问集群内部服务。
+func newVerificationClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !utils.IsPublicIP(ip) {
+				return fmt.Errorf("connecting to %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 3 {
+				return fmt.Errorf("too many redirects")
+			}
+			return nil
+		},
+	}
+}
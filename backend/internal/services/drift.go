@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"url-manager-system/backend/internal/config"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/k8s"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const driftLockKey = "drift:lock"
+
+// DriftService 配置漂移检测服务
+// 比较集群中URL的Deployment、Service和路由与按URL记录生成的期望状态；
+// drift_policy为heal的项目由后台工作线程定期检查，发现漂移后通过部署操作重新应用期望状态。
+type DriftService struct {
+	db              *sql.DB
+	redis           *redis.Client
+	urlService      *URLService
+	resourceManager *k8s.ResourceManager
+	ingressManager  *k8s.IngressManager
+	config          *config.Config
+}
+
+// NewDriftService 创建配置漂移检测服务
+func NewDriftService(db *sql.DB, redis *redis.Client, urlService *URLService, resourceManager *k8s.ResourceManager, ingressManager *k8s.IngressManager, cfg *config.Config) *DriftService {
+	return &DriftService{
+		db:              db,
+		redis:           redis,
+		urlService:      urlService,
+		resourceManager: resourceManager,
+		ingressManager:  ingressManager,
+		config:          cfg,
+	}
+}
+
+// GetURLDrift 检测URL的配置漂移
+// 模版创建的URL由YAML生成资源，没有可比较的期望规格；未部署的URL没有Kubernetes资源。
+func (s *DriftService) GetURLDrift(ctx context.Context, id uuid.UUID) (*models.DriftReport, error) {
+	if s.resourceManager == nil || s.ingressManager == nil {
+		return nil, fmt.Errorf("kubernetes not available")
+	}
+
+	url, err := s.urlService.GetEphemeralURL(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if url.TemplateID != nil {
+		return nil, fmt.Errorf("drift detection is not supported for template URLs")
+	}
+	if url.Status != models.StatusActive && url.Status != models.StatusDegraded && url.Status != models.StatusWaiting {
+		return nil, fmt.Errorf("URL is not deployed")
+	}
+
+	return s.detect(ctx, url)
+}
+
+// detect 汇总Deployment、Service和路由的差异
+func (s *DriftService) detect(ctx context.Context, url *models.EphemeralURL) (*models.DriftReport, error) {
+	items, err := s.resourceManager.DetectDrift(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	routeItems, err := s.ingressManager.RouteDrift(ctx, url, url.Project)
+	if err != nil {
+		return nil, err
+	}
+	items = append(items, routeItems...)
+	if items == nil {
+		items = []models.DriftItem{}
+	}
+
+	return &models.DriftReport{
+		URLID:       url.ID,
+		DriftPolicy: url.Project.DriftPolicy,
+		Drifted:     len(items) > 0,
+		Items:       items,
+		CheckedAt:   time.Now(),
+	}, nil
+}
+
+// StartWorker 启动漂移自愈工作线程，未配置间隔或Kubernetes不可用时立即返回
+func (s *DriftService) StartWorker() {
+	if s.config.Drift.Interval <= 0 || s.resourceManager == nil || s.ingressManager == nil {
+		logrus.Info("Drift self-healing worker disabled")
+		return
+	}
+	logrus.WithField("interval", s.config.Drift.Interval).Info("Starting drift self-healing worker")
+
+	ticker := time.NewTicker(s.config.Drift.Interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.healProjects(context.Background())
+	}
+}
+
+// healProjects 检查自愈项目下的活跃URL，为发生漂移的URL提交重新部署操作
+// 有未完成操作的URL正在变更中，留到下一轮检查。
+func (s *DriftService) healProjects(ctx context.Context) {
+	lock, err := s.redis.SetNX(ctx, driftLockKey, "locked", lockTTL).Result()
+	if err != nil || !lock {
+		return
+	}
+	defer s.redis.Del(ctx, driftLockKey)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT eu.id FROM ephemeral_urls eu
+		INNER JOIN projects p ON eu.project_id = p.id
+		WHERE p.drift_policy = $1 AND eu.status = $2 AND eu.template_id IS NULL
+		  AND NOT EXISTS (
+		    SELECT 1 FROM operations o
+		    WHERE o.url_id = eu.id AND o.status IN ('pending', 'running')
+		  )
+	`, models.DriftPolicyHeal, models.StatusActive)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query URLs for drift check")
+		return
+	}
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			logrus.WithError(err).Error("Failed to scan URL for drift check")
+			continue
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		url, err := s.urlService.GetEphemeralURL(ctx, id)
+		if err != nil {
+			logrus.WithError(err).WithField("url_id", id).Warn("Failed to load URL for drift check")
+			continue
+		}
+
+		report, err := s.detect(ctx, url)
+		if err != nil {
+			logrus.WithError(err).WithField("url_id", id).Warn("Failed to detect drift")
+			continue
+		}
+		if !report.Drifted {
+			continue
+		}
+
+		op, err := s.enqueueHeal(ctx, url, report)
+		if err != nil {
+			logrus.WithError(err).WithField("url_id", id).Error("Failed to enqueue drift self-healing")
+			continue
+		}
+		logrus.WithFields(logrus.Fields{
+			"url_id":       id,
+			"operation_id": op.ID,
+			"differences":  len(report.Items),
+		}).Warn("Configuration drift detected, redeploying URL")
+	}
+}
+
+// enqueueHeal 提交按URL记录重新部署的操作，URL保持active状态
+func (s *DriftService) enqueueHeal(ctx context.Context, url *models.EphemeralURL, report *models.DriftReport) (*models.Operation, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	op := newOperation(s.config, models.OperationDeploy, url, models.OperationData{
+		"keep_active": true,
+		"drift":       report.Items,
+	})
+	op.Source = models.OperationSourceDrift
+	if err := enqueueOperation(ctx, tx, op); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return op, nil
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"url-manager-system/backend/internal/config"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/k8s"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	gcLockKey = "gc:lock"
+	// ephemeralServicePrefix URL的Service名称前缀，只回收完全指向这类Service的路由
+	ephemeralServicePrefix = "svc-ephemeral-"
+)
+
+// GCService 孤儿Kubernetes资源回收服务
+// 对比集群中带有系统标签的资源和ephemeral_urls：URL记录不存在或已删除的Deployment、Service、Secret，
+// 以及只转发到这些URL的Service的路由资源，超过宽限期后删除。URL记录随项目删除后，其资源也由此回收。
+type GCService struct {
+	db              *sql.DB
+	redis           *redis.Client
+	resourceManager *k8s.ResourceManager
+	ingressManager  *k8s.IngressManager
+	config          config.GCConfig
+}
+
+// NewGCService 创建孤儿资源回收服务
+func NewGCService(db *sql.DB, redis *redis.Client, resourceManager *k8s.ResourceManager, ingressManager *k8s.IngressManager, cfg config.GCConfig) *GCService {
+	return &GCService{
+		db:              db,
+		redis:           redis,
+		resourceManager: resourceManager,
+		ingressManager:  ingressManager,
+		config:          cfg,
+	}
+}
+
+// StartWorker 启动定期回收工作线程，未配置间隔或Kubernetes不可用时立即返回
+func (s *GCService) StartWorker() {
+	if s.config.Interval <= 0 || s.resourceManager == nil || s.ingressManager == nil {
+		logrus.Info("Orphan resource GC worker disabled")
+		return
+	}
+	logrus.WithField("interval", s.config.Interval).Info("Starting orphan resource GC worker")
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		report, err := s.Run(context.Background(), false)
+		if err != nil {
+			logrus.WithError(err).Warn("Orphan resource GC failed")
+			continue
+		}
+		if len(report.Orphans) > 0 {
+			logrus.WithFields(logrus.Fields{
+				"orphans": len(report.Orphans),
+				"deleted": report.Deleted,
+				"failed":  report.Failed,
+			}).Info("Orphan resource GC completed")
+		}
+	}
+}
+
+// Run 执行一次回收，dryRun为true时只报告不删除
+func (s *GCService) Run(ctx context.Context, dryRun bool) (*models.GCReport, error) {
+	if s.resourceManager == nil || s.ingressManager == nil {
+		return nil, fmt.Errorf("kubernetes not available")
+	}
+
+	lock, err := s.redis.SetNX(ctx, gcLockKey, "locked", lockTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire gc lock: %w", err)
+	}
+	if !lock {
+		return nil, fmt.Errorf("garbage collection is already running")
+	}
+	defer func() {
+		if err := s.redis.Del(context.Background(), gcLockKey).Err(); err != nil {
+			logrus.WithError(err).Error("Failed to release gc lock")
+		}
+	}()
+
+	report := &models.GCReport{
+		DryRun:      dryRun,
+		GracePeriod: s.config.GracePeriod.String(),
+		Orphans:     []models.GCOrphan{},
+		StartedAt:   time.Now(),
+	}
+
+	urlStatuses, liveServices, err := s.loadURLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.collectResources(ctx, report, urlStatuses); err != nil {
+		return nil, err
+	}
+	if err := s.collectRoutes(ctx, report, liveServices); err != nil {
+		return nil, err
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// loadURLs 读取所有URL的状态，以及未删除URL使用的Service
+func (s *GCService) loadURLs(ctx context.Context) (map[string]string, map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, status, k8s_service_name FROM ephemeral_urls")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query URLs: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := make(map[string]string)
+	services := make(map[string]bool)
+	for rows.Next() {
+		var id, status string
+		var serviceName *string
+		if err := rows.Scan(&id, &status, &serviceName); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan URL: %w", err)
+		}
+		statuses[id] = status
+		if serviceName != nil && status != models.StatusDeleted {
+			services[*serviceName] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating URLs: %w", err)
+	}
+	return statuses, services, nil
+}
+
+// collectResources 回收URL记录不存在或已删除的Deployment、Service和Secret
+func (s *GCService) collectResources(ctx context.Context, report *models.GCReport, urlStatuses map[string]string) error {
+	resources, err := s.resourceManager.ListManagedResources(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, res := range resources {
+		report.Scanned++
+		if res.URLID == "" {
+			continue
+		}
+
+		var reason string
+		switch status, ok := urlStatuses[res.URLID]; {
+		case !ok:
+			reason = "URL record not found"
+		case status == models.StatusDeleted:
+			reason = "URL is deleted"
+		default:
+			continue
+		}
+
+		if s.inGrace(res.CreatedAt) {
+			report.InGrace++
+			continue
+		}
+
+		urlID := res.URLID
+		orphan := models.GCOrphan{Kind: res.Kind, Name: res.Name, URLID: &urlID, Reason: reason}
+		if !report.DryRun {
+			s.delete(&orphan, report, func() error {
+				return s.resourceManager.DeleteManagedResource(ctx, res)
+			})
+		}
+		report.Orphans = append(report.Orphans, orphan)
+	}
+	return nil
+}
+
+// collectRoutes 回收只转发到已删除URL的Service的路由资源
+// 指向别名兜底页面或其他非URL Service的路由不处理。
+func (s *GCService) collectRoutes(ctx context.Context, report *models.GCReport, liveServices map[string]bool) error {
+	routes, err := s.ingressManager.ListRoutes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list routes: %w", err)
+	}
+
+	for _, route := range routes {
+		report.Scanned++
+		if !isOrphanRoute(route.Services, liveServices) {
+			continue
+		}
+
+		if s.inGrace(route.CreatedAt) {
+			report.InGrace++
+			continue
+		}
+
+		orphan := models.GCOrphan{Kind: route.Kind, Name: route.Name, Reason: "route only targets services of deleted URLs"}
+		if route.Project != "" {
+			project := route.Project
+			orphan.Project = &project
+		}
+		if !report.DryRun {
+			s.delete(&orphan, report, func() error {
+				return s.ingressManager.DeleteRoute(ctx, route)
+			})
+		}
+		report.Orphans = append(report.Orphans, orphan)
+	}
+	return nil
+}
+
+// isOrphanRoute 路由的所有后端都是URL的Service且都不属于未删除的URL
+func isOrphanRoute(services []string, liveServices map[string]bool) bool {
+	if len(services) == 0 {
+		return false
+	}
+	for _, name := range services {
+		if !strings.HasPrefix(name, ephemeralServicePrefix) || liveServices[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// inGrace 资源是否仍在宽限期内，刚创建的资源可能属于尚未提交的URL记录
+func (s *GCService) inGrace(createdAt time.Time) bool {
+	return time.Since(createdAt) < s.config.GracePeriod
+}
+
+// delete 删除孤儿资源并记录结果
+func (s *GCService) delete(orphan *models.GCOrphan, report *models.GCReport, remove func() error) {
+	if err := remove(); err != nil {
+		msg := err.Error()
+		orphan.Error = &msg
+		report.Failed++
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"kind": orphan.Kind,
+			"name": orphan.Name,
+		}).Warn("Failed to delete orphan resource")
+		return
+	}
+
+	orphan.Deleted = true
+	report.Deleted++
+	logrus.WithFields(logrus.Fields{
+		"kind":   orphan.Kind,
+		"name":   orphan.Name,
+		"reason": orphan.Reason,
+	}).Info("Deleted orphan resource")
+}
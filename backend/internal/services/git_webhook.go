@@ -0,0 +1,374 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	gitWebhookSecretLength = 32
+	gitWebhookSecretChars  = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	gitWebhookDefaultTTL   = 86400
+	gitWebhookDefaultTag   = "{sha}"
+)
+
+// gitRepositoryPattern 仓库路径格式，例如 owner/repo 或 group/subgroup/repo
+var gitRepositoryPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)+$`)
+
+// gitWebhookColumns git_webhooks表查询列，与scanGitWebhook保持一致
+const gitWebhookColumns = `id, project_id, template_id, user_id, provider, repository, secret,
+		       image_repository, image_tag_format, ttl_seconds, created_at, updated_at`
+
+// GitWebhookService Git webhook服务，根据PR事件创建、更新和删除预览URL
+type GitWebhookService struct {
+	db             *sql.DB
+	urlService     *URLService
+	cleanupService *CleanupService
+}
+
+// NewGitWebhookService 创建Git webhook服务
+func NewGitWebhookService(db *sql.DB, urlService *URLService, cleanupService *CleanupService) *GitWebhookService {
+	return &GitWebhookService{
+		db:             db,
+		urlService:     urlService,
+		cleanupService: cleanupService,
+	}
+}
+
+// CreateGitWebhook 创建Git webhook，未指定密钥时自动生成，只能使用用户可见的模版
+func (s *GitWebhookService) CreateGitWebhook(ctx context.Context, projectID, userID uuid.UUID, isAdmin bool, req *models.CreateGitWebhookRequest) (*models.GitWebhook, error) {
+	repository := strings.Trim(strings.TrimSpace(req.Repository), "/")
+	if !gitRepositoryPattern.MatchString(repository) {
+		return nil, fmt.Errorf("invalid git webhook: repository must be in the form owner/repo")
+	}
+
+	tagFormat := req.ImageTagFormat
+	if tagFormat == "" {
+		tagFormat = gitWebhookDefaultTag
+	}
+	ttlSeconds := req.TTLSeconds
+	if ttlSeconds == 0 {
+		ttlSeconds = gitWebhookDefaultTTL
+	}
+
+	// 用示例提交渲染一次镜像，确保镜像仓库和标签格式能组成合法的镜像名
+	sampleTag := utils.RenderImageTag(tagFormat, 1, "main", "0123456789abcdef0123456789abcdef01234567")
+	if sampleTag == "" {
+		return nil, fmt.Errorf("invalid git webhook: image_tag_format renders an empty tag")
+	}
+	if strings.Contains(req.ImageRepository, "@") || !utils.ValidateImageName(req.ImageRepository+":"+sampleTag) {
+		return nil, fmt.Errorf("invalid git webhook: invalid image repository %s", req.ImageRepository)
+	}
+
+	project, err := s.urlService.getProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := s.urlService.templateService.GetTemplateForUser(ctx, req.TemplateID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if template.Visibility == models.TemplateVisibilityProject && (template.ProjectID == nil || *template.ProjectID != project.ID) {
+		return nil, fmt.Errorf("template is not available in this project")
+	}
+
+	var count int
+	err = s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM git_webhooks WHERE project_id = $1 AND LOWER(repository) = LOWER($2)",
+		projectID, repository).Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check repository: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("git webhook for repository '%s' already exists in this project", repository)
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret, err = utils.GenerateRandomString(gitWebhookSecretLength, gitWebhookSecretChars)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %w", err)
+		}
+	}
+
+	now := time.Now()
+	webhook := &models.GitWebhook{
+		ID:              uuid.New(),
+		ProjectID:       projectID,
+		TemplateID:      req.TemplateID,
+		UserID:          &userID,
+		Provider:        req.Provider,
+		Repository:      repository,
+		Secret:          secret,
+		ImageRepository: req.ImageRepository,
+		ImageTagFormat:  tagFormat,
+		TTLSeconds:      ttlSeconds,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	query := `
+		INSERT INTO git_webhooks (
+			id, project_id, template_id, user_id, provider, repository, secret,
+			image_repository, image_tag_format, ttl_seconds, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		)
+	`
+	_, err = s.db.ExecContext(ctx, query,
+		webhook.ID, webhook.ProjectID, webhook.TemplateID, webhook.UserID, webhook.Provider, webhook.Repository, webhook.Secret,
+		webhook.ImageRepository, webhook.ImageTagFormat, webhook.TTLSeconds, webhook.CreatedAt, webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create git webhook: %w", err)
+	}
+
+	webhook.WebhookPath = gitWebhookPath(webhook.ID)
+
+	logrus.WithFields(logrus.Fields{
+		"webhook_id": webhook.ID,
+		"project_id": projectID,
+		"provider":   webhook.Provider,
+		"repository": webhook.Repository,
+	}).Info("Git webhook created successfully")
+
+	return webhook, nil
+}
+
+// ListGitWebhooks 列出项目的Git webhook，不返回密钥
+func (s *GitWebhookService) ListGitWebhooks(ctx context.Context, projectID uuid.UUID) ([]models.GitWebhook, error) {
+	query := `SELECT ` + gitWebhookColumns + ` FROM git_webhooks WHERE project_id = $1 ORDER BY created_at DESC`
+	rows, err := s.db.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list git webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []models.GitWebhook{}
+	for rows.Next() {
+		webhook, err := scanGitWebhook(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan git webhook")
+			continue
+		}
+		webhook.Secret = ""
+		webhooks = append(webhooks, *webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating git webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteGitWebhook 删除Git webhook，已创建的预览URL保留到过期
+func (s *GitWebhookService) DeleteGitWebhook(ctx context.Context, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM git_webhooks WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete git webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("git webhook not found")
+	}
+
+	logrus.WithField("webhook_id", id).Info("Git webhook deleted successfully")
+	return nil
+}
+
+// HandleDelivery 校验并处理一次webhook投递
+// signature 为 X-Hub-Signature-256（GitHub）或 X-Gitlab-Token（GitLab）请求头的值。
+func (s *GitWebhookService) HandleDelivery(ctx context.Context, id uuid.UUID, eventType, signature string, body []byte) (*models.GitWebhookResult, error) {
+	webhook, err := s.getGitWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch webhook.Provider {
+	case models.GitProviderGitHub:
+		if !utils.VerifyGitHubSignature(webhook.Secret, body, signature) {
+			return nil, fmt.Errorf("invalid webhook signature")
+		}
+		if eventType == "ping" {
+			return &models.GitWebhookResult{Action: models.GitWebhookIgnored, Message: "pong"}, nil
+		}
+	case models.GitProviderGitLab:
+		if !utils.SecureCompare(webhook.Secret, signature) {
+			return nil, fmt.Errorf("invalid webhook signature")
+		}
+	}
+
+	event, err := utils.ParseGitWebhookEvent(webhook.Provider, eventType, body)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return &models.GitWebhookResult{Action: models.GitWebhookIgnored, Message: fmt.Sprintf("event %q is not handled", eventType)}, nil
+	}
+	if !strings.EqualFold(event.Repository, webhook.Repository) {
+		return nil, fmt.Errorf("invalid webhook payload: repository %s does not match %s", event.Repository, webhook.Repository)
+	}
+
+	return s.handleEvent(ctx, webhook, event)
+}
+
+// handleEvent 根据事件创建、更新或删除预览URL
+func (s *GitWebhookService) handleEvent(ctx context.Context, webhook *models.GitWebhook, event *models.GitEvent) (*models.GitWebhookResult, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"webhook_id": webhook.ID,
+		"repository": event.Repository,
+		"action":     event.Action,
+		"pr_number":  event.PRNumber,
+		"branch":     event.Branch,
+		"commit":     event.CommitSHA,
+	})
+
+	url, err := s.findPreviewURL(ctx, webhook, event)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Action == models.GitEventClose {
+		if url == nil {
+			return &models.GitWebhookResult{Action: models.GitWebhookIgnored, Message: "no preview URL for this pull request"}, nil
+		}
+		if err := s.cleanupService.ForceCleanupURL(ctx, url.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete preview URL: %w", err)
+		}
+		logger.WithField("url_id", url.ID).Info("Preview URL deleted for closed pull request")
+		return &models.GitWebhookResult{Action: models.GitWebhookDeleted, URLID: &url.ID}, nil
+	}
+
+	tag := utils.RenderImageTag(webhook.ImageTagFormat, event.PRNumber, event.Branch, event.CommitSHA)
+	if tag == "" {
+		return nil, fmt.Errorf("invalid webhook payload: image tag is empty")
+	}
+	image := webhook.ImageRepository + ":" + tag
+
+	if url != nil {
+		if err := s.urlService.RolloutImage(ctx, url, image, event.CommitSHA, "Git webhook"); err != nil {
+			return nil, fmt.Errorf("failed to redeploy preview URL: %w", err)
+		}
+		logger.WithField("url_id", url.ID).Info("Preview URL redeployed")
+		return &models.GitWebhookResult{Action: models.GitWebhookRedeployed, URLID: &url.ID, Message: image}, nil
+	}
+
+	// 分支推送只更新已有的预览URL，不为每个分支创建新的URL
+	if event.PRNumber == 0 {
+		return &models.GitWebhookResult{Action: models.GitWebhookIgnored, Message: "no preview URL for this branch"}, nil
+	}
+
+	// 预览URL以webhook创建者的身份使用模版，模版对其不再可见时不创建
+	userID, isAdmin, err := s.webhookOwner(ctx, webhook)
+	if err != nil {
+		return nil, err
+	}
+	req := &models.CreateEphemeralURLFromTemplateRequest{
+		TemplateID: webhook.TemplateID,
+		TTLSeconds: webhook.TTLSeconds,
+	}
+	resp, err := s.urlService.createURLFromTemplate(ctx, webhook.ProjectID, userID, isAdmin, req, templateURLOptions{image: image, git: event})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create preview URL: %w", err)
+	}
+
+	logger.WithField("url_id", resp.ID).Info("Preview URL created for pull request")
+	return &models.GitWebhookResult{Action: models.GitWebhookCreated, URLID: &resp.ID, Message: resp.URL}, nil
+}
+
+// webhookOwner 返回webhook创建者及其是否为管理员，未记录创建者时使用项目所有者
+// 用户已被删除时返回空用户，只能使用组织内公开的模版。
+func (s *GitWebhookService) webhookOwner(ctx context.Context, webhook *models.GitWebhook) (uuid.UUID, bool, error) {
+	var (
+		userID uuid.UUID
+		role   string
+	)
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, COALESCE(role, '') FROM users
+		WHERE id = COALESCE($1, (SELECT user_id FROM projects WHERE id = $2))
+	`, webhook.UserID, webhook.ProjectID).Scan(&userID, &role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, false, nil
+		}
+		return uuid.Nil, false, fmt.Errorf("failed to get webhook owner: %w", err)
+	}
+	return userID, role == models.RoleAdmin, nil
+}
+
+// findPreviewURL 查找事件对应的未删除预览URL，PR事件按PR编号匹配，推送事件按分支匹配
+func (s *GitWebhookService) findPreviewURL(ctx context.Context, webhook *models.GitWebhook, event *models.GitEvent) (*models.EphemeralURL, error) {
+	query := `
+		SELECT id FROM ephemeral_urls
+		WHERE project_id = $1 AND LOWER(git_repository) = LOWER($2) AND git_pr_number = $3 AND status <> $4
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	args := []interface{}{webhook.ProjectID, webhook.Repository, event.PRNumber, models.StatusDeleted}
+	if event.PRNumber == 0 {
+		query = `
+			SELECT id FROM ephemeral_urls
+			WHERE project_id = $1 AND LOWER(git_repository) = LOWER($2) AND git_branch = $3 AND status <> $4
+			ORDER BY created_at DESC
+			LIMIT 1
+		`
+		args[2] = event.Branch
+	}
+
+	var id uuid.UUID
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find preview URL: %w", err)
+	}
+
+	return s.urlService.GetEphemeralURL(ctx, id)
+}
+
+// getGitWebhook 获取Git webhook（包含密钥）
+func (s *GitWebhookService) getGitWebhook(ctx context.Context, id uuid.UUID) (*models.GitWebhook, error) {
+	query := `SELECT ` + gitWebhookColumns + ` FROM git_webhooks WHERE id = $1`
+	webhook, err := scanGitWebhook(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("git webhook not found")
+		}
+		return nil, fmt.Errorf("failed to get git webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// scanGitWebhook 扫描一行Git webhook记录
+func scanGitWebhook(row rowScanner) (*models.GitWebhook, error) {
+	webhook := &models.GitWebhook{}
+	err := row.Scan(
+		&webhook.ID, &webhook.ProjectID, &webhook.TemplateID, &webhook.UserID, &webhook.Provider, &webhook.Repository, &webhook.Secret,
+		&webhook.ImageRepository, &webhook.ImageTagFormat, &webhook.TTLSeconds, &webhook.CreatedAt, &webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	webhook.WebhookPath = gitWebhookPath(webhook.ID)
+	return webhook, nil
+}
+
+// gitWebhookPath 在Git平台上配置的webhook接收路径
+func gitWebhookPath(id uuid.UUID) string {
+	return fmt.Sprintf("/api/v1/webhooks/git/%s", id)
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"url-manager-system/backend/internal/config"
+	"url-manager-system/backend/internal/db/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// idempotencyKeyPrefix 幂等键在Redis中的前缀
+const idempotencyKeyPrefix = "idempotency:"
+
+// idempotencyPendingTTL 处理中记录的保留时间
+// 服务进程在请求完成前退出时，处理中记录最多占用幂等键这么久，之后客户端可以使用同一个键重试。
+const idempotencyPendingTTL = 5 * time.Minute
+
+// IdempotencyService 幂等键服务
+// 同一用户使用同一个Idempotency-Key重复提交时重放第一次请求的响应，避免客户端重试创建重复的URL。
+type IdempotencyService struct {
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+// NewIdempotencyService 创建幂等键服务
+func NewIdempotencyService(redis *redis.Client, cfg config.IdempotencyConfig) *IdempotencyService {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &IdempotencyService{
+		redis: redis,
+		ttl:   ttl,
+	}
+}
+
+// Begin 占用幂等键，返回nil表示这是第一次请求，应继续处理；返回记录表示应重放已保存的响应
+// 同一个键用于不同的请求，或第一次请求仍在处理时返回错误。
+func (s *IdempotencyService) Begin(ctx context.Context, scope, key, requestHash string) (*models.IdempotencyRecord, error) {
+	redisKey := s.redisKey(scope, key)
+
+	pending, err := json.Marshal(models.IdempotencyRecord{RequestHash: requestHash})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode idempotency record: %w", err)
+	}
+
+	acquired, err := s.redis.SetNX(ctx, redisKey, pending, idempotencyPendingTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to store idempotency key: %w", err)
+	}
+	if acquired {
+		return nil, nil
+	}
+
+	data, err := s.redis.Get(ctx, redisKey).Bytes()
+	if err == redis.Nil {
+		// 键在两次调用之间过期，按第一次请求处理
+		return s.Begin(ctx, scope, key, requestHash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	var record models.IdempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotency record: %w", err)
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, fmt.Errorf("idempotency key already used with a different request")
+	}
+	if !record.Completed {
+		return nil, fmt.Errorf("idempotency key already used by a request in progress")
+	}
+	return &record, nil
+}
+
+// Complete 保存请求的响应，保留时间内使用同一个键的重复请求将收到该响应
+// 保留时间从请求完成时开始计算。
+func (s *IdempotencyService) Complete(ctx context.Context, scope, key, requestHash string, statusCode int, body []byte) error {
+	record := models.IdempotencyRecord{
+		RequestHash: requestHash,
+		Completed:   true,
+		StatusCode:  statusCode,
+	}
+	if json.Valid(body) {
+		record.Body = body
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency record: %w", err)
+	}
+
+	if err := s.redis.Set(ctx, s.redisKey(scope, key), data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store idempotency response: %w", err)
+	}
+	return nil
+}
+
+// Release 释放幂等键，请求因服务端错误失败时允许客户端使用同一个键重试
+func (s *IdempotencyService) Release(ctx context.Context, scope, key string) error {
+	if err := s.redis.Del(ctx, s.redisKey(scope, key)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// redisKey 幂等键按用户隔离，不同用户可以使用相同的键
+func (s *IdempotencyService) redisKey(scope, key string) string {
+	return idempotencyKeyPrefix + scope + ":" + key
+}
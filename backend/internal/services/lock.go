@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseLockScript 只有锁仍由自己持有时才删除，避免锁过期后删除其他实例获取的锁
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// acquireLock 获取分布式锁，成功时返回用于释放锁的持有者令牌
+func acquireLock(ctx context.Context, client *redis.Client, key string, ttl time.Duration) (string, bool, error) {
+	token := uuid.New().String()
+	ok, err := client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+// releaseLock 释放由token持有的分布式锁
+func releaseLock(ctx context.Context, client *redis.Client, key, token string) error {
+	return releaseLockScript.Run(ctx, client, []string{key}, token).Err()
+}
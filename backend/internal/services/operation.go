@@ -0,0 +1,494 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"url-manager-system/backend/internal/config"
+	"url-manager-system/backend/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// operationPollInterval 没有可执行的操作时工作线程的轮询间隔
+	operationPollInterval = time.Second
+	// operationLease 领取操作后的租约，进程退出后租约到期的操作会被重新领取
+	operationLease = 5 * time.Minute
+	// operationTimeout 单次执行的超时时间，必须小于租约
+	operationTimeout = 2 * time.Minute
+	// operationBaseBackoff 第一次重试前的等待时间，之后每次翻倍，最长operationMaxBackoff
+	operationBaseBackoff = 5 * time.Second
+	operationMaxBackoff  = 5 * time.Minute
+)
+
+// operationColumns operations表查询列，与scanOperation保持一致
+const operationColumns = `id, type, url_id, project_id, source, status, progress, payload, result, error, attempts, max_attempts,
+	next_run_at, started_at, finished_at, created_at, updated_at`
+
+// permanentError 重试也无法成功的错误，操作直接失败
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent 标记错误不需要重试
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// OperationService 异步操作服务
+// operations表是URL的Kubernetes变更的outbox：API请求、清理任务和webhook在修改URL记录的同一事务中写入操作，
+// 工作线程按创建顺序幂等地执行操作的Kubernetes部分，同一URL的操作依次执行，失败时按指数退避重试。
+type OperationService struct {
+	db         *sql.DB
+	urlService *URLService
+	config     *config.Config
+}
+
+// NewOperationService 创建异步操作服务
+func NewOperationService(db *sql.DB, urlService *URLService, cfg *config.Config) *OperationService {
+	return &OperationService{
+		db:         db,
+		urlService: urlService,
+		config:     cfg,
+	}
+}
+
+// GetOperation 获取异步操作
+func (s *OperationService) GetOperation(ctx context.Context, id uuid.UUID) (*models.Operation, error) {
+	query := fmt.Sprintf("SELECT %s FROM operations WHERE id = $1", operationColumns)
+	op, err := scanOperation(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("operation not found")
+		}
+		return nil, fmt.Errorf("failed to get operation: %w", err)
+	}
+	return op, nil
+}
+
+// StartWorkers 启动执行异步操作的工作线程
+func (s *OperationService) StartWorkers() {
+	workers := s.config.Operations.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	logrus.WithField("workers", workers).Info("Starting operation workers")
+
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+}
+
+// work 循环领取并执行操作
+func (s *OperationService) work() {
+	for {
+		op, err := s.claim(context.Background())
+		if err != nil {
+			logrus.WithError(err).Error("Failed to claim operation")
+		}
+		if op == nil {
+			time.Sleep(operationPollInterval)
+			continue
+		}
+		s.run(op)
+	}
+}
+
+// claim 领取最早的一个可执行操作：等待执行且已到重试时间，或执行中但租约已过期（执行的进程已退出）
+// 同一URL存在更早的未完成操作时跳过，保证同一URL的操作按顺序执行；SKIP LOCKED使多个实例可以同时领取。
+func (s *OperationService) claim(ctx context.Context) (*models.Operation, error) {
+	query := fmt.Sprintf(`
+		UPDATE operations
+		SET status = $1, attempts = attempts + 1, locked_until = $2,
+		    started_at = COALESCE(started_at, NOW()), updated_at = NOW()
+		WHERE id = (
+			SELECT o.id FROM operations o
+			WHERE ((o.status = $3 AND o.next_run_at <= NOW()) OR (o.status = $1 AND o.locked_until < NOW()))
+			  AND NOT EXISTS (
+				SELECT 1 FROM operations p
+				WHERE p.url_id = o.url_id AND p.created_at < o.created_at AND p.status IN ($1, $3)
+			  )
+			ORDER BY o.created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %s
+	`, operationColumns)
+
+	op, err := scanOperation(s.db.QueryRowContext(ctx, query,
+		models.OperationStatusRunning, time.Now().Add(operationLease), models.OperationStatusPending))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return op, err
+}
+
+// run 执行操作并保存结果
+func (s *OperationService) run(op *models.Operation) {
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+
+	logger := logrus.WithFields(logrus.Fields{
+		"operation_id": op.ID,
+		"type":         op.Type,
+		"url_id":       op.URLID,
+		"attempt":      op.Attempts,
+	})
+	logger.Info("Running operation")
+
+	result, err := s.execute(ctx, op)
+	if err == nil {
+		s.complete(op, result)
+		logger.Info("Operation succeeded")
+		return
+	}
+
+	var perm *permanentError
+	if errors.As(err, &perm) || op.Attempts >= op.MaxAttempts {
+		s.fail(op, err)
+		logger.WithError(err).Error("Operation failed")
+		return
+	}
+
+	s.retry(op, err)
+	logger.WithError(err).Warn("Operation failed, will retry")
+}
+
+// execute 按操作类型执行Kubernetes部分
+func (s *OperationService) execute(ctx context.Context, op *models.Operation) (models.OperationData, error) {
+	progress := func(step string) {
+		if _, err := s.db.ExecContext(ctx, "UPDATE operations SET progress = $2, updated_at = NOW() WHERE id = $1", op.ID, step); err != nil {
+			logrus.WithError(err).WithField("operation_id", op.ID).Warn("Failed to update operation progress")
+		}
+	}
+
+	switch op.Type {
+	case models.OperationCreate:
+		return s.urlService.runCreateOperation(ctx, op, progress)
+	case models.OperationUpdate:
+		return s.urlService.runUpdateOperation(ctx, op, progress)
+	case models.OperationDelete:
+		return s.urlService.runDeleteOperation(ctx, op, progress)
+	case models.OperationDeploy:
+		return s.urlService.runDeployOperation(ctx, op, progress)
+	case models.OperationRollout:
+		return s.urlService.runRolloutOperation(ctx, op, progress)
+	case models.OperationRollback:
+		return s.urlService.runRollbackOperation(ctx, op, progress)
+	default:
+		return nil, permanent(fmt.Errorf("unknown operation type: %s", op.Type))
+	}
+}
+
+// complete 标记操作成功
+func (s *OperationService) complete(op *models.Operation, result models.OperationData) {
+	_, err := s.db.Exec(`
+		UPDATE operations
+		SET status = $2, result = $3, error = NULL, locked_until = NULL, finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, op.ID, models.OperationStatusSucceeded, result)
+	if err != nil {
+		logrus.WithError(err).WithField("operation_id", op.ID).Error("Failed to complete operation")
+	}
+}
+
+// retry 记录失败原因，按指数退避安排下一次执行
+func (s *OperationService) retry(op *models.Operation, cause error) {
+	_, err := s.db.Exec(`
+		UPDATE operations
+		SET status = $2, error = $3, locked_until = NULL, next_run_at = $4, updated_at = NOW()
+		WHERE id = $1
+	`, op.ID, models.OperationStatusPending, cause.Error(), time.Now().Add(operationBackoff(op.Attempts)))
+	if err != nil {
+		logrus.WithError(err).WithField("operation_id", op.ID).Error("Failed to schedule operation retry")
+	}
+}
+
+// fail 标记操作失败，并把URL标记为失败
+func (s *OperationService) fail(op *models.Operation, cause error) {
+	ctx := context.Background()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE operations
+		SET status = $2, error = $3, locked_until = NULL, finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, op.ID, models.OperationStatusFailed, cause.Error())
+	if err != nil {
+		logrus.WithError(err).WithField("operation_id", op.ID).Error("Failed to mark operation failed")
+	}
+
+	// 更新操作失败只是旧路由没有清理，滚动更新失败时旧镜像仍在运行，漂移自愈失败时URL仍按漂移后的配置运行，都不影响URL本身
+	if op.Type == models.OperationUpdate || op.Type == models.OperationRollout || op.Source == models.OperationSourceDrift {
+		return
+	}
+	if err := s.urlService.updateURLStatus(ctx, op.URLID, models.StatusFailed, cause.Error()); err != nil {
+		logrus.WithError(err).WithField("url_id", op.URLID).Error("Failed to mark URL failed")
+	}
+}
+
+// operationBackoff 第attempts次失败后的重试等待时间
+func operationBackoff(attempts int) time.Duration {
+	backoff := operationBaseBackoff
+	for i := 1; i < attempts && backoff < operationMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > operationMaxBackoff {
+		backoff = operationMaxBackoff
+	}
+	return backoff
+}
+
+// newOperation 构建URL的异步操作
+func newOperation(cfg *config.Config, opType string, url *models.EphemeralURL, payload models.OperationData) *models.Operation {
+	maxAttempts := cfg.Operations.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	now := time.Now()
+	return &models.Operation{
+		ID:          uuid.New(),
+		Type:        opType,
+		URLID:       url.ID,
+		ProjectID:   url.ProjectID,
+		Source:      models.OperationSourceAPI,
+		Status:      models.OperationStatusPending,
+		Payload:     payload,
+		MaxAttempts: maxAttempts,
+		NextRunAt:   now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// enqueueOperation 在事务中写入异步操作，事务提交后才会被工作线程领取
+func enqueueOperation(ctx context.Context, tx *sql.Tx, op *models.Operation) error {
+	query := `
+		INSERT INTO operations (id, type, url_id, project_id, source, status, payload, max_attempts, next_run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := tx.ExecContext(ctx, query,
+		op.ID, op.Type, op.URLID, op.ProjectID, op.Source, op.Status, op.Payload, op.MaxAttempts, op.NextRunAt, op.CreatedAt, op.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue operation: %w", err)
+	}
+	return nil
+}
+
+// scanOperation 扫描一行异步操作记录
+func scanOperation(row rowScanner) (*models.Operation, error) {
+	op := &models.Operation{}
+	err := row.Scan(&op.ID, &op.Type, &op.URLID, &op.ProjectID, &op.Source, &op.Status, &op.Progress, &op.Payload, &op.Result, &op.Error,
+		&op.Attempts, &op.MaxAttempts, &op.NextRunAt, &op.StartedAt, &op.FinishedAt, &op.CreatedAt, &op.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// loadOperationURL 获取操作对应的URL，URL不存在时操作无法完成
+func (s *URLService) loadOperationURL(ctx context.Context, op *models.Operation) (*models.EphemeralURL, error) {
+	url, err := s.GetEphemeralURL(ctx, op.URLID)
+	if err != nil {
+		if err.Error() == "URL not found" {
+			return nil, permanent(err)
+		}
+		return nil, err
+	}
+	return url, nil
+}
+
+// runCreateOperation 创建URL的Kubernetes资源，完成后等待Pod就绪
+func (s *URLService) runCreateOperation(ctx context.Context, op *models.Operation, progress func(string)) (models.OperationData, error) {
+	url, err := s.loadOperationURL(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建操作执行前URL已被删除
+	if url.Status == models.StatusDeleting || url.Status == models.StatusDeleted {
+		return models.OperationData{"skipped": fmt.Sprintf("URL is %s", url.Status)}, nil
+	}
+
+	progress("creating kubernetes resources")
+	if yamlSpec, _ := op.Payload["yaml"].(string); yamlSpec != "" {
+		err = s.createKubernetesResourcesFromYAML(ctx, url, yamlSpec)
+	} else {
+		err = s.createKubernetesResources(ctx, url, url.Project)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes resources: %w", err)
+	}
+	s.recordRevision(ctx, url, op, nil)
+
+	// 资源创建成功后，设置为等待状态（等待Pod Ready）
+	progress("waiting for pods")
+	if err := s.updateURLStatus(ctx, url.ID, models.StatusWaiting, ""); err != nil {
+		return nil, fmt.Errorf("failed to update status: %w", err)
+	}
+	go s.verifyDeployment(url)
+
+	return models.OperationData{
+		"url":    s.publicURL(url.Project, url.Path, url.TLSReady),
+		"status": models.StatusWaiting,
+	}, nil
+}
+
+// runDeployOperation 按项目当前的证书设置创建或更新URL的Kubernetes资源
+func (s *URLService) runDeployOperation(ctx context.Context, op *models.Operation, progress func(string)) (models.OperationData, error) {
+	if s.resourceManager == nil || s.ingressManager == nil {
+		return nil, permanent(fmt.Errorf("kubernetes not available"))
+	}
+
+	url, err := s.loadOperationURL(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+
+	// active状态的URL更新前记录集群中运行的版本，更新失败时回滚
+	keepActive, _ := op.Payload["keep_active"].(bool)
+	since := time.Now()
+	var previous *models.URLRevision
+	if keepActive {
+		previous = s.rollbackTarget(ctx, url)
+	}
+
+	progress("deploying kubernetes resources")
+	s.applyTLS(ctx, url.Project, url)
+	if err := s.createKubernetesResources(ctx, url, url.Project); err != nil {
+		return nil, fmt.Errorf("failed to deploy Kubernetes resources: %w", err)
+	}
+	s.recordRevision(ctx, url, op, nil)
+
+	// 更新部署状态
+	_, err = s.db.ExecContext(ctx,
+		"UPDATE ephemeral_urls SET deployed = true, deployment_requested_at = NOW(), tls_secret_name = $2, tls_ready = $3 WHERE id = $1",
+		url.ID, url.TLSSecretName, url.TLSReady)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update deployment status: %w", err)
+	}
+
+	// 如果原来是active状态，保持active状态并监控更新；否则设置为waiting状态
+	status := models.StatusActive
+	if keepActive {
+		go s.watchRollout(url, previous, since)
+	} else {
+		status = models.StatusWaiting
+		if err := s.updateURLStatus(ctx, url.ID, status, ""); err != nil {
+			return nil, fmt.Errorf("failed to update status: %w", err)
+		}
+	}
+
+	return models.OperationData{
+		"url":    s.publicURL(url.Project, url.Path, url.TLSReady),
+		"status": status,
+	}, nil
+}
+
+// runUpdateOperation 更新URL后清理不再使用的自定义域名路由
+func (s *URLService) runUpdateOperation(ctx context.Context, op *models.Operation, progress func(string)) (models.OperationData, error) {
+	host, _ := op.Payload["remove_host"].(string)
+	if host == "" || s.ingressManager == nil {
+		return models.OperationData{}, nil
+	}
+
+	url, err := s.loadOperationURL(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+
+	// 原域名已被项目中其他URL使用时，路由已指向该URL，不再移除
+	inUse, err := ingressHostInUse(ctx, s.db, url.ProjectID, url.ID, host)
+	if err != nil {
+		return nil, err
+	}
+	if inUse {
+		return models.OperationData{"skipped": fmt.Sprintf("host %s is used by another URL", host)}, nil
+	}
+
+	progress("removing custom host route")
+	if err := s.ingressManager.RemoveHost(ctx, url.Project.Name, host); err != nil {
+		return nil, fmt.Errorf("failed to remove custom host route: %w", err)
+	}
+
+	return models.OperationData{"removed_host": host}, nil
+}
+
+// runRolloutOperation 按URL记录中的镜像滚动更新Deployment
+// 模版创建的URL只替换Deployment主容器的镜像，其他URL按记录重新生成Deployment。
+func (s *URLService) runRolloutOperation(ctx context.Context, op *models.Operation, progress func(string)) (models.OperationData, error) {
+	if s.resourceManager == nil {
+		return nil, permanent(fmt.Errorf("kubernetes resource manager not available"))
+	}
+
+	url, err := s.loadOperationURL(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	if url.Status == models.StatusDeleting || url.Status == models.StatusDeleted {
+		return models.OperationData{"skipped": fmt.Sprintf("URL is %s", url.Status)}, nil
+	}
+	if url.K8sDeploymentName == nil {
+		return nil, permanent(fmt.Errorf("URL has no deployment"))
+	}
+
+	since := time.Now()
+	previous := s.rollbackTarget(ctx, url)
+
+	progress("rolling out image")
+	if url.TemplateID != nil {
+		err = s.resourceManager.SetDeploymentImage(ctx, *url.K8sDeploymentName, url.Image)
+	} else {
+		err = s.resourceManager.UpdateDeployment(ctx, url)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll out image: %w", err)
+	}
+	s.recordRevision(ctx, url, op, nil)
+	if url.Status == models.StatusActive || url.Status == models.StatusDegraded {
+		go s.watchRollout(url, previous, since)
+	}
+
+	return models.OperationData{"image": url.Image}, nil
+}
+
+// runDeleteOperation 删除URL的Kubernetes资源和URL记录，URL已不存在时视为删除完成
+func (s *URLService) runDeleteOperation(ctx context.Context, op *models.Operation, progress func(string)) (models.OperationData, error) {
+	url, err := s.GetEphemeralURL(ctx, op.URLID)
+	if err != nil {
+		if err.Error() == "URL not found" {
+			return models.OperationData{"status": models.StatusDeleted}, nil
+		}
+		return nil, err
+	}
+
+	if s.resourceManager != nil && s.ingressManager != nil {
+		progress("deleting kubernetes resources")
+		if err := s.deleteKubernetesResources(ctx, url); err != nil {
+			return nil, fmt.Errorf("failed to delete Kubernetes resources: %w", err)
+		}
+	}
+
+	// 过期清理保留URL记录，只标记为已删除
+	if keepRecord, _ := op.Payload["keep_record"].(bool); keepRecord {
+		if err := s.updateURLStatus(ctx, url.ID, models.StatusDeleted, ""); err != nil {
+			return nil, fmt.Errorf("failed to update final status: %w", err)
+		}
+		return models.OperationData{"status": models.StatusDeleted}, nil
+	}
+
+	// 从数据库中删除URL记录
+	progress("deleting URL record")
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM ephemeral_urls WHERE id = $1", url.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete URL from database: %w", err)
+	}
+
+	return models.OperationData{"status": models.StatusDeleted}, nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"time"
+	"url-manager-system/backend/internal/config"
 	"url-manager-system/backend/internal/db/models"
 
 	"github.com/google/uuid"
@@ -13,34 +14,77 @@ import (
 
 // ProjectService 项目服务
 type ProjectService struct {
-	db *sql.DB
+	db     *sql.DB
+	config *config.Config
 }
 
 // NewProjectService 创建项目服务
-func NewProjectService(db *sql.DB) *ProjectService {
-	return &ProjectService{db: db}
+func NewProjectService(db *sql.DB, cfg *config.Config) *ProjectService {
+	return &ProjectService{db: db, config: cfg}
 }
 
-// CreateProject 创建项目
-func (s *ProjectService) CreateProject(ctx context.Context, userID uuid.UUID, name, description string) (*models.Project, error) {
+// CreateProject 创建项目，未指定的路由模式和证书模式使用 path 和 none
+func (s *ProjectService) CreateProject(ctx context.Context, userID uuid.UUID, name, description string, settings models.ProjectRoutingSettings) (*models.Project, error) {
+	routingMode := settings.RoutingMode
+	if routingMode == "" {
+		routingMode = models.RoutingModePath
+	}
+	if err := s.validateRoutingMode(routingMode); err != nil {
+		return nil, err
+	}
+
+	tlsMode := settings.TLSMode
+	if tlsMode == "" {
+		tlsMode = models.TLSModeNone
+	}
+	if err := s.validateTLSMode(tlsMode, settings.TLSSecretName); err != nil {
+		return nil, err
+	}
+	var tlsSecretName *string
+	if settings.TLSSecretName != nil && *settings.TLSSecretName != "" {
+		tlsSecretName = settings.TLSSecretName
+	}
+
+	driftPolicy := settings.DriftPolicy
+	if driftPolicy == "" {
+		driftPolicy = models.DriftPolicyReport
+	}
+	if err := validateDriftPolicy(driftPolicy); err != nil {
+		return nil, err
+	}
+
+	retryPolicy := defaultRetryPolicy()
+	if settings.RetryPolicy != nil {
+		retryPolicy = *settings.RetryPolicy
+		if err := normalizeRetryPolicy(&retryPolicy); err != nil {
+			return nil, err
+		}
+	}
+
 	project := &models.Project{
-		ID:          uuid.New(),
-		UserID:      userID,
-		Name:        name,
-		Description: description,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:            uuid.New(),
+		UserID:        userID,
+		Name:          name,
+		Description:   description,
+		RoutingMode:   routingMode,
+		TLSMode:       tlsMode,
+		TLSSecretName: tlsSecretName,
+		DriftPolicy:   driftPolicy,
+		RetryPolicy:   retryPolicy,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	query := `
-		INSERT INTO projects (id, user_id, name, description, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, user_id, name, description, created_at, updated_at
+		INSERT INTO projects (id, user_id, name, description, routing_mode, tls_mode, tls_secret_name, drift_policy, retry_policy, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, user_id, name, description, routing_mode, tls_mode, tls_secret_name, drift_policy, retry_policy, created_at, updated_at
 	`
 
 	err := s.db.QueryRowContext(ctx, query,
-		project.ID, project.UserID, project.Name, project.Description, project.CreatedAt, project.UpdatedAt,
-	).Scan(&project.ID, &project.UserID, &project.Name, &project.Description, &project.CreatedAt, &project.UpdatedAt)
+		project.ID, project.UserID, project.Name, project.Description, project.RoutingMode, project.TLSMode, project.TLSSecretName, project.DriftPolicy,
+		project.RetryPolicy, project.CreatedAt, project.UpdatedAt,
+	).Scan(&project.ID, &project.UserID, &project.Name, &project.Description, &project.RoutingMode, &project.TLSMode, &project.TLSSecretName, &project.DriftPolicy, &project.RetryPolicy, &project.CreatedAt, &project.UpdatedAt)
 
 	if err != nil {
 		logrus.WithError(err).Error("Failed to create project")
@@ -55,13 +99,13 @@ func (s *ProjectService) CreateProject(ctx context.Context, userID uuid.UUID, na
 func (s *ProjectService) GetProject(ctx context.Context, id uuid.UUID) (*models.Project, error) {
 	project := &models.Project{}
 	query := `
-		SELECT id, user_id, name, description, created_at, updated_at
+		SELECT id, user_id, name, description, routing_mode, tls_mode, tls_secret_name, drift_policy, retry_policy, created_at, updated_at
 		FROM projects
 		WHERE id = $1
 	`
 
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&project.ID, &project.UserID, &project.Name, &project.Description, &project.CreatedAt, &project.UpdatedAt,
+		&project.ID, &project.UserID, &project.Name, &project.Description, &project.RoutingMode, &project.TLSMode, &project.TLSSecretName, &project.DriftPolicy, &project.RetryPolicy, &project.CreatedAt, &project.UpdatedAt,
 	)
 
 	if err != nil {
@@ -79,13 +123,13 @@ func (s *ProjectService) GetProject(ctx context.Context, id uuid.UUID) (*models.
 func (s *ProjectService) GetProjectByName(ctx context.Context, name string) (*models.Project, error) {
 	project := &models.Project{}
 	query := `
-		SELECT id, user_id, name, description, created_at, updated_at
+		SELECT id, user_id, name, description, routing_mode, tls_mode, tls_secret_name, drift_policy, retry_policy, created_at, updated_at
 		FROM projects
 		WHERE name = $1
 	`
 
 	err := s.db.QueryRowContext(ctx, query, name).Scan(
-		&project.ID, &project.UserID, &project.Name, &project.Description, &project.CreatedAt, &project.UpdatedAt,
+		&project.ID, &project.UserID, &project.Name, &project.Description, &project.RoutingMode, &project.TLSMode, &project.TLSSecretName, &project.DriftPolicy, &project.RetryPolicy, &project.CreatedAt, &project.UpdatedAt,
 	)
 
 	if err != nil {
@@ -108,7 +152,7 @@ func (s *ProjectService) ListProjects(ctx context.Context, userID *uuid.UUID, is
 		// 管理员可以查看所有项目
 		countQuery = "SELECT COUNT(*) FROM projects"
 		listQuery = `
-			SELECT id, user_id, name, description, created_at, updated_at
+			SELECT id, user_id, name, description, routing_mode, tls_mode, tls_secret_name, drift_policy, retry_policy, created_at, updated_at
 			FROM projects
 			ORDER BY created_at DESC
 			LIMIT $1 OFFSET $2
@@ -118,7 +162,7 @@ func (s *ProjectService) ListProjects(ctx context.Context, userID *uuid.UUID, is
 		// 普通用户只能查看自己的项目
 		countQuery = "SELECT COUNT(*) FROM projects WHERE user_id = $1"
 		listQuery = `
-			SELECT id, user_id, name, description, created_at, updated_at
+			SELECT id, user_id, name, description, routing_mode, tls_mode, tls_secret_name, drift_policy, retry_policy, created_at, updated_at
 			FROM projects
 			WHERE user_id = $1
 			ORDER BY created_at DESC
@@ -155,7 +199,7 @@ func (s *ProjectService) ListProjects(ctx context.Context, userID *uuid.UUID, is
 	for rows.Next() {
 		var project models.Project
 		err := rows.Scan(
-			&project.ID, &project.UserID, &project.Name, &project.Description, &project.CreatedAt, &project.UpdatedAt,
+			&project.ID, &project.UserID, &project.Name, &project.Description, &project.RoutingMode, &project.TLSMode, &project.TLSSecretName, &project.DriftPolicy, &project.RetryPolicy, &project.CreatedAt, &project.UpdatedAt,
 		)
 		if err != nil {
 			logrus.WithError(err).Error("Failed to scan project")
@@ -172,18 +216,62 @@ func (s *ProjectService) ListProjects(ctx context.Context, userID *uuid.UUID, is
 	return projects, total, nil
 }
 
-// UpdateProject 更新项目
-func (s *ProjectService) UpdateProject(ctx context.Context, id uuid.UUID, name, description string) (*models.Project, error) {
+// UpdateProject 更新项目，未指定的路由和证书设置保持不变
+// 项目下还有未删除的URL时不能切换路由模式，避免已有的访问地址失效；
+// 证书设置只影响之后创建或重新部署的URL。
+func (s *ProjectService) UpdateProject(ctx context.Context, id uuid.UUID, name, description string, settings models.ProjectRoutingSettings) (*models.Project, error) {
+	routingMode := settings.RoutingMode
+	if settings.DriftPolicy != "" {
+		if err := validateDriftPolicy(settings.DriftPolicy); err != nil {
+			return nil, err
+		}
+	}
+	if settings.RetryPolicy != nil {
+		if err := normalizeRetryPolicy(settings.RetryPolicy); err != nil {
+			return nil, err
+		}
+	}
+	if settings.TLSMode != "" {
+		if err := s.validateTLSMode(settings.TLSMode, settings.TLSSecretName); err != nil {
+			return nil, err
+		}
+	}
+
+	if routingMode != "" {
+		if err := s.validateRoutingMode(routingMode); err != nil {
+			return nil, err
+		}
+
+		var urlCount int
+		countQuery := `
+			SELECT COUNT(*) FROM ephemeral_urls
+			WHERE project_id = $1 AND status != 'deleted'
+			  AND EXISTS (SELECT 1 FROM projects WHERE id = $1 AND routing_mode != $2)
+		`
+		if err := s.db.QueryRowContext(ctx, countQuery, id, routingMode).Scan(&urlCount); err != nil {
+			logrus.WithError(err).Error("Failed to count project URLs")
+			return nil, fmt.Errorf("failed to count project URLs: %w", err)
+		}
+		if urlCount > 0 {
+			return nil, fmt.Errorf("cannot change routing mode of project with URLs")
+		}
+	}
+
 	query := `
 		UPDATE projects 
-		SET name = $2, description = $3, updated_at = $4
+		SET name = $2, description = $3, updated_at = $4,
+		    routing_mode = COALESCE(NULLIF($5, ''), routing_mode),
+		    tls_mode = COALESCE(NULLIF($6, ''), tls_mode),
+		    tls_secret_name = CASE WHEN $7::text IS NULL THEN tls_secret_name ELSE NULLIF($7::text, '') END,
+		    drift_policy = COALESCE(NULLIF($8, ''), drift_policy),
+		    retry_policy = COALESCE($9, retry_policy)
 		WHERE id = $1
-		RETURNING id, user_id, name, description, created_at, updated_at
+		RETURNING id, user_id, name, description, routing_mode, tls_mode, tls_secret_name, drift_policy, retry_policy, created_at, updated_at
 	`
 
 	project := &models.Project{}
-	err := s.db.QueryRowContext(ctx, query, id, name, description, time.Now()).Scan(
-		&project.ID, &project.UserID, &project.Name, &project.Description, &project.CreatedAt, &project.UpdatedAt,
+	err := s.db.QueryRowContext(ctx, query, id, name, description, time.Now(), routingMode, settings.TLSMode, settings.TLSSecretName, settings.DriftPolicy, settings.RetryPolicy).Scan(
+		&project.ID, &project.UserID, &project.Name, &project.Description, &project.RoutingMode, &project.TLSMode, &project.TLSSecretName, &project.DriftPolicy, &project.RetryPolicy, &project.CreatedAt, &project.UpdatedAt,
 	)
 
 	if err != nil {
@@ -198,13 +286,92 @@ func (s *ProjectService) UpdateProject(ctx context.Context, id uuid.UUID, name,
 	return project, nil
 }
 
+// validateRoutingMode 校验路由模式，子域名模式需要配置泛域名
+func (s *ProjectService) validateRoutingMode(routingMode string) error {
+	switch routingMode {
+	case models.RoutingModePath:
+		return nil
+	case models.RoutingModeSubdomain:
+		if s.config.K8s.WildcardDomain == "" {
+			return fmt.Errorf("invalid routing mode: subdomain routing requires k8s.wildcard_domain to be configured")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid routing mode '%s': must be 'path' or 'subdomain'", routingMode)
+	}
+}
+
+// validateTLSMode 校验证书模式，secret模式需要指定证书Secret，cert-manager模式需要配置ClusterIssuer
+func (s *ProjectService) validateTLSMode(tlsMode string, secretName *string) error {
+	switch tlsMode {
+	case models.TLSModeNone:
+		return nil
+	case models.TLSModeSecret:
+		if (secretName == nil || *secretName == "") && s.config.K8s.TLSSecretName == "" {
+			return fmt.Errorf("invalid tls mode: secret mode requires tls_secret_name or k8s.tls_secret_name to be configured")
+		}
+		return nil
+	case models.TLSModeCertManager:
+		if s.config.K8s.CertManagerIssuer == "" {
+			return fmt.Errorf("invalid tls mode: cert-manager mode requires k8s.cert_manager_issuer to be configured")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid tls mode '%s': must be 'none', 'secret' or 'cert-manager'", tlsMode)
+	}
+}
+
+// validateDriftPolicy 校验配置漂移策略
+func validateDriftPolicy(policy string) error {
+	switch policy {
+	case models.DriftPolicyReport, models.DriftPolicyHeal:
+		return nil
+	default:
+		return fmt.Errorf("invalid drift policy '%s': must be 'report' or 'heal'", policy)
+	}
+}
+
+// defaultRetryPolicy 新项目的重试策略，默认不自动重试
+func defaultRetryPolicy() models.RetryPolicy {
+	return models.RetryPolicy{
+		MaxAttempts:       0,
+		BackoffSeconds:    60,
+		RetryableFailures: []string{models.FailureImagePull, models.FailureUnschedulable, models.FailureQuotaExceeded},
+	}
+}
+
+// normalizeRetryPolicy 校验重试策略，未指定的等待时间和失败分类使用默认值
+func normalizeRetryPolicy(policy *models.RetryPolicy) error {
+	if policy.MaxAttempts < 0 || policy.MaxAttempts > 10 {
+		return fmt.Errorf("invalid retry policy: max_attempts must be between 0 and 10")
+	}
+	if policy.BackoffSeconds == 0 {
+		policy.BackoffSeconds = defaultRetryPolicy().BackoffSeconds
+	}
+	if policy.BackoffSeconds < 10 || policy.BackoffSeconds > 3600 {
+		return fmt.Errorf("invalid retry policy: backoff_seconds must be between 10 and 3600")
+	}
+	if len(policy.RetryableFailures) == 0 {
+		policy.RetryableFailures = defaultRetryPolicy().RetryableFailures
+	}
+	for _, code := range policy.RetryableFailures {
+		switch code {
+		case models.FailureQuotaExceeded, models.FailureUnschedulable, models.FailureImagePull, models.FailureConfigError,
+			models.FailureOOMKilled, models.FailureCrashLoop, models.FailureProbeFailed, models.FailureUnknown:
+		default:
+			return fmt.Errorf("invalid retry policy: unknown failure code '%s'", code)
+		}
+	}
+	return nil
+}
+
 // DeleteProject 删除项目
 func (s *ProjectService) DeleteProject(ctx context.Context, id uuid.UUID) error {
 	// 检查项目下是否还有活跃的URL
 	var activeURLCount int
 	countQuery := `
 		SELECT COUNT(*) FROM ephemeral_urls 
-		WHERE project_id = $1 AND status IN ('creating', 'active')
+		WHERE project_id = $1 AND status IN ('creating', 'active', 'degraded')
 	`
 	err := s.db.QueryRowContext(ctx, countQuery, id).Scan(&activeURLCount)
 	if err != nil {
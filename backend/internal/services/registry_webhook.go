@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"url-manager-system/backend/internal/config"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// RegistryWebhookService 镜像仓库webhook服务，镜像推送匹配跟踪规则的新标签时自动更新URL
+type RegistryWebhookService struct {
+	db         *sql.DB
+	urlService *URLService
+	config     *config.Config
+}
+
+// NewRegistryWebhookService 创建镜像仓库webhook服务
+func NewRegistryWebhookService(db *sql.DB, urlService *URLService, cfg *config.Config) *RegistryWebhookService {
+	return &RegistryWebhookService{
+		db:         db,
+		urlService: urlService,
+		config:     cfg,
+	}
+}
+
+// trackedURL 设置了标签跟踪规则的active URL
+type trackedURL struct {
+	id      uuid.UUID
+	image   string
+	pattern string
+}
+
+// HandleNotification 校验令牌并处理一次镜像仓库通知
+// token 为 Authorization 请求头的值，支持 "Bearer <token>" 或直接传令牌。
+func (s *RegistryWebhookService) HandleNotification(ctx context.Context, token string, body []byte) (*models.RegistryWebhookResult, error) {
+	expected := s.config.Security.RegistryWebhookToken
+	if expected == "" {
+		return nil, fmt.Errorf("registry webhook is not enabled")
+	}
+	if !utils.SecureCompare(expected, strings.TrimSpace(strings.TrimPrefix(token, "Bearer "))) {
+		return nil, fmt.Errorf("invalid webhook token")
+	}
+
+	events, err := utils.ParseRegistryWebhook(body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RegistryWebhookResult{Events: len(events), Updated: []uuid.UUID{}}
+	if len(events) == 0 {
+		return result, nil
+	}
+
+	tracked, err := s.listTrackedURLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一通知中同一URL可能匹配多个标签，以最后一个为准
+	targets := make(map[uuid.UUID]string)
+	var order []uuid.UUID
+	for _, event := range events {
+		for _, url := range tracked {
+			if !utils.RegistryEventMatchesImage(event, url.image) || !utils.MatchTagPattern(url.pattern, event.Tag) {
+				continue
+			}
+			if _, exists := targets[url.id]; !exists {
+				order = append(order, url.id)
+			}
+			targets[url.id] = utils.ReplaceImageTag(url.image, event.Tag)
+		}
+	}
+
+	for _, id := range order {
+		image := targets[id]
+		if err := s.rollout(ctx, id, image); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"url_id": id,
+				"image":  image,
+			}).Error("Failed to roll out pushed image")
+			continue
+		}
+		result.Updated = append(result.Updated, id)
+	}
+
+	return result, nil
+}
+
+// rollout 将URL更新到推送的镜像，镜像未变化时跳过
+func (s *RegistryWebhookService) rollout(ctx context.Context, id uuid.UUID, image string) error {
+	url, err := s.urlService.GetEphemeralURL(ctx, id)
+	if err != nil {
+		return err
+	}
+	if url.Image == image {
+		return nil
+	}
+
+	if err := s.urlService.RolloutImage(ctx, url, image, "", "镜像仓库推送"); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"url_id":    id,
+		"old_image": url.Image,
+		"new_image": image,
+	}).Info("URL updated from registry push")
+	return nil
+}
+
+// listTrackedURLs 列出设置了标签跟踪规则的active和degraded URL，degraded的URL可由新镜像修复
+func (s *RegistryWebhookService) listTrackedURLs(ctx context.Context) ([]trackedURL, error) {
+	query := `
+		SELECT id, image, track_tag_pattern
+		FROM ephemeral_urls
+		WHERE status IN ($1, $2) AND track_tag_pattern IS NOT NULL
+	`
+	rows, err := s.db.QueryContext(ctx, query, models.StatusActive, models.StatusDegraded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked URLs: %w", err)
+	}
+	defer rows.Close()
+
+	var urls []trackedURL
+	for rows.Next() {
+		var url trackedURL
+		if err := rows.Scan(&url.id, &url.image, &url.pattern); err != nil {
+			logrus.WithError(err).Error("Failed to scan tracked URL")
+			continue
+		}
+		urls = append(urls, url)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tracked URLs: %w", err)
+	}
+
+	return urls, nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"url-manager-system/backend/internal/config"
+	"url-manager-system/backend/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	retryLockKey       = "retry:lock"
+	retryLockTTL       = 5 * time.Minute // 单轮最多提交50个URL的重试操作，不需要清理任务的长锁
+	retryCheckInterval = 30 * time.Second
+	maxRetryBackoff    = time.Hour
+)
+
+// RetryService 按项目重试策略自动重新部署失败的URL
+// URL失败时由updateURLStatus根据失败分类安排next_retry_at，工作线程到期后提交来源为retry的部署操作。
+type RetryService struct {
+	db         *sql.DB
+	redis      *redis.Client
+	urlService *URLService
+	config     *config.Config
+}
+
+// NewRetryService 创建自动重试服务
+func NewRetryService(db *sql.DB, redis *redis.Client, urlService *URLService, cfg *config.Config) *RetryService {
+	return &RetryService{
+		db:         db,
+		redis:      redis,
+		urlService: urlService,
+		config:     cfg,
+	}
+}
+
+// StartWorker 启动自动重试工作线程
+func (s *RetryService) StartWorker() {
+	logrus.WithField("interval", retryCheckInterval).Info("Starting URL retry worker")
+
+	ticker := time.NewTicker(retryCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.retryDueURLs(context.Background())
+	}
+}
+
+// retryDueURLs 重新部署到达重试时间的失败URL
+func (s *RetryService) retryDueURLs(ctx context.Context) {
+	token, lock, err := acquireLock(ctx, s.redis, retryLockKey, retryLockTTL)
+	if err != nil || !lock {
+		return
+	}
+	defer func() {
+		if err := releaseLock(ctx, s.redis, retryLockKey, token); err != nil {
+			logrus.WithError(err).Error("Failed to release retry lock")
+		}
+	}()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id FROM ephemeral_urls
+		WHERE status = $1 AND next_retry_at <= NOW()
+		ORDER BY next_retry_at
+		LIMIT 50
+	`, models.StatusFailed)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query URLs due for retry")
+		return
+	}
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			logrus.WithError(err).Error("Failed to scan URL due for retry")
+			continue
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		url, err := s.urlService.GetEphemeralURL(ctx, id)
+		if err != nil {
+			logrus.WithError(err).WithField("url_id", id).Warn("Failed to load URL for retry")
+			continue
+		}
+
+		op, err := s.enqueueRetry(ctx, url)
+		if err != nil {
+			logrus.WithError(err).WithField("url_id", id).Error("Failed to enqueue URL retry")
+			continue
+		}
+		if op == nil {
+			continue
+		}
+		logrus.WithFields(logrus.Fields{
+			"url_id":       id,
+			"attempt":      url.RetryCount + 1,
+			"operation_id": op.ID,
+		}).Info("Retrying failed URL deployment")
+	}
+}
+
+// enqueueRetry 把URL恢复为creating并提交部署操作，URL已不在等待重试时返回nil
+func (s *RetryService) enqueueRetry(ctx context.Context, url *models.EphemeralURL) (*models.Operation, error) {
+	attempt := url.RetryCount + 1
+	reason := ""
+	if url.Diagnosis != nil {
+		reason = url.Diagnosis.Code
+	}
+	logEntry := models.LogEntry{
+		Timestamp: time.Now(),
+		Level:     "info",
+		Message:   fmt.Sprintf("开始第%d次自动重试", attempt),
+		Details:   reason,
+	}
+	logsJSON, _ := json.Marshal([]models.LogEntry{logEntry})
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 只处理仍在等待重试的URL，期间手动部署或删除的URL跳过
+	result, err := tx.ExecContext(ctx, `
+		UPDATE ephemeral_urls
+		SET status = $2, error_message = NULL, diagnosis = NULL, next_retry_at = NULL, retry_count = $3,
+			updated_at = NOW(), logs = logs || $4::jsonb
+		WHERE id = $1 AND status = $5 AND next_retry_at IS NOT NULL
+	`, url.ID, models.StatusCreating, attempt, string(logsJSON), models.StatusFailed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update URL: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+
+	op := newOperation(s.config, models.OperationDeploy, url, models.OperationData{
+		"keep_active": false,
+		"retry":       attempt,
+	})
+	op.Source = models.OperationSourceRetry
+	if err := enqueueOperation(ctx, tx, op); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return op, nil
+}
+
+// nextRetryTime 按项目重试策略计算失败URL的下一次重试时间，不重试时返回nil
+// 环境成员由环境部署流程处理，模版URL的资源由YAML生成，都不自动重试。
+func nextRetryTime(url *models.EphemeralURL, diagnosis *models.FailureDiagnosis) *time.Time {
+	if url.Project == nil || url.StackID != nil || url.TemplateID != nil || diagnosis == nil {
+		return nil
+	}
+	policy := url.Project.RetryPolicy
+	if url.RetryCount >= policy.MaxAttempts {
+		return nil
+	}
+	retryable := false
+	for _, code := range policy.RetryableFailures {
+		if code == diagnosis.Code {
+			retryable = true
+			break
+		}
+	}
+	if !retryable {
+		return nil
+	}
+
+	backoff := time.Duration(policy.BackoffSeconds) * time.Second
+	for i := 0; i < url.RetryCount && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	next := time.Now().Add(backoff)
+	return &next
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"url-manager-system/backend/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const revisionColumns = `id, url_id, revision, image, env, replicas, resources, container_config, source, operation_id, rollback_of, created_at`
+
+// ListURLRevisions 列出URL的配置版本，按版本号倒序，每个版本附带与上一版本相比的变化
+func (s *URLService) ListURLRevisions(ctx context.Context, id uuid.UUID) ([]models.URLRevision, error) {
+	if _, err := s.GetEphemeralURL(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+revisionColumns+" FROM url_revisions WHERE url_id = $1 ORDER BY revision", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.URLRevision{}
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		rev.Changes = []models.RevisionChange{}
+		if n := len(revisions); n > 0 {
+			rev.Changes = revisionChanges(&revisions[n-1], rev)
+		}
+		revisions = append(revisions, *rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating revisions: %w", err)
+	}
+
+	for i, j := 0, len(revisions)-1; i < j; i, j = i+1, j-1 {
+		revisions[i], revisions[j] = revisions[j], revisions[i]
+	}
+	return revisions, nil
+}
+
+// RollbackURL 将URL记录恢复为历史版本的配置，并提交回滚操作重新应用Deployment
+// 模版创建的URL由YAML生成资源，不支持回滚。
+func (s *URLService) RollbackURL(ctx context.Context, id uuid.UUID, revision int) (*models.Operation, error) {
+	url, err := s.GetEphemeralURL(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if url.TemplateID != nil {
+		return nil, fmt.Errorf("rollback is not supported for template URLs")
+	}
+	if url.Status != models.StatusActive && url.Status != models.StatusDegraded &&
+		url.Status != models.StatusWaiting && url.Status != models.StatusFailed {
+		return nil, fmt.Errorf("URL cannot be rolled back in current status: %s", url.Status)
+	}
+
+	target, err := scanRevision(s.db.QueryRowContext(ctx,
+		"SELECT "+revisionColumns+" FROM url_revisions WHERE url_id = $1 AND revision = $2", id, revision))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("revision not found")
+		}
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE ephemeral_urls
+		SET image = $2, env = $3, replicas = $4, resources = $5, container_config = $6, updated_at = NOW()
+		WHERE id = $1
+	`, id, target.Image, target.Env, target.Replicas, target.Resources, target.ContainerConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update URL: %w", err)
+	}
+
+	// active状态的URL回滚完成后保持active，与重新部署一致；degraded状态的URL在回滚完成后恢复active
+	keepActive := url.Status == models.StatusActive || url.Status == models.StatusDegraded
+	if !keepActive {
+		if err := setURLStatusTx(ctx, tx, url.ID, models.StatusCreating); err != nil {
+			return nil, err
+		}
+	}
+
+	op := newOperation(s.config, models.OperationRollback, url, models.OperationData{
+		"revision":    revision,
+		"keep_active": keepActive,
+	})
+	if err := enqueueOperation(ctx, tx, op); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"url_id":       url.ID,
+		"revision":     revision,
+		"operation_id": op.ID,
+	}).Info("URL rollback accepted")
+
+	return op, nil
+}
+
+// runRollbackOperation 按URL记录中已恢复的历史配置更新Secret和Deployment
+func (s *URLService) runRollbackOperation(ctx context.Context, op *models.Operation, progress func(string)) (models.OperationData, error) {
+	if s.resourceManager == nil {
+		return nil, permanent(fmt.Errorf("kubernetes resource manager not available"))
+	}
+
+	url, err := s.loadOperationURL(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	if url.Status == models.StatusDeleting || url.Status == models.StatusDeleted {
+		return models.OperationData{"skipped": fmt.Sprintf("URL is %s", url.Status)}, nil
+	}
+
+	since := time.Now()
+	progress("rolling back deployment")
+	if url.K8sSecretName != nil {
+		if err := s.resourceManager.CreateSecret(ctx, url); err != nil {
+			return nil, fmt.Errorf("failed to update secret: %w", err)
+		}
+	}
+	if err := s.resourceManager.CreateOrUpdateDeployment(ctx, url); err != nil {
+		return nil, fmt.Errorf("failed to roll back deployment: %w", err)
+	}
+
+	target := operationInt(op.Payload["revision"])
+	s.recordRevision(ctx, url, op, &target)
+
+	// 回滚本身失败时没有更早的版本可以自动回滚，URL保持degraded
+	status := models.StatusActive
+	if keepActive, _ := op.Payload["keep_active"].(bool); keepActive {
+		go s.watchRollout(url, nil, since)
+	} else {
+		status = models.StatusWaiting
+		if err := s.updateURLStatus(ctx, url.ID, status, ""); err != nil {
+			return nil, fmt.Errorf("failed to update status: %w", err)
+		}
+	}
+
+	return models.OperationData{
+		"rolled_back_to": target,
+		"image":          url.Image,
+		"status":         status,
+	}, nil
+}
+
+// recordRevision 记录URL当前应用到集群的配置，与最新版本相同时不记录
+// 记录失败不影响已完成的Kubernetes变更，只写日志。
+func (s *URLService) recordRevision(ctx context.Context, url *models.EphemeralURL, op *models.Operation, rollbackOf *int) {
+	if url.TemplateID != nil {
+		return
+	}
+
+	current := urlRevision(url)
+	current.Source = op.Type
+	current.OperationID = &op.ID
+	current.RollbackOf = rollbackOf
+
+	latest, err := s.latestRevision(ctx, url.ID)
+	switch {
+	case err == sql.ErrNoRows:
+		current.Revision = 1
+	case err != nil:
+		logrus.WithError(err).WithField("url_id", url.ID).Error("Failed to get latest revision")
+		return
+	default:
+		if len(revisionChanges(latest, current)) == 0 {
+			return
+		}
+		current.Revision = latest.Revision + 1
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO url_revisions (id, url_id, revision, image, env, replicas, resources, container_config, source, operation_id, rollback_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, current.ID, current.URLID, current.Revision, current.Image, current.Env, current.Replicas, current.Resources,
+		current.ContainerConfig, current.Source, current.OperationID, current.RollbackOf)
+	if err != nil {
+		logrus.WithError(err).WithField("url_id", url.ID).Error("Failed to record revision")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"url_id":   url.ID,
+		"revision": current.Revision,
+		"source":   current.Source,
+	}).Info("URL revision recorded")
+}
+
+// latestRevision 获取URL最新的配置版本，没有版本时返回sql.ErrNoRows
+func (s *URLService) latestRevision(ctx context.Context, urlID uuid.UUID) (*models.URLRevision, error) {
+	return scanRevision(s.db.QueryRowContext(ctx,
+		"SELECT "+revisionColumns+" FROM url_revisions WHERE url_id = $1 ORDER BY revision DESC LIMIT 1", urlID))
+}
+
+// urlRevision 按URL记录中的配置构建版本
+func urlRevision(url *models.EphemeralURL) *models.URLRevision {
+	rev := &models.URLRevision{
+		ID:              uuid.New(),
+		URLID:           url.ID,
+		Image:           url.Image,
+		Env:             url.Env,
+		Replicas:        url.Replicas,
+		Resources:       url.Resources,
+		ContainerConfig: url.ContainerConfig,
+	}
+	if rev.Env == nil {
+		rev.Env = models.EnvironmentVars{}
+	}
+	return rev
+}
+
+// scanRevision 扫描一行配置版本记录
+func scanRevision(row rowScanner) (*models.URLRevision, error) {
+	rev := &models.URLRevision{}
+	err := row.Scan(&rev.ID, &rev.URLID, &rev.Revision, &rev.Image, &rev.Env, &rev.Replicas, &rev.Resources,
+		&rev.ContainerConfig, &rev.Source, &rev.OperationID, &rev.RollbackOf, &rev.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// revisionChanges 比较两个版本的配置
+func revisionChanges(from, to *models.URLRevision) []models.RevisionChange {
+	changes := []models.RevisionChange{}
+	add := func(field, a, b string) {
+		if a != b {
+			changes = append(changes, models.RevisionChange{Field: field, From: a, To: b})
+		}
+	}
+
+	add("image", from.Image, to.Image)
+	add("replicas", strconv.Itoa(from.Replicas), strconv.Itoa(to.Replicas))
+	add("env", formatRevisionEnv(from.Env), formatRevisionEnv(to.Env))
+	add("resources.requests.cpu", from.Resources.Requests.CPU, to.Resources.Requests.CPU)
+	add("resources.requests.memory", from.Resources.Requests.Memory, to.Resources.Requests.Memory)
+	add("resources.limits.cpu", from.Resources.Limits.CPU, to.Resources.Limits.CPU)
+	add("resources.limits.memory", from.Resources.Limits.Memory, to.Resources.Limits.Memory)
+	add("container_config", formatRevisionJSON(from.ContainerConfig), formatRevisionJSON(to.ContainerConfig))
+	return changes
+}
+
+// formatRevisionEnv 环境变量按名称排序后格式化
+func formatRevisionEnv(env models.EnvironmentVars) string {
+	parts := make([]string, 0, len(env))
+	for _, e := range env {
+		parts = append(parts, e.Name+"="+e.Value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func formatRevisionJSON(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// operationInt 读取操作payload中的整数，JSON反序列化后数字为float64
+func operationInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
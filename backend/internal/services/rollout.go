@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"url-manager-system/backend/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const rolloutCheckInterval = 10 * time.Second
+
+// watchRollout 监控活跃URL的Deployment更新
+// 新Pod出现崩溃或镜像拉取失败时URL标记为degraded；更新失败或超时后自动回滚到previous，
+// previous为空（首次记录的版本、模版URL或回滚本身）时URL保持degraded等待人工处理。
+// 同一URL开始新的更新后，旧的监控退出。
+func (s *URLService) watchRollout(url *models.EphemeralURL, previous *models.URLRevision, since time.Time) {
+	if s.resourceManager == nil || url.K8sDeploymentName == nil || s.config.Rollout.Timeout <= 0 {
+		return
+	}
+	ctx := context.Background()
+	watchID := uuid.New()
+	s.rolloutWatches.Store(url.ID, watchID)
+	defer s.rolloutWatches.CompareAndDelete(url.ID, watchID)
+
+	logger := logrus.WithFields(logrus.Fields{
+		"url_id":          url.ID,
+		"deployment_name": *url.K8sDeploymentName,
+	})
+	logger.Info("Watching deployment rollout")
+
+	deadline := time.Now().Add(s.config.Rollout.Timeout)
+	ticker := time.NewTicker(rolloutCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if current, _ := s.rolloutWatches.Load(url.ID); current != watchID {
+			logger.Info("Rollout superseded by a newer update")
+			return
+		}
+
+		var status string
+		if err := s.db.QueryRowContext(ctx, "SELECT status FROM ephemeral_urls WHERE id = $1", url.ID).Scan(&status); err != nil {
+			if err == sql.ErrNoRows {
+				return
+			}
+			logger.WithError(err).Warn("Failed to get URL status")
+			continue
+		}
+		if status != models.StatusActive && status != models.StatusDegraded {
+			return
+		}
+
+		rollout, err := s.resourceManager.GetRolloutStatus(ctx, *url.K8sDeploymentName, since, s.config.Rollout.RestartThreshold)
+		switch {
+		case err != nil:
+			logger.WithError(err).Warn("Failed to get rollout status")
+		case rollout.Failed:
+			s.failRollout(ctx, url, previous, rollout.Problem)
+			return
+		case rollout.Complete:
+			if status == models.StatusDegraded {
+				entry := models.LogEntry{Timestamp: time.Now(), Level: "info", Message: "更新已完成，URL恢复正常"}
+				if err := s.setRolloutStatus(ctx, url.ID, models.StatusActive, "", entry); err != nil {
+					logger.WithError(err).Error("Failed to mark URL active")
+				}
+			}
+			logger.Info("Deployment rollout completed")
+			return
+		case rollout.Problem != "" && status == models.StatusActive:
+			entry := models.LogEntry{Timestamp: time.Now(), Level: "warn", Message: "更新出现问题", Details: rollout.Problem}
+			if err := s.setRolloutStatus(ctx, url.ID, models.StatusDegraded, rollout.Problem, entry); err != nil {
+				logger.WithError(err).Error("Failed to mark URL degraded")
+			}
+			logger.WithField("problem", rollout.Problem).Warn("Deployment rollout degraded")
+		}
+
+		if time.Now().After(deadline) {
+			s.failRollout(ctx, url, previous, fmt.Sprintf("rollout not completed within %s", s.config.Rollout.Timeout))
+			return
+		}
+	}
+}
+
+// failRollout 更新失败后将URL记录恢复为previous的配置，并提交回滚操作
+// URL在回滚完成前保持degraded，由回滚操作的监控恢复为active。
+func (s *URLService) failRollout(ctx context.Context, url *models.EphemeralURL, previous *models.URLRevision, reason string) {
+	logger := logrus.WithFields(logrus.Fields{
+		"url_id": url.ID,
+		"reason": reason,
+	})
+
+	if previous == nil {
+		entry := models.LogEntry{Timestamp: time.Now(), Level: "error", Message: "更新失败，没有可回滚的版本", Details: reason}
+		if err := s.setRolloutStatus(ctx, url.ID, models.StatusDegraded, reason, entry); err != nil {
+			logger.WithError(err).Error("Failed to mark URL degraded")
+		}
+		logger.Error("Deployment rollout failed, no revision to roll back to")
+		return
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	entry := models.LogEntry{
+		Timestamp: time.Now(),
+		Level:     "error",
+		Message:   fmt.Sprintf("更新失败，自动回滚到版本 %d", previous.Revision),
+		Details:   reason,
+	}
+	logsJSON, _ := json.Marshal([]models.LogEntry{entry})
+	result, err := tx.ExecContext(ctx, `
+		UPDATE ephemeral_urls
+		SET image = $2, env = $3, replicas = $4, resources = $5, container_config = $6,
+			status = $7, error_message = $8, updated_at = NOW(), logs = logs || $9::jsonb
+		WHERE id = $1 AND status IN ($10, $11)
+	`, url.ID, previous.Image, previous.Env, previous.Replicas, previous.Resources, previous.ContainerConfig,
+		models.StatusDegraded, reason, string(logsJSON), models.StatusActive, models.StatusDegraded)
+	if err != nil {
+		logger.WithError(err).Error("Failed to restore previous revision")
+		return
+	}
+	// URL已被删除或进入其他状态
+	if n, _ := result.RowsAffected(); n == 0 {
+		return
+	}
+
+	op := newOperation(s.config, models.OperationRollback, url, models.OperationData{
+		"revision":    previous.Revision,
+		"keep_active": true,
+		"reason":      reason,
+	})
+	op.Source = models.OperationSourceHealth
+	if err := enqueueOperation(ctx, tx, op); err != nil {
+		logger.WithError(err).Error("Failed to enqueue automatic rollback")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit automatic rollback")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"revision":     previous.Revision,
+		"operation_id": op.ID,
+	}).Warn("Deployment rollout failed, rolling back")
+}
+
+// setRolloutStatus 在active和degraded之间切换URL状态，不重新计算过期时间
+func (s *URLService) setRolloutStatus(ctx context.Context, id uuid.UUID, status, errorMessage string, entry models.LogEntry) error {
+	var errMsg *string
+	if errorMessage != "" {
+		errMsg = &errorMessage
+	}
+	logsJSON, _ := json.Marshal([]models.LogEntry{entry})
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE ephemeral_urls
+		SET status = $2, error_message = $3, updated_at = NOW(), logs = logs || $4::jsonb
+		WHERE id = $1 AND status IN ($5, $6)
+	`, id, status, errMsg, string(logsJSON), models.StatusActive, models.StatusDegraded)
+	return err
+}
+
+// rollbackTarget 更新失败时回滚的版本，即集群中当前运行的最新记录版本
+// URL记录与该版本相同（本次部署没有改变配置）时返回nil。
+func (s *URLService) rollbackTarget(ctx context.Context, url *models.EphemeralURL) *models.URLRevision {
+	if url.TemplateID != nil {
+		return nil
+	}
+
+	latest, err := s.latestRevision(ctx, url.ID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logrus.WithError(err).WithField("url_id", url.ID).Warn("Failed to get latest revision")
+		}
+		return nil
+	}
+	if len(revisionChanges(latest, urlRevision(url))) == 0 {
+		return nil
+	}
+	return latest
+}
+
+// resumeRolloutWatches 服务重启后继续监控处于degraded状态的URL
+// 重启前的更新起点已无法得知，只等待更新完成或超时，超时后URL保持degraded。
+func (s *URLService) resumeRolloutWatches() {
+	if s.resourceManager == nil {
+		return
+	}
+	ctx := context.Background()
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id FROM ephemeral_urls WHERE status = $1 AND k8s_deployment_name IS NOT NULL", models.StatusDegraded)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query degraded URLs")
+		return
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			logrus.WithError(err).Error("Failed to scan degraded URL")
+			continue
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		url, err := s.GetEphemeralURL(ctx, id)
+		if err != nil {
+			logrus.WithError(err).WithField("url_id", id).Warn("Failed to load degraded URL")
+			continue
+		}
+		go s.watchRollout(url, nil, time.Now())
+	}
+}
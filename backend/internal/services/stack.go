@@ -0,0 +1,679 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"url-manager-system/backend/internal/config"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	stackCleanupLockKey = "url_manager:stack_cleanup_lock"
+	stackReadyTimeout   = 10 * time.Minute
+	stackPollInterval   = 10 * time.Second
+)
+
+// stackColumns stacks表查询列，与scanStack保持一致
+const stackColumns = `id, project_id, user_id, name, description, services, env, status, ttl_seconds,
+		       error_message, started_at, expire_at, created_at, updated_at`
+
+// StackService 环境服务
+type StackService struct {
+	db             *sql.DB
+	redis          *redis.Client
+	urlService     *URLService
+	cleanupService *CleanupService
+	config         *config.Config
+}
+
+// stackMember 部署计划中的单个服务
+type stackMember struct {
+	spec models.StackServiceSpec
+	url  *models.EphemeralURL
+	yaml string // 基于模版的服务渲染后的YAML，基于镜像的服务为空
+}
+
+// rowScanner 兼容sql.Row和sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// NewStackService 创建环境服务
+func NewStackService(db *sql.DB, redis *redis.Client, urlService *URLService, cleanupService *CleanupService, cfg *config.Config) *StackService {
+	return &StackService{
+		db:             db,
+		redis:          redis,
+		urlService:     urlService,
+		cleanupService: cleanupService,
+		config:         cfg,
+	}
+}
+
+// CreateStack 创建环境
+// 所有服务的URL记录在同一事务中写入；Kubernetes资源随后按依赖顺序异步部署，任一服务失败时回滚整个环境。
+func (s *StackService) CreateStack(ctx context.Context, projectID, userID uuid.UUID, isAdmin bool, req *models.CreateStackRequest) (*models.Stack, error) {
+	levels, err := utils.PlanStackServices(req.Services)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stack: %w", err)
+	}
+	if req.TTLSeconds > s.config.Security.MaxTTLSeconds {
+		return nil, fmt.Errorf("invalid stack: TTL cannot exceed %d seconds", s.config.Security.MaxTTLSeconds)
+	}
+	for _, env := range req.Env {
+		if !utils.ValidateEnvironmentVariableName(env.Name) {
+			return nil, fmt.Errorf("invalid stack: invalid environment variable name: %s", env.Name)
+		}
+	}
+
+	project, err := s.urlService.getProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stacks WHERE project_id = $1 AND name = $2", projectID, req.Name).Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check stack name: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("stack '%s' already exists in this project", req.Name)
+	}
+
+	// 按声明顺序构建服务，确定各服务的K8s Service名称
+	members := make(map[string]*stackMember, len(req.Services))
+	paths := make(map[string]string)
+	for _, spec := range req.Services {
+		member, err := s.buildMember(ctx, project, spec, req.TTLSeconds, userID, isAdmin)
+		if err != nil {
+			return nil, err
+		}
+		if other, exists := paths[member.url.Path]; exists {
+			return nil, fmt.Errorf("invalid stack: services %q and %q use the same path '%s'", other, spec.Name, member.url.Path)
+		}
+		paths[member.url.Path] = spec.Name
+		members[spec.Name] = member
+	}
+
+	// 注入共享环境变量和服务地址，服务自身的环境变量优先
+	wiring := models.EnvironmentVars{{Name: "STACK_NAME", Value: req.Name}}
+	for _, spec := range req.Services {
+		wiring = append(wiring, models.EnvironmentVar{
+			Name:  utils.StackServiceHostEnvName(spec.Name),
+			Value: *members[spec.Name].url.K8sServiceName,
+		})
+	}
+	for _, spec := range req.Services {
+		member := members[spec.Name]
+		env := utils.MergeEnvironmentVars(req.Env, wiring, spec.Env)
+		if member.spec.TemplateID != nil {
+			member.yaml, err = utils.InjectWorkloadEnv(member.yaml, env)
+			if err != nil {
+				return nil, fmt.Errorf("invalid stack: service %q: %w", spec.Name, err)
+			}
+			member.url.Env = utils.MergeEnvironmentVars(member.url.Env, env)
+		} else {
+			member.url.Env = env
+			if member.url.K8sSecretName == nil {
+				member.url.K8sSecretName = stringPtr(fmt.Sprintf("secret-ephemeral-%s", member.url.ID.String()[:8]))
+			}
+		}
+	}
+
+	now := time.Now()
+	stack := &models.Stack{
+		ID:          uuid.New(),
+		ProjectID:   projectID,
+		UserID:      &userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Services:    req.Services,
+		Env:         req.Env,
+		Status:      models.StackStatusDeploying,
+		TTLSeconds:  req.TTLSeconds,
+		ExpireAt:    now.Add(365 * 24 * time.Hour), // 所有服务就绪后才开始计算过期时间
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	k8sAvailable := s.urlService.resourceManager != nil && s.urlService.ingressManager != nil
+	if !k8sAvailable {
+		stack.Status = models.StackStatusDraft
+	}
+
+	// 开始事务处理
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.insertStackRecord(ctx, tx, stack); err != nil {
+		return nil, fmt.Errorf("failed to insert stack record: %w", err)
+	}
+
+	for _, spec := range req.Services {
+		member := members[spec.Name]
+		member.url.StackID = &stack.ID
+		member.url.UserID = userID
+		if err := s.insertMemberRecord(ctx, tx, member.url); err != nil {
+			return nil, fmt.Errorf("failed to insert URL record for service %q: %w", spec.Name, err)
+		}
+		if member.url.TemplateID != nil {
+			if err := s.urlService.recordTemplateUsage(ctx, tx, *member.url.TemplateID); err != nil {
+				return nil, fmt.Errorf("failed to record template usage: %w", err)
+			}
+		}
+	}
+
+	// 提交事务
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	plan := make([][]*stackMember, len(levels))
+	for i, level := range levels {
+		for _, spec := range level {
+			plan[i] = append(plan[i], members[spec.Name])
+		}
+	}
+
+	if k8sAvailable {
+		// 异步按依赖顺序部署
+		go s.deployStack(stack, project, plan)
+	} else {
+		for _, member := range members {
+			s.urlService.updateURLStatus(ctx, member.url.ID, "draft", "Kubernetes not available")
+		}
+		logrus.Warn("Kubernetes not available, stack created in draft mode")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"stack_id":   stack.ID,
+		"project_id": projectID,
+		"services":   len(req.Services),
+		"levels":     len(levels),
+	}).Info("Stack created successfully")
+
+	return s.GetStack(ctx, stack.ID)
+}
+
+// buildMember 构建单个服务的URL记录，基于模版的服务同时渲染YAML
+// 基于模版的服务只能使用userID可见的模版。
+func (s *StackService) buildMember(ctx context.Context, project *models.Project, spec models.StackServiceSpec, ttlSeconds int, userID uuid.UUID, isAdmin bool) (*stackMember, error) {
+	member := &stackMember{spec: spec}
+
+	if spec.TemplateID != nil {
+		path, err := s.urlService.resolveTemplateURLPath(ctx, project, spec.Path, *spec.TemplateID)
+		if err != nil {
+			return nil, fmt.Errorf("service %q: %w", spec.Name, err)
+		}
+		url, processedYAML, err := s.urlService.renderTemplateURL(ctx, project, path, *spec.TemplateID, ttlSeconds, userID, isAdmin)
+		if err != nil {
+			return nil, fmt.Errorf("service %q: %w", spec.Name, err)
+		}
+		member.url = url
+		member.yaml = processedYAML
+	} else {
+		req := &models.CreateEphemeralURLRequest{
+			Image:           spec.Image,
+			Env:             spec.Env,
+			TTLSeconds:      ttlSeconds,
+			Replicas:        spec.Replicas,
+			Resources:       spec.Resources,
+			ContainerConfig: spec.ContainerConfig,
+		}
+		if err := s.urlService.validateImageURLRequest(req); err != nil {
+			return nil, fmt.Errorf("invalid stack: service %q: %w", spec.Name, err)
+		}
+		if spec.Path != "" {
+			return nil, fmt.Errorf("invalid stack: service %q: path is only supported for template services", spec.Name)
+		}
+		path, err := s.urlService.generateUniquePath(ctx, project.ID, spec.Image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate unique path: %w", err)
+		}
+		member.url = s.urlService.buildImageURL(project.ID, path, req)
+		s.urlService.applyTLS(ctx, project, member.url)
+	}
+
+	member.url.StackService = stringPtr(spec.Name)
+	member.url.ExpireAt = time.Now().Add(365 * 24 * time.Hour) // 由环境统一控制过期时间
+	member.url.Project = project
+	return member, nil
+}
+
+// insertStackRecord 插入环境记录
+func (s *StackService) insertStackRecord(ctx context.Context, tx *sql.Tx, stack *models.Stack) error {
+	query := `
+		INSERT INTO stacks (
+			id, project_id, user_id, name, description, services, env, status, ttl_seconds,
+			expire_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		stack.ID, stack.ProjectID, stack.UserID, stack.Name, stack.Description, stack.Services, stack.Env,
+		stack.Status, stack.TTLSeconds, stack.ExpireAt, stack.CreatedAt, stack.UpdatedAt,
+	)
+
+	return err
+}
+
+// insertMemberRecord 插入环境成员URL记录
+func (s *StackService) insertMemberRecord(ctx context.Context, tx *sql.Tx, url *models.EphemeralURL) error {
+	query := `
+		INSERT INTO ephemeral_urls (
+			id, project_id, user_id, template_id, path, image, env, replicas, resources, container_config,
+			status, ttl_seconds, k8s_deployment_name, k8s_service_name, k8s_secret_name,
+			stack_id, stack_service, expire_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		url.ID, url.ProjectID, url.UserID, url.TemplateID, url.Path, url.Image, url.Env, url.Replicas,
+		url.Resources, url.ContainerConfig, url.Status, url.TTLSeconds,
+		url.K8sDeploymentName, url.K8sServiceName, url.K8sSecretName,
+		url.StackID, url.StackService, url.ExpireAt, url.CreatedAt, url.UpdatedAt,
+	)
+
+	return err
+}
+
+// deployStack 按依赖层级部署环境，每层全部就绪后再部署下一层
+func (s *StackService) deployStack(stack *models.Stack, project *models.Project, plan [][]*stackMember) {
+	ctx := context.Background()
+
+	for _, level := range plan {
+		// 环境在部署过程中被删除时停止部署
+		if status, err := s.storedStatus(ctx, stack.ID); err != nil || status != models.StackStatusDeploying {
+			logrus.WithField("stack_id", stack.ID).Warn("Stack is no longer deploying, stopping deployment")
+			return
+		}
+
+		for _, member := range level {
+			if err := s.deployMember(ctx, project, member); err != nil {
+				s.rollbackStack(ctx, stack, plan, member, err.Error())
+				return
+			}
+		}
+
+		if failed, err := s.waitForLevel(ctx, level); err != nil {
+			s.rollbackStack(ctx, stack, plan, failed, err.Error())
+			return
+		}
+	}
+
+	// 所有服务就绪，开始计算环境的共享TTL
+	now := time.Now()
+	expireAt := now.Add(time.Duration(stack.TTLSeconds) * time.Second)
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE stacks SET status = $2, error_message = NULL, started_at = $3, expire_at = $4, updated_at = $3
+		WHERE id = $1 AND status = $5
+	`, stack.ID, models.StackStatusActive, now, expireAt, models.StackStatusDeploying)
+	if err != nil {
+		logrus.WithError(err).WithField("stack_id", stack.ID).Error("Failed to mark stack as active")
+		return
+	}
+	if _, err := s.db.ExecContext(ctx, "UPDATE ephemeral_urls SET expire_at = $1 WHERE stack_id = $2", expireAt, stack.ID); err != nil {
+		logrus.WithError(err).WithField("stack_id", stack.ID).Warn("Failed to align member expiration")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"stack_id":  stack.ID,
+		"services":  len(stack.Services),
+		"expire_at": expireAt,
+	}).Info("Stack is now active and TTL countdown started")
+}
+
+// deployMember 创建单个服务的Kubernetes资源
+func (s *StackService) deployMember(ctx context.Context, project *models.Project, member *stackMember) error {
+	if member.spec.TemplateID != nil {
+		if err := s.urlService.createKubernetesResourcesFromYAML(ctx, member.url, member.yaml); err != nil {
+			return fmt.Errorf("service %s: %w", member.spec.Name, err)
+		}
+	} else if err := s.urlService.createKubernetesResources(ctx, member.url, project); err != nil {
+		return fmt.Errorf("service %s: %w", member.spec.Name, err)
+	}
+	s.urlService.updateURLStatus(ctx, member.url.ID, models.StatusWaiting, "")
+	return nil
+}
+
+// waitForLevel 等待同一层的所有服务就绪，返回首个失败的服务
+func (s *StackService) waitForLevel(ctx context.Context, level []*stackMember) (*stackMember, error) {
+	pending := make([]*stackMember, len(level))
+	copy(pending, level)
+
+	timeout := time.After(stackReadyTimeout)
+	ticker := time.NewTicker(stackPollInterval)
+	defer ticker.Stop()
+
+	for len(pending) > 0 {
+		select {
+		case <-timeout:
+			return pending[0], fmt.Errorf("service %s did not become ready within %s", pending[0].spec.Name, stackReadyTimeout)
+		case <-ticker.C:
+			var waiting []*stackMember
+			for _, member := range pending {
+				ready, err := s.urlService.resourceManager.CheckDeploymentReady(ctx, *member.url.K8sDeploymentName)
+				if err != nil {
+					if strings.Contains(err.Error(), "not found") {
+						return member, fmt.Errorf("service %s: deployment not found: %v", member.spec.Name, err)
+					}
+					logrus.WithError(err).WithField("url_id", member.url.ID).Error("Failed to check deployment status")
+					waiting = append(waiting, member)
+					continue
+				}
+				if !ready {
+					waiting = append(waiting, member)
+					continue
+				}
+				s.urlService.updateURLStatus(ctx, member.url.ID, models.StatusActive, "")
+			}
+			pending = waiting
+		}
+	}
+
+	return nil, nil
+}
+
+// rollbackStack 部署失败时删除环境中所有服务的资源，并将环境标记为失败
+func (s *StackService) rollbackStack(ctx context.Context, stack *models.Stack, plan [][]*stackMember, failed *stackMember, reason string) {
+	logrus.WithFields(logrus.Fields{
+		"stack_id": stack.ID,
+		"service":  failed.spec.Name,
+		"reason":   reason,
+	}).Error("Stack deployment failed, rolling back")
+
+	for i := len(plan) - 1; i >= 0; i-- {
+		for _, member := range plan[i] {
+			if err := s.cleanupService.deleteKubernetesResources(ctx, member.url); err != nil {
+				logrus.WithError(err).WithField("url_id", member.url.ID).Warn("Failed to delete stack member resources during rollback")
+			}
+
+			message := reason
+			if member != failed {
+				message = fmt.Sprintf("stack rollback: %s", reason)
+			}
+			s.urlService.updateURLStatus(ctx, member.url.ID, models.StatusFailed, message)
+		}
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE stacks SET status = $2, error_message = $3, updated_at = NOW()
+		WHERE id = $1 AND status = $4
+	`, stack.ID, models.StackStatusFailed, reason, models.StackStatusDeploying)
+	if err != nil {
+		logrus.WithError(err).WithField("stack_id", stack.ID).Error("Failed to mark stack as failed")
+	}
+}
+
+// GetStack 获取环境及其成员URL，状态由成员状态推导
+func (s *StackService) GetStack(ctx context.Context, id uuid.UUID) (*models.Stack, error) {
+	query := `SELECT ` + stackColumns + ` FROM stacks WHERE id = $1`
+
+	stack, err := scanStack(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("stack not found")
+		}
+		return nil, fmt.Errorf("failed to get stack: %w", err)
+	}
+
+	if err := s.loadMembers(ctx, stack); err != nil {
+		return nil, err
+	}
+
+	return stack, nil
+}
+
+// ListStacks 列出项目的环境
+func (s *StackService) ListStacks(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]models.Stack, int, error) {
+	var total int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stacks WHERE project_id = $1", projectID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count stacks: %w", err)
+	}
+
+	query := `SELECT ` + stackColumns + ` FROM stacks WHERE project_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+	rows, err := s.db.QueryContext(ctx, query, projectID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list stacks: %w", err)
+	}
+	defer rows.Close()
+
+	stacks := []models.Stack{}
+	for rows.Next() {
+		stack, err := scanStack(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan stack")
+			continue
+		}
+		stacks = append(stacks, *stack)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating stacks: %w", err)
+	}
+
+	for i := range stacks {
+		if err := s.loadMembers(ctx, &stacks[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return stacks, total, nil
+}
+
+// scanStack 扫描stacks表的一行
+func scanStack(row rowScanner) (*models.Stack, error) {
+	stack := &models.Stack{}
+	err := row.Scan(
+		&stack.ID, &stack.ProjectID, &stack.UserID, &stack.Name, &stack.Description, &stack.Services, &stack.Env,
+		&stack.Status, &stack.TTLSeconds, &stack.ErrorMessage, &stack.StartedAt, &stack.ExpireAt,
+		&stack.CreatedAt, &stack.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return stack, nil
+}
+
+// loadMembers 加载环境的成员URL（按服务声明顺序），并推导环境状态
+func (s *StackService) loadMembers(ctx context.Context, stack *models.Stack) error {
+	query := `
+		SELECT id, project_id, template_id, path, image, env, replicas, resources,
+		       status, ttl_seconds, k8s_deployment_name, k8s_service_name, k8s_secret_name,
+		       error_message, stack_id, stack_service, started_at, expire_at, created_at, updated_at
+		FROM ephemeral_urls
+		WHERE stack_id = $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, stack.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list stack members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []models.EphemeralURL
+	for rows.Next() {
+		var url models.EphemeralURL
+		err := rows.Scan(
+			&url.ID, &url.ProjectID, &url.TemplateID, &url.Path, &url.Image, &url.Env, &url.Replicas, &url.Resources,
+			&url.Status, &url.TTLSeconds, &url.K8sDeploymentName, &url.K8sServiceName, &url.K8sSecretName,
+			&url.ErrorMessage, &url.StackID, &url.StackService, &url.StartedAt, &url.ExpireAt, &url.CreatedAt, &url.UpdatedAt,
+		)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan stack member")
+			continue
+		}
+		members = append(members, url)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating stack members: %w", err)
+	}
+
+	order := make(map[string]int, len(stack.Services))
+	for i, spec := range stack.Services {
+		order[spec.Name] = i
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return order[memberName(&members[i])] < order[memberName(&members[j])]
+	})
+
+	statuses := make([]string, len(members))
+	for i := range members {
+		statuses[i] = members[i].Status
+	}
+	stack.Members = members
+	stack.Status = utils.AggregateStackStatus(stack.Status, statuses)
+
+	return nil
+}
+
+// memberName 成员URL在环境中的服务名
+func memberName(url *models.EphemeralURL) string {
+	if url.StackService == nil {
+		return ""
+	}
+	return *url.StackService
+}
+
+// storedStatus 获取环境记录中保存的状态
+func (s *StackService) storedStatus(ctx context.Context, id uuid.UUID) (string, error) {
+	var status string
+	err := s.db.QueryRowContext(ctx, "SELECT status FROM stacks WHERE id = $1", id).Scan(&status)
+	return status, err
+}
+
+// DeleteStack 删除环境：按依赖的逆序删除所有服务的资源，再删除环境及成员记录
+func (s *StackService) DeleteStack(ctx context.Context, id uuid.UUID) error {
+	stack, err := s.GetStack(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.urlService.getProject(ctx, stack.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, "UPDATE stacks SET status = $2, updated_at = NOW() WHERE id = $1", id, models.StackStatusDeleting)
+	if err != nil {
+		return fmt.Errorf("failed to update stack status: %w", err)
+	}
+
+	// 依赖方先于被依赖方删除
+	members := make(map[string]*models.EphemeralURL, len(stack.Members))
+	for i := range stack.Members {
+		members[memberName(&stack.Members[i])] = &stack.Members[i]
+	}
+	levels, err := utils.PlanStackServices(stack.Services)
+	if err != nil {
+		return fmt.Errorf("failed to plan stack teardown: %w", err)
+	}
+	for i := len(levels) - 1; i >= 0; i-- {
+		for _, spec := range levels[i] {
+			url, exists := members[spec.Name]
+			if !exists {
+				continue
+			}
+			url.Project = project
+			if err := s.cleanupService.deleteKubernetesResources(ctx, url); err != nil {
+				logrus.WithError(err).WithField("url_id", url.ID).Warn("Failed to delete stack member resources")
+			}
+		}
+	}
+
+	// 成员URL记录随环境级联删除
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM stacks WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete stack: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"stack_id": id,
+		"services": len(stack.Members),
+	}).Info("Stack deleted successfully")
+	return nil
+}
+
+// StartWorker 启动环境过期清理工作线程
+func (s *StackService) StartWorker() {
+	logrus.Info("Starting stack cleanup worker")
+
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runCleanup()
+		}
+	}
+}
+
+// runCleanup 删除已过期、部署失败超过1小时或长时间未完成部署的环境
+func (s *StackService) runCleanup() {
+	ctx := context.Background()
+
+	lock, err := s.redis.SetNX(ctx, stackCleanupLockKey, "locked", lockTTL).Result()
+	if err != nil || !lock {
+		logrus.Debug("Another instance is running stack cleanup, skipping")
+		return
+	}
+	defer func() {
+		if err := s.redis.Del(ctx, stackCleanupLockKey).Err(); err != nil {
+			logrus.WithError(err).Error("Failed to release stack cleanup lock")
+		}
+	}()
+
+	query := `
+		SELECT id FROM stacks
+		WHERE (status = 'active' AND expire_at <= NOW())
+		   OR (status = 'failed' AND updated_at <= NOW() - INTERVAL '1 hour')
+		   OR (status = 'deploying' AND created_at <= NOW() - INTERVAL '2 hours')
+		   OR (status = 'deleting' AND updated_at <= NOW() - INTERVAL '30 minutes')
+		ORDER BY expire_at ASC
+		LIMIT 50
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query expired stacks")
+		return
+	}
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			logrus.WithError(err).Error("Failed to scan expired stack")
+			continue
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if err := s.DeleteStack(ctx, id); err != nil {
+			logrus.WithError(err).WithField("stack_id", id).Error("Failed to cleanup stack")
+		}
+	}
+
+	if len(ids) > 0 {
+		logrus.WithField("count", len(ids)).Info("Cleaned up expired stacks")
+	}
+}
@@ -3,10 +3,12 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/k8s"
 	"url-manager-system/backend/internal/utils"
 
 	"github.com/google/uuid"
@@ -16,13 +18,15 @@ import (
 
 // TemplateService 模版服务
 type TemplateService struct {
-	db *sqlx.DB
+	db              *sqlx.DB
+	resourceManager *k8s.ResourceManager // 用于服务端dry-run预览，可能为nil
 }
 
 // NewTemplateService 创建模版服务
-func NewTemplateService(db *sqlx.DB) *TemplateService {
+func NewTemplateService(db *sqlx.DB, resourceManager *k8s.ResourceManager) *TemplateService {
 	return &TemplateService{
-		db: db,
+		db:              db,
+		resourceManager: resourceManager,
 	}
 }
 
@@ -39,16 +43,27 @@ func (s *TemplateService) CreateTemplate(ctx context.Context, userID uuid.UUID,
 		return nil, fmt.Errorf("template name '%s' already exists", req.Name)
 	}
 
-	// 验证YAML格式
-	if err := utils.ValidateYAML(req.YamlSpec); err != nil {
-		return nil, fmt.Errorf("invalid YAML format: %w", err)
+	templateType, yamlSpec, kustomizeBundle, parsedSpec, err := prepareTemplateContent(req.TemplateType, req.YamlSpec, req.KustomizeBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.ValidateTemplateParameters(req.Parameters); err != nil {
+		return nil, fmt.Errorf("invalid template parameters: %w", err)
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = models.TemplateVisibilityPrivate
+	}
+	projectID, err := s.resolveTemplateProject(ctx, visibility, req.ProjectID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 解析YAML到结构化数据
-	parsedSpec, err := utils.ParseYAMLToTemplateSpec(req.YamlSpec)
+	tags, err := utils.NormalizeTemplateTags(req.Tags)
 	if err != nil {
-		logrus.WithError(err).Warn("Failed to parse YAML spec, using empty parsed spec")
-		parsedSpec = &models.TemplateSpec{}
+		return nil, fmt.Errorf("invalid template tags: %w", err)
 	}
 
 	// 如果请求中提供了解析后的规格，使用它
@@ -58,29 +73,108 @@ func (s *TemplateService) CreateTemplate(ctx context.Context, userID uuid.UUID,
 
 	// 创建模版记录
 	template := &models.AppTemplate{
-		ID:          uuid.New(),
-		UserID:      userID,
-		Name:        req.Name,
-		Description: req.Description,
-		YamlSpec:    req.YamlSpec,
-		ParsedSpec:  *parsedSpec,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:              uuid.New(),
+		UserID:          userID,
+		Name:            req.Name,
+		Description:     req.Description,
+		TemplateType:    templateType,
+		YamlSpec:        yamlSpec,
+		KustomizeBundle: kustomizeBundle,
+		ParsedSpec:      *parsedSpec,
+		Parameters:      templateParameters(templateType, yamlSpec, kustomizeBundle, req.Parameters),
+		Visibility:      visibility,
+		ProjectID:       projectID,
+		Category:        strings.TrimSpace(req.Category),
+		Tags:            tags,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
+	if err := s.insertTemplate(ctx, s.db, template); err != nil {
+		return nil, err
+	}
+
+	logrus.WithField("template_id", template.ID).Info("Template created successfully")
+	return template, nil
+}
+
+// insertTemplate 插入模版记录
+func (s *TemplateService) insertTemplate(ctx context.Context, db sqlx.ExtContext, template *models.AppTemplate) error {
 	query := `
-		INSERT INTO app_templates (id, user_id, name, description, yaml_spec, parsed_spec, created_at, updated_at)
-		VALUES (:id, :user_id, :name, :description, :yaml_spec, :parsed_spec, :created_at, :updated_at)
+		INSERT INTO app_templates (id, user_id, name, description, template_type, yaml_spec, kustomize_bundle, parsed_spec,
+			parameters, managed, catalog_source, catalog_revision, visibility, project_id, category, tags, featured,
+			created_at, updated_at)
+		VALUES (:id, :user_id, :name, :description, :template_type, :yaml_spec, :kustomize_bundle, :parsed_spec,
+			:parameters, :managed, :catalog_source, :catalog_revision, :visibility, :project_id, :category, :tags, :featured,
+			:created_at, :updated_at)
 	`
 
-	_, err = s.db.NamedExecContext(ctx, query, template)
+	_, err := sqlx.NamedExecContext(ctx, db, query, template)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to create template")
-		return nil, fmt.Errorf("failed to create template: %w", err)
+		return fmt.Errorf("failed to create template: %w", err)
 	}
+	return nil
+}
 
-	logrus.WithField("template_id", template.ID).Info("Template created successfully")
-	return template, nil
+// prepareTemplateContent 验证模版内容并解析结构化数据
+// 返回规范化后的模版类型、YAML、Kustomize模版包以及解析后的规格。
+func prepareTemplateContent(templateType, yamlSpec string, bundle *models.KustomizeBundle) (string, string, *models.KustomizeBundle, *models.TemplateSpec, error) {
+	if templateType == "" {
+		templateType = models.TemplateTypeYAML
+	}
+
+	var parsedSpec *models.TemplateSpec
+	switch templateType {
+	case models.TemplateTypeKustomize:
+		// 构建Kustomize模版包以验证其有效性，并从构建结果中解析结构化数据
+		if bundle == nil {
+			return "", "", nil, nil, fmt.Errorf("kustomize bundle is required for kustomize templates")
+		}
+		built, err := utils.BuildKustomizeBundle(bundle, DefaultPreviewVariables())
+		if err != nil {
+			return "", "", nil, nil, fmt.Errorf("invalid kustomize bundle: %w", err)
+		}
+		if yamlSpec == "" {
+			yamlSpec = bundle.Base["kustomization.yaml"]
+		}
+		parsedSpec, err = utils.ParseYAMLToTemplateSpec(built)
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to parse kustomize output, using empty parsed spec")
+			parsedSpec = &models.TemplateSpec{}
+		}
+	default:
+		if strings.TrimSpace(yamlSpec) == "" {
+			return "", "", nil, nil, fmt.Errorf("invalid YAML format: YAML specification cannot be empty")
+		}
+
+		// 验证YAML格式
+		if err := utils.ValidateYAML(yamlSpec); err != nil {
+			return "", "", nil, nil, fmt.Errorf("invalid YAML format: %w", err)
+		}
+
+		// 解析YAML到结构化数据
+		var err error
+		parsedSpec, err = utils.ParseYAMLToTemplateSpec(yamlSpec)
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to parse YAML spec, using empty parsed spec")
+			parsedSpec = &models.TemplateSpec{}
+		}
+		bundle = nil
+	}
+
+	return templateType, yamlSpec, bundle, parsedSpec, nil
+}
+
+// templateParameters 根据模版内容生成参数定义，仅保留模版中实际引用的参数
+func templateParameters(templateType, yamlSpec string, bundle *models.KustomizeBundle, declared []models.TemplateParameter) models.TemplateParameters {
+	var placeholders []string
+	if templateType == models.TemplateTypeKustomize {
+		placeholders = utils.KustomizeBundleVariables(bundle)
+	} else {
+		placeholders = utils.ExtractPlaceholders(yamlSpec)
+	}
+	return utils.BuildTemplateParameters(declared, placeholders)
 }
 
 // GetTemplate 获取单个模版
@@ -100,52 +194,110 @@ func (s *TemplateService) GetTemplate(ctx context.Context, id uuid.UUID) (*model
 	return &template, nil
 }
 
-// ListTemplates 列出模版（支持用户过滤）
-func (s *TemplateService) ListTemplates(ctx context.Context, userID *uuid.UUID, isAdmin bool, limit, offset int) ([]models.AppTemplate, int, error) {
-	var countQuery, listQuery string
+// GetTemplateForUser 获取用户可见的单个模版，不可见的模版视为不存在
+func (s *TemplateService) GetTemplateForUser(ctx context.Context, id uuid.UUID, userID uuid.UUID, isAdmin bool) (*models.AppTemplate, error) {
+	template, err := s.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	visible, err := s.canViewTemplate(ctx, template, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, fmt.Errorf("template not found")
+	}
+
+	return template, nil
+}
+
+// ListTemplates 列出用户可见的模版，支持搜索和过滤
+func (s *TemplateService) ListTemplates(ctx context.Context, userID uuid.UUID, isAdmin bool, filter *models.TemplateListFilter, limit, offset int) ([]models.AppTemplate, int, error) {
+	var conditions []string
 	var args []interface{}
+	argIndex := 1
 
-	if isAdmin {
-		// 管理员可以查看所有模版
-		countQuery = "SELECT COUNT(*) FROM app_templates"
-		listQuery = `
-			SELECT * FROM app_templates 
-			ORDER BY created_at DESC 
-			LIMIT $1 OFFSET $2
-		`
-		args = []interface{}{limit, offset}
-	} else {
-		// 普通用户只能查看自己的模版
-		countQuery = "SELECT COUNT(*) FROM app_templates WHERE user_id = $1"
-		listQuery = `
-			SELECT * FROM app_templates 
-			WHERE user_id = $1
-			ORDER BY created_at DESC 
-			LIMIT $2 OFFSET $3
-		`
-		args = []interface{}{*userID, limit, offset}
+	if !isAdmin {
+		// 普通用户只能查看组织内公开、自己创建或所属项目共享的模版
+		conditions = append(conditions, templateVisibilityCondition(argIndex))
+		args = append(args, userID)
+		argIndex++
 	}
 
-	// 获取总数
-	var total int
-	if isAdmin {
-		err := s.db.GetContext(ctx, &total, countQuery)
-		if err != nil {
-			logrus.WithError(err).Error("Failed to count templates")
-			return nil, 0, fmt.Errorf("failed to count templates: %w", err)
+	if filter != nil {
+		if filter.Search != "" {
+			conditions = append(conditions, fmt.Sprintf("(name ILIKE $%d OR description ILIKE $%d OR category ILIKE $%d)", argIndex, argIndex, argIndex))
+			args = append(args, "%"+likeEscaper.Replace(filter.Search)+"%")
+			argIndex++
 		}
-	} else {
-		err := s.db.GetContext(ctx, &total, countQuery, *userID)
-		if err != nil {
-			logrus.WithError(err).Error("Failed to count templates")
-			return nil, 0, fmt.Errorf("failed to count templates: %w", err)
+		if filter.Category != "" {
+			conditions = append(conditions, fmt.Sprintf("category = $%d", argIndex))
+			args = append(args, filter.Category)
+			argIndex++
+		}
+		if len(filter.Tags) > 0 {
+			tags, err := json.Marshal(filter.Tags)
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid tags filter: %w", err)
+			}
+			conditions = append(conditions, fmt.Sprintf("tags @> $%d::jsonb", argIndex))
+			args = append(args, string(tags))
+			argIndex++
+		}
+		if filter.Visibility != "" {
+			conditions = append(conditions, fmt.Sprintf("visibility = $%d", argIndex))
+			args = append(args, filter.Visibility)
+			argIndex++
+		}
+		if filter.TemplateType != "" {
+			conditions = append(conditions, fmt.Sprintf("template_type = $%d", argIndex))
+			args = append(args, filter.TemplateType)
+			argIndex++
+		}
+		if filter.ProjectID != nil {
+			conditions = append(conditions, fmt.Sprintf("project_id = $%d", argIndex))
+			args = append(args, *filter.ProjectID)
+			argIndex++
 		}
+		if filter.Featured != nil {
+			conditions = append(conditions, fmt.Sprintf("featured = $%d", argIndex))
+			args = append(args, *filter.Featured)
+			argIndex++
+		}
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// 获取总数
+	var total int
+	countQuery := "SELECT COUNT(*) FROM app_templates " + whereClause
+	if err := s.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		logrus.WithError(err).Error("Failed to count templates")
+		return nil, 0, fmt.Errorf("failed to count templates: %w", err)
 	}
 
 	// 获取模版列表
+	orderBy := "featured DESC, created_at DESC"
+	if filter != nil {
+		switch filter.Sort {
+		case "popular":
+			orderBy = "usage_count DESC, created_at DESC"
+		case "name":
+			orderBy = "name ASC"
+		case "recent":
+			orderBy = "created_at DESC"
+		}
+	}
+
+	listQuery := fmt.Sprintf("SELECT * FROM app_templates %s ORDER BY %s LIMIT $%d OFFSET $%d", whereClause, orderBy, argIndex, argIndex+1)
+	args = append(args, limit, offset)
+
 	var templates []models.AppTemplate
-	err := s.db.SelectContext(ctx, &templates, listQuery, args...)
-	if err != nil {
+	if err := s.db.SelectContext(ctx, &templates, listQuery, args...); err != nil {
 		logrus.WithError(err).Error("Failed to list templates")
 		return nil, 0, fmt.Errorf("failed to list templates: %w", err)
 	}
@@ -153,33 +305,99 @@ func (s *TemplateService) ListTemplates(ctx context.Context, userID *uuid.UUID,
 	return templates, total, nil
 }
 
+// likeEscaper 转义LIKE模式中的通配符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// templateVisibilityCondition 普通用户可见模版的过滤条件，$argIndex 为当前用户ID
+func templateVisibilityCondition(argIndex int) string {
+	return fmt.Sprintf(`(visibility = 'organization' OR user_id = $%d OR
+		(visibility = 'project' AND project_id IN (SELECT id FROM projects WHERE user_id = $%d)))`, argIndex, argIndex)
+}
+
+// canViewTemplate 判断用户是否可以查看模版，规则与 templateVisibilityCondition 一致
+func (s *TemplateService) canViewTemplate(ctx context.Context, template *models.AppTemplate, userID uuid.UUID, isAdmin bool) (bool, error) {
+	if isAdmin || template.Visibility == models.TemplateVisibilityOrganization || template.UserID == userID {
+		return true, nil
+	}
+	if template.Visibility != models.TemplateVisibilityProject || template.ProjectID == nil {
+		return false, nil
+	}
+
+	var count int
+	err := s.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM projects WHERE id = $1 AND user_id = $2", *template.ProjectID, userID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to check template visibility")
+		return false, fmt.Errorf("failed to check template visibility: %w", err)
+	}
+	return count > 0, nil
+}
+
+// resolveTemplateProject 校验可见范围对应的项目，非project可见范围时不关联项目
+func (s *TemplateService) resolveTemplateProject(ctx context.Context, visibility string, projectID *uuid.UUID) (*uuid.UUID, error) {
+	if visibility != models.TemplateVisibilityProject {
+		return nil, nil
+	}
+	if projectID == nil {
+		return nil, fmt.Errorf("invalid visibility: project_id is required for project visibility")
+	}
+
+	var count int
+	if err := s.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM projects WHERE id = $1", *projectID); err != nil {
+		logrus.WithError(err).Error("Failed to check template project")
+		return nil, fmt.Errorf("failed to check template project: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("invalid visibility: project not found")
+	}
+	return projectID, nil
+}
+
 // UpdateTemplate 更新模版
 func (s *TemplateService) UpdateTemplate(ctx context.Context, id uuid.UUID, userID uuid.UUID, isAdmin bool, req *models.UpdateAppTemplateRequest) (*models.AppTemplate, error) {
 	// 检查模版是否存在
-	existingTemplate, err := s.GetTemplate(ctx, id)
+	existingTemplate, err := s.GetTemplateForUser(ctx, id, userID, isAdmin)
 	if err != nil {
 		return nil, err
 	}
+	if existingTemplate.Managed {
+		return nil, fmt.Errorf("template is managed by catalog and read-only")
+	}
 
-	// 所有登录用户都可以更新所有模版
-
-	// 如果名称发生变化，检查新名称在同一用户下的唯一性
-	if req.Name != existingTemplate.Name {
-		var count int
-		var checkQuery string
-		var checkArgs []interface{}
+	// 所有可见该模版的用户都可以更新模版内容，可见范围只能由创建者或管理员修改
+	visibility := existingTemplate.Visibility
+	projectID := existingTemplate.ProjectID
+	if req.Visibility != "" || req.ProjectID != nil {
+		if !isAdmin && existingTemplate.UserID != userID {
+			return nil, fmt.Errorf("access denied: template belongs to another user")
+		}
+		if req.Visibility != "" {
+			visibility = req.Visibility
+		}
+		if req.ProjectID != nil {
+			projectID = req.ProjectID
+		}
+		projectID, err = s.resolveTemplateProject(ctx, visibility, projectID)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-		if isAdmin {
-			// 管理员可以使用全局唯一的模版名
-			checkQuery = "SELECT COUNT(*) FROM app_templates WHERE name = $1 AND id != $2"
-			checkArgs = []interface{}{req.Name, id}
-		} else {
-			// 普通用户只需要在自己的模版中唯一
-			checkQuery = "SELECT COUNT(*) FROM app_templates WHERE name = $1 AND user_id = $2 AND id != $3"
-			checkArgs = []interface{}{req.Name, userID, id}
+	category := existingTemplate.Category
+	if req.Category != nil {
+		category = strings.TrimSpace(*req.Category)
+	}
+	tags := existingTemplate.Tags
+	if req.Tags != nil {
+		tags, err = utils.NormalizeTemplateTags(req.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template tags: %w", err)
 		}
+	}
 
-		err := s.db.GetContext(ctx, &count, checkQuery, checkArgs...)
+	// 如果名称发生变化，检查新名称的唯一性（模版名称全局唯一）
+	if req.Name != existingTemplate.Name {
+		var count int
+		err := s.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM app_templates WHERE name = $1 AND id != $2", req.Name, id)
 		if err != nil {
 			logrus.WithError(err).Error("Failed to check template name uniqueness")
 			return nil, fmt.Errorf("failed to check template name uniqueness: %w", err)
@@ -191,17 +409,44 @@ func (s *TemplateService) UpdateTemplate(ctx context.Context, id uuid.UUID, user
 
 	var yamlSpec string
 	var parsedSpec *models.TemplateSpec
+	kustomizeBundle := existingTemplate.KustomizeBundle
 
-	// 检查是否提供了解析后的规格（新方式）
-	if req.ParsedSpec != nil && req.ParsedSpec.Image != "" {
-		// 使用解析后的规格重新生成YAML
-		generatedYAML, err := utils.GenerateYAMLFromTemplateSpec(req.ParsedSpec)
+	if existingTemplate.TemplateType == models.TemplateTypeKustomize {
+		// Kustomize模版：更新base与补丁后重新构建并解析
+		yamlSpec = existingTemplate.YamlSpec
+		parsedSpec = &existingTemplate.ParsedSpec
+		if req.KustomizeBundle != nil {
+			built, err := utils.BuildKustomizeBundle(req.KustomizeBundle, DefaultPreviewVariables())
+			if err != nil {
+				return nil, fmt.Errorf("invalid kustomize bundle: %w", err)
+			}
+			kustomizeBundle = req.KustomizeBundle
+			yamlSpec = req.KustomizeBundle.Base["kustomization.yaml"]
+			if parsed, err := utils.ParseYAMLToTemplateSpec(built); err == nil {
+				parsedSpec = parsed
+			} else {
+				logrus.WithError(err).Warn("Failed to parse kustomize output, using existing parsed spec")
+			}
+		}
+	} else if req.ParsedSpec != nil && req.ParsedSpec.Image != "" {
+		// 将结构化规格合并回原始YAML，保留其他资源和未建模的字段
+		baseYAML := existingTemplate.YamlSpec
+		if req.YamlSpec != "" {
+			baseYAML = req.YamlSpec
+		}
+		generatedYAML, err := utils.GenerateYAMLFromTemplateSpec(req.ParsedSpec, baseYAML)
 		if err != nil {
 			logrus.WithError(err).Error("Failed to generate YAML from parsed spec")
 			return nil, fmt.Errorf("failed to generate YAML from parsed spec: %w", err)
 		}
 		yamlSpec = generatedYAML
-		parsedSpec = req.ParsedSpec
+
+		// 以合并后的YAML为准重新解析，ExtraResources等只读字段随之更新
+		if parsed, err := utils.ParseYAMLToTemplateSpec(generatedYAML); err == nil {
+			parsedSpec = parsed
+		} else {
+			parsedSpec = req.ParsedSpec
+		}
 	} else if req.YamlSpec != "" {
 		// 验证YAML格式
 		if err := utils.ValidateYAML(req.YamlSpec); err != nil {
@@ -223,14 +468,26 @@ func (s *TemplateService) UpdateTemplate(ctx context.Context, id uuid.UUID, user
 		parsedSpec = &existingTemplate.ParsedSpec
 	}
 
+	// 参数定义：未提供时沿用原有定义，并随模版内容重新计算
+	declared := []models.TemplateParameter(existingTemplate.Parameters)
+	if req.Parameters != nil {
+		if err := utils.ValidateTemplateParameters(req.Parameters); err != nil {
+			return nil, fmt.Errorf("invalid template parameters: %w", err)
+		}
+		declared = req.Parameters
+	}
+	parameters := templateParameters(existingTemplate.TemplateType, yamlSpec, kustomizeBundle, declared)
+
 	// 更新模版
 	query := `
 		UPDATE app_templates
-		SET name = $1, description = $2, yaml_spec = $3, parsed_spec = $4, updated_at = $5
-		WHERE id = $6
+		SET name = $1, description = $2, yaml_spec = $3, kustomize_bundle = $4, parsed_spec = $5, parameters = $6,
+			visibility = $7, project_id = $8, category = $9, tags = $10, updated_at = $11
+		WHERE id = $12
 	`
 
-	_, err = s.db.ExecContext(ctx, query, req.Name, req.Description, yamlSpec, parsedSpec, time.Now(), id)
+	_, err = s.db.ExecContext(ctx, query, req.Name, req.Description, yamlSpec, kustomizeBundle, parsedSpec, parameters,
+		visibility, projectID, category, tags, time.Now(), id)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to update template")
 		return nil, fmt.Errorf("failed to update template: %w", err)
@@ -243,12 +500,15 @@ func (s *TemplateService) UpdateTemplate(ctx context.Context, id uuid.UUID, user
 // DeleteTemplate 删除模版
 func (s *TemplateService) DeleteTemplate(ctx context.Context, id uuid.UUID, userID uuid.UUID, isAdmin bool) error {
 	// 检查模版是否存在
-	_, err := s.GetTemplate(ctx, id)
+	template, err := s.GetTemplateForUser(ctx, id, userID, isAdmin)
 	if err != nil {
 		return err
 	}
+	if template.Managed {
+		return fmt.Errorf("template is managed by catalog and read-only")
+	}
 
-	// 所有登录用户都可以删除所有模版
+	// 所有可见该模版的用户都可以删除模版
 
 	// 检查模版是否被URL使用
 	var urlCount int
@@ -282,13 +542,25 @@ func (s *TemplateService) DeleteTemplate(ctx context.Context, id uuid.UUID, user
 	return nil
 }
 
-// ProcessTemplate 处理模版，替换占位符
-func (s *TemplateService) ProcessTemplate(ctx context.Context, templateID uuid.UUID, variables map[string]string) (string, error) {
-	template, err := s.GetTemplate(ctx, templateID)
+// ProcessTemplate 处理用户可见的模版，替换占位符
+func (s *TemplateService) ProcessTemplate(ctx context.Context, templateID uuid.UUID, userID uuid.UUID, isAdmin bool, variables map[string]string) (string, error) {
+	template, err := s.GetTemplateForUser(ctx, templateID, userID, isAdmin)
 	if err != nil {
 		return "", err
 	}
 
+	return renderTemplate(template, variables)
+}
+
+// renderTemplate 按变量渲染已加载的模版，调用方负责校验模版可见性
+func renderTemplate(template *models.AppTemplate, variables map[string]string) (string, error) {
+	variables = applyParameterDefaults(template.Parameters, variables)
+
+	// Kustomize模版：在进程内构建base与补丁
+	if template.TemplateType == models.TemplateTypeKustomize {
+		return utils.BuildKustomizeBundle(template.KustomizeBundle, variables)
+	}
+
 	yamlSpec := template.YamlSpec
 
 	// 替换占位符
@@ -300,6 +572,40 @@ func (s *TemplateService) ProcessTemplate(ctx context.Context, templateID uuid.U
 	return yamlSpec, nil
 }
 
+// DryRunTemplate 渲染模版并以服务端dry-run方式提交到集群，返回渲染结果和逐文档的校验结果
+func (s *TemplateService) DryRunTemplate(ctx context.Context, templateID uuid.UUID, userID uuid.UUID, isAdmin bool, variables map[string]string) (string, *models.TemplateDryRunResult, error) {
+	if s.resourceManager == nil {
+		return "", nil, fmt.Errorf("kubernetes resource manager not available")
+	}
+
+	processedYAML, err := s.ProcessTemplate(ctx, templateID, userID, isAdmin, variables)
+	if err != nil {
+		return "", nil, err
+	}
+
+	result, err := s.resourceManager.DryRunResourcesFromYAML(ctx, processedYAML)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to dry-run template")
+		return "", nil, fmt.Errorf("failed to dry-run template: %w", err)
+	}
+
+	return processedYAML, result, nil
+}
+
+// applyParameterDefaults 为未提供的模版参数填充默认值，不修改传入的变量
+func applyParameterDefaults(parameters models.TemplateParameters, variables map[string]string) map[string]string {
+	merged := make(map[string]string, len(variables)+len(parameters))
+	for key, value := range variables {
+		merged[key] = value
+	}
+	for _, parameter := range parameters {
+		if _, ok := merged[parameter.Name]; !ok && parameter.Default != "" {
+			merged[parameter.Name] = parameter.Default
+		}
+	}
+	return merged
+}
+
 // validateYamlSpec 验证YAML规范基本格式
 func (s *TemplateService) validateYamlSpec(yamlSpec string) error {
 	if strings.TrimSpace(yamlSpec) == "" {
@@ -319,41 +625,311 @@ func (s *TemplateService) validateYamlSpec(yamlSpec string) error {
 	return nil
 }
 
-// GetTemplateVariables 获取模版中的占位符变量
-func (s *TemplateService) GetTemplateVariables(ctx context.Context, templateID uuid.UUID) ([]string, error) {
-	template, err := s.GetTemplate(ctx, templateID)
+// GetTemplateVariables 获取用户可见模版中的占位符变量
+func (s *TemplateService) GetTemplateVariables(ctx context.Context, templateID uuid.UUID, userID uuid.UUID, isAdmin bool) ([]string, error) {
+	template, err := s.GetTemplateForUser(ctx, templateID, userID, isAdmin)
 	if err != nil {
 		return nil, err
 	}
 
-	var variables []string
-	yamlSpec := template.YamlSpec
+	if template.TemplateType == models.TemplateTypeKustomize {
+		return utils.KustomizeBundleVariables(template.KustomizeBundle), nil
+	}
 
 	// 简单的占位符解析（查找 ${VARIABLE_NAME} 模式）
-	variableMap := make(map[string]bool)
+	return utils.ExtractPlaceholders(template.YamlSpec), nil
+}
+
+// DefaultPreviewVariables 预览模版时使用的默认示例变量
+func DefaultPreviewVariables() map[string]string {
+	return map[string]string{
+		"PATH":            "example-path",
+		"HOST":            "example.com",
+		"TLS_SECRET_NAME": "example-tls",
+		"SERVICE_NAME":    "example-service",
+		"DEPLOYMENT_NAME": "example-deployment",
+		"PROJECT_NAME":    "example-project",
+		"UUID":            "abc12345",
+	}
+}
 
-	start := 0
-	for {
-		startIdx := strings.Index(yamlSpec[start:], "${")
-		if startIdx == -1 {
-			break
+// ExportTemplates 将用户可见的模版导出为模版包，ids为空时导出全部可见模版
+func (s *TemplateService) ExportTemplates(ctx context.Context, userID uuid.UUID, isAdmin bool, ids []uuid.UUID) ([]byte, error) {
+	var templates []models.AppTemplate
+	var err error
+	if len(ids) == 0 {
+		err = s.db.SelectContext(ctx, &templates, "SELECT * FROM app_templates ORDER BY name")
+	} else {
+		var query string
+		var args []interface{}
+		query, args, err = sqlx.In("SELECT * FROM app_templates WHERE id IN (?) ORDER BY name", ids)
+		if err == nil {
+			err = s.db.SelectContext(ctx, &templates, s.db.Rebind(query), args...)
 		}
-		startIdx += start
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to load templates for export")
+		return nil, fmt.Errorf("failed to load templates for export: %w", err)
+	}
+
+	if len(ids) > 0 && len(templates) != len(ids) {
+		return nil, fmt.Errorf("template not found")
+	}
 
-		endIdx := strings.Index(yamlSpec[startIdx:], "}")
-		if endIdx == -1 {
-			break
+	entries := make([]models.TemplateBundleEntry, 0, len(templates))
+	for _, template := range templates {
+		visible, err := s.canViewTemplate(ctx, &template, userID, isAdmin)
+		if err != nil {
+			return nil, err
+		}
+		if !visible {
+			if len(ids) > 0 {
+				return nil, fmt.Errorf("template not found")
+			}
+			continue
 		}
-		endIdx += startIdx
+		entries = append(entries, bundleEntryFromTemplate(&template))
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no templates to export")
+	}
 
-		variable := yamlSpec[startIdx+2 : endIdx]
-		if variable != "" && !variableMap[variable] {
-			variables = append(variables, variable)
-			variableMap[variable] = true
+	return utils.EncodeTemplateBundle(entries)
+}
+
+// ImportTemplates 导入模版包
+// 同名模版默认跳过，overwrite为true时覆盖其内容；由模版目录管理的模版始终不会被覆盖。
+func (s *TemplateService) ImportTemplates(ctx context.Context, userID uuid.UUID, bundle *models.TemplateBundle, overwrite bool) (*models.TemplateImportResult, error) {
+	result := &models.TemplateImportResult{
+		Created: []string{},
+		Updated: []string{},
+		Skipped: []string{},
+		Errors:  []string{},
+	}
+
+	for i := range bundle.Templates {
+		entry := &bundle.Templates[i]
+
+		existing, err := s.getTemplateByName(ctx, entry.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.Managed {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: template is managed by catalog and read-only", entry.Name))
+			continue
+		}
+		if existing != nil && !overwrite {
+			result.Skipped = append(result.Skipped, entry.Name)
+			continue
+		}
+
+		template, err := templateFromBundleEntry(entry)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", entry.Name, err))
+			continue
+		}
+
+		if existing != nil {
+			template.ID = existing.ID
+			if err := s.updateTemplateContent(ctx, s.db, template); err != nil {
+				return nil, err
+			}
+			result.Updated = append(result.Updated, entry.Name)
+			continue
+		}
+
+		template.ID = uuid.New()
+		template.UserID = userID
+		template.CreatedAt = time.Now()
+		template.UpdatedAt = template.CreatedAt
+		if err := s.insertTemplate(ctx, s.db, template); err != nil {
+			return nil, err
+		}
+		result.Created = append(result.Created, entry.Name)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"created": len(result.Created),
+		"updated": len(result.Updated),
+		"skipped": len(result.Skipped),
+		"errors":  len(result.Errors),
+	}).Info("Templates imported")
+
+	return result, nil
+}
+
+// SyncCatalogTemplate 将模版目录中的模版写入数据库，返回 created、updated 或 unchanged
+func (s *TemplateService) SyncCatalogTemplate(ctx context.Context, ownerID uuid.UUID, entry *models.TemplateBundleEntry, source, revision string) (string, error) {
+	existing, err := s.getTemplateByName(ctx, entry.Name)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil && !existing.Managed {
+		return "", fmt.Errorf("template name '%s' is already used by an unmanaged template", entry.Name)
+	}
+
+	template, err := templateFromBundleEntry(entry)
+	if err != nil {
+		return "", err
+	}
+	template.Managed = true
+	template.CatalogSource = &source
+	template.CatalogRevision = &revision
+
+	if existing == nil {
+		template.ID = uuid.New()
+		template.UserID = ownerID
+		template.CreatedAt = time.Now()
+		template.UpdatedAt = template.CreatedAt
+		if err := s.insertTemplate(ctx, s.db, template); err != nil {
+			return "", err
 		}
+		return "created", nil
+	}
 
-		start = endIdx + 1
+	template.ID = existing.ID
+	unchanged := sameTemplateContent(existing, template)
+	if unchanged && existing.CatalogSource != nil && *existing.CatalogSource == source {
+		return "unchanged", nil
+	}
+	if err := s.updateTemplateContent(ctx, s.db, template); err != nil {
+		return "", err
 	}
+	if unchanged {
+		return "unchanged", nil
+	}
+	return "updated", nil
+}
+
+// SetTemplateFeatured 设置模版推荐标记
+func (s *TemplateService) SetTemplateFeatured(ctx context.Context, id uuid.UUID, featured bool) (*models.AppTemplate, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE app_templates SET featured = $1, updated_at = $2 WHERE id = $3", featured, time.Now(), id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to set template featured flag")
+		return nil, fmt.Errorf("failed to set template featured flag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("template not found")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"template_id": id,
+		"featured":    featured,
+	}).Info("Template featured flag updated")
+	return s.GetTemplate(ctx, id)
+}
+
+// ListCatalogTemplates 列出由模版目录管理的模版
+func (s *TemplateService) ListCatalogTemplates(ctx context.Context) ([]models.AppTemplate, error) {
+	var templates []models.AppTemplate
+	err := s.db.SelectContext(ctx, &templates, "SELECT * FROM app_templates WHERE managed = TRUE ORDER BY name")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list catalog templates")
+		return nil, fmt.Errorf("failed to list catalog templates: %w", err)
+	}
+	return templates, nil
+}
+
+// RemoveCatalogTemplate 删除已从模版目录中移除的模版，仍被URL使用时保留
+func (s *TemplateService) RemoveCatalogTemplate(ctx context.Context, id uuid.UUID) error {
+	var urlCount int
+	err := s.db.GetContext(ctx, &urlCount, "SELECT COUNT(*) FROM ephemeral_urls WHERE template_id = $1", id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to check template usage")
+		return fmt.Errorf("failed to check template usage: %w", err)
+	}
+	if urlCount > 0 {
+		return fmt.Errorf("template is being used by %d URLs, cannot delete", urlCount)
+	}
+
+	_, err = s.db.ExecContext(ctx, "DELETE FROM app_templates WHERE id = $1 AND managed = TRUE", id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to delete catalog template")
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	return nil
+}
+
+// getTemplateByName 按名称查找模版，不存在时返回nil
+func (s *TemplateService) getTemplateByName(ctx context.Context, name string) (*models.AppTemplate, error) {
+	var template models.AppTemplate
+	err := s.db.GetContext(ctx, &template, "SELECT * FROM app_templates WHERE name = $1", name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logrus.WithError(err).Error("Failed to get template by name")
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	return &template, nil
+}
+
+// updateTemplateContent 覆盖模版内容及目录信息
+func (s *TemplateService) updateTemplateContent(ctx context.Context, db sqlx.ExtContext, template *models.AppTemplate) error {
+	query := `
+		UPDATE app_templates
+		SET description = $1, template_type = $2, yaml_spec = $3, kustomize_bundle = $4, parsed_spec = $5, parameters = $6,
+			category = $7, tags = $8, managed = $9, catalog_source = $10, catalog_revision = $11, updated_at = $12
+		WHERE id = $13
+	`
+
+	_, err := db.ExecContext(ctx, query, template.Description, template.TemplateType, template.YamlSpec, template.KustomizeBundle,
+		template.ParsedSpec, template.Parameters, template.Category, template.Tags, template.Managed, template.CatalogSource,
+		template.CatalogRevision, time.Now(), template.ID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update template")
+		return fmt.Errorf("failed to update template: %w", err)
+	}
+	return nil
+}
+
+// templateFromBundleEntry 根据模版包条目构建模版内容
+func templateFromBundleEntry(entry *models.TemplateBundleEntry) (*models.AppTemplate, error) {
+	templateType, yamlSpec, kustomizeBundle, parsedSpec, err := prepareTemplateContent(entry.TemplateType, entry.YamlSpec, entry.KustomizeBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AppTemplate{
+		Name:            entry.Name,
+		Description:     entry.Description,
+		TemplateType:    templateType,
+		YamlSpec:        yamlSpec,
+		KustomizeBundle: kustomizeBundle,
+		ParsedSpec:      *parsedSpec,
+		Parameters:      templateParameters(templateType, yamlSpec, kustomizeBundle, entry.Parameters),
+		Visibility:      models.TemplateVisibilityOrganization,
+		Category:        entry.Category,
+		Tags:            entry.Tags,
+	}, nil
+}
+
+// bundleEntryFromTemplate 将模版转换为模版包条目
+func bundleEntryFromTemplate(template *models.AppTemplate) models.TemplateBundleEntry {
+	entry := models.TemplateBundleEntry{
+		Name:         template.Name,
+		Description:  template.Description,
+		TemplateType: template.TemplateType,
+		Parameters:   template.Parameters,
+		Category:     template.Category,
+		Tags:         template.Tags,
+	}
+	if template.TemplateType == models.TemplateTypeKustomize {
+		entry.KustomizeBundle = template.KustomizeBundle
+	} else {
+		entry.YamlSpec = template.YamlSpec
+	}
+	return entry
+}
 
-	return variables, nil
+// sameTemplateContent 比较两个模版的内容是否一致
+func sameTemplateContent(a, b *models.AppTemplate) bool {
+	left, _ := json.Marshal(bundleEntryFromTemplate(a))
+	right, _ := json.Marshal(bundleEntryFromTemplate(b))
+	return string(left) == string(right)
 }
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"url-manager-system/backend/internal/db/models"
+
+	"github.com/google/uuid"
+)
+
+func TestCanViewTemplate(t *testing.T) {
+	owner := uuid.New()
+	other := uuid.New()
+	s := &TemplateService{}
+
+	tests := []struct {
+		name       string
+		visibility string
+		userID     uuid.UUID
+		isAdmin    bool
+		want       bool
+	}{
+		{"private template used by owner", models.TemplateVisibilityPrivate, owner, false, true},
+		{"private template used by non-owner", models.TemplateVisibilityPrivate, other, false, false},
+		{"private template used by admin", models.TemplateVisibilityPrivate, other, true, true},
+		{"organization template used by non-owner", models.TemplateVisibilityOrganization, other, false, true},
+		{"project template without project", models.TemplateVisibilityProject, other, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &models.AppTemplate{ID: uuid.New(), UserID: owner, Visibility: tt.visibility}
+			got, err := s.canViewTemplate(context.Background(), template, tt.userID, tt.isAdmin)
+			if err != nil {
+				t.Fatalf("canViewTemplate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("canViewTemplate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
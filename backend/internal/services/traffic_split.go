@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/k8s"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// trafficSplitColumns traffic_splits表查询列，与scanTrafficSplit保持一致
+const trafficSplitColumns = `id, project_id, primary_url_id, canary_url_id, canary_weight, status, reverted_at, user_id, created_at, updated_at`
+
+// TrafficSplitService 流量切分服务
+type TrafficSplitService struct {
+	db             *sql.DB
+	urlService     *URLService
+	ingressManager *k8s.IngressManager
+}
+
+// NewTrafficSplitService 创建流量切分服务
+func NewTrafficSplitService(db *sql.DB, urlService *URLService, ingressManager *k8s.IngressManager) *TrafficSplitService {
+	return &TrafficSplitService{
+		db:             db,
+		urlService:     urlService,
+		ingressManager: ingressManager,
+	}
+}
+
+// CreateSplit 将主URL路由上的部分流量转发给金丝雀URL
+func (s *TrafficSplitService) CreateSplit(ctx context.Context, projectID, userID uuid.UUID, req *models.CreateTrafficSplitRequest) (*models.TrafficSplit, error) {
+	if req.PrimaryURLID == req.CanaryURLID {
+		return nil, fmt.Errorf("invalid traffic split: primary and canary must be different URLs")
+	}
+
+	project, err := s.urlService.getProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	primary, err := s.resolveURL(ctx, projectID, req.PrimaryURLID)
+	if err != nil {
+		return nil, err
+	}
+	canary, err := s.resolveURL(ctx, projectID, req.CanaryURLID)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing int
+	err = s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM traffic_splits WHERE primary_url_id = $1 AND status = $2",
+		primary.ID, models.TrafficSplitStatusActive).Scan(&existing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check traffic split: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("traffic split for URL '%s' already exists", primary.Path)
+	}
+
+	if err := s.route(ctx, project, primary, canary, *req.CanaryWeight); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	split := &models.TrafficSplit{
+		ID:           uuid.New(),
+		ProjectID:    projectID,
+		PrimaryURLID: primary.ID,
+		CanaryURLID:  canary.ID,
+		CanaryWeight: *req.CanaryWeight,
+		Status:       models.TrafficSplitStatusActive,
+		UserID:       &userID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	query := `
+		INSERT INTO traffic_splits (id, project_id, primary_url_id, canary_url_id, canary_weight, status, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = s.db.ExecContext(ctx, query,
+		split.ID, split.ProjectID, split.PrimaryURLID, split.CanaryURLID, split.CanaryWeight, split.Status, split.UserID, split.CreatedAt, split.UpdatedAt)
+	if err != nil {
+		// 路由已切分但记录未保存，恢复路由避免留下无法管理的切分
+		if s.ingressManager != nil {
+			if rerr := s.ingressManager.RemoveSplit(ctx, project, primary); rerr != nil {
+				logrus.WithError(rerr).WithField("url_id", primary.ID).Error("Failed to revert traffic split")
+			}
+		}
+		if strings.Contains(err.Error(), "idx_traffic_splits_active_primary") {
+			return nil, fmt.Errorf("traffic split for URL '%s' already exists", primary.Path)
+		}
+		return nil, fmt.Errorf("failed to create traffic split: %w", err)
+	}
+	split.PrimaryWeight = 100 - split.CanaryWeight
+
+	logrus.WithFields(logrus.Fields{
+		"split_id":      split.ID,
+		"project_id":    projectID,
+		"primary":       primary.ID,
+		"canary":        canary.ID,
+		"canary_weight": split.CanaryWeight,
+	}).Info("Traffic split created")
+
+	return split, nil
+}
+
+// GetSplit 获取流量切分
+func (s *TrafficSplitService) GetSplit(ctx context.Context, id uuid.UUID) (*models.TrafficSplit, error) {
+	query := `SELECT ` + trafficSplitColumns + ` FROM traffic_splits WHERE id = $1`
+	split, err := scanTrafficSplit(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("traffic split not found")
+		}
+		return nil, fmt.Errorf("failed to get traffic split: %w", err)
+	}
+	return split, nil
+}
+
+// ListSplits 列出项目的流量切分，包括已自动恢复的记录
+func (s *TrafficSplitService) ListSplits(ctx context.Context, projectID uuid.UUID) ([]models.TrafficSplit, error) {
+	if _, err := s.urlService.getProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + trafficSplitColumns + ` FROM traffic_splits WHERE project_id = $1 ORDER BY created_at DESC`
+	rows, err := s.db.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list traffic splits: %w", err)
+	}
+	defer rows.Close()
+
+	splits := []models.TrafficSplit{}
+	for rows.Next() {
+		split, err := scanTrafficSplit(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan traffic split")
+			continue
+		}
+		splits = append(splits, *split)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating traffic splits: %w", err)
+	}
+
+	return splits, nil
+}
+
+// UpdateSplit 调整流量切分的权重
+func (s *TrafficSplitService) UpdateSplit(ctx context.Context, id uuid.UUID, req *models.UpdateTrafficSplitRequest) (*models.TrafficSplit, error) {
+	split, err := s.GetSplit(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if split.Status != models.TrafficSplitStatusActive {
+		return nil, fmt.Errorf("invalid traffic split: split has been reverted")
+	}
+
+	project, err := s.urlService.getProject(ctx, split.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	primary, err := s.resolveURL(ctx, split.ProjectID, split.PrimaryURLID)
+	if err != nil {
+		return nil, err
+	}
+	canary, err := s.resolveURL(ctx, split.ProjectID, split.CanaryURLID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.route(ctx, project, primary, canary, *req.CanaryWeight); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.ExecContext(ctx, "UPDATE traffic_splits SET canary_weight = $2, updated_at = NOW() WHERE id = $1", id, *req.CanaryWeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update traffic split: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"split_id": id,
+		"from":     split.CanaryWeight,
+		"to":       *req.CanaryWeight,
+	}).Info("Traffic split weight adjusted")
+
+	return s.GetSplit(ctx, id)
+}
+
+// DeleteSplit 删除流量切分，生效中的切分先恢复主URL的路由
+func (s *TrafficSplitService) DeleteSplit(ctx context.Context, id uuid.UUID) error {
+	split, err := s.GetSplit(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if split.Status == models.TrafficSplitStatusActive && s.ingressManager != nil {
+		project, err := s.urlService.getProject(ctx, split.ProjectID)
+		if err != nil {
+			return err
+		}
+		primary, err := s.urlService.GetEphemeralURL(ctx, split.PrimaryURLID)
+		if err != nil {
+			return err
+		}
+		if primary.K8sServiceName != nil {
+			if err := s.ingressManager.RemoveSplit(ctx, project, primary); err != nil {
+				return fmt.Errorf("failed to revert traffic split: %w", err)
+			}
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM traffic_splits WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete traffic split: %w", err)
+	}
+
+	logrus.WithField("split_id", id).Info("Traffic split deleted")
+	return nil
+}
+
+// resolveURL 校验参与切分的URL：必须属于同一项目、已部署且有Service
+func (s *TrafficSplitService) resolveURL(ctx context.Context, projectID, urlID uuid.UUID) (*models.EphemeralURL, error) {
+	url, err := s.urlService.GetEphemeralURL(ctx, urlID)
+	if err != nil {
+		return nil, err
+	}
+	if url.ProjectID != projectID {
+		return nil, fmt.Errorf("URL not found")
+	}
+	if (url.Status != models.StatusWaiting && url.Status != models.StatusActive && url.Status != models.StatusDegraded) || url.K8sServiceName == nil {
+		return nil, fmt.Errorf("invalid traffic split: URL %s is %s and cannot receive traffic", url.Path, url.Status)
+	}
+	return url, nil
+}
+
+// route 按权重切分主URL路由的流量
+func (s *TrafficSplitService) route(ctx context.Context, project *models.Project, primary, canary *models.EphemeralURL, canaryWeight int) error {
+	if s.ingressManager == nil {
+		logrus.WithField("url_id", primary.ID).Warn("Kubernetes not available, traffic split saved without routing")
+		return nil
+	}
+	if err := s.ingressManager.SetSplit(ctx, project, primary, canary, canaryWeight); err != nil {
+		return fmt.Errorf("failed to split traffic: %w", err)
+	}
+	return nil
+}
+
+// scanTrafficSplit 扫描一行流量切分记录
+func scanTrafficSplit(row rowScanner) (*models.TrafficSplit, error) {
+	split := &models.TrafficSplit{}
+	err := row.Scan(&split.ID, &split.ProjectID, &split.PrimaryURLID, &split.CanaryURLID, &split.CanaryWeight,
+		&split.Status, &split.RevertedAt, &split.UserID, &split.CreatedAt, &split.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	split.PrimaryWeight = 100 - split.CanaryWeight
+	return split, nil
+}
+
+// reapplyTrafficSplit 主URL重新部署后恢复其路由上生效的流量切分
+// 重新部署会把路由的后端替换为主URL自己的Service，需要在添加路由之后调用。
+func reapplyTrafficSplit(ctx context.Context, db *sql.DB, im *k8s.IngressManager, url *models.EphemeralURL, project *models.Project) error {
+	var canaryWeight int
+	var canaryService *string
+	err := db.QueryRowContext(ctx, `
+		SELECT ts.canary_weight, cu.k8s_service_name
+		FROM traffic_splits ts
+		INNER JOIN ephemeral_urls cu ON cu.id = ts.canary_url_id
+		WHERE ts.primary_url_id = $1 AND ts.status = $2
+	`, url.ID, models.TrafficSplitStatusActive).Scan(&canaryWeight, &canaryService)
+	if err == sql.ErrNoRows || (err == nil && canaryService == nil) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get traffic split: %w", err)
+	}
+
+	canary := &models.EphemeralURL{K8sServiceName: canaryService}
+	if err := im.SetSplit(ctx, project, url, canary, canaryWeight); err != nil {
+		return fmt.Errorf("failed to restore traffic split: %w", err)
+	}
+	return nil
+}
+
+// revertURLTrafficSplits 在URL的资源被删除前恢复它参与的流量切分，全部流量回到主URL
+// 由URL删除和清理流程调用，失败只记录日志，不阻塞URL的删除。
+func revertURLTrafficSplits(ctx context.Context, db *sql.DB, im *k8s.IngressManager, url *models.EphemeralURL) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT ts.id, pu.path, pu.k8s_service_name, p.name, p.routing_mode
+		FROM traffic_splits ts
+		INNER JOIN ephemeral_urls pu ON pu.id = ts.primary_url_id
+		INNER JOIN projects p ON p.id = ts.project_id
+		WHERE ts.status = $2 AND (ts.primary_url_id = $1 OR ts.canary_url_id = $1)
+	`, url.ID, models.TrafficSplitStatusActive)
+	if err != nil {
+		logrus.WithError(err).WithField("url_id", url.ID).Error("Failed to list traffic splits of URL")
+		return
+	}
+	defer rows.Close()
+
+	type splitRef struct {
+		id      uuid.UUID
+		project models.Project
+		primary models.EphemeralURL
+	}
+	var splits []splitRef
+	for rows.Next() {
+		var ref splitRef
+		if err := rows.Scan(&ref.id, &ref.primary.Path, &ref.primary.K8sServiceName, &ref.project.Name, &ref.project.RoutingMode); err != nil {
+			logrus.WithError(err).Error("Failed to scan traffic split")
+			continue
+		}
+		splits = append(splits, ref)
+	}
+	rows.Close()
+
+	for _, split := range splits {
+		if im != nil && split.primary.K8sServiceName != nil {
+			if err := im.RemoveSplit(ctx, &split.project, &split.primary); err != nil {
+				logrus.WithError(err).WithField("split_id", split.id).Error("Failed to revert traffic split")
+			}
+		}
+		_, err := db.ExecContext(ctx,
+			"UPDATE traffic_splits SET status = $2, reverted_at = NOW(), updated_at = NOW() WHERE id = $1",
+			split.id, models.TrafficSplitStatusReverted)
+		if err != nil {
+			logrus.WithError(err).WithField("split_id", split.id).Error("Failed to mark traffic split reverted")
+			continue
+		}
+		logrus.WithFields(logrus.Fields{
+			"split_id": split.id,
+			"url_id":   url.ID,
+		}).Info("Traffic split reverted")
+	}
+}
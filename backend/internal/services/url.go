@@ -6,7 +6,9 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 	"url-manager-system/backend/internal/config"
 	"url-manager-system/backend/internal/db/models"
@@ -30,6 +32,7 @@ type URLService struct {
 	ingressManager  *k8s.IngressManager
 	templateService *TemplateService
 	config          *config.Config
+	rolloutWatches  sync.Map // URL ID -> 当前更新监控的标识，同一URL开始新的更新后旧的监控退出
 }
 
 // NewURLService 创建URL服务
@@ -46,20 +49,8 @@ func NewURLService(db *sql.DB, resourceManager *k8s.ResourceManager, ingressMana
 // CreateEphemeralURL 创建临时URL
 func (s *URLService) CreateEphemeralURL(ctx context.Context, projectID uuid.UUID, req *models.CreateEphemeralURLRequest) (*models.CreateEphemeralURLResponse, error) {
 	// 验证请求
-	if err := s.validateCreateRequest(req); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
-	}
-
-	// 验证容器配置
-	if err := utils.ValidateContainerConfig(req.ContainerConfig); err != nil {
-		return nil, fmt.Errorf("container config validation failed: %w", err)
-	}
-
-	// 验证设备映射安全性
-	for _, device := range req.ContainerConfig.Devices {
-		if err := utils.ValidateDeviceMapping(device); err != nil {
-			return nil, fmt.Errorf("device mapping validation failed: %w", err)
-		}
+	if err := s.validateImageURLRequest(req); err != nil {
+		return nil, err
 	}
 
 	// 获取项目信息
@@ -68,6 +59,18 @@ func (s *URLService) CreateEphemeralURL(ctx context.Context, projectID uuid.UUID
 		return nil, err
 	}
 
+	// 自定义域名必须已被项目验证
+	if req.IngressHost != nil {
+		host, err := checkIngressHost(ctx, s.db, projectID, uuid.Nil, *req.IngressHost)
+		if err != nil {
+			return nil, err
+		}
+		req.IngressHost = nil
+		if host != "" {
+			req.IngressHost = &host
+		}
+	}
+
 	// 生成随机路径
 	path, err := s.generateUniquePath(ctx, projectID, req.Image)
 	if err != nil {
@@ -75,6 +78,89 @@ func (s *URLService) CreateEphemeralURL(ctx context.Context, projectID uuid.UUID
 	}
 
 	// 创建URL记录
+	url := s.buildImageURL(projectID, path, req)
+	s.applyTLS(ctx, project, url)
+
+	// 开始事务处理，Kubernetes资源由异步操作在事务提交后创建
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 插入数据库记录
+	if err := s.insertURLRecord(ctx, tx, url); err != nil {
+		return nil, fmt.Errorf("failed to insert URL record: %w", err)
+	}
+
+	// 检查Kubernetes资源管理器是否可用，不可用时不创建操作
+	var op *models.Operation
+	if s.resourceManager != nil && s.ingressManager != nil {
+		op = newOperation(s.config, models.OperationCreate, url, nil)
+		if err := enqueueOperation(ctx, tx, op); err != nil {
+			return nil, err
+		}
+	}
+
+	// 提交事务
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if op == nil {
+		// Kubernetes不可用，设置为draft状态
+		s.updateURLStatus(ctx, url.ID, "draft", "Kubernetes not available")
+		logrus.Warn("Kubernetes not available, URL created in draft mode")
+	}
+
+	// 构建返回URL
+	fullURL := s.publicURL(project, path, url.TLSReady)
+
+	logrus.WithFields(logrus.Fields{
+		"url_id":     url.ID,
+		"project_id": projectID,
+		"path":       path,
+	}).Info("Ephemeral URL accepted")
+
+	return s.createResponse(url, fullURL, op), nil
+}
+
+// createResponse 构建创建URL的响应，附带创建资源的异步操作
+func (s *URLService) createResponse(url *models.EphemeralURL, fullURL string, op *models.Operation) *models.CreateEphemeralURLResponse {
+	resp := &models.CreateEphemeralURLResponse{
+		URL: fullURL,
+		ID:  url.ID,
+	}
+	if op != nil {
+		resp.OperationID = &op.ID
+	}
+	return resp
+}
+
+// validateImageURLRequest 验证基于镜像创建URL的请求
+func (s *URLService) validateImageURLRequest(req *models.CreateEphemeralURLRequest) error {
+	// 验证请求
+	if err := s.validateCreateRequest(req); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	// 验证容器配置
+	if err := utils.ValidateContainerConfig(req.ContainerConfig); err != nil {
+		return fmt.Errorf("container config validation failed: %w", err)
+	}
+
+	// 验证设备映射安全性
+	for _, device := range req.ContainerConfig.Devices {
+		if err := utils.ValidateDeviceMapping(device); err != nil {
+			return fmt.Errorf("device mapping validation failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// buildImageURL 根据创建请求构建URL记录，补全默认资源配置并生成K8s资源名称
+func (s *URLService) buildImageURL(projectID uuid.UUID, path string, req *models.CreateEphemeralURLRequest) *models.EphemeralURL {
 	url := &models.EphemeralURL{
 		ID:              uuid.New(),
 		ProjectID:       projectID,
@@ -101,6 +187,10 @@ func (s *URLService) CreateEphemeralURL(ctx context.Context, projectID uuid.UUID
 		},
 	}
 
+	if req.TrackTagPattern != "" {
+		url.TrackTagPattern = stringPtr(req.TrackTagPattern)
+	}
+
 	// 设置默认值
 	if url.Replicas == 0 {
 		url.Replicas = 1
@@ -127,114 +217,136 @@ func (s *URLService) CreateEphemeralURL(ctx context.Context, projectID uuid.UUID
 		url.K8sSecretName = stringPtr(fmt.Sprintf("secret-ephemeral-%s", url.ID.String()[:8]))
 	}
 
-	// 开始事务处理
+	return url
+}
+
+// DeployURL 部署URL到Kubernetes集群，校验通过后返回执行部署的异步操作
+func (s *URLService) DeployURL(ctx context.Context, urlID uuid.UUID) (*models.Operation, error) {
+	// 获取URL信息
+	url, err := s.GetEphemeralURL(ctx, urlID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URL: %w", err)
+	}
+
+	// 检查状态 - 允许draft、failed、active状态进行部署（active状态用于更新）
+	allowedStatuses := []string{"draft", models.StatusFailed, models.StatusActive, models.StatusDegraded, models.StatusWaiting, models.StatusCreating}
+	isAllowed := false
+	for _, status := range allowedStatuses {
+		if url.Status == status {
+			isAllowed = true
+			break
+		}
+	}
+
+	if !isAllowed {
+		return nil, fmt.Errorf("URL is not in deployable status, current status: %s", url.Status)
+	}
+
+	// 自定义域名可能已在重新验证中失效
+	if url.IngressHost != nil {
+		if _, err := checkIngressHost(ctx, s.db, url.ProjectID, url.ID, *url.IngressHost); err != nil {
+			return nil, err
+		}
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	// 插入数据库记录
-	if err := s.insertURLRecord(ctx, tx, url); err != nil {
-		return nil, fmt.Errorf("failed to insert URL record: %w", err)
+	// 手动部署后重新计算自动重试次数
+	if _, err := tx.ExecContext(ctx, "UPDATE ephemeral_urls SET retry_count = 0, next_retry_at = NULL WHERE id = $1", url.ID); err != nil {
+		return nil, fmt.Errorf("failed to reset retry count: %w", err)
 	}
 
-	// 检查Kubernetes资源管理器是否可用
-	if s.resourceManager == nil || s.ingressManager == nil {
-		// Kubernetes不可用，设置为draft状态
-		s.updateURLStatus(ctx, url.ID, "draft", "Kubernetes not available")
-		logrus.Warn("Kubernetes not available, URL created in draft mode")
-	} else {
-		// Kubernetes可用，创建资源并设置为waiting状态
-		if err := s.createKubernetesResources(ctx, url, project.Name); err != nil {
-			logrus.WithError(err).Error("Failed to create Kubernetes resources")
-			// 更新状态为失败
-			s.updateURLStatus(ctx, url.ID, models.StatusFailed, err.Error())
-			return nil, fmt.Errorf("failed to create Kubernetes resources: %w", err)
+	// 更新状态为创建中（如果不是active状态），active和degraded状态的URL部署后由更新监控决定状态
+	keepActive := url.Status == models.StatusActive || url.Status == models.StatusDegraded
+	if !keepActive {
+		if err := setURLStatusTx(ctx, tx, url.ID, models.StatusCreating); err != nil {
+			return nil, err
 		}
-		// 资源创建成功后，设置为等待状态（等待Pod Ready）
-		s.updateURLStatus(ctx, url.ID, models.StatusWaiting, "")
 	}
 
-	// 提交事务
+	op := newOperation(s.config, models.OperationDeploy, url, models.OperationData{"keep_active": keepActive})
+	if err := enqueueOperation(ctx, tx, op); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// 异步验证部署状态
-	go s.verifyDeployment(url)
-
-	// 构建返回URL
-	fullURL := fmt.Sprintf("https://%s%s", s.config.K8s.DefaultDomain, path)
-
 	logrus.WithFields(logrus.Fields{
-		"url_id":     url.ID,
-		"project_id": projectID,
-		"path":       path,
-	}).Info("Ephemeral URL created successfully")
+		"url_id":       url.ID,
+		"operation_id": op.ID,
+	}).Info("URL deployment accepted")
 
-	return &models.CreateEphemeralURLResponse{
-		URL: fullURL,
-		ID:  url.ID,
-	}, nil
+	return op, nil
 }
 
-// DeployURL 部署URL到Kubernetes集群
-func (s *URLService) DeployURL(ctx context.Context, urlID uuid.UUID) error {
-	// 获取URL信息
-	url, err := s.GetEphemeralURL(ctx, urlID)
-	if err != nil {
-		return fmt.Errorf("failed to get URL: %w", err)
+// RolloutImage 将URL滚动更新到新镜像，并在URL日志中记录触发来源和部署的提交
+// 新镜像和滚动更新操作在同一事务中写入，Deployment由异步操作更新。
+func (s *URLService) RolloutImage(ctx context.Context, url *models.EphemeralURL, image, commit, trigger string) error {
+	if !utils.ValidateImageName(image) {
+		return fmt.Errorf("invalid image name format: %s", image)
+	}
+	if url.K8sDeploymentName == nil {
+		return fmt.Errorf("URL has no deployment")
+	}
+	if s.resourceManager == nil {
+		return fmt.Errorf("kubernetes resource manager not available")
 	}
 
-	// 检查状态 - 允许draft、failed、active状态进行部署（active状态用于更新）
-	allowedStatuses := []string{"draft", models.StatusFailed, models.StatusActive, models.StatusWaiting, models.StatusCreating}
-	isAllowed := false
-	for _, status := range allowedStatuses {
-		if url.Status == status {
-			isAllowed = true
-			break
-		}
+	logEntry := models.LogEntry{
+		Timestamp: time.Now(),
+		Level:     "info",
+		Message:   fmt.Sprintf("镜像已更新（%s）", trigger),
+		Details:   fmt.Sprintf("%s -> %s", url.Image, image),
+	}
+	if commit != "" {
+		logEntry.Details = fmt.Sprintf("%s (commit %s)", logEntry.Details, commit)
 	}
+	logsJSON, _ := json.Marshal([]models.LogEntry{logEntry})
 
-	if !isAllowed {
-		return fmt.Errorf("URL is not in deployable status, current status: %s", url.Status)
+	var gitCommit *string
+	if commit != "" {
+		gitCommit = &commit
 	}
 
-	// 获取项目信息
-	var projectName string
-	err = s.db.QueryRowContext(ctx, "SELECT name FROM projects WHERE id = $1", url.ProjectID).Scan(&projectName)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get project name: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// 更新状态为创建中（如果不是active状态）
-	if url.Status != models.StatusActive {
-		s.updateURLStatus(ctx, url.ID, models.StatusCreating, "")
+	query := `
+		UPDATE ephemeral_urls
+		SET image = $2, git_commit = COALESCE($3, git_commit), updated_at = NOW(),
+			logs = logs || $4::jsonb
+		WHERE id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, url.ID, image, gitCommit, string(logsJSON)); err != nil {
+		return fmt.Errorf("failed to update URL image: %w", err)
 	}
 
-	// 创建或更新Kubernetes资源
-	if err := s.createKubernetesResources(ctx, url, projectName); err != nil {
-		logrus.WithError(err).Error("Failed to deploy Kubernetes resources")
-		s.updateURLStatus(ctx, url.ID, models.StatusFailed, err.Error())
-		return fmt.Errorf("failed to deploy Kubernetes resources: %w", err)
+	op := newOperation(s.config, models.OperationRollout, url, models.OperationData{"image": image})
+	op.Source = models.OperationSourceWebhook
+	if err := enqueueOperation(ctx, tx, op); err != nil {
+		return err
 	}
 
-	// 更新部署状态
-	_, err = s.db.ExecContext(ctx,
-		"UPDATE ephemeral_urls SET deployed = true, deployment_requested_at = NOW() WHERE id = $1",
-		url.ID)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to update deployment status")
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// 如果原来是active状态，保持active状态；否则设置为waiting状态
-	if url.Status == models.StatusActive {
-		logrus.WithField("url_id", url.ID).Info("URL updated successfully")
-	} else {
-		s.updateURLStatus(ctx, url.ID, models.StatusWaiting, "")
-		logrus.WithField("url_id", url.ID).Info("URL deployed successfully")
-	}
+	logrus.WithFields(logrus.Fields{
+		"url_id":       url.ID,
+		"image":        image,
+		"commit":       commit,
+		"trigger":      trigger,
+		"operation_id": op.ID,
+	}).Info("URL image rollout accepted")
 
 	return nil
 }
@@ -242,10 +354,12 @@ func (s *URLService) DeployURL(ctx context.Context, urlID uuid.UUID) error {
 // GetEphemeralURL 获取临时URL
 func (s *URLService) GetEphemeralURL(ctx context.Context, id uuid.UUID) (*models.EphemeralURL, error) {
 	query := `
-		SELECT eu.id, eu.project_id, eu.path, eu.image, eu.env, eu.replicas, eu.resources,
+		SELECT eu.id, eu.project_id, eu.template_id, eu.path, eu.image, eu.env, eu.replicas, eu.resources,
 		       eu.container_config, eu.status, eu.k8s_deployment_name, eu.k8s_service_name, eu.k8s_secret_name,
-		       eu.error_message, eu.expire_at, eu.created_at, eu.updated_at,
-		       p.id, p.name, p.description, p.created_at, p.updated_at
+		       eu.error_message, eu.diagnosis, eu.retry_count, eu.next_retry_at, eu.stack_id, eu.stack_service,
+		       eu.git_repository, eu.git_pr_number, eu.git_branch, eu.git_commit, eu.track_tag_pattern, eu.ingress_host,
+		       eu.tls_secret_name, eu.tls_ready, eu.expire_at, eu.created_at, eu.updated_at,
+		       p.id, p.name, p.description, p.routing_mode, p.tls_mode, p.tls_secret_name, p.drift_policy, p.retry_policy, p.created_at, p.updated_at
 		FROM ephemeral_urls eu
 		INNER JOIN projects p ON eu.project_id = p.id
 		WHERE eu.id = $1
@@ -253,10 +367,13 @@ func (s *URLService) GetEphemeralURL(ctx context.Context, id uuid.UUID) (*models
 
 	url := &models.EphemeralURL{Project: &models.Project{}}
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&url.ID, &url.ProjectID, &url.Path, &url.Image, &url.Env, &url.Replicas, &url.Resources,
+		&url.ID, &url.ProjectID, &url.TemplateID, &url.Path, &url.Image, &url.Env, &url.Replicas, &url.Resources,
 		&url.ContainerConfig, &url.Status, &url.K8sDeploymentName, &url.K8sServiceName, &url.K8sSecretName,
-		&url.ErrorMessage, &url.ExpireAt, &url.CreatedAt, &url.UpdatedAt,
-		&url.Project.ID, &url.Project.Name, &url.Project.Description, &url.Project.CreatedAt, &url.Project.UpdatedAt,
+		&url.ErrorMessage, &url.Diagnosis, &url.RetryCount, &url.NextRetryAt, &url.StackID, &url.StackService,
+		&url.GitRepository, &url.GitPRNumber, &url.GitBranch, &url.GitCommit, &url.TrackTagPattern, &url.IngressHost,
+		&url.TLSSecretName, &url.TLSReady, &url.ExpireAt, &url.CreatedAt, &url.UpdatedAt,
+		&url.Project.ID, &url.Project.Name, &url.Project.Description, &url.Project.RoutingMode,
+		&url.Project.TLSMode, &url.Project.TLSSecretName, &url.Project.DriftPolicy, &url.Project.RetryPolicy, &url.Project.CreatedAt, &url.Project.UpdatedAt,
 	)
 
 	if err != nil {
@@ -265,12 +382,13 @@ func (s *URLService) GetEphemeralURL(ctx context.Context, id uuid.UUID) (*models
 		}
 		return nil, fmt.Errorf("failed to get URL: %w", err)
 	}
+	url.URL = s.publicURL(url.Project, url.Path, url.TLSReady)
 
 	return url, nil
 }
 
 // UpdateEphemeralURL 更新临时URL
-func (s *URLService) UpdateEphemeralURL(ctx context.Context, id uuid.UUID, req *models.UpdateEphemeralURLRequest) (*models.EphemeralURL, error) {
+func (s *URLService) UpdateEphemeralURL(ctx context.Context, id uuid.UUID, req *models.UpdateEphemeralURLRequest) (*models.Operation, error) {
 	logrus.WithFields(logrus.Fields{
 		"url_id":  id.String(),
 		"request": req,
@@ -344,9 +462,34 @@ func (s *URLService) UpdateEphemeralURL(ctx context.Context, id uuid.UUID, req *
 		"container_config": req.ContainerConfig,
 	}).Info("Updating container_config")
 
+	// 空字符串表示不再使用自定义域名，更换或取消时由异步操作移除原域名的路由，新域名在重新部署时生效
+	payload := models.OperationData{}
 	if req.IngressHost != nil {
+		host, err := checkIngressHost(ctx, s.db, existingURL.ProjectID, existingURL.ID, *req.IngressHost)
+		if err != nil {
+			return nil, err
+		}
 		setParts = append(setParts, fmt.Sprintf("ingress_host = $%d", argIndex))
-		args = append(args, *req.IngressHost)
+		if host == "" {
+			args = append(args, nil)
+		} else {
+			args = append(args, host)
+		}
+		argIndex++
+
+		if existingURL.IngressHost != nil && *existingURL.IngressHost != host {
+			payload["remove_host"] = *existingURL.IngressHost
+		}
+	}
+
+	// 空字符串表示取消跟踪
+	if req.TrackTagPattern != nil {
+		setParts = append(setParts, fmt.Sprintf("track_tag_pattern = $%d", argIndex))
+		if *req.TrackTagPattern == "" {
+			args = append(args, nil)
+		} else {
+			args = append(args, *req.TrackTagPattern)
+		}
 		argIndex++
 	}
 
@@ -370,16 +513,31 @@ func (s *URLService) UpdateEphemeralURL(ctx context.Context, id uuid.UUID, req *
 		"args":   args,
 	}).Info("Executing update query")
 
-	_, err = s.db.ExecContext(ctx, query, args...)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		logrus.WithError(err).WithField("url_id", id.String()).Error("Failed to execute update query")
 		return nil, fmt.Errorf("failed to update URL: %w", err)
 	}
 
+	op := newOperation(s.config, models.OperationUpdate, existingURL, payload)
+	if err := enqueueOperation(ctx, tx, op); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	logrus.WithField("url_id", id.String()).Info("Database update completed successfully")
 
 	// 如果状态为active且TTL被更新，重新计算过期时间（从 started_at 起算）
-	if existingURL.Status == models.StatusActive && req.TTLSeconds > 0 {
+	if (existingURL.Status == models.StatusActive || existingURL.Status == models.StatusDegraded) && req.TTLSeconds > 0 {
 		var base time.Time
 		if existingURL.StartedAt != nil && !existingURL.StartedAt.IsZero() {
 			base = *existingURL.StartedAt
@@ -393,14 +551,11 @@ func (s *URLService) UpdateEphemeralURL(ctx context.Context, id uuid.UUID, req *
 		}
 	}
 
-	// 获取更新后的URL
-	updatedURL, err := s.GetEphemeralURL(ctx, id)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get updated URL: %w", err)
-	}
-
-	logrus.WithField("url_id", id).Info("URL updated successfully")
-	return updatedURL, nil
+	logrus.WithFields(logrus.Fields{
+		"url_id":       id,
+		"operation_id": op.ID,
+	}).Info("URL updated successfully")
+	return op, nil
 }
 
 // GetURLContainerStatus 获取URL容器状态
@@ -499,9 +654,41 @@ func (s *URLService) validateUpdateRequest(req *models.UpdateEphemeralURLRequest
 	if req.Replicas > 0 && (req.Replicas < 1 || req.Replicas > 10) {
 		return fmt.Errorf("replicas must be between 1 and 10")
 	}
+	if req.TrackTagPattern != nil && *req.TrackTagPattern != "" {
+		if err := utils.ValidateTagPattern(*req.TrackTagPattern); err != nil {
+			return err
+		}
+	}
+	if err := utils.ValidateContainerConfig(req.ContainerConfig); err != nil {
+		return fmt.Errorf("invalid container config: %w", err)
+	}
+	return s.validateStartupTimeout(req.ContainerConfig)
+}
+
+// validateStartupTimeout 验证URL设置的启动超时不超过配置的上限
+func (s *URLService) validateStartupTimeout(containerConfig models.ContainerConfig) error {
+	timeout := time.Duration(containerConfig.StartupTimeoutSeconds) * time.Second
+	if max := s.config.Readiness.MaxStartupTimeout; max > 0 && timeout > max {
+		return fmt.Errorf("startup timeout cannot exceed %d seconds", int(max.Seconds()))
+	}
 	return nil
 }
 
+// defaultStartupTimeout 配置未设置有效启动超时时使用的默认值
+const defaultStartupTimeout = 15 * time.Minute
+
+// startupTimeout 部署后等待Pod就绪的时间，URL未设置时使用配置的默认值
+// verifyDeployment、monitorPodStatus和cleanupStuckURLs都按此判断部署超时。
+func startupTimeout(cfg config.ReadinessConfig, containerConfig models.ContainerConfig) time.Duration {
+	if containerConfig.StartupTimeoutSeconds > 0 {
+		return time.Duration(containerConfig.StartupTimeoutSeconds) * time.Second
+	}
+	if cfg.StartupTimeout > 0 {
+		return cfg.StartupTimeout
+	}
+	return defaultStartupTimeout
+}
+
 // updateURLExpireAt 更新URL过期时间
 func (s *URLService) updateURLExpireAt(ctx context.Context, id uuid.UUID, expireAt time.Time) error {
 	query := "UPDATE ephemeral_urls SET expire_at = $1, updated_at = $2 WHERE id = $3"
@@ -521,15 +708,22 @@ func (s *URLService) ListEphemeralURLs(ctx context.Context, projectID uuid.UUID,
 
 	// 获取URL列表
 	query := `
-		SELECT id, project_id, path, image, env, replicas, resources,
+		SELECT id, project_id, template_id, path, image, env, replicas, resources,
 		       status, k8s_deployment_name, k8s_service_name, k8s_secret_name,
-		       error_message, expire_at, created_at, updated_at
+		       error_message, diagnosis, retry_count, next_retry_at, git_repository, git_pr_number, git_branch, git_commit, track_tag_pattern, ingress_host,
+		       tls_secret_name, tls_ready, expire_at, created_at, updated_at
 		FROM ephemeral_urls
 		WHERE project_id = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
+	// 用于生成访问地址
+	project, err := s.getProject(ctx, projectID)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	rows, err := s.db.QueryContext(ctx, query, projectID, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list URLs: %w", err)
@@ -540,14 +734,16 @@ func (s *URLService) ListEphemeralURLs(ctx context.Context, projectID uuid.UUID,
 	for rows.Next() {
 		var url models.EphemeralURL
 		err := rows.Scan(
-			&url.ID, &url.ProjectID, &url.Path, &url.Image, &url.Env, &url.Replicas, &url.Resources,
+			&url.ID, &url.ProjectID, &url.TemplateID, &url.Path, &url.Image, &url.Env, &url.Replicas, &url.Resources,
 			&url.Status, &url.K8sDeploymentName, &url.K8sServiceName, &url.K8sSecretName,
-			&url.ErrorMessage, &url.ExpireAt, &url.CreatedAt, &url.UpdatedAt,
+			&url.ErrorMessage, &url.Diagnosis, &url.RetryCount, &url.NextRetryAt, &url.GitRepository, &url.GitPRNumber, &url.GitBranch, &url.GitCommit, &url.TrackTagPattern, &url.IngressHost,
+			&url.TLSSecretName, &url.TLSReady, &url.ExpireAt, &url.CreatedAt, &url.UpdatedAt,
 		)
 		if err != nil {
 			logrus.WithError(err).Error("Failed to scan URL")
 			continue
 		}
+		url.URL = s.publicURL(project, url.Path, url.TLSReady)
 		urls = append(urls, url)
 	}
 
@@ -558,34 +754,44 @@ func (s *URLService) ListEphemeralURLs(ctx context.Context, projectID uuid.UUID,
 	return urls, total, nil
 }
 
-// DeleteEphemeralURL 删除临时URL
-func (s *URLService) DeleteEphemeralURL(ctx context.Context, id uuid.UUID) error {
+// DeleteEphemeralURL 删除临时URL，返回删除Kubernetes资源和URL记录的异步操作
+func (s *URLService) DeleteEphemeralURL(ctx context.Context, id uuid.UUID) (*models.Operation, error) {
 	// 获取URL信息
 	url, err := s.GetEphemeralURL(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// 环境成员只能随环境整体删除
+	if url.StackID != nil {
+		return nil, fmt.Errorf("URL belongs to a stack and can only be deleted with the stack")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
 	// 更新状态为删除中
-	if err := s.updateURLStatus(ctx, id, models.StatusDeleting, ""); err != nil {
-		return fmt.Errorf("failed to update status: %w", err)
+	if err := setURLStatusTx(ctx, tx, id, models.StatusDeleting); err != nil {
+		return nil, fmt.Errorf("failed to update status: %w", err)
 	}
 
-	// 删除Kubernetes资源
-	if err := s.deleteKubernetesResources(ctx, url); err != nil {
-		logrus.WithError(err).Error("Failed to delete Kubernetes resources")
-		// 更新状态为失败
-		s.updateURLStatus(ctx, id, models.StatusFailed, err.Error())
-		return fmt.Errorf("failed to delete Kubernetes resources: %w", err)
+	op := newOperation(s.config, models.OperationDelete, url, nil)
+	if err := enqueueOperation(ctx, tx, op); err != nil {
+		return nil, err
 	}
 
-	// 更新状态为已删除
-	if err := s.updateURLStatus(ctx, id, models.StatusDeleted, ""); err != nil {
-		return fmt.Errorf("failed to update final status: %w", err)
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	logrus.WithField("url_id", id).Info("Ephemeral URL deleted successfully")
-	return nil
+	logrus.WithFields(logrus.Fields{
+		"url_id":       id,
+		"operation_id": op.ID,
+	}).Info("Ephemeral URL deletion accepted")
+	return op, nil
 }
 
 // validateCreateRequest 验证创建请求
@@ -595,6 +801,13 @@ func (s *URLService) validateCreateRequest(req *models.CreateEphemeralURLRequest
 		return fmt.Errorf("invalid image name format: %s", req.Image)
 	}
 
+	// 验证镜像标签跟踪规则
+	if req.TrackTagPattern != "" {
+		if err := utils.ValidateTagPattern(req.TrackTagPattern); err != nil {
+			return err
+		}
+	}
+
 	// 验证镜像白名单
 	// if !s.isImageAllowed(req.Image) {
 	// 	return fmt.Errorf("image %s is not in allowed list", req.Image)
@@ -610,6 +823,11 @@ func (s *URLService) validateCreateRequest(req *models.CreateEphemeralURLRequest
 		return fmt.Errorf("TTL cannot exceed %d seconds", s.config.Security.MaxTTLSeconds)
 	}
 
+	// 验证启动超时
+	if err := s.validateStartupTimeout(req.ContainerConfig); err != nil {
+		return err
+	}
+
 	// 验证环境变量
 	for _, env := range req.Env {
 		if !utils.ValidateEnvironmentVariableName(env.Name) {
@@ -655,6 +873,15 @@ func (s *URLService) generateHashPath(projectID uuid.UUID, image string) string
 	return fmt.Sprintf("/%x", hash[:4])
 }
 
+// subdomainPathPattern 子域名路由模式下自定义路径的格式
+var subdomainPathPattern = regexp.MustCompile(`^/[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// pathCountQuery 统计项目内已占用某路径的URL和别名数量
+const pathCountQuery = `
+	SELECT (SELECT COUNT(*) FROM ephemeral_urls WHERE project_id = $1 AND path = $2)
+	     + (SELECT COUNT(*) FROM url_aliases WHERE project_id = $1 AND path = $2)
+`
+
 // generateUniquePath 生成唯一路径
 func (s *URLService) generateUniquePath(ctx context.Context, projectID uuid.UUID, image string) (string, error) {
 	maxRetries := 10
@@ -663,8 +890,7 @@ func (s *URLService) generateUniquePath(ctx context.Context, projectID uuid.UUID
 
 		// 检查路径是否已存在
 		var count int
-		query := `SELECT COUNT(*) FROM ephemeral_urls WHERE project_id = $1 AND path = $2`
-		err := s.db.QueryRowContext(ctx, query, projectID, path).Scan(&count)
+		err := s.db.QueryRowContext(ctx, pathCountQuery, projectID, path).Scan(&count)
 		if err != nil {
 			return "", err
 		}
@@ -680,9 +906,10 @@ func (s *URLService) generateUniquePath(ctx context.Context, projectID uuid.UUID
 // getProject 获取项目信息
 func (s *URLService) getProject(ctx context.Context, projectID uuid.UUID) (*models.Project, error) {
 	project := &models.Project{}
-	query := `SELECT id, name, description, created_at, updated_at FROM projects WHERE id = $1`
+	query := `SELECT id, name, description, routing_mode, tls_mode, tls_secret_name, drift_policy, retry_policy, created_at, updated_at FROM projects WHERE id = $1`
 	err := s.db.QueryRowContext(ctx, query, projectID).Scan(
-		&project.ID, &project.Name, &project.Description, &project.CreatedAt, &project.UpdatedAt,
+		&project.ID, &project.Name, &project.Description, &project.RoutingMode,
+		&project.TLSMode, &project.TLSSecretName, &project.DriftPolicy, &project.RetryPolicy, &project.CreatedAt, &project.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -697,25 +924,77 @@ func (s *URLService) getProject(ctx context.Context, projectID uuid.UUID) (*mode
 func (s *URLService) insertURLRecord(ctx context.Context, tx *sql.Tx, url *models.EphemeralURL) error {
 	query := `
 		INSERT INTO ephemeral_urls (
-			id, project_id, path, image, env, replicas, resources, status, ttl_seconds,
-			k8s_deployment_name, k8s_service_name, k8s_secret_name,
-			expire_at, created_at, updated_at
+			id, project_id, path, image, env, replicas, resources, container_config, status, ttl_seconds,
+			k8s_deployment_name, k8s_service_name, k8s_secret_name, track_tag_pattern, ingress_host,
+			tls_secret_name, tls_ready, expire_at, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
 		)
 	`
 
 	_, err := tx.ExecContext(ctx, query,
-		url.ID, url.ProjectID, url.Path, url.Image, url.Env, url.Replicas, url.Resources, url.Status, url.TTLSeconds,
-		url.K8sDeploymentName, url.K8sServiceName, url.K8sSecretName,
-		url.ExpireAt, url.CreatedAt, url.UpdatedAt,
+		url.ID, url.ProjectID, url.Path, url.Image, url.Env, url.Replicas, url.Resources, url.ContainerConfig, url.Status, url.TTLSeconds,
+		url.K8sDeploymentName, url.K8sServiceName, url.K8sSecretName, url.TrackTagPattern, url.IngressHost,
+		url.TLSSecretName, url.TLSReady, url.ExpireAt, url.CreatedAt, url.UpdatedAt,
 	)
 
 	return err
 }
 
+// urlHost 按项目的路由模式返回URL的访问域名
+func (s *URLService) urlHost(project *models.Project, path string) string {
+	if project.RoutingMode == models.RoutingModeSubdomain {
+		return utils.SubdomainHost(path, project.Name, s.config.K8s.WildcardDomain)
+	}
+	return s.config.K8s.DefaultDomain
+}
+
+// publicURL 按项目的路由模式生成URL的完整访问地址，证书就绪前使用http
+func (s *URLService) publicURL(project *models.Project, path string, tlsReady bool) string {
+	return utils.RoutedURL(s.urlHost(project, path), path, project.RoutingMode == models.RoutingModeSubdomain, tlsReady)
+}
+
+// tlsSecretName 返回项目下某个访问域名使用的证书Secret，项目未配置TLS时返回nil
+// cert-manager模式下默认域名按项目签发证书，子域名按域名签发，避免多个Ingress争用同一个Certificate。
+func (s *URLService) tlsSecretName(project *models.Project, host string) *string {
+	switch project.TLSMode {
+	case models.TLSModeSecret:
+		if project.TLSSecretName != nil {
+			return project.TLSSecretName
+		}
+		if s.config.K8s.TLSSecretName != "" {
+			return stringPtr(s.config.K8s.TLSSecretName)
+		}
+	case models.TLSModeCertManager:
+		if host == s.config.K8s.DefaultDomain {
+			return stringPtr(fmt.Sprintf("project-%s-tls", utils.SanitizeKubernetesName(project.Name)))
+		}
+		return stringPtr(host + "-tls")
+	}
+	return nil
+}
+
+// applyTLS 按项目的证书设置填充URL使用的证书Secret及其当前是否就绪
+func (s *URLService) applyTLS(ctx context.Context, project *models.Project, url *models.EphemeralURL) {
+	url.TLSSecretName = s.tlsSecretName(project, s.urlHost(project, url.Path))
+	url.TLSReady = url.TLSSecretName != nil && s.tlsSecretReady(ctx, *url.TLSSecretName)
+}
+
+// tlsSecretReady 检查证书Secret是否已就绪，Kubernetes不可用或检查失败时视为未就绪
+func (s *URLService) tlsSecretReady(ctx context.Context, secretName string) bool {
+	if s.resourceManager == nil {
+		return false
+	}
+	ready, err := s.resourceManager.CheckTLSSecretReady(ctx, secretName)
+	if err != nil {
+		logrus.WithError(err).WithField("secret", secretName).Warn("Failed to check TLS secret")
+		return false
+	}
+	return ready
+}
+
 // createKubernetesResources 创建Kubernetes资源
-func (s *URLService) createKubernetesResources(ctx context.Context, url *models.EphemeralURL, projectName string) error {
+func (s *URLService) createKubernetesResources(ctx context.Context, url *models.EphemeralURL, project *models.Project) error {
 	// 检查Kubernetes资源管理器是否可用
 	if s.resourceManager == nil || s.ingressManager == nil {
 		logrus.Warn("Kubernetes managers not available, skipping Kubernetes resource creation")
@@ -739,19 +1018,26 @@ func (s *URLService) createKubernetesResources(ctx context.Context, url *models.
 		return fmt.Errorf("failed to create or update service: %w", err)
 	}
 
-	// 添加Ingress路径
-	if err := s.ingressManager.AddPath(ctx, url, projectName); err != nil {
-		return fmt.Errorf("failed to add ingress path: %w", err)
+	// 按项目的路由模式添加Ingress路由
+	if err := s.ingressManager.AddRoute(ctx, url, project); err != nil {
+		return fmt.Errorf("failed to add ingress route: %w", err)
 	}
 
-	return nil
+	// 重新部署会重置路由后端，恢复该URL路由上生效的流量切分
+	return reapplyTrafficSplit(ctx, s.db, s.ingressManager, url, project)
 }
 
 // deleteKubernetesResources 删除Kubernetes资源
 func (s *URLService) deleteKubernetesResources(ctx context.Context, url *models.EphemeralURL) error {
-	// 从Ingress移除路径
-	if err := s.ingressManager.RemovePath(ctx, url.Project.Name, url.Path); err != nil {
-		logrus.WithError(err).Warn("Failed to remove ingress path")
+	// 指向该URL的别名切换到兜底页面
+	detachURLAliases(ctx, s.db, s.resourceManager, s.ingressManager, s.config, url, url.Project.Name)
+
+	// 该URL参与的流量切分恢复为全部流量转发给主URL
+	revertURLTrafficSplits(ctx, s.db, s.ingressManager, url)
+
+	// 从Ingress移除路由，仍被其他URL使用的自定义域名路由保留
+	if err := s.ingressManager.RemoveRoute(ctx, routeOwnedURL(ctx, s.db, url), url.Project.Name); err != nil {
+		logrus.WithError(err).Warn("Failed to remove ingress route")
 	}
 
 	// 删除Deployment
@@ -795,8 +1081,8 @@ func (s *URLService) updateURLStatus(ctx context.Context, id uuid.UUID, status,
 		newExpireAt := time.Now().Add(time.Duration(url.TTLSeconds) * time.Second)
 		query = `
 			UPDATE ephemeral_urls
-			SET status = $2, error_message = $3, started_at = NOW(), expire_at = $4, updated_at = NOW(),
-				logs = logs || $5::jsonb
+			SET status = $2, error_message = $3, diagnosis = NULL, retry_count = 0, next_retry_at = NULL,
+				started_at = NOW(), expire_at = $4, updated_at = NOW(), logs = logs || $5::jsonb
 			WHERE id = $1
 		`
 		logEntry := models.LogEntry{
@@ -813,72 +1099,126 @@ func (s *URLService) updateURLStatus(ctx context.Context, id uuid.UUID, status,
 		}
 		args = []interface{}{id, status, errMsg, newExpireAt, string(logsJSON)}
 	} else {
-		// 其他状态更新
+		// 其他状态更新，失败时记录诊断结果并按项目重试策略安排重试
 		query = `
 			UPDATE ephemeral_urls
 			SET status = $2, error_message = $3, updated_at = $4,
-				logs = logs || $5::jsonb
+				logs = logs || $5::jsonb, diagnosis = $6, next_retry_at = $7
 			WHERE id = $1
 		`
 
-		var logEntry models.LogEntry
-		switch status {
-		case models.StatusFailed:
-			logEntry = models.LogEntry{
-				Timestamp: time.Now(),
-				Level:     "error",
-				Message:   "URL部署失败",
-				Details:   errorMessage,
-			}
-		case models.StatusDeleting:
-			logEntry = models.LogEntry{
-				Timestamp: time.Now(),
-				Level:     "info",
-				Message:   "开始删除URL",
-			}
-		case models.StatusDeleted:
-			logEntry = models.LogEntry{
-				Timestamp: time.Now(),
-				Level:     "info",
-				Message:   "URL已成功删除",
+		logEntries := []models.LogEntry{statusLogEntry(status, errorMessage)}
+		var diagnosis *models.FailureDiagnosis
+		var nextRetryAt *time.Time
+		if status == models.StatusFailed {
+			diagnosis = s.diagnoseFailure(ctx, url, errorMessage)
+			if diagnosis != nil {
+				logEntries[0].Details = fmt.Sprintf("%s（%s：%s）", errorMessage, diagnosis.Code, diagnosis.Hint)
 			}
-		default:
-			logEntry = models.LogEntry{
-				Timestamp: time.Now(),
-				Level:     "info",
-				Message:   fmt.Sprintf("状态更新为: %s", status),
+			if nextRetryAt = nextRetryTime(url, diagnosis); nextRetryAt != nil {
+				logEntries = append(logEntries, models.LogEntry{
+					Timestamp: time.Now(),
+					Level:     "info",
+					Message:   fmt.Sprintf("将自动重试（第%d/%d次）", url.RetryCount+1, url.Project.RetryPolicy.MaxAttempts),
+					Details:   fmt.Sprintf("重试时间: %s", nextRetryAt.Format("2006-01-02 15:04:05")),
+				})
 			}
 		}
-		logsJSON, _ := json.Marshal([]models.LogEntry{logEntry})
+		logsJSON, _ := json.Marshal(logEntries)
 
 		var errMsg *string
 		if errorMessage != "" {
 			errMsg = &errorMessage
 		}
-		args = []interface{}{id, status, errMsg, time.Now(), string(logsJSON)}
+		args = []interface{}{id, status, errMsg, time.Now(), string(logsJSON), diagnosis, nextRetryAt}
 	}
 
 	_, err = s.db.ExecContext(ctx, query, args...)
 	return err
 }
 
+// diagnoseFailure 判断URL部署失败的原因
+// 创建资源时的配额错误从错误信息判断，其他失败从Deployment的Pod状态和事件判断；无法获取时返回nil，不影响状态更新。
+func (s *URLService) diagnoseFailure(ctx context.Context, url *models.EphemeralURL, errorMessage string) *models.FailureDiagnosis {
+	if diagnosis := k8s.ClassifyError(errorMessage); diagnosis != nil {
+		return diagnosis
+	}
+	if s.resourceManager == nil || url.K8sDeploymentName == nil {
+		return nil
+	}
+
+	diagnosis, err := s.resourceManager.DiagnoseFailure(ctx, *url.K8sDeploymentName)
+	if err != nil {
+		logrus.WithError(err).WithField("url_id", url.ID).Warn("Failed to diagnose deployment failure")
+		return nil
+	}
+	return diagnosis
+}
+
+// statusLogEntry 构建状态变化写入URL日志的记录
+func statusLogEntry(status, errorMessage string) models.LogEntry {
+	switch status {
+	case models.StatusFailed:
+		return models.LogEntry{
+			Timestamp: time.Now(),
+			Level:     "error",
+			Message:   "URL部署失败",
+			Details:   errorMessage,
+		}
+	case models.StatusDeleting:
+		return models.LogEntry{
+			Timestamp: time.Now(),
+			Level:     "info",
+			Message:   "开始删除URL",
+		}
+	case models.StatusDeleted:
+		return models.LogEntry{
+			Timestamp: time.Now(),
+			Level:     "info",
+			Message:   "URL已成功删除",
+		}
+	default:
+		return models.LogEntry{
+			Timestamp: time.Now(),
+			Level:     "info",
+			Message:   fmt.Sprintf("状态更新为: %s", status),
+		}
+	}
+}
+
+// setURLStatusTx 在事务中更新URL状态，用于与异步操作一起提交的状态变化
+func setURLStatusTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, status string) error {
+	logsJSON, _ := json.Marshal([]models.LogEntry{statusLogEntry(status, "")})
+	_, err := tx.ExecContext(ctx, `
+		UPDATE ephemeral_urls
+		SET status = $2, error_message = NULL, diagnosis = NULL, next_retry_at = NULL, updated_at = NOW(), logs = logs || $3::jsonb
+		WHERE id = $1
+	`, id, status, string(logsJSON))
+	if err != nil {
+		return fmt.Errorf("failed to update URL status: %w", err)
+	}
+	return nil
+}
+
 // verifyDeployment 异步验证部署状态
 func (s *URLService) verifyDeployment(url *models.EphemeralURL) {
 	ctx := context.Background()
-	timeout := time.After(5 * time.Minute)
+	limit := startupTimeout(s.config.Readiness, url.ContainerConfig)
+	timeout := time.After(limit)
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
 	logrus.WithFields(logrus.Fields{
 		"url_id":          url.ID,
 		"deployment_name": url.K8sDeploymentName,
+		"timeout":         limit,
 	}).Info("Starting deployment verification")
 
 	for {
 		select {
 		case <-timeout:
 			logrus.WithField("url_id", url.ID).Warn("Deployment verification timeout")
-			s.updateURLStatus(ctx, url.ID, models.StatusFailed, "deployment verification timeout")
+			s.updateURLStatus(ctx, url.ID, models.StatusFailed, fmt.Sprintf("Pod failed to become ready within %s", limit))
 			return
 		case <-ticker.C:
 			if url.K8sDeploymentName != nil {
@@ -913,8 +1253,20 @@ func stringPtr(s string) *string {
 	return &s
 }
 
-// CreateEphemeralURLFromTemplate 基于模版创建临时URL
-func (s *URLService) CreateEphemeralURLFromTemplate(ctx context.Context, projectID uuid.UUID, req *models.CreateEphemeralURLFromTemplateRequest) (*models.CreateEphemeralURLResponse, error) {
+// templateURLOptions 基于模版创建URL时的附加选项
+type templateURLOptions struct {
+	image string           // 覆盖模版主容器的镜像，为空时使用模版中的镜像
+	git   *models.GitEvent // 由Git webhook创建时记录来源PR
+}
+
+// CreateEphemeralURLFromTemplate 基于用户可见的模版创建临时URL
+func (s *URLService) CreateEphemeralURLFromTemplate(ctx context.Context, projectID, userID uuid.UUID, isAdmin bool, req *models.CreateEphemeralURLFromTemplateRequest) (*models.CreateEphemeralURLResponse, error) {
+	return s.createURLFromTemplate(ctx, projectID, userID, isAdmin, req, templateURLOptions{})
+}
+
+// createURLFromTemplate 基于模版创建临时URL，可覆盖镜像并记录来源PR
+// userID和isAdmin为使用模版的用户，模版对其不可见时返回template not found。
+func (s *URLService) createURLFromTemplate(ctx context.Context, projectID, userID uuid.UUID, isAdmin bool, req *models.CreateEphemeralURLFromTemplateRequest, opts templateURLOptions) (*models.CreateEphemeralURLResponse, error) {
 	// 获取项目信息
 	project, err := s.getProject(ctx, projectID)
 	if err != nil {
@@ -922,60 +1274,165 @@ func (s *URLService) CreateEphemeralURLFromTemplate(ctx context.Context, project
 	}
 
 	// 生成路径（优先使用用户指定的路径）
-	var path string
-	if req.Path != "" {
-		// 验证自定义路径格式
-		if !strings.HasPrefix(req.Path, "/") {
-			req.Path = "/" + req.Path
-		}
-		// 检查路径是否已存在
-		var count int
-		query := `SELECT COUNT(*) FROM ephemeral_urls WHERE project_id = $1 AND path = $2`
-		err := s.db.QueryRowContext(ctx, query, projectID, req.Path).Scan(&count)
+	path, err := s.resolveTemplateURLPath(ctx, project, req.Path, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 渲染模版并构建URL记录
+	url, processedYAML, err := s.renderTemplateURL(ctx, project, path, req.TemplateID, req.TTLSeconds, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	// 覆盖镜像
+	if opts.image != "" {
+		processedYAML, err = utils.SetWorkloadImage(processedYAML, opts.image)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check path uniqueness: %w", err)
+			return nil, fmt.Errorf("failed to set image: %w", err)
 		}
-		if count > 0 {
-			return nil, fmt.Errorf("path '%s' already exists in this project", req.Path)
+		url.Image = opts.image
+	}
+
+	// 记录来源PR
+	if opts.git != nil {
+		url.GitRepository = stringPtr(opts.git.Repository)
+		url.GitBranch = stringPtr(opts.git.Branch)
+		url.GitCommit = stringPtr(opts.git.CommitSHA)
+		if opts.git.PRNumber > 0 {
+			prNumber := opts.git.PRNumber
+			url.GitPRNumber = &prNumber
 		}
-		path = req.Path
-	} else {
+	}
+
+	// 开始事务处理
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 插入数据库记录
+	if err := s.insertURLRecordWithTemplate(ctx, tx, url); err != nil {
+		return nil, fmt.Errorf("failed to insert URL record: %w", err)
+	}
+
+	// 累加模版使用次数
+	if err := s.recordTemplateUsage(ctx, tx, req.TemplateID); err != nil {
+		return nil, fmt.Errorf("failed to record template usage: %w", err)
+	}
+
+	// 在开发环境中，不实际创建Kubernetes资源，只保存到数据库；生产环境由异步操作按渲染后的YAML创建资源
+	var op *models.Operation
+	if s.config.Environment != "development" {
+		op = newOperation(s.config, models.OperationCreate, url, models.OperationData{"yaml": processedYAML})
+		if err := enqueueOperation(ctx, tx, op); err != nil {
+			return nil, err
+		}
+	}
+
+	// 提交事务
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if op == nil {
+		// 开发环境：设置为draft状态，不部署
+		s.updateURLStatus(ctx, url.ID, "draft", "")
+		logrus.Info("URL created from template in draft mode (development environment)")
+	}
+
+	// 构建URL
+	fullURL := s.publicURL(project, path, url.TLSReady)
+
+	logrus.WithFields(logrus.Fields{
+		"url_id":      url.ID,
+		"project_id":  projectID,
+		"template_id": req.TemplateID,
+		"path":        path,
+	}).Info("Ephemeral URL from template accepted")
+
+	return s.createResponse(url, fullURL, op), nil
+}
+
+// resolveTemplateURLPath 校验用户指定的路径，未指定时生成随机路径
+func (s *URLService) resolveTemplateURLPath(ctx context.Context, project *models.Project, requested string, templateID uuid.UUID) (string, error) {
+	projectID := project.ID
+	if requested == "" {
 		// 生成随机路径
-		path, err = s.generateUniquePath(ctx, projectID, fmt.Sprintf("template-%s", req.TemplateID.String()))
+		path, err := s.generateUniquePath(ctx, projectID, fmt.Sprintf("template-%s", templateID.String()))
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate unique path: %w", err)
+			return "", fmt.Errorf("failed to generate unique path: %w", err)
 		}
+		return path, nil
+	}
+
+	// 验证自定义路径格式
+	if !strings.HasPrefix(requested, "/") {
+		requested = "/" + requested
 	}
+	// 子域名模式下路径作为子域名使用，必须是合法的DNS标签
+	if project.RoutingMode == models.RoutingModeSubdomain && !subdomainPathPattern.MatchString(requested) {
+		return "", fmt.Errorf("invalid path '%s': subdomain routing requires a lowercase alphanumeric path without '/' of at most 63 characters", requested)
+	}
+	// 检查路径是否已存在
+	var count int
+	err := s.db.QueryRowContext(ctx, pathCountQuery, projectID, requested).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to check path uniqueness: %w", err)
+	}
+	if count > 0 {
+		return "", fmt.Errorf("path '%s' already exists in this project", requested)
+	}
+	return requested, nil
+}
 
+// renderTemplateURL 渲染用户可见的模版并构建URL记录，返回记录和渲染后的YAML
+func (s *URLService) renderTemplateURL(ctx context.Context, project *models.Project, path string, templateID uuid.UUID, ttlSeconds int, userID uuid.UUID, isAdmin bool) (*models.EphemeralURL, string, error) {
 	// 生成全局唯一的资源名称
 	baseID := uuid.New().String()[:8]
 
+	// 模版自行声明Ingress时通过 ${HOST} 和 ${TLS_SECRET_NAME} 使用项目的路由和证书设置
+	host := s.urlHost(project, path)
+	tlsSecretName := s.tlsSecretName(project, host)
+	tlsSecretVariable := ""
+	if tlsSecretName != nil {
+		tlsSecretVariable = *tlsSecretName
+	}
+
 	// 生成模版变量
 	variables := map[string]string{
 		"PATH":            strings.TrimPrefix(path, "/"),
 		"SERVICE_NAME":    fmt.Sprintf("svc-ephemeral-%s", baseID),
 		"DEPLOYMENT_NAME": fmt.Sprintf("ephemeral-%s", baseID),
 		"PROJECT_NAME":    project.Name,
+		"HOST":            host,
+		"TLS_SECRET_NAME": tlsSecretVariable,
 		"UUID":            baseID,
 	}
 
-	// 获取模板信息
-	template, err := s.templateService.GetTemplate(ctx, req.TemplateID)
+	// 获取模板信息，用户不可见的模版按不存在处理
+	template, err := s.templateService.GetTemplateForUser(ctx, templateID, userID, isAdmin)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get template: %w", err)
+		return nil, "", err
+	}
+
+	// 项目可见范围的模版只能在关联项目中使用
+	if template.Visibility == models.TemplateVisibilityProject && (template.ProjectID == nil || *template.ProjectID != project.ID) {
+		return nil, "", fmt.Errorf("template is not available in this project")
 	}
 
 	// 处理模版，获取处理后的YAML
-	processedYAML, err := s.templateService.ProcessTemplate(ctx, req.TemplateID, variables)
+	processedYAML, err := renderTemplate(template, variables)
 	if err != nil {
-		return nil, fmt.Errorf("failed to process template: %w", err)
+		return nil, "", fmt.Errorf("failed to process template: %w", err)
 	}
 
 	// 从模板解析规格创建URL记录
 	url := &models.EphemeralURL{
 		ID:         uuid.New(),
-		ProjectID:  projectID,
-		TemplateID: &req.TemplateID,
+		ProjectID:  project.ID,
+		TemplateID: &templateID,
 		Path:       path,
 		Image:      template.ParsedSpec.Image,     // 使用模板中解析的镜像
 		Env:        template.ParsedSpec.Env,       // 使用模板中的环境变量
@@ -987,7 +1444,7 @@ func (s *URLService) CreateEphemeralURLFromTemplate(ctx context.Context, project
 			WorkingDir: template.ParsedSpec.WorkingDir, // 使用模板中的工作目录
 		},
 		Status:     models.StatusCreating,
-		TTLSeconds: req.TTLSeconds,                 // 保存TTL值
+		TTLSeconds: ttlSeconds,                     // 保存TTL值
 		ExpireAt:   time.Now().Add(24 * time.Hour), // 临时过期时间
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
@@ -996,58 +1453,21 @@ func (s *URLService) CreateEphemeralURLFromTemplate(ctx context.Context, project
 	// 生成K8s资源名称
 	deploymentName := variables["DEPLOYMENT_NAME"]
 	serviceName := variables["SERVICE_NAME"]
-	url.K8sDeploymentName = &deploymentName
-	url.K8sServiceName = &serviceName
-
-	// 开始事务处理
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// 插入数据库记录
-	if err := s.insertURLRecordWithTemplate(ctx, tx, url); err != nil {
-		return nil, fmt.Errorf("failed to insert URL record: %w", err)
-	}
-
-	// 在开发环境中，不实际创建Kubernetes资源，只保存到数据库
-	if s.config.Environment == "development" {
-		// 开发环境：设置为draft状态，不部署
-		s.updateURLStatus(ctx, url.ID, "draft", "")
-		logrus.Info("URL created from template in draft mode (development environment)")
-	} else {
-		// 生产环境：实际创建Kubernetes资源
-		if err := s.createKubernetesResourcesFromYAML(ctx, url, processedYAML); err != nil {
-			logrus.WithError(err).Error("Failed to create Kubernetes resources from template")
-			// 更新状态为失败
-			s.updateURLStatus(ctx, url.ID, models.StatusFailed, err.Error())
-			return nil, fmt.Errorf("failed to create Kubernetes resources: %w", err)
+	// Kustomize模版的资源名称由base名称加nameSuffix组成，以构建结果为准
+	if template.TemplateType == models.TemplateTypeKustomize {
+		if name := utils.FindResourceName(processedYAML, "Deployment"); name != "" {
+			deploymentName = name
+		}
+		if name := utils.FindResourceName(processedYAML, "Service"); name != "" {
+			serviceName = name
 		}
 	}
+	url.K8sDeploymentName = &deploymentName
+	url.K8sServiceName = &serviceName
+	url.TLSSecretName = tlsSecretName
+	url.TLSReady = tlsSecretName != nil && s.tlsSecretReady(ctx, *tlsSecretName)
 
-	// 提交事务
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	// 异步验证部署状态
-	go s.verifyDeployment(url)
-
-	// 构建URL
-	fullURL := fmt.Sprintf("https://%s%s", s.config.K8s.DefaultDomain, path)
-
-	logrus.WithFields(logrus.Fields{
-		"url_id":      url.ID,
-		"project_id":  projectID,
-		"template_id": req.TemplateID,
-		"path":        path,
-	}).Info("Ephemeral URL created from template successfully")
-
-	return &models.CreateEphemeralURLResponse{
-		URL: fullURL,
-		ID:  url.ID,
-	}, nil
+	return url, processedYAML, nil
 }
 
 // insertURLRecordWithTemplate 插入包含模版ID的URL记录
@@ -1056,9 +1476,10 @@ func (s *URLService) insertURLRecordWithTemplate(ctx context.Context, tx *sql.Tx
 		INSERT INTO ephemeral_urls (
 			id, project_id, template_id, path, image, env, replicas, resources, container_config, status, ttl_seconds,
 			k8s_deployment_name, k8s_service_name, k8s_secret_name,
+			git_repository, git_pr_number, git_branch, git_commit, tls_secret_name, tls_ready,
 			expire_at, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
 		)
 	`
 
@@ -1066,12 +1487,20 @@ func (s *URLService) insertURLRecordWithTemplate(ctx context.Context, tx *sql.Tx
 		url.ID, url.ProjectID, url.TemplateID, url.Path, url.Image,
 		url.Env, url.Replicas, url.Resources, url.ContainerConfig,
 		url.Status, url.TTLSeconds, url.K8sDeploymentName, url.K8sServiceName, url.K8sSecretName,
+		url.GitRepository, url.GitPRNumber, url.GitBranch, url.GitCommit, url.TLSSecretName, url.TLSReady,
 		url.ExpireAt, url.CreatedAt, url.UpdatedAt,
 	)
 
 	return err
 }
 
+// recordTemplateUsage 更新模版的使用次数和最近使用时间
+func (s *URLService) recordTemplateUsage(ctx context.Context, tx *sql.Tx, templateID uuid.UUID) error {
+	query := `UPDATE app_templates SET usage_count = usage_count + 1, last_used_at = $1 WHERE id = $2`
+	_, err := tx.ExecContext(ctx, query, time.Now(), templateID)
+	return err
+}
+
 // createKubernetesResourcesFromYAML 从处理后的YAML创建 Kubernetes 资源
 func (s *URLService) createKubernetesResourcesFromYAML(ctx context.Context, url *models.EphemeralURL, yamlSpec string) error {
 	if s.resourceManager == nil {
@@ -1085,15 +1514,13 @@ func (s *URLService) createKubernetesResourcesFromYAML(ctx context.Context, url
 		return fmt.Errorf("failed to create resources from YAML: %w", err)
 	}
 
-	// 更新状态为waiting（等待Pod Ready）
-	s.updateURLStatus(ctx, url.ID, models.StatusWaiting, "")
-
 	return nil
 }
 
 // StartPodMonitor 启动Pod状态监控服务
 func (s *URLService) StartPodMonitor() {
 	logrus.Info("Starting Pod status monitor")
+	s.resumeRolloutWatches()
 
 	ticker := time.NewTicker(30 * time.Second) // 每30秒检查一次
 	defer ticker.Stop()
@@ -1102,7 +1529,112 @@ func (s *URLService) StartPodMonitor() {
 		select {
 		case <-ticker.C:
 			s.monitorPodStatus()
+			s.monitorCertificates()
+		}
+	}
+}
+
+// MigrateSharedIngresses 将旧版本每个项目共用的Ingress拆分为每条路由独立的路由资源
+// 启动时执行一次，已拆分的项目不再有共用Ingress，重复执行没有副作用。
+func (s *URLService) MigrateSharedIngresses() {
+	if s.ingressManager == nil {
+		return
+	}
+	ctx := context.Background()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT name FROM projects")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list projects for ingress migration")
+		return
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			logrus.WithError(err).Error("Failed to scan project name")
+			continue
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+
+	for _, name := range names {
+		migrated, err := s.ingressManager.SplitSharedIngresses(ctx, name)
+		if err != nil {
+			logrus.WithError(err).WithField("project", name).Error("Failed to split shared project ingress")
+			continue
+		}
+		if migrated > 0 {
+			logrus.WithFields(logrus.Fields{
+				"project": name,
+				"routes":  migrated,
+			}).Info("Split shared project ingress into per-route resources")
+		}
+	}
+}
+
+// monitorCertificates 检查等待证书的URL，证书Secret就绪后改用https访问地址
+func (s *URLService) monitorCertificates() {
+	if s.resourceManager == nil {
+		return
+	}
+	ctx := context.Background()
+
+	query := `
+		SELECT id, tls_secret_name
+		FROM ephemeral_urls
+		WHERE status IN ($1, $2, $3) AND tls_secret_name IS NOT NULL AND tls_ready = false
+	`
+	rows, err := s.db.QueryContext(ctx, query, models.StatusWaiting, models.StatusActive, models.StatusDegraded)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query URLs waiting for certificates")
+		return
+	}
+
+	pending := make(map[uuid.UUID]string)
+	for rows.Next() {
+		var id uuid.UUID
+		var secretName string
+		if err := rows.Scan(&id, &secretName); err != nil {
+			logrus.WithError(err).Error("Failed to scan URL waiting for certificate")
+			continue
+		}
+		pending[id] = secretName
+	}
+	rows.Close()
+
+	// 同一Secret可能被多个URL共用，每轮只检查一次
+	checked := make(map[string]bool)
+	for id, secretName := range pending {
+		ready, ok := checked[secretName]
+		if !ok {
+			ready = s.tlsSecretReady(ctx, secretName)
+			checked[secretName] = ready
+		}
+		if !ready {
+			continue
+		}
+
+		logEntry := models.LogEntry{
+			Timestamp: time.Now(),
+			Level:     "info",
+			Message:   "TLS证书已就绪",
+			Details:   fmt.Sprintf("证书Secret: %s", secretName),
+		}
+		logsJSON, _ := json.Marshal([]models.LogEntry{logEntry})
+		_, err := s.db.ExecContext(ctx, `
+			UPDATE ephemeral_urls
+			SET tls_ready = true, logs = logs || $2::jsonb, updated_at = NOW()
+			WHERE id = $1
+		`, id, string(logsJSON))
+		if err != nil {
+			logrus.WithError(err).WithField("url_id", id).Error("Failed to mark certificate ready")
+			continue
 		}
+		logrus.WithFields(logrus.Fields{
+			"url_id": id,
+			"secret": secretName,
+		}).Info("TLS certificate ready")
 	}
 }
 
@@ -1110,11 +1642,11 @@ func (s *URLService) StartPodMonitor() {
 func (s *URLService) monitorPodStatus() {
 	ctx := context.Background()
 
-	// 查找所有处于waiting状态的URL
+	// 查找所有处于waiting状态的URL（环境成员由环境部署流程等待就绪）
 	query := `
-		SELECT id, k8s_deployment_name, ttl_seconds, created_at
+		SELECT id, k8s_deployment_name, ttl_seconds, container_config, COALESCE(deployment_requested_at, created_at)
 		FROM ephemeral_urls 
-		WHERE status = $1 AND k8s_deployment_name IS NOT NULL
+		WHERE status = $1 AND k8s_deployment_name IS NOT NULL AND stack_id IS NULL
 	`
 
 	rows, err := s.db.QueryContext(ctx, query, models.StatusWaiting)
@@ -1126,21 +1658,22 @@ func (s *URLService) monitorPodStatus() {
 
 	for rows.Next() {
 		var (
-			urlID          uuid.UUID
-			deploymentName sql.NullString
-			ttlSeconds     int
-			createdAt      time.Time
+			urlID           uuid.UUID
+			deploymentName  sql.NullString
+			ttlSeconds      int
+			containerConfig models.ContainerConfig
+			createdAt       time.Time
 		)
 
-		if err := rows.Scan(&urlID, &deploymentName, &ttlSeconds, &createdAt); err != nil {
+		if err := rows.Scan(&urlID, &deploymentName, &ttlSeconds, &containerConfig, &createdAt); err != nil {
 			logrus.WithError(err).Error("Failed to scan waiting URL")
 			continue
 		}
 
-		// 检查是否超时（创建后15分钟还没Ready）
-		if time.Since(createdAt) > 15*time.Minute {
+		// 检查是否超时（创建或最近一次部署后超过启动超时还没Ready，重新部署和自动重试从部署时间起算）
+		if limit := startupTimeout(s.config.Readiness, containerConfig); time.Since(createdAt) > limit {
 			logrus.WithField("url_id", urlID).Warn("URL has been waiting too long, marking as failed")
-			s.updateURLStatus(ctx, urlID, models.StatusFailed, "Pod failed to become ready within 15 minutes")
+			s.updateURLStatus(ctx, urlID, models.StatusFailed, fmt.Sprintf("Pod failed to become ready within %s", limit))
 			continue
 		}
 
@@ -1170,7 +1703,7 @@ func (s *URLService) monitorPodStatus() {
 				// 更新数据库：设置 started_at, expire_at 和 status
 				updateQuery := `
 					UPDATE ephemeral_urls 
-					SET started_at = $1, expire_at = $2, status = $3, updated_at = $4
+					SET started_at = $1, expire_at = $2, status = $3, updated_at = $4, retry_count = 0
 					WHERE id = $5
 				`
 				_, err = s.db.ExecContext(ctx, updateQuery, now, expireAt, models.StatusActive, now, urlID)
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"url-manager-system/backend/internal/config"
+	"url-manager-system/backend/internal/db/models"
+	"url-manager-system/backend/internal/k8s"
+
+	"github.com/google/uuid"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// fakeStore 内存中的ephemeral_urls和projects表，只支持创建URL和按ID读取URL的语句
+type fakeStore struct {
+	mu       sync.Mutex
+	urls     map[string]map[string]driver.Value
+	projects map[string]map[string]driver.Value
+}
+
+// fakeURLDefaults 插入语句未指定的列在数据库中的默认值
+var fakeURLDefaults = map[string]driver.Value{"retry_count": int64(0)}
+
+func (s *fakeStore) Connect(context.Context) (driver.Conn, error) { return &fakeConn{store: s}, nil }
+func (s *fakeStore) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ store *fakeStore }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if !strings.Contains(query, "INSERT INTO ephemeral_urls") {
+		return nil, fmt.Errorf("unsupported statement: %s", query)
+	}
+	columns := splitColumns(query[strings.Index(query, "(")+1 : strings.Index(query, ")")])
+	row := make(map[string]driver.Value, len(columns))
+	for i, column := range columns {
+		row[column] = args[i].Value
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	c.store.urls[fmt.Sprint(row["id"])] = row
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.Contains(query, "FROM ephemeral_urls eu") {
+		return nil, fmt.Errorf("unsupported query: %s", query)
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	rows := &fakeRows{columns: splitColumns(query[strings.Index(query, "SELECT")+len("SELECT") : strings.Index(query, "FROM")])}
+	urlRow, ok := c.store.urls[fmt.Sprint(args[0].Value)]
+	if !ok {
+		return rows, nil
+	}
+	projectRow := c.store.projects[fmt.Sprint(urlRow["project_id"])]
+
+	values := make([]driver.Value, len(rows.columns))
+	for i, column := range rows.columns {
+		switch {
+		case strings.HasPrefix(column, "eu."):
+			name := strings.TrimPrefix(column, "eu.")
+			value, ok := urlRow[name]
+			if !ok {
+				value = fakeURLDefaults[name]
+			}
+			values[i] = value
+		case strings.HasPrefix(column, "p."):
+			values[i] = projectRow[strings.TrimPrefix(column, "p.")]
+		}
+	}
+	rows.values = [][]driver.Value{values}
+	return rows, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func splitColumns(list string) []string {
+	var columns []string
+	for _, column := range strings.Split(list, ",") {
+		columns = append(columns, strings.TrimSpace(column))
+	}
+	return columns
+}
+
+// fakeAPIServer 记录提交的Deployment，其他请求返回404
+func fakeAPIServer(t *testing.T) (*k8s.ResourceManager, func() *appsv1.Deployment) {
+	var (
+		mu       sync.Mutex
+		captured *appsv1.Deployment
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/deployments") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			return
+		}
+		deployment := &appsv1.Deployment{}
+		if err := json.NewDecoder(r.Body).Decode(deployment); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		captured = deployment
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(deployment)
+	}))
+	t.Cleanup(server.Close)
+
+	// 客户端从HOME下的kubeconfig读取API Server地址
+	home := t.TempDir()
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user: {}
+`, server.URL)
+	if err := os.MkdirAll(filepath.Join(home, ".kube"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".kube", "config"), []byte(kubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+
+	client, err := k8s.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return k8s.NewResourceManager(client, "test"), func() *appsv1.Deployment {
+		mu.Lock()
+		defer mu.Unlock()
+		return captured
+	}
+}
+
+// createThroughWorker 按创建接口构建并写入URL记录，再像创建操作一样重新读取记录并创建Deployment
+// 返回重新读取的URL和提交到集群的Deployment容器。
+func createThroughWorker(t *testing.T, req *models.CreateEphemeralURLRequest) (*models.EphemeralURL, corev1.Container) {
+	t.Helper()
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	store := &fakeStore{
+		urls: map[string]map[string]driver.Value{},
+		projects: map[string]map[string]driver.Value{projectID.String(): {
+			"id": projectID.String(), "name": "demo", "description": "", "routing_mode": models.RoutingModePath,
+			"tls_mode": models.TLSModeNone, "drift_policy": "", "retry_policy": []byte(`{}`),
+			"created_at": time.Now(), "updated_at": time.Now(),
+		}},
+	}
+	db := sql.OpenDB(store)
+	defer db.Close()
+
+	resourceManager, deployment := fakeAPIServer(t)
+	cfg := &config.Config{
+		K8s:       config.K8sConfig{DefaultDomain: "example.com"},
+		Security:  config.SecurityConfig{MaxReplicas: 3, MaxTTLSeconds: 86400, DefaultCPULimit: "500m", DefaultMemLimit: "512Mi"},
+		Readiness: config.ReadinessConfig{StartupTimeout: 15 * time.Minute, MaxStartupTimeout: time.Hour},
+	}
+	s := NewURLService(db, resourceManager, nil, nil, cfg)
+
+	if err := s.validateImageURLRequest(req); err != nil {
+		t.Fatalf("validateImageURLRequest() error = %v", err)
+	}
+	url := s.buildImageURL(projectID, "/demo", req)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.insertURLRecord(ctx, tx, url); err != nil {
+		t.Fatalf("insertURLRecord() error = %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := s.loadOperationURL(ctx, &models.Operation{URLID: url.ID})
+	if err != nil {
+		t.Fatalf("loadOperationURL() error = %v", err)
+	}
+	if err := s.resourceManager.CreateOrUpdateDeployment(ctx, stored); err != nil {
+		t.Fatalf("CreateOrUpdateDeployment() error = %v", err)
+	}
+
+	created := deployment()
+	if created == nil || len(created.Spec.Template.Spec.Containers) != 1 {
+		t.Fatalf("no deployment submitted to the API server")
+	}
+	return stored, created.Spec.Template.Spec.Containers[0]
+}
+
+func TestCreateURLKeepsContainerConfig(t *testing.T) {
+	_, container := createThroughWorker(t, &models.CreateEphemeralURLRequest{
+		Image:      "nginx:latest",
+		TTLSeconds: 3600,
+		ContainerConfig: models.ContainerConfig{
+			Command:    []string{"/bin/server"},
+			Args:       []string{"--port", "80"},
+			WorkingDir: "/app",
+			TTY:        true,
+		},
+	})
+
+	if strings.Join(container.Command, " ") != "/bin/server" || strings.Join(container.Args, " ") != "--port 80" {
+		t.Errorf("container command = %v %v, want /bin/server --port 80", container.Command, container.Args)
+	}
+	if container.WorkingDir != "/app" || !container.TTY {
+		t.Errorf("container working dir = %q tty = %v, want /app true", container.WorkingDir, container.TTY)
+	}
+}
+
+func TestCreateURLAppliesProbesAndStartupTimeout(t *testing.T) {
+	stored, container := createThroughWorker(t, &models.CreateEphemeralURLRequest{
+		Image:      "nginx:latest",
+		TTLSeconds: 3600,
+		ContainerConfig: models.ContainerConfig{
+			ReadinessProbe:        &models.ContainerProbe{Type: models.ProbeTypeHTTP, Path: "/healthz", Port: 8080},
+			LivenessProbe:         &models.ContainerProbe{Type: models.ProbeTypeExec, Command: []string{"cat", "/tmp/alive"}},
+			StartupTimeoutSeconds: 600,
+		},
+	})
+
+	readiness := container.ReadinessProbe
+	if readiness == nil || readiness.HTTPGet == nil {
+		t.Fatalf("readiness probe = %v, want HTTPGet action", readiness)
+	}
+	if readiness.HTTPGet.Path != "/healthz" || readiness.HTTPGet.Port.IntValue() != 8080 {
+		t.Errorf("readiness probe = %s:%d, want /healthz:8080", readiness.HTTPGet.Path, readiness.HTTPGet.Port.IntValue())
+	}
+	liveness := container.LivenessProbe
+	if liveness == nil || liveness.Exec == nil || strings.Join(liveness.Exec.Command, " ") != "cat /tmp/alive" {
+		t.Errorf("liveness probe = %v, want exec cat /tmp/alive", liveness)
+	}
+
+	if got := startupTimeout(config.ReadinessConfig{StartupTimeout: 15 * time.Minute}, stored.ContainerConfig); got != 10*time.Minute {
+		t.Errorf("startupTimeout() = %v, want 10m0s", got)
+	}
+}
+
+func TestStartupTimeoutDefault(t *testing.T) {
+	if got := startupTimeout(config.ReadinessConfig{}, models.ContainerConfig{}); got != defaultStartupTimeout {
+		t.Errorf("startupTimeout() with zero config = %v, want %v", got, defaultStartupTimeout)
+	}
+}
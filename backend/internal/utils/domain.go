@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// domainLabelPattern 域名中的单个标签
+var domainLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// DomainChallengePath HTTP验证方式下验证文件的路径
+const DomainChallengePath = "/.well-known/url-manager-verification.txt"
+
+// NormalizeDomain 规范化并校验自定义域名：转为小写、去掉末尾的点，至少包含两级且不能是IP地址
+func NormalizeDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" || len(domain) > 253 {
+		return "", fmt.Errorf("invalid domain: length must be between 1 and 253")
+	}
+	if net.ParseIP(domain) != nil {
+		return "", fmt.Errorf("invalid domain: IP addresses are not allowed")
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("invalid domain: %s must contain at least two labels", domain)
+	}
+	for _, label := range labels {
+		if !domainLabelPattern.MatchString(label) {
+			return "", fmt.Errorf("invalid domain: label '%s' must consist of lowercase letters, digits and '-'", label)
+		}
+	}
+	return domain, nil
+}
+
+// IsDomainWithin 判断域名是否为parent本身或其子域名，parent可以是 *. 开头的泛域名
+func IsDomainWithin(domain, parent string) bool {
+	parent = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(parent), "*"), ".")
+	if parent == "" {
+		return false
+	}
+	return domain == parent || strings.HasSuffix(domain, "."+parent)
+}
+
+// DomainChallengeRecord DNS验证方式下需要添加TXT记录的名称
+func DomainChallengeRecord(domain string) string {
+	return "_url-manager-challenge." + domain
+}
+
+// DomainChallengeValue TXT记录值及验证文件内容
+func DomainChallengeValue(token string) string {
+	return "url-manager-verification=" + token
+}
+
+// IsPublicIP 判断是否为公网地址，用于拒绝访问回环、内网和链路本地地址
+func IsPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
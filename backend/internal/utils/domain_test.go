@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"net"
+	"testing"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		domain   string
+		expected string
+		wantErr  bool
+	}{
+		{name: "valid", domain: "demo.example.com", expected: "demo.example.com"},
+		{name: "uppercase and trailing dot", domain: " Demo.Example.COM. ", expected: "demo.example.com"},
+		{name: "single label", domain: "localhost", wantErr: true},
+		{name: "ip address", domain: "10.0.0.1", wantErr: true},
+		{name: "wildcard", domain: "*.example.com", wantErr: true},
+		{name: "leading hyphen", domain: "-demo.example.com", wantErr: true},
+		{name: "empty label", domain: "demo..example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeDomain(tt.domain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("NormalizeDomain() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsDomainWithin(t *testing.T) {
+	tests := []struct {
+		domain   string
+		parent   string
+		expected bool
+	}{
+		{domain: "example.com", parent: "example.com", expected: true},
+		{domain: "a.preview.example.com", parent: "*.preview.example.com", expected: true},
+		{domain: "badexample.com", parent: "example.com", expected: false},
+		{domain: "example.com", parent: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain+"/"+tt.parent, func(t *testing.T) {
+			if got := IsDomainWithin(tt.domain, tt.parent); got != tt.expected {
+				t.Errorf("IsDomainWithin() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected bool
+	}{
+		{ip: "93.184.216.34", expected: true},
+		{ip: "127.0.0.1", expected: false},
+		{ip: "10.96.0.1", expected: false},
+		{ip: "169.254.169.254", expected: false},
+		{ip: "::1", expected: false},
+		{ip: "fd00::1", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := IsPublicIP(net.ParseIP(tt.ip)); got != tt.expected {
+				t.Errorf("IsPublicIP(%s) = %v, want %v", tt.ip, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// maxCatalogFileSize 模版目录中单个文件的大小上限
+const maxCatalogFileSize = 1 << 20
+
+// GitCatalogOptions Git模版目录读取选项
+type GitCatalogOptions struct {
+	Repository string // 远程仓库URL或本地仓库路径（支持 file:// 前缀）
+	Branch     string // 为空时使用仓库默认分支
+	Path       string // 仓库内存放模版包的目录
+	Token      string // HTTPS访问令牌，可选
+}
+
+// GitCatalogSnapshot 模版目录在某个提交上的快照
+type GitCatalogSnapshot struct {
+	Revision string            // 提交哈希
+	Files    map[string][]byte // 相对于目录路径的文件名 -> 内容，仅包含 .yaml/.yml 文件
+}
+
+// ReadGitCatalog 读取Git仓库中的模版包文件
+// 远程仓库以浅克隆方式读入内存，不写本地磁盘，也不依赖git命令行。
+func ReadGitCatalog(ctx context.Context, opts GitCatalogOptions) (*GitCatalogSnapshot, error) {
+	if opts.Repository == "" {
+		return nil, fmt.Errorf("catalog repository is required")
+	}
+
+	repo, err := openCatalogRepository(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var ref *plumbing.Reference
+	if opts.Branch != "" {
+		ref, err = repo.Reference(plumbing.NewBranchReferenceName(opts.Branch), true)
+		if err != nil {
+			// 浅克隆时分支只存在于远程跟踪引用下
+			ref, err = repo.Reference(plumbing.NewRemoteReferenceName("origin", opts.Branch), true)
+		}
+	} else {
+		ref, err = repo.Head()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve catalog branch: %w", err)
+	}
+
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog commit: %w", err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog tree: %w", err)
+	}
+
+	dir := strings.Trim(path.Clean("/"+opts.Path), "/")
+	if dir != "" {
+		tree, err = tree.Tree(dir)
+		if err != nil {
+			return nil, fmt.Errorf("catalog path '%s' not found in repository: %w", dir, err)
+		}
+	}
+
+	snapshot := &GitCatalogSnapshot{
+		Revision: commit.Hash.String(),
+		Files:    make(map[string][]byte),
+	}
+
+	err = tree.Files().ForEach(func(file *object.File) error {
+		ext := strings.ToLower(path.Ext(file.Name))
+		if ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		if file.Size > maxCatalogFileSize {
+			return fmt.Errorf("catalog file '%s' exceeds %d bytes", file.Name, maxCatalogFileSize)
+		}
+
+		reader, err := file.Reader()
+		if err != nil {
+			return fmt.Errorf("failed to open catalog file '%s': %w", file.Name, err)
+		}
+		defer reader.Close()
+
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read catalog file '%s': %w", file.Name, err)
+		}
+		snapshot.Files[file.Name] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// openCatalogRepository 打开本地仓库或将远程仓库克隆到内存
+func openCatalogRepository(ctx context.Context, opts GitCatalogOptions) (*git.Repository, error) {
+	if localPath, ok := localRepositoryPath(opts.Repository); ok {
+		repo, err := git.PlainOpen(localPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open catalog repository: %w", err)
+		}
+		return repo, nil
+	}
+
+	cloneOpts := &git.CloneOptions{
+		URL:          opts.Repository,
+		Depth:        1,
+		SingleBranch: true,
+		Tags:         git.NoTags,
+	}
+	if opts.Branch != "" {
+		cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(opts.Branch)
+	}
+	if opts.Token != "" {
+		cloneOpts.Auth = &http.BasicAuth{Username: "git", Password: opts.Token}
+	}
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, cloneOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone catalog repository: %w", err)
+	}
+	return repo, nil
+}
+
+// localRepositoryPath 判断仓库地址是否为本地路径
+func localRepositoryPath(repository string) (string, bool) {
+	if strings.HasPrefix(repository, "file://") {
+		return strings.TrimPrefix(repository, "file://"), true
+	}
+	if strings.Contains(repository, "://") || strings.Contains(repository, "@") {
+		return "", false
+	}
+	return repository, true
+}
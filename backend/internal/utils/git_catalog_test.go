@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// newTestCatalogRepository 创建包含给定文件的bare仓库，返回仓库路径和提交哈希
+func newTestCatalogRepository(t *testing.T, files map[string]string) (string, string) {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "catalog.git")
+	storage := filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault())
+	worktreeFS := memfs.New()
+
+	repo, err := git.Init(storage, worktreeFS)
+	if err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	for name, content := range files {
+		file, err := worktreeFS.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		file.Close()
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+	}
+
+	hash, err := worktree.Commit("add templates", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	return dir, hash.String()
+}
+
+func TestReadGitCatalog(t *testing.T) {
+	dir, revision := newTestCatalogRepository(t, map[string]string{
+		"templates/web.yaml":        "apiVersion: url-manager-system/v1\n",
+		"templates/team/api.yml":    "apiVersion: url-manager-system/v1\n",
+		"templates/README.md":       "# catalog\n",
+		"other/ignored.yaml":        "kind: Ignored\n",
+		"templates/team/notes.json": "{}",
+	})
+
+	snapshot, err := ReadGitCatalog(context.Background(), GitCatalogOptions{
+		Repository: "file://" + dir,
+		Path:       "templates",
+	})
+	if err != nil {
+		t.Fatalf("ReadGitCatalog() error = %v", err)
+	}
+
+	if snapshot.Revision != revision {
+		t.Errorf("revision = %s, expected %s", snapshot.Revision, revision)
+	}
+	if len(snapshot.Files) != 2 {
+		t.Errorf("files = %v, expected web.yaml and team/api.yml", snapshot.Files)
+	}
+	for _, name := range []string{"web.yaml", "team/api.yml"} {
+		if _, ok := snapshot.Files[name]; !ok {
+			t.Errorf("file %s not found in snapshot", name)
+		}
+	}
+}
+
+func TestReadGitCatalogErrors(t *testing.T) {
+	dir, _ := newTestCatalogRepository(t, map[string]string{
+		"templates/web.yaml": "apiVersion: url-manager-system/v1\n",
+	})
+
+	tests := []struct {
+		name string
+		opts GitCatalogOptions
+	}{
+		{name: "missing path", opts: GitCatalogOptions{Repository: dir, Path: "missing"}},
+		{name: "missing branch", opts: GitCatalogOptions{Repository: dir, Branch: "missing", Path: "templates"}},
+		{name: "missing repository", opts: GitCatalogOptions{Repository: filepath.Join(dir, "missing")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadGitCatalog(context.Background(), tt.opts); err == nil {
+				t.Errorf("ReadGitCatalog() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"url-manager-system/backend/internal/db/models"
+)
+
+// zeroCommitSHA 分支被删除时推送事件中的提交哈希
+const zeroCommitSHA = "0000000000000000000000000000000000000000"
+
+// imageTagInvalidChars 镜像标签中不允许的字符
+var imageTagInvalidChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// imageTagSeparators 连续的分隔符
+var imageTagSeparators = regexp.MustCompile(`[._-]{2,}`)
+
+// VerifyGitHubSignature 校验GitHub的 X-Hub-Signature-256 请求头
+func VerifyGitHubSignature(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// githubPayload GitHub pull_request 和 push 事件中用到的字段
+type githubPayload struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	Ref         string `json:"ref"`
+	After       string `json:"after"`
+	Deleted     bool   `json:"deleted"`
+	PullRequest struct {
+		Head struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// gitlabPayload GitLab Merge Request Hook 和 Push Hook 中用到的字段
+type gitlabPayload struct {
+	ObjectKind       string `json:"object_kind"`
+	Ref              string `json:"ref"`
+	After            string `json:"after"`
+	CheckoutSHA      string `json:"checkout_sha"`
+	ObjectAttributes struct {
+		IID          int    `json:"iid"`
+		Action       string `json:"action"`
+		OldRev       string `json:"oldrev"`
+		SourceBranch string `json:"source_branch"`
+		LastCommit   struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"object_attributes"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+}
+
+// ParseGitWebhookEvent 解析GitHub/GitLab的webhook载荷
+// eventType 为 X-GitHub-Event 或 X-Gitlab-Event 请求头；与预览环境无关的事件返回nil。
+func ParseGitWebhookEvent(provider, eventType string, body []byte) (*models.GitEvent, error) {
+	switch provider {
+	case models.GitProviderGitHub:
+		return parseGitHubEvent(eventType, body)
+	case models.GitProviderGitLab:
+		return parseGitLabEvent(eventType, body)
+	default:
+		return nil, fmt.Errorf("unsupported git provider %q", provider)
+	}
+}
+
+// parseGitHubEvent 解析GitHub事件
+func parseGitHubEvent(eventType string, body []byte) (*models.GitEvent, error) {
+	if eventType != "pull_request" && eventType != "push" {
+		return nil, nil
+	}
+
+	var payload githubPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	event := &models.GitEvent{Repository: payload.Repository.FullName}
+
+	if eventType == "push" {
+		if payload.Deleted || payload.After == zeroCommitSHA || !strings.HasPrefix(payload.Ref, "refs/heads/") {
+			return nil, nil
+		}
+		event.Action = models.GitEventUpdate
+		event.Branch = strings.TrimPrefix(payload.Ref, "refs/heads/")
+		event.CommitSHA = payload.After
+		return event, nil
+	}
+
+	switch payload.Action {
+	case "opened", "reopened":
+		event.Action = models.GitEventOpen
+	case "synchronize":
+		event.Action = models.GitEventUpdate
+	case "closed":
+		event.Action = models.GitEventClose
+	default:
+		return nil, nil
+	}
+	event.PRNumber = payload.Number
+	event.Branch = payload.PullRequest.Head.Ref
+	event.CommitSHA = payload.PullRequest.Head.SHA
+
+	return event, nil
+}
+
+// parseGitLabEvent 解析GitLab事件
+func parseGitLabEvent(eventType string, body []byte) (*models.GitEvent, error) {
+	if eventType != "Merge Request Hook" && eventType != "Push Hook" {
+		return nil, nil
+	}
+
+	var payload gitlabPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	event := &models.GitEvent{Repository: payload.Project.PathWithNamespace}
+
+	if eventType == "Push Hook" {
+		sha := payload.CheckoutSHA
+		if sha == "" {
+			sha = payload.After
+		}
+		if sha == "" || sha == zeroCommitSHA || !strings.HasPrefix(payload.Ref, "refs/heads/") {
+			return nil, nil
+		}
+		event.Action = models.GitEventUpdate
+		event.Branch = strings.TrimPrefix(payload.Ref, "refs/heads/")
+		event.CommitSHA = sha
+		return event, nil
+	}
+
+	attrs := payload.ObjectAttributes
+	switch attrs.Action {
+	case "open", "reopen":
+		event.Action = models.GitEventOpen
+	case "update":
+		// 只有带 oldrev 的更新才表示有新的提交，标题、标签等修改不触发重新部署
+		if attrs.OldRev == "" {
+			return nil, nil
+		}
+		event.Action = models.GitEventUpdate
+	case "close", "merge":
+		event.Action = models.GitEventClose
+	default:
+		return nil, nil
+	}
+	event.PRNumber = attrs.IID
+	event.Branch = attrs.SourceBranch
+	event.CommitSHA = attrs.LastCommit.ID
+
+	return event, nil
+}
+
+// RenderImageTag 按格式生成镜像标签，结果只包含合法的标签字符
+// 支持的占位符：{sha} {short_sha} {number} {branch}
+func RenderImageTag(format string, prNumber int, branch, commitSHA string) string {
+	shortSHA := commitSHA
+	if len(shortSHA) > 7 {
+		shortSHA = shortSHA[:7]
+	}
+
+	tag := strings.NewReplacer(
+		"{sha}", commitSHA,
+		"{short_sha}", shortSHA,
+		"{number}", strconv.Itoa(prNumber),
+		"{branch}", branch,
+	).Replace(format)
+
+	tag = imageTagInvalidChars.ReplaceAllString(strings.ToLower(tag), "-")
+	tag = imageTagSeparators.ReplaceAllString(tag, "-")
+	tag = strings.Trim(tag, "._-")
+	if len(tag) > 128 {
+		tag = strings.TrimRight(tag[:128], "._-")
+	}
+	return tag
+}
+
+// SetWorkloadImage 替换YAML中主工作负载主容器的镜像，其他内容保持不变
+func SetWorkloadImage(yamlContent, image string) (string, error) {
+	spec, err := ParseYAMLToTemplateSpec(yamlContent)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse workload: %w", err)
+	}
+	spec.Image = image
+
+	return GenerateYAMLFromTemplateSpec(spec, yamlContent)
+}
@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+
+	"url-manager-system/backend/internal/db/models"
+)
+
+func TestVerifyGitHubSignature(t *testing.T) {
+	secret := "0123456789abcdef"
+	body := []byte(`{"action":"opened"}`)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !VerifyGitHubSignature(secret, body, signature) {
+		t.Error("VerifyGitHubSignature() rejected a valid signature")
+	}
+	if VerifyGitHubSignature("another-secret-value", body, signature) {
+		t.Error("VerifyGitHubSignature() accepted a signature made with another secret")
+	}
+	if VerifyGitHubSignature(secret, []byte(`{"action":"closed"}`), signature) {
+		t.Error("VerifyGitHubSignature() accepted a tampered body")
+	}
+	for _, header := range []string{"", "sha1=abc", "sha256=not-hex"} {
+		if VerifyGitHubSignature(secret, body, header) {
+			t.Errorf("VerifyGitHubSignature() accepted malformed header %q", header)
+		}
+	}
+}
+
+func TestParseGitWebhookEvent(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  string
+		eventType string
+		body      string
+		expected  *models.GitEvent
+	}{
+		{
+			name:      "github pull request opened",
+			provider:  models.GitProviderGitHub,
+			eventType: "pull_request",
+			body:      `{"action":"opened","number":42,"pull_request":{"head":{"ref":"feature/login","sha":"abc123"}},"repository":{"full_name":"acme/web"}}`,
+			expected:  &models.GitEvent{Action: models.GitEventOpen, Repository: "acme/web", PRNumber: 42, Branch: "feature/login", CommitSHA: "abc123"},
+		},
+		{
+			name:      "github pull request synchronize",
+			provider:  models.GitProviderGitHub,
+			eventType: "pull_request",
+			body:      `{"action":"synchronize","number":42,"pull_request":{"head":{"ref":"feature/login","sha":"def456"}},"repository":{"full_name":"acme/web"}}`,
+			expected:  &models.GitEvent{Action: models.GitEventUpdate, Repository: "acme/web", PRNumber: 42, Branch: "feature/login", CommitSHA: "def456"},
+		},
+		{
+			name:      "github pull request closed",
+			provider:  models.GitProviderGitHub,
+			eventType: "pull_request",
+			body:      `{"action":"closed","number":42,"pull_request":{"head":{"ref":"feature/login","sha":"def456"}},"repository":{"full_name":"acme/web"}}`,
+			expected:  &models.GitEvent{Action: models.GitEventClose, Repository: "acme/web", PRNumber: 42, Branch: "feature/login", CommitSHA: "def456"},
+		},
+		{
+			name:      "github pull request labeled",
+			provider:  models.GitProviderGitHub,
+			eventType: "pull_request",
+			body:      `{"action":"labeled","number":42,"repository":{"full_name":"acme/web"}}`,
+		},
+		{
+			name:      "github push",
+			provider:  models.GitProviderGitHub,
+			eventType: "push",
+			body:      `{"ref":"refs/heads/main","after":"fff000","repository":{"full_name":"acme/web"}}`,
+			expected:  &models.GitEvent{Action: models.GitEventUpdate, Repository: "acme/web", Branch: "main", CommitSHA: "fff000"},
+		},
+		{
+			name:      "github branch deleted",
+			provider:  models.GitProviderGitHub,
+			eventType: "push",
+			body:      `{"ref":"refs/heads/main","after":"0000000000000000000000000000000000000000","deleted":true,"repository":{"full_name":"acme/web"}}`,
+		},
+		{
+			name:      "github tag push",
+			provider:  models.GitProviderGitHub,
+			eventType: "push",
+			body:      `{"ref":"refs/tags/v1.0.0","after":"fff000","repository":{"full_name":"acme/web"}}`,
+		},
+		{
+			name:      "github issues",
+			provider:  models.GitProviderGitHub,
+			eventType: "issues",
+			body:      `{}`,
+		},
+		{
+			name:      "gitlab merge request opened",
+			provider:  models.GitProviderGitLab,
+			eventType: "Merge Request Hook",
+			body:      `{"object_kind":"merge_request","object_attributes":{"iid":7,"action":"open","source_branch":"fix-bug","last_commit":{"id":"aaa111"}},"project":{"path_with_namespace":"group/sub/app"}}`,
+			expected:  &models.GitEvent{Action: models.GitEventOpen, Repository: "group/sub/app", PRNumber: 7, Branch: "fix-bug", CommitSHA: "aaa111"},
+		},
+		{
+			name:      "gitlab merge request new commits",
+			provider:  models.GitProviderGitLab,
+			eventType: "Merge Request Hook",
+			body:      `{"object_kind":"merge_request","object_attributes":{"iid":7,"action":"update","oldrev":"aaa111","source_branch":"fix-bug","last_commit":{"id":"bbb222"}},"project":{"path_with_namespace":"group/sub/app"}}`,
+			expected:  &models.GitEvent{Action: models.GitEventUpdate, Repository: "group/sub/app", PRNumber: 7, Branch: "fix-bug", CommitSHA: "bbb222"},
+		},
+		{
+			name:      "gitlab merge request title edited",
+			provider:  models.GitProviderGitLab,
+			eventType: "Merge Request Hook",
+			body:      `{"object_kind":"merge_request","object_attributes":{"iid":7,"action":"update","source_branch":"fix-bug","last_commit":{"id":"bbb222"}},"project":{"path_with_namespace":"group/sub/app"}}`,
+		},
+		{
+			name:      "gitlab merge request merged",
+			provider:  models.GitProviderGitLab,
+			eventType: "Merge Request Hook",
+			body:      `{"object_kind":"merge_request","object_attributes":{"iid":7,"action":"merge","source_branch":"fix-bug","last_commit":{"id":"bbb222"}},"project":{"path_with_namespace":"group/sub/app"}}`,
+			expected:  &models.GitEvent{Action: models.GitEventClose, Repository: "group/sub/app", PRNumber: 7, Branch: "fix-bug", CommitSHA: "bbb222"},
+		},
+		{
+			name:      "gitlab push",
+			provider:  models.GitProviderGitLab,
+			eventType: "Push Hook",
+			body:      `{"object_kind":"push","ref":"refs/heads/develop","after":"ccc333","checkout_sha":"ccc333","project":{"path_with_namespace":"group/app"}}`,
+			expected:  &models.GitEvent{Action: models.GitEventUpdate, Repository: "group/app", Branch: "develop", CommitSHA: "ccc333"},
+		},
+		{
+			name:      "gitlab branch deleted",
+			provider:  models.GitProviderGitLab,
+			eventType: "Push Hook",
+			body:      `{"object_kind":"push","ref":"refs/heads/develop","after":"0000000000000000000000000000000000000000","checkout_sha":null,"project":{"path_with_namespace":"group/app"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseGitWebhookEvent(tt.provider, tt.eventType, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("ParseGitWebhookEvent() error = %v", err)
+			}
+			if !reflect.DeepEqual(event, tt.expected) {
+				t.Errorf("ParseGitWebhookEvent() = %+v, expected %+v", event, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseGitWebhookEventInvalid(t *testing.T) {
+	if _, err := ParseGitWebhookEvent(models.GitProviderGitHub, "pull_request", []byte(`{`)); err == nil || !strings.Contains(err.Error(), "invalid webhook payload") {
+		t.Errorf("ParseGitWebhookEvent() error = %v, expected invalid payload error", err)
+	}
+	if _, err := ParseGitWebhookEvent("bitbucket", "push", []byte(`{}`)); err == nil {
+		t.Error("ParseGitWebhookEvent() accepted an unsupported provider")
+	}
+}
+
+func TestRenderImageTag(t *testing.T) {
+	sha := "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		format   string
+		number   int
+		branch   string
+		expected string
+	}{
+		{"{sha}", 1, "main", sha},
+		{"pr-{number}-{short_sha}", 42, "main", "pr-42-0123456"},
+		{"{branch}", 0, "Feature/Login_Page", "feature-login_page"},
+		{"{branch}-{short_sha}", 0, "-release--1.0.", "release-1.0-0123456"},
+		{"{branch}", 0, strings.Repeat("a", 200), strings.Repeat("a", 128)},
+	}
+
+	for _, tt := range tests {
+		if got := RenderImageTag(tt.format, tt.number, tt.branch, sha); got != tt.expected {
+			t.Errorf("RenderImageTag(%q, %d, %q) = %q, expected %q", tt.format, tt.number, tt.branch, got, tt.expected)
+		}
+	}
+}
+
+func TestSetWorkloadImage(t *testing.T) {
+	yamlContent := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:latest
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc-web
+spec:
+  ports:
+  - port: 80
+`
+
+	result, err := SetWorkloadImage(yamlContent, "registry.example.com/acme/web:pr-42")
+	if err != nil {
+		t.Fatalf("SetWorkloadImage() error = %v", err)
+	}
+
+	spec, err := ParseYAMLToTemplateSpec(result)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTemplateSpec() error = %v", err)
+	}
+	if spec.Image != "registry.example.com/acme/web:pr-42" {
+		t.Errorf("SetWorkloadImage() image = %s", spec.Image)
+	}
+	if FindResourceName(result, "Service") != "svc-web" {
+		t.Errorf("SetWorkloadImage() dropped the Service document:\n%s", result)
+	}
+}
@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"url-manager-system/backend/internal/db/models"
+
+	"gopkg.in/yaml.v2"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	// kustomizeRoot 内存文件系统中的工作目录
+	kustomizeRoot = "/bundle"
+	// kustomizationFile base中必须存在的Kustomization文件名
+	kustomizationFile = "kustomization.yaml"
+)
+
+// BuildKustomizeBundle 在进程内构建Kustomize模版包
+// base之上会生成一个overlay：注入 nameSuffix、标准标签/注解以及模版中定义的补丁，
+// 补丁和base文件中的 ${VAR} 占位符会先被替换为变量值。
+func BuildKustomizeBundle(bundle *models.KustomizeBundle, variables map[string]string) (string, error) {
+	if bundle == nil {
+		return "", fmt.Errorf("kustomize bundle is required")
+	}
+	if err := ValidateKustomizeBundle(bundle); err != nil {
+		return "", err
+	}
+
+	fSys := filesys.MakeFsInMemory()
+	baseDir := path.Join(kustomizeRoot, "base")
+	overlayDir := path.Join(kustomizeRoot, "overlay")
+
+	// 写入base文件
+	for name, content := range bundle.Base {
+		filePath := path.Join(baseDir, path.Clean("/"+name))
+		if err := fSys.MkdirAll(path.Dir(filePath)); err != nil {
+			return "", fmt.Errorf("failed to create directory for %s: %w", name, err)
+		}
+		if err := fSys.WriteFile(filePath, []byte(substituteVariables(content, variables))); err != nil {
+			return "", fmt.Errorf("failed to write base file %s: %w", name, err)
+		}
+	}
+
+	// 生成overlay
+	overlay, err := generateOverlayKustomization(bundle, variables)
+	if err != nil {
+		return "", err
+	}
+	if err := fSys.MkdirAll(overlayDir); err != nil {
+		return "", fmt.Errorf("failed to create overlay directory: %w", err)
+	}
+	if err := fSys.WriteFile(path.Join(overlayDir, kustomizationFile), overlay); err != nil {
+		return "", fmt.Errorf("failed to write overlay kustomization: %w", err)
+	}
+
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resMap, err := kustomizer.Run(fSys, overlayDir)
+	if err != nil {
+		return "", fmt.Errorf("kustomize build failed: %w", err)
+	}
+
+	output, err := resMap.AsYaml()
+	if err != nil {
+		return "", fmt.Errorf("failed to render kustomize output: %w", err)
+	}
+
+	return string(output), nil
+}
+
+// ValidateKustomizeBundle 验证Kustomize模版包的基本结构
+func ValidateKustomizeBundle(bundle *models.KustomizeBundle) error {
+	if len(bundle.Base) == 0 {
+		return fmt.Errorf("kustomize bundle base cannot be empty")
+	}
+
+	if _, ok := bundle.Base[kustomizationFile]; !ok {
+		return fmt.Errorf("kustomize bundle base must contain %s", kustomizationFile)
+	}
+
+	for name := range bundle.Base {
+		if strings.Contains(name, "..") {
+			return fmt.Errorf("invalid base file path: %s", name)
+		}
+	}
+
+	for i, patch := range bundle.Patches {
+		if strings.TrimSpace(patch.Patch) == "" {
+			return fmt.Errorf("patch %d cannot be empty", i)
+		}
+	}
+
+	return nil
+}
+
+// KustomizeBundleVariables 获取Kustomize模版包中的 ${VAR} 占位符
+func KustomizeBundleVariables(bundle *models.KustomizeBundle) []string {
+	if bundle == nil {
+		return nil
+	}
+
+	// 按文件名排序，保证结果稳定
+	names := make([]string, 0, len(bundle.Base))
+	for name := range bundle.Base {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var contents []string
+	for _, name := range names {
+		contents = append(contents, bundle.Base[name])
+	}
+	for _, patch := range bundle.Patches {
+		contents = append(contents, patch.Patch)
+	}
+
+	return ExtractPlaceholders(strings.Join(contents, "\n"))
+}
+
+// ExtractPlaceholders 提取文本中的 ${VAR} 占位符（去重并保持出现顺序）
+func ExtractPlaceholders(content string) []string {
+	var variables []string
+	variableMap := make(map[string]bool)
+
+	start := 0
+	for {
+		startIdx := strings.Index(content[start:], "${")
+		if startIdx == -1 {
+			break
+		}
+		startIdx += start
+
+		endIdx := strings.Index(content[startIdx:], "}")
+		if endIdx == -1 {
+			break
+		}
+		endIdx += startIdx
+
+		variable := content[startIdx+2 : endIdx]
+		if variable != "" && !variableMap[variable] {
+			variables = append(variables, variable)
+			variableMap[variable] = true
+		}
+
+		start = endIdx + 1
+	}
+
+	return variables
+}
+
+// FindResourceName 查找多文档YAML中第一个指定类型资源的名称
+func FindResourceName(yamlContent, kind string) string {
+	for _, doc := range splitYAMLDocuments(yamlContent) {
+		var resource struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name string `yaml:"name"`
+			} `yaml:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &resource); err != nil {
+			continue
+		}
+		if resource.Kind == kind && resource.Metadata.Name != "" {
+			return resource.Metadata.Name
+		}
+	}
+	return ""
+}
+
+// generateOverlayKustomization 生成叠加在base之上的overlay kustomization.yaml
+func generateOverlayKustomization(bundle *models.KustomizeBundle, variables map[string]string) ([]byte, error) {
+	kustomization := map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  []string{"../base"},
+	}
+
+	// 使用UUID作为名称后缀，保证每个URL实例的资源名称唯一
+	if uuid := variables["UUID"]; uuid != "" {
+		kustomization["nameSuffix"] = "-" + SanitizeKubernetesName(uuid)
+	}
+
+	// 注入标准变量作为标签和注解
+	labels := map[string]string{
+		"managed-by": "url-manager-system",
+	}
+	annotations := map[string]string{}
+	if name := variables["DEPLOYMENT_NAME"]; name != "" {
+		labels["ephemeral-url-deployment"] = SanitizeKubernetesLabel(name)
+	}
+	if name := variables["SERVICE_NAME"]; name != "" {
+		labels["ephemeral-url-service"] = SanitizeKubernetesLabel(name)
+	}
+	if urlPath := variables["PATH"]; urlPath != "" {
+		labels["ephemeral-url-path"] = SanitizeKubernetesLabel(urlPath)
+		annotations["url-manager-system/path"] = "/" + strings.TrimPrefix(urlPath, "/")
+	}
+	kustomization["commonLabels"] = labels
+	if len(annotations) > 0 {
+		kustomization["commonAnnotations"] = annotations
+	}
+
+	// 添加补丁
+	if len(bundle.Patches) > 0 {
+		patches := make([]map[string]interface{}, len(bundle.Patches))
+		for i, patch := range bundle.Patches {
+			entry := map[string]interface{}{
+				"patch": substituteVariables(patch.Patch, variables),
+			}
+			if patch.Target != nil {
+				entry["target"] = generatePatchTarget(patch.Target)
+			}
+			patches[i] = entry
+		}
+		kustomization["patches"] = patches
+	}
+
+	data, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal overlay kustomization: %w", err)
+	}
+	return data, nil
+}
+
+// generatePatchTarget 生成补丁目标选择器
+func generatePatchTarget(target *models.KustomizePatchTarget) map[string]interface{} {
+	selector := make(map[string]interface{})
+	if target.Group != "" {
+		selector["group"] = target.Group
+	}
+	if target.Version != "" {
+		selector["version"] = target.Version
+	}
+	if target.Kind != "" {
+		selector["kind"] = target.Kind
+	}
+	if target.Name != "" {
+		selector["name"] = target.Name
+	}
+	if target.Namespace != "" {
+		selector["namespace"] = target.Namespace
+	}
+	if target.LabelSelector != "" {
+		selector["labelSelector"] = target.LabelSelector
+	}
+	if target.AnnotationSelector != "" {
+		selector["annotationSelector"] = target.AnnotationSelector
+	}
+	return selector
+}
+
+// substituteVariables 替换 ${VAR} 占位符
+func substituteVariables(content string, variables map[string]string) string {
+	for key, value := range variables {
+		content = strings.ReplaceAll(content, fmt.Sprintf("${%s}", key), value)
+	}
+	return content
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"url-manager-system/backend/internal/db/models"
+)
+
+func testKustomizeBundle() *models.KustomizeBundle {
+	return &models.KustomizeBundle{
+		Base: map[string]string{
+			"kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+- service.yaml
+`,
+			"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.21
+`,
+			"service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+  ports:
+  - port: 80
+    targetPort: 80
+`,
+		},
+		Patches: []models.KustomizePatch{
+			{
+				Patch: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        env:
+        - name: BASE_PATH
+          value: /${PATH}
+`,
+			},
+		},
+	}
+}
+
+func TestBuildKustomizeBundle(t *testing.T) {
+	variables := map[string]string{
+		"PATH":            "a1b2c3d4",
+		"SERVICE_NAME":    "svc-ephemeral-abc12345",
+		"DEPLOYMENT_NAME": "ephemeral-abc12345",
+		"UUID":            "abc12345",
+	}
+
+	output, err := BuildKustomizeBundle(testKustomizeBundle(), variables)
+	if err != nil {
+		t.Fatalf("BuildKustomizeBundle() error = %v", err)
+	}
+
+	if got := FindResourceName(output, "Deployment"); got != "web-abc12345" {
+		t.Errorf("Deployment name = %q, expected %q", got, "web-abc12345")
+	}
+	if got := FindResourceName(output, "Service"); got != "web-abc12345" {
+		t.Errorf("Service name = %q, expected %q", got, "web-abc12345")
+	}
+
+	for _, expected := range []string{
+		"ephemeral-url-deployment: ephemeral-abc12345",
+		"ephemeral-url-service: svc-ephemeral-abc12345",
+		"ephemeral-url-path: a1b2c3d4",
+		"managed-by: url-manager-system",
+		"value: /a1b2c3d4",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("output does not contain %q:\n%s", expected, output)
+		}
+	}
+
+	spec, err := ParseYAMLToTemplateSpec(output)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTemplateSpec() error = %v", err)
+	}
+	if spec.Image != "nginx:1.21" {
+		t.Errorf("parsed image = %q, expected %q", spec.Image, "nginx:1.21")
+	}
+}
+
+func TestValidateKustomizeBundle(t *testing.T) {
+	tests := []struct {
+		name    string
+		bundle  *models.KustomizeBundle
+		wantErr bool
+	}{
+		{
+			name:    "valid bundle",
+			bundle:  testKustomizeBundle(),
+			wantErr: false,
+		},
+		{
+			name:    "empty base",
+			bundle:  &models.KustomizeBundle{},
+			wantErr: true,
+		},
+		{
+			name: "missing kustomization.yaml",
+			bundle: &models.KustomizeBundle{
+				Base: map[string]string{"deployment.yaml": "kind: Deployment"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "path traversal",
+			bundle: &models.KustomizeBundle{
+				Base: map[string]string{
+					"kustomization.yaml": "resources: []",
+					"../etc/passwd":      "x",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty patch",
+			bundle: &models.KustomizeBundle{
+				Base:    map[string]string{"kustomization.yaml": "resources: []"},
+				Patches: []models.KustomizePatch{{Patch: "  "}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKustomizeBundle(tt.bundle)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateKustomizeBundle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKustomizeBundleVariables(t *testing.T) {
+	bundle := testKustomizeBundle()
+	bundle.Base["configmap.yaml"] = "data:\n  project: ${PROJECT_NAME}\n"
+
+	variables := KustomizeBundleVariables(bundle)
+	expected := []string{"PROJECT_NAME", "PATH"}
+	if len(variables) != len(expected) {
+		t.Fatalf("KustomizeBundleVariables() = %v, expected %v", variables, expected)
+	}
+	for i := range expected {
+		if variables[i] != expected[i] {
+			t.Errorf("KustomizeBundleVariables()[%d] = %q, expected %q", i, variables[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"url-manager-system/backend/internal/db/models"
+)
+
+// defaultRegistryHost 未指定仓库地址的镜像所在的默认仓库
+const defaultRegistryHost = "docker.io"
+
+// registryPayload Docker Registry v2 通知和 Harbor webhook 中用到的字段
+type registryPayload struct {
+	// Docker Registry v2
+	Events []struct {
+		Action string `json:"action"`
+		Target struct {
+			Repository string `json:"repository"`
+			Tag        string `json:"tag"`
+			Digest     string `json:"digest"`
+		} `json:"target"`
+		Request struct {
+			Host string `json:"host"`
+		} `json:"request"`
+	} `json:"events"`
+
+	// Harbor
+	Type      string `json:"type"`
+	EventData struct {
+		Resources []struct {
+			Tag         string `json:"tag"`
+			Digest      string `json:"digest"`
+			ResourceURL string `json:"resource_url"`
+		} `json:"resources"`
+		Repository struct {
+			RepoFullName string `json:"repo_full_name"`
+		} `json:"repository"`
+	} `json:"event_data"`
+}
+
+// ParseRegistryWebhook 解析Docker Registry v2通知或Harbor webhook，返回其中带标签的推送事件
+func ParseRegistryWebhook(body []byte) ([]models.RegistryPushEvent, error) {
+	var payload registryPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	var events []models.RegistryPushEvent
+	switch {
+	case payload.Type != "":
+		// Harbor 2.x 为 PUSH_ARTIFACT，1.x 为 pushImage
+		if payload.Type != "PUSH_ARTIFACT" && payload.Type != "pushImage" {
+			return events, nil
+		}
+		for _, resource := range payload.EventData.Resources {
+			if resource.Tag == "" {
+				continue
+			}
+			host := ""
+			if i := strings.Index(resource.ResourceURL, "/"); i > 0 {
+				host = resource.ResourceURL[:i]
+			}
+			events = append(events, models.RegistryPushEvent{
+				Host:       host,
+				Repository: payload.EventData.Repository.RepoFullName,
+				Tag:        resource.Tag,
+				Digest:     resource.Digest,
+			})
+		}
+	case payload.Events != nil:
+		for _, event := range payload.Events {
+			// 只有推送manifest的事件带标签，层推送事件忽略
+			if event.Action != "push" || event.Target.Tag == "" {
+				continue
+			}
+			events = append(events, models.RegistryPushEvent{
+				Host:       event.Request.Host,
+				Repository: event.Target.Repository,
+				Tag:        event.Target.Tag,
+				Digest:     event.Target.Digest,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("invalid webhook payload: unrecognized registry notification format")
+	}
+
+	return events, nil
+}
+
+// ParseImageReference 将镜像名拆分为仓库地址、仓库路径和标签
+// 未指定仓库地址时为 docker.io，官方镜像补全 library/ 前缀；按digest引用的镜像标签为空。
+func ParseImageReference(image string) (host, repository, tag string) {
+	name := image
+	digest := false
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+		digest = true
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		if !digest {
+			tag = name[i+1:]
+		}
+		name = name[:i]
+	}
+
+	host = defaultRegistryHost
+	repository = name
+	if i := strings.Index(name, "/"); i >= 0 {
+		first := name[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			host = first
+			repository = name[i+1:]
+		}
+	}
+	if host == defaultRegistryHost && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	return host, repository, tag
+}
+
+// ReplaceImageTag 替换镜像标签，保留镜像名原有的书写形式
+func ReplaceImageTag(image, tag string) string {
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	return name + ":" + tag
+}
+
+// RegistryEventMatchesImage 判断推送事件是否属于镜像所在的仓库
+// 事件未携带仓库地址时只比较仓库路径。
+func RegistryEventMatchesImage(event models.RegistryPushEvent, image string) bool {
+	host, repository, _ := ParseImageReference(image)
+	if event.Host != "" && !strings.EqualFold(event.Host, host) {
+		return false
+	}
+	return strings.EqualFold(event.Repository, repository)
+}
+
+// ValidateTagPattern 验证镜像标签跟踪规则，支持 * ? [...] 通配符
+func ValidateTagPattern(pattern string) error {
+	if pattern == "" || len(pattern) > 128 {
+		return fmt.Errorf("track tag pattern must be 1-128 characters")
+	}
+	if strings.Contains(pattern, "/") {
+		return fmt.Errorf("track tag pattern must not contain '/'")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid track tag pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// MatchTagPattern 判断标签是否匹配跟踪规则
+func MatchTagPattern(pattern, tag string) bool {
+	matched, err := path.Match(pattern, tag)
+	return err == nil && matched
+}
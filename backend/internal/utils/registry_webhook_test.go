@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"url-manager-system/backend/internal/db/models"
+)
+
+func TestParseRegistryWebhook(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []models.RegistryPushEvent
+	}{
+		{
+			name: "docker registry",
+			body: `{"events":[
+				{"action":"push","target":{"mediaType":"application/vnd.docker.image.rootfs.diff.tar.gzip","repository":"acme/web","digest":"sha256:layer"},"request":{"host":"registry.example.com:5000"}},
+				{"action":"push","target":{"mediaType":"application/vnd.docker.distribution.manifest.v2+json","repository":"acme/web","tag":"v1.2.0","digest":"sha256:abc"},"request":{"host":"registry.example.com:5000"}},
+				{"action":"pull","target":{"repository":"acme/web","tag":"v1.1.0"},"request":{"host":"registry.example.com:5000"}}
+			]}`,
+			expected: []models.RegistryPushEvent{{Host: "registry.example.com:5000", Repository: "acme/web", Tag: "v1.2.0", Digest: "sha256:abc"}},
+		},
+		{
+			name: "harbor",
+			body: `{"type":"PUSH_ARTIFACT","event_data":{"resources":[{"digest":"sha256:def","tag":"main-42","resource_url":"harbor.example.com/library/web:main-42"}],"repository":{"name":"web","namespace":"library","repo_full_name":"library/web"}}}`,
+			expected: []models.RegistryPushEvent{{Host: "harbor.example.com", Repository: "library/web", Tag: "main-42", Digest: "sha256:def"}},
+		},
+		{
+			name: "harbor delete",
+			body: `{"type":"DELETE_ARTIFACT","event_data":{"resources":[{"tag":"main-42","resource_url":"harbor.example.com/library/web:main-42"}],"repository":{"repo_full_name":"library/web"}}}`,
+		},
+		{
+			name: "empty registry notification",
+			body: `{"events":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := ParseRegistryWebhook([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("ParseRegistryWebhook() error = %v", err)
+			}
+			if !reflect.DeepEqual(events, tt.expected) {
+				t.Errorf("ParseRegistryWebhook() = %+v, expected %+v", events, tt.expected)
+			}
+		})
+	}
+
+	for _, body := range []string{`{`, `{"foo":"bar"}`} {
+		if _, err := ParseRegistryWebhook([]byte(body)); err == nil || !strings.Contains(err.Error(), "invalid webhook payload") {
+			t.Errorf("ParseRegistryWebhook(%s) error = %v, expected invalid payload error", body, err)
+		}
+	}
+}
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image      string
+		host       string
+		repository string
+		tag        string
+	}{
+		{"nginx", "docker.io", "library/nginx", ""},
+		{"nginx:1.25", "docker.io", "library/nginx", "1.25"},
+		{"acme/web:v1", "docker.io", "acme/web", "v1"},
+		{"registry.example.com:5000/acme/web:v1", "registry.example.com:5000", "acme/web", "v1"},
+		{"localhost/web", "localhost", "web", ""},
+		{"harbor.example.com/library/web@sha256:abc", "harbor.example.com", "library/web", ""},
+	}
+
+	for _, tt := range tests {
+		host, repository, tag := ParseImageReference(tt.image)
+		if host != tt.host || repository != tt.repository || tag != tt.tag {
+			t.Errorf("ParseImageReference(%q) = (%q, %q, %q), expected (%q, %q, %q)", tt.image, host, repository, tag, tt.host, tt.repository, tt.tag)
+		}
+	}
+
+	if got := ReplaceImageTag("registry.example.com:5000/acme/web:v1", "v2"); got != "registry.example.com:5000/acme/web:v2" {
+		t.Errorf("ReplaceImageTag() = %s", got)
+	}
+	if got := ReplaceImageTag("nginx", "1.25"); got != "nginx:1.25" {
+		t.Errorf("ReplaceImageTag() = %s", got)
+	}
+}
+
+func TestRegistryEventMatchesImage(t *testing.T) {
+	event := models.RegistryPushEvent{Host: "registry.example.com:5000", Repository: "acme/web", Tag: "v2"}
+
+	if !RegistryEventMatchesImage(event, "registry.example.com:5000/acme/web:v1") {
+		t.Error("RegistryEventMatchesImage() rejected the tracked image")
+	}
+	if RegistryEventMatchesImage(event, "registry.example.com:5000/acme/api:v1") {
+		t.Error("RegistryEventMatchesImage() accepted another repository")
+	}
+	if RegistryEventMatchesImage(event, "other.example.com/acme/web:v1") {
+		t.Error("RegistryEventMatchesImage() accepted another registry")
+	}
+
+	event.Host = ""
+	if !RegistryEventMatchesImage(event, "other.example.com/acme/web:v1") {
+		t.Error("RegistryEventMatchesImage() should only compare repositories when the event has no host")
+	}
+}
+
+func TestTagPattern(t *testing.T) {
+	for _, pattern := range []string{"*", "v1.*", "main-*", "release-[0-9]*"} {
+		if err := ValidateTagPattern(pattern); err != nil {
+			t.Errorf("ValidateTagPattern(%q) error = %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"", "v1/*", "[", strings.Repeat("a", 129)} {
+		if err := ValidateTagPattern(pattern); err == nil {
+			t.Errorf("ValidateTagPattern(%q) expected error", pattern)
+		}
+	}
+
+	if !MatchTagPattern("v1.*", "v1.2.3") || MatchTagPattern("v1.*", "v2.0.0") {
+		t.Error("MatchTagPattern() mismatched v1.*")
+	}
+	if !MatchTagPattern("main-*", "main-42") || MatchTagPattern("main-*", "feature-42") {
+		t.Error("MatchTagPattern() mismatched main-*")
+	}
+}
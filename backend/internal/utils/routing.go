@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SubdomainHost 生成子域名路由模式下URL的访问域名：<路径>.<项目>.<泛域名>
+// 路径和项目名各自转换为DNS标签，多级路径用连字符连接。
+func SubdomainHost(path, projectName, wildcardDomain string) string {
+	slug := SanitizeKubernetesName(strings.ReplaceAll(strings.Trim(path, "/"), "/", "-"))
+	project := SanitizeKubernetesName(projectName)
+	domain := strings.TrimPrefix(strings.TrimPrefix(wildcardDomain, "*"), ".")
+	return fmt.Sprintf("%s.%s.%s", slug, project, domain)
+}
+
+// RoutedURL 生成URL的完整访问地址，子域名模式下访问根路径，证书未就绪时使用http
+func RoutedURL(host, path string, subdomain, tls bool) string {
+	scheme := "http"
+	if tls {
+		scheme = "https"
+	}
+	if subdomain {
+		return fmt.Sprintf("%s://%s/", scheme, host)
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, host, path)
+}
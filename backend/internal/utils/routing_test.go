@@ -0,0 +1,48 @@
+package utils
+
+import "testing"
+
+func TestSubdomainHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		project  string
+		domain   string
+		expected string
+	}{
+		{name: "hash path", path: "/a1b2c3d4", project: "demo", domain: "preview.example.com", expected: "a1b2c3d4.demo.preview.example.com"},
+		{name: "nested path", path: "/pr/42/", project: "Web Shop", domain: "preview.example.com", expected: "pr-42.web-shop.preview.example.com"},
+		{name: "wildcard prefix", path: "/app", project: "demo", domain: "*.preview.example.com", expected: "app.demo.preview.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SubdomainHost(tt.path, tt.project, tt.domain); got != tt.expected {
+				t.Errorf("SubdomainHost() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRoutedURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		host      string
+		path      string
+		subdomain bool
+		tls       bool
+		expected  string
+	}{
+		{name: "path", host: "example.com", path: "/abc", tls: true, expected: "https://example.com/abc"},
+		{name: "subdomain", host: "abc.demo.preview.example.com", path: "/abc", subdomain: true, tls: true, expected: "https://abc.demo.preview.example.com/"},
+		{name: "certificate not ready", host: "example.com", path: "/abc", expected: "http://example.com/abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoutedURL(tt.host, tt.path, tt.subdomain, tt.tls); got != tt.expected {
+				t.Errorf("RoutedURL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
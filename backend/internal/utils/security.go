@@ -194,6 +194,46 @@ func ValidateContainerConfig(config models.ContainerConfig) error {
 		return fmt.Errorf("工作目录必须是绝对路径")
 	}
 
+	// 验证探针和启动超时
+	if config.ReadinessProbe != nil {
+		if err := ValidateProbe(*config.ReadinessProbe); err != nil {
+			return fmt.Errorf("就绪探针: %w", err)
+		}
+	}
+	if config.LivenessProbe != nil {
+		if err := ValidateProbe(*config.LivenessProbe); err != nil {
+			return fmt.Errorf("存活探针: %w", err)
+		}
+	}
+	if config.StartupTimeoutSeconds < 0 {
+		return fmt.Errorf("启动超时时间不能为负数")
+	}
+
+	return nil
+}
+
+// ValidateProbe 验证容器探针配置
+func ValidateProbe(probe models.ContainerProbe) error {
+	switch probe.Type {
+	case models.ProbeTypeHTTP:
+		if probe.Path != "" && !strings.HasPrefix(probe.Path, "/") {
+			return fmt.Errorf("HTTP探针路径必须以/开头")
+		}
+	case models.ProbeTypeTCP:
+	case models.ProbeTypeExec:
+		if len(probe.Command) == 0 {
+			return fmt.Errorf("exec探针必须指定命令")
+		}
+	default:
+		return fmt.Errorf("探针类型无效，只支持 http、tcp、exec")
+	}
+
+	if probe.Port < 0 || probe.Port > 65535 {
+		return fmt.Errorf("探针端口必须在1-65535之间")
+	}
+	if probe.InitialDelaySeconds < 0 || probe.PeriodSeconds < 0 || probe.TimeoutSeconds < 0 || probe.FailureThreshold < 0 {
+		return fmt.Errorf("探针时间和次数不能为负数")
+	}
 	return nil
 }
 
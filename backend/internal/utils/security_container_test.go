@@ -72,6 +72,50 @@ func TestValidateContainerConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid probes",
+			config: models.ContainerConfig{
+				ReadinessProbe:        &models.ContainerProbe{Type: models.ProbeTypeHTTP, Path: "/healthz", Port: 8080},
+				LivenessProbe:         &models.ContainerProbe{Type: models.ProbeTypeExec, Command: []string{"cat", "/tmp/healthy"}},
+				StartupTimeoutSeconds: 600,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid probe type",
+			config: models.ContainerConfig{
+				ReadinessProbe: &models.ContainerProbe{Type: "grpc"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "http probe with relative path",
+			config: models.ContainerConfig{
+				ReadinessProbe: &models.ContainerProbe{Type: models.ProbeTypeHTTP, Path: "healthz"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "exec probe without command",
+			config: models.ContainerConfig{
+				LivenessProbe: &models.ContainerProbe{Type: models.ProbeTypeExec},
+			},
+			wantErr: true,
+		},
+		{
+			name: "probe port out of range",
+			config: models.ContainerConfig{
+				ReadinessProbe: &models.ContainerProbe{Type: models.ProbeTypeTCP, Port: 70000},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative startup timeout",
+			config: models.ContainerConfig{
+				StartupTimeoutSeconds: -1,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"url-manager-system/backend/internal/db/models"
+)
+
+// stackServiceNamePattern 环境内服务名格式（DNS标签子集，便于转换为环境变量名）
+var stackServiceNamePattern = regexp.MustCompile(`^[a-z]([a-z0-9-]{0,38}[a-z0-9])?$`)
+
+// PlanStackServices 验证环境服务定义并按依赖关系分层
+// 返回的每一层只依赖之前各层的服务，层内保持声明顺序；依赖缺失或存在循环时返回错误。
+func PlanStackServices(services []models.StackServiceSpec) ([][]models.StackServiceSpec, error) {
+	if len(services) == 0 {
+		return nil, fmt.Errorf("stack must contain at least one service")
+	}
+
+	index := make(map[string]int, len(services))
+	for i, service := range services {
+		if !stackServiceNamePattern.MatchString(service.Name) {
+			return nil, fmt.Errorf("invalid service name %q: must be lowercase alphanumeric or '-', start with a letter and be at most 40 characters", service.Name)
+		}
+		if _, exists := index[service.Name]; exists {
+			return nil, fmt.Errorf("duplicate service name %q", service.Name)
+		}
+		if (service.TemplateID == nil) == (service.Image == "") {
+			return nil, fmt.Errorf("service %q: exactly one of template_id or image is required", service.Name)
+		}
+		index[service.Name] = i
+	}
+
+	remaining := make([]int, len(services))
+	dependents := make([][]int, len(services))
+	for i, service := range services {
+		seen := make(map[string]bool)
+		for _, dep := range service.DependsOn {
+			j, exists := index[dep]
+			if !exists {
+				return nil, fmt.Errorf("service %q depends on unknown service %q", service.Name, dep)
+			}
+			if j == i {
+				return nil, fmt.Errorf("service %q cannot depend on itself", service.Name)
+			}
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			remaining[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	var levels [][]models.StackServiceSpec
+	planned := 0
+	current := make([]int, 0, len(services))
+	for i := range services {
+		if remaining[i] == 0 {
+			current = append(current, i)
+		}
+	}
+
+	for len(current) > 0 {
+		level := make([]models.StackServiceSpec, 0, len(current))
+		ready := make(map[int]bool)
+		for _, i := range current {
+			level = append(level, services[i])
+			for _, j := range dependents[i] {
+				remaining[j]--
+				if remaining[j] == 0 {
+					ready[j] = true
+				}
+			}
+		}
+		levels = append(levels, level)
+		planned += len(level)
+
+		// 按声明顺序生成下一层
+		current = current[:0]
+		for i := range services {
+			if ready[i] {
+				current = append(current, i)
+			}
+		}
+	}
+
+	if planned != len(services) {
+		var cyclic []string
+		for i, service := range services {
+			if remaining[i] > 0 {
+				cyclic = append(cyclic, service.Name)
+			}
+		}
+		return nil, fmt.Errorf("dependency cycle detected among services: %s", strings.Join(cyclic, ", "))
+	}
+
+	return levels, nil
+}
+
+// StackServiceHostEnvName 环境内服务地址对应的环境变量名，例如 api-gateway -> STACK_API_GATEWAY_HOST
+func StackServiceHostEnvName(name string) string {
+	return "STACK_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_HOST"
+}
+
+// MergeEnvironmentVars 按顺序合并多组环境变量，同名变量以后出现的值为准并保留首次出现的位置
+func MergeEnvironmentVars(layers ...models.EnvironmentVars) models.EnvironmentVars {
+	var merged models.EnvironmentVars
+	positions := make(map[string]int)
+	for _, layer := range layers {
+		for _, env := range layer {
+			if i, exists := positions[env.Name]; exists {
+				merged[i].Value = env.Value
+				continue
+			}
+			positions[env.Name] = len(merged)
+			merged = append(merged, env)
+		}
+	}
+	return merged
+}
+
+// InjectWorkloadEnv 向YAML中主工作负载的主容器注入环境变量，同名变量会被覆盖，其他内容保持不变
+func InjectWorkloadEnv(yamlContent string, env models.EnvironmentVars) (string, error) {
+	if len(env) == 0 {
+		return yamlContent, nil
+	}
+
+	spec, err := ParseYAMLToTemplateSpec(yamlContent)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse workload: %w", err)
+	}
+	spec.Env = MergeEnvironmentVars(spec.Env, env)
+
+	return GenerateYAMLFromTemplateSpec(spec, yamlContent)
+}
+
+// AggregateStackStatus 根据环境记录的状态和成员URL状态推导环境的整体状态
+// 部署中的环境只要有成员失败即视为失败；已就绪的环境有成员不再active时视为降级。
+func AggregateStackStatus(stored string, members []string) string {
+	switch stored {
+	case models.StackStatusDeploying:
+		for _, status := range members {
+			if status == models.StatusFailed {
+				return models.StackStatusFailed
+			}
+		}
+		return models.StackStatusDeploying
+	case models.StackStatusActive:
+		if len(members) == 0 {
+			return models.StackStatusDegraded
+		}
+		for _, status := range members {
+			if status != models.StatusActive {
+				return models.StackStatusDegraded
+			}
+		}
+		return models.StackStatusActive
+	default:
+		return stored
+	}
+}
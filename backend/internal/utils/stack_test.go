@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"url-manager-system/backend/internal/db/models"
+
+	"github.com/google/uuid"
+)
+
+func stackLevelNames(levels [][]models.StackServiceSpec) [][]string {
+	var names [][]string
+	for _, level := range levels {
+		var row []string
+		for _, service := range level {
+			row = append(row, service.Name)
+		}
+		names = append(names, row)
+	}
+	return names
+}
+
+func TestPlanStackServices(t *testing.T) {
+	templateID := uuid.New()
+	services := []models.StackServiceSpec{
+		{Name: "web", Image: "nginx:latest", DependsOn: []string{"api"}},
+		{Name: "api", Image: "api:1.0", DependsOn: []string{"db", "cache", "db"}},
+		{Name: "db", TemplateID: &templateID},
+		{Name: "cache", Image: "redis:7"},
+		{Name: "worker", Image: "worker:1.0", DependsOn: []string{"db"}},
+	}
+
+	levels, err := PlanStackServices(services)
+	if err != nil {
+		t.Fatalf("PlanStackServices() error = %v", err)
+	}
+
+	expected := [][]string{{"db", "cache"}, {"api", "worker"}, {"web"}}
+	if got := stackLevelNames(levels); !reflect.DeepEqual(got, expected) {
+		t.Errorf("PlanStackServices() levels = %v, expected %v", got, expected)
+	}
+}
+
+func TestPlanStackServicesInvalid(t *testing.T) {
+	templateID := uuid.New()
+	tests := []struct {
+		name     string
+		services []models.StackServiceSpec
+		errMsg   string
+	}{
+		{name: "empty", services: nil, errMsg: "at least one service"},
+		{name: "invalid name", services: []models.StackServiceSpec{{Name: "Web_1", Image: "nginx"}}, errMsg: "invalid service name"},
+		{name: "duplicate", services: []models.StackServiceSpec{{Name: "web", Image: "nginx"}, {Name: "web", Image: "nginx"}}, errMsg: "duplicate service name"},
+		{name: "no source", services: []models.StackServiceSpec{{Name: "web"}}, errMsg: "exactly one of"},
+		{name: "both sources", services: []models.StackServiceSpec{{Name: "web", Image: "nginx", TemplateID: &templateID}}, errMsg: "exactly one of"},
+		{name: "unknown dependency", services: []models.StackServiceSpec{{Name: "web", Image: "nginx", DependsOn: []string{"api"}}}, errMsg: "unknown service"},
+		{name: "self dependency", services: []models.StackServiceSpec{{Name: "web", Image: "nginx", DependsOn: []string{"web"}}}, errMsg: "itself"},
+		{
+			name: "cycle",
+			services: []models.StackServiceSpec{
+				{Name: "db", Image: "postgres"},
+				{Name: "a", Image: "a", DependsOn: []string{"b", "db"}},
+				{Name: "b", Image: "b", DependsOn: []string{"a"}},
+			},
+			errMsg: "dependency cycle detected among services: a, b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PlanStackServices(tt.services)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("PlanStackServices() error = %v, expected to contain %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestMergeEnvironmentVars(t *testing.T) {
+	merged := MergeEnvironmentVars(
+		models.EnvironmentVars{{Name: "A", Value: "1"}, {Name: "B", Value: "1"}},
+		nil,
+		models.EnvironmentVars{{Name: "C", Value: "2"}, {Name: "A", Value: "2"}},
+	)
+
+	expected := models.EnvironmentVars{{Name: "A", Value: "2"}, {Name: "B", Value: "1"}, {Name: "C", Value: "2"}}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("MergeEnvironmentVars() = %v, expected %v", merged, expected)
+	}
+
+	if name := StackServiceHostEnvName("api-gateway"); name != "STACK_API_GATEWAY_HOST" {
+		t.Errorf("StackServiceHostEnvName() = %s", name)
+	}
+}
+
+func TestInjectWorkloadEnv(t *testing.T) {
+	yamlContent := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:latest
+        env:
+        - name: MODE
+          value: dev
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc-web
+spec:
+  ports:
+  - port: 80
+`
+
+	result, err := InjectWorkloadEnv(yamlContent, models.EnvironmentVars{
+		{Name: "MODE", Value: "stack"},
+		{Name: "STACK_DB_HOST", Value: "svc-ephemeral-1234"},
+	})
+	if err != nil {
+		t.Fatalf("InjectWorkloadEnv() error = %v", err)
+	}
+
+	spec, err := ParseYAMLToTemplateSpec(result)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTemplateSpec() error = %v", err)
+	}
+	expected := models.EnvironmentVars{{Name: "MODE", Value: "stack"}, {Name: "STACK_DB_HOST", Value: "svc-ephemeral-1234"}}
+	if !reflect.DeepEqual(spec.Env, expected) {
+		t.Errorf("injected env = %v, expected %v", spec.Env, expected)
+	}
+	if FindResourceName(result, "Service") != "svc-web" {
+		t.Errorf("InjectWorkloadEnv() dropped the Service document:\n%s", result)
+	}
+}
+
+func TestAggregateStackStatus(t *testing.T) {
+	tests := []struct {
+		stored   string
+		members  []string
+		expected string
+	}{
+		{models.StackStatusDeploying, []string{models.StatusActive, models.StatusWaiting}, models.StackStatusDeploying},
+		{models.StackStatusDeploying, []string{models.StatusActive, models.StatusFailed}, models.StackStatusFailed},
+		{models.StackStatusActive, []string{models.StatusActive, models.StatusActive}, models.StackStatusActive},
+		{models.StackStatusActive, []string{models.StatusActive, models.StatusFailed}, models.StackStatusDegraded},
+		{models.StackStatusActive, nil, models.StackStatusDegraded},
+		{models.StackStatusFailed, []string{models.StatusActive}, models.StackStatusFailed},
+		{models.StackStatusDraft, []string{"draft"}, models.StackStatusDraft},
+	}
+
+	for _, tt := range tests {
+		if got := AggregateStackStatus(tt.stored, tt.members); got != tt.expected {
+			t.Errorf("AggregateStackStatus(%s, %v) = %s, expected %s", tt.stored, tt.members, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"url-manager-system/backend/internal/db/models"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SystemTemplateVariables 基于模版创建URL时由系统自动填充的变量
+var SystemTemplateVariables = []string{"PATH", "HOST", "TLS_SECRET_NAME", "SERVICE_NAME", "DEPLOYMENT_NAME", "PROJECT_NAME", "UUID"}
+
+// parameterNamePattern 模版参数名称格式，与 ${VAR} 占位符一致
+var parameterNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EncodeTemplateBundle 将模版序列化为可移植的模版包YAML
+func EncodeTemplateBundle(entries []models.TemplateBundleEntry) ([]byte, error) {
+	bundle := models.TemplateBundle{
+		APIVersion: models.TemplateBundleAPIVersion,
+		Kind:       models.TemplateBundleKind,
+		Templates:  entries,
+	}
+
+	data, err := yaml.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal template bundle: %w", err)
+	}
+	return data, nil
+}
+
+// DecodeTemplateBundle 解析并验证模版包（YAML或JSON）
+func DecodeTemplateBundle(data []byte) (*models.TemplateBundle, error) {
+	var bundle models.TemplateBundle
+	if err := yaml.UnmarshalStrict(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid template bundle: %w", err)
+	}
+
+	if bundle.APIVersion != models.TemplateBundleAPIVersion {
+		return nil, fmt.Errorf("invalid template bundle: unsupported apiVersion %q", bundle.APIVersion)
+	}
+	if bundle.Kind != models.TemplateBundleKind {
+		return nil, fmt.Errorf("invalid template bundle: unsupported kind %q", bundle.Kind)
+	}
+	if len(bundle.Templates) == 0 {
+		return nil, fmt.Errorf("invalid template bundle: no templates defined")
+	}
+
+	names := make(map[string]bool)
+	for i := range bundle.Templates {
+		entry := &bundle.Templates[i]
+		if err := ValidateTemplateBundleEntry(entry); err != nil {
+			return nil, fmt.Errorf("invalid template bundle: template %d: %w", i, err)
+		}
+		if names[entry.Name] {
+			return nil, fmt.Errorf("invalid template bundle: duplicate template name %q", entry.Name)
+		}
+		names[entry.Name] = true
+	}
+
+	return &bundle, nil
+}
+
+// ValidateTemplateBundleEntry 验证模版包中的单个模版，并补全默认模版类型
+func ValidateTemplateBundleEntry(entry *models.TemplateBundleEntry) error {
+	if entry.Name == "" || len(entry.Name) > 100 {
+		return fmt.Errorf("template name must be 1-100 characters")
+	}
+
+	switch entry.TemplateType {
+	case "", models.TemplateTypeYAML:
+		entry.TemplateType = models.TemplateTypeYAML
+		if entry.YamlSpec == "" {
+			return fmt.Errorf("template %q: yaml_spec is required", entry.Name)
+		}
+		if err := ValidateYAML(entry.YamlSpec); err != nil {
+			return fmt.Errorf("template %q: %w", entry.Name, err)
+		}
+	case models.TemplateTypeKustomize:
+		if entry.KustomizeBundle == nil {
+			return fmt.Errorf("template %q: kustomize_bundle is required", entry.Name)
+		}
+		if err := ValidateKustomizeBundle(entry.KustomizeBundle); err != nil {
+			return fmt.Errorf("template %q: %w", entry.Name, err)
+		}
+	default:
+		return fmt.Errorf("template %q: unsupported template type %q", entry.Name, entry.TemplateType)
+	}
+
+	if utf8.RuneCountInString(entry.Category) > 50 {
+		return fmt.Errorf("template %q: category must be at most 50 characters", entry.Name)
+	}
+	if len(entry.Tags) > 0 {
+		tags, err := NormalizeTemplateTags(entry.Tags)
+		if err != nil {
+			return fmt.Errorf("template %q: %w", entry.Name, err)
+		}
+		entry.Tags = tags
+	}
+
+	return ValidateTemplateParameters(entry.Parameters)
+}
+
+// ValidateTemplateParameters 验证模版参数定义
+func ValidateTemplateParameters(parameters []models.TemplateParameter) error {
+	names := make(map[string]bool)
+	for _, parameter := range parameters {
+		if !parameterNamePattern.MatchString(parameter.Name) {
+			return fmt.Errorf("invalid parameter name %q", parameter.Name)
+		}
+		if names[parameter.Name] {
+			return fmt.Errorf("duplicate parameter %q", parameter.Name)
+		}
+		names[parameter.Name] = true
+	}
+	return nil
+}
+
+// BuildTemplateParameters 合并声明的参数与模版中检测到的占位符
+// 声明的参数按原有顺序保留，未被模版引用的声明会被丢弃；
+// 未声明且不由系统填充的占位符作为必填参数追加在后面。
+func BuildTemplateParameters(declared []models.TemplateParameter, placeholders []string) []models.TemplateParameter {
+	referenced := make(map[string]bool)
+	for _, name := range placeholders {
+		referenced[name] = true
+	}
+
+	known := make(map[string]bool)
+	for _, name := range SystemTemplateVariables {
+		known[name] = true
+	}
+
+	var parameters []models.TemplateParameter
+	for _, parameter := range declared {
+		if !referenced[parameter.Name] || known[parameter.Name] {
+			continue
+		}
+		known[parameter.Name] = true
+		parameters = append(parameters, parameter)
+	}
+
+	for _, name := range placeholders {
+		if known[name] {
+			continue
+		}
+		known[name] = true
+		parameters = append(parameters, models.TemplateParameter{
+			Name:     name,
+			Required: true,
+		})
+	}
+
+	return parameters
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"url-manager-system/backend/internal/db/models"
+)
+
+const testBundleDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ${DEPLOYMENT_NAME}
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx:${IMAGE_TAG}
+        env:
+        - name: LOG_LEVEL
+          value: ${LOG_LEVEL}
+`
+
+func TestTemplateBundleRoundTrip(t *testing.T) {
+	entries := []models.TemplateBundleEntry{
+		{
+			Name:         "web",
+			Description:  "nginx web server",
+			TemplateType: models.TemplateTypeYAML,
+			Category:     "web",
+			Tags:         []string{"nginx", "frontend"},
+			YamlSpec:     testBundleDeployment,
+			Parameters: []models.TemplateParameter{
+				{Name: "IMAGE_TAG", Default: "1.25"},
+				{Name: "LOG_LEVEL", Required: true},
+			},
+		},
+		{
+			Name:            "kustomized",
+			TemplateType:    models.TemplateTypeKustomize,
+			KustomizeBundle: testKustomizeBundle(),
+		},
+	}
+
+	data, err := EncodeTemplateBundle(entries)
+	if err != nil {
+		t.Fatalf("EncodeTemplateBundle() error = %v", err)
+	}
+
+	bundle, err := DecodeTemplateBundle(data)
+	if err != nil {
+		t.Fatalf("DecodeTemplateBundle() error = %v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(bundle.Templates, entries) {
+		t.Errorf("decoded templates = %+v, expected %+v", bundle.Templates, entries)
+	}
+}
+
+func TestDecodeTemplateBundleJSON(t *testing.T) {
+	data := `{"apiVersion":"url-manager-system/v1","kind":"TemplateBundle","templates":[{"name":"web","yaml_spec":"apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"}]}`
+
+	bundle, err := DecodeTemplateBundle([]byte(data))
+	if err != nil {
+		t.Fatalf("DecodeTemplateBundle() error = %v", err)
+	}
+	if bundle.Templates[0].TemplateType != models.TemplateTypeYAML {
+		t.Errorf("template type = %q, expected default %q", bundle.Templates[0].TemplateType, models.TemplateTypeYAML)
+	}
+}
+
+func TestDecodeTemplateBundleInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "wrong kind",
+			data:    "apiVersion: url-manager-system/v1\nkind: Other\ntemplates: []\n",
+			wantErr: "unsupported kind",
+		},
+		{
+			name:    "no templates",
+			data:    "apiVersion: url-manager-system/v1\nkind: TemplateBundle\n",
+			wantErr: "no templates defined",
+		},
+		{
+			name:    "unknown field",
+			data:    "apiVersion: url-manager-system/v1\nkind: TemplateBundle\ntemplates:\n- name: web\n  yaml: x\n",
+			wantErr: "field yaml not found",
+		},
+		{
+			name:    "duplicate names",
+			data:    "apiVersion: url-manager-system/v1\nkind: TemplateBundle\ntemplates:\n- name: web\n  yaml_spec: 'kind: Service'\n- name: web\n  yaml_spec: 'kind: Service'\n",
+			wantErr: "duplicate template name",
+		},
+		{
+			name:    "missing kustomize bundle",
+			data:    "apiVersion: url-manager-system/v1\nkind: TemplateBundle\ntemplates:\n- name: web\n  template_type: kustomize\n",
+			wantErr: "kustomize_bundle is required",
+		},
+		{
+			name:    "invalid parameter name",
+			data:    "apiVersion: url-manager-system/v1\nkind: TemplateBundle\ntemplates:\n- name: web\n  yaml_spec: 'kind: Service'\n  parameters:\n  - name: IMAGE-TAG\n",
+			wantErr: "invalid parameter name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeTemplateBundle([]byte(tt.data))
+			if err == nil {
+				t.Fatalf("DecodeTemplateBundle() expected error containing %q", tt.wantErr)
+			}
+			if !strings.HasPrefix(err.Error(), "invalid template bundle") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("DecodeTemplateBundle() error = %v, expected %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildTemplateParameters(t *testing.T) {
+	declared := []models.TemplateParameter{
+		{Name: "LOG_LEVEL", Default: "info"},
+		{Name: "UNUSED", Default: "x"},
+		{Name: "PATH", Default: "ignored"},
+	}
+
+	parameters := BuildTemplateParameters(declared, ExtractPlaceholders(testBundleDeployment))
+
+	expected := []models.TemplateParameter{
+		{Name: "LOG_LEVEL", Default: "info"},
+		{Name: "IMAGE_TAG", Required: true},
+	}
+	if !reflect.DeepEqual(parameters, expected) {
+		t.Errorf("BuildTemplateParameters() = %+v, expected %+v", parameters, expected)
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxTemplateTags      = 20
+	maxTemplateTagLength = 30
+)
+
+// NormalizeTemplateTags 规范化模版标签：去除首尾空白、转为小写并去重
+func NormalizeTemplateTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTemplateTagLength {
+			return nil, fmt.Errorf("tag '%s' exceeds %d characters", tag, maxTemplateTagLength)
+		}
+		// 列表过滤时以逗号分隔多个标签
+		if strings.Contains(tag, ",") {
+			return nil, fmt.Errorf("tag '%s' must not contain commas", tag)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > maxTemplateTags {
+		return nil, fmt.Errorf("at most %d tags are allowed", maxTemplateTags)
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTemplateTags(t *testing.T) {
+	tags, err := NormalizeTemplateTags([]string{" Web ", "nginx", "", "web", "前端"})
+	if err != nil {
+		t.Fatalf("NormalizeTemplateTags() error = %v", err)
+	}
+
+	expected := []string{"web", "nginx", "前端"}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("NormalizeTemplateTags() = %v, expected %v", tags, expected)
+	}
+
+	if tags, err := NormalizeTemplateTags(nil); err != nil || len(tags) != 0 {
+		t.Errorf("NormalizeTemplateTags(nil) = %v, %v, expected empty slice", tags, err)
+	}
+}
+
+func TestNormalizeTemplateTagsInvalid(t *testing.T) {
+	tooMany := make([]string, 21)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("a", i+1)
+	}
+
+	tests := []struct {
+		name string
+		tags []string
+	}{
+		{name: "comma", tags: []string{"a,b"}},
+		{name: "too long", tags: []string{strings.Repeat("x", 31)}},
+		{name: "too many", tags: tooMany},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NormalizeTemplateTags(tt.tags); err == nil {
+				t.Errorf("NormalizeTemplateTags(%v) expected error", tt.tags)
+			}
+		})
+	}
+}
@@ -0,0 +1,509 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"url-manager-system/backend/internal/db/models"
+
+	"gopkg.in/yaml.v2"
+)
+
+// mergeSchema 描述TemplateSpec在工作负载YAML中管理的字段范围
+// 值为nil的字段整体替换；fields/dynamic 描述映射，item 描述列表元素：
+// listKey 非空时按该字段匹配列表元素，primary 为true时只管理列表的第一个元素。
+type mergeSchema struct {
+	fields    map[string]*mergeSchema
+	dynamic   bool
+	conflicts map[string][]string
+	item      *mergeSchema
+	listKey   string
+	primary   bool
+}
+
+var (
+	// dynamicMapSchema 所有键都由TemplateSpec管理的字符串映射（标签、注解等）
+	dynamicMapSchema = &mergeSchema{dynamic: true}
+
+	resourceQuantitySchema = &mergeSchema{fields: map[string]*mergeSchema{
+		"cpu":    nil,
+		"memory": nil,
+	}}
+
+	probeSchema = &mergeSchema{
+		fields: map[string]*mergeSchema{
+			"initialDelaySeconds": nil,
+			"periodSeconds":       nil,
+			"timeoutSeconds":      nil,
+			"successThreshold":    nil,
+			"failureThreshold":    nil,
+			"httpGet": {fields: map[string]*mergeSchema{
+				"path":        nil,
+				"port":        nil,
+				"host":        nil,
+				"scheme":      nil,
+				"httpHeaders": nil,
+			}},
+			"tcpSocket": {fields: map[string]*mergeSchema{
+				"port": nil,
+				"host": nil,
+			}},
+			"exec": {fields: map[string]*mergeSchema{
+				"command": nil,
+			}},
+		},
+		conflicts: map[string][]string{
+			"httpGet":   {"grpc"},
+			"tcpSocket": {"grpc"},
+			"exec":      {"grpc"},
+		},
+	}
+
+	containerSchema = &mergeSchema{fields: map[string]*mergeSchema{
+		"name":            nil,
+		"image":           nil,
+		"imagePullPolicy": nil,
+		"command":         nil,
+		"args":            nil,
+		"workingDir":      nil,
+		"env": {listKey: "name", item: &mergeSchema{
+			fields: map[string]*mergeSchema{
+				"name":  nil,
+				"value": nil,
+			},
+			conflicts: map[string][]string{
+				"value": {"valueFrom"},
+			},
+		}},
+		"ports": {listKey: "containerPort", item: &mergeSchema{fields: map[string]*mergeSchema{
+			"containerPort": nil,
+			"name":          nil,
+			"protocol":      nil,
+		}}},
+		"resources": {fields: map[string]*mergeSchema{
+			"requests": resourceQuantitySchema,
+			"limits":   resourceQuantitySchema,
+		}},
+		"volumeMounts": {listKey: "mountPath", item: &mergeSchema{fields: map[string]*mergeSchema{
+			"name":      nil,
+			"mountPath": nil,
+			"subPath":   nil,
+			"readOnly":  nil,
+		}}},
+		"livenessProbe":  probeSchema,
+		"readinessProbe": probeSchema,
+		"startupProbe":   probeSchema,
+		"securityContext": {fields: map[string]*mergeSchema{
+			"runAsUser":                nil,
+			"runAsGroup":               nil,
+			"runAsNonRoot":             nil,
+			"readOnlyRootFilesystem":   nil,
+			"allowPrivilegeEscalation": nil,
+			"capabilities":             nil,
+		}},
+	}}
+
+	podSpecSchema = &mergeSchema{fields: map[string]*mergeSchema{
+		"containers":     {primary: true, item: containerSchema},
+		"initContainers": {listKey: "name", item: containerSchema},
+		"volumes": {listKey: "name", item: &mergeSchema{fields: map[string]*mergeSchema{
+			"name":                  nil,
+			"emptyDir":              nil,
+			"hostPath":              nil,
+			"configMap":             nil,
+			"secret":                nil,
+			"persistentVolumeClaim": nil,
+		}}},
+		"restartPolicy":      nil,
+		"serviceAccountName": nil,
+		"nodeSelector":       dynamicMapSchema,
+		"tolerations":        nil,
+		"affinity":           nil,
+		"hostNetwork":        nil,
+		"dnsPolicy":          nil,
+		"imagePullSecrets":   nil,
+	}}
+
+	// workloadSchema 主工作负载中由TemplateSpec管理的字段；selector、strategy等字段不受管理
+	workloadSchema = &mergeSchema{fields: map[string]*mergeSchema{
+		"metadata": {fields: map[string]*mergeSchema{
+			"name":        nil,
+			"namespace":   nil,
+			"labels":      dynamicMapSchema,
+			"annotations": dynamicMapSchema,
+		}},
+		"spec": {fields: map[string]*mergeSchema{
+			"replicas": nil,
+			"template": {fields: map[string]*mergeSchema{
+				"metadata": {fields: map[string]*mergeSchema{
+					"labels":      dynamicMapSchema,
+					"annotations": dynamicMapSchema,
+				}},
+				"spec": podSpecSchema,
+			}},
+		}},
+	}}
+)
+
+// mergeTemplateSpecIntoYAML 将结构化规格合并回原始多文档YAML
+// 只有与原始YAML解析结果不同的字段会被改写；规格未发生变化时原样返回baseYAML。
+func mergeTemplateSpecIntoYAML(baseYAML string, spec *models.TemplateSpec) (string, error) {
+	docs := splitYAMLDocuments(baseYAML)
+
+	workloadIndex := findWorkloadDocument(docs)
+	if workloadIndex < 0 {
+		// 原始YAML中没有工作负载，生成新的工作负载并保留其他资源
+		generated, err := GenerateYAMLFromTemplateSpec(spec, "")
+		if err != nil {
+			return "", err
+		}
+		return joinYAMLDocuments(append([]string{generated}, docs...)), nil
+	}
+
+	var workload yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(docs[workloadIndex]), &workload); err != nil {
+		return "", fmt.Errorf("invalid YAML format: %w", err)
+	}
+
+	current, err := ParseYAMLToTemplateSpec(docs[workloadIndex])
+	if err != nil {
+		return "", err
+	}
+
+	currentFields := normalizeValue(generateManagedWorkload(current))
+	desiredFields := normalizeValue(generateManagedWorkload(spec))
+	if reflect.DeepEqual(currentFields, desiredFields) {
+		return baseYAML, nil
+	}
+
+	merged := mergeValue(workload, currentFields, desiredFields, workloadSchema)
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal workload to YAML: %w", err)
+	}
+	docs[workloadIndex] = leadingComments(docs[workloadIndex]) + string(data)
+
+	return joinYAMLDocuments(docs), nil
+}
+
+// generateManagedWorkload 生成工作负载中由TemplateSpec管理的字段
+// 与 GenerateYAMLFromTemplateSpec 不同，这里不注入默认的app标签和selector。
+func generateManagedWorkload(spec *models.TemplateSpec) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"name": getDeploymentName(spec),
+	}
+	if spec.Namespace != "" {
+		metadata["namespace"] = spec.Namespace
+	}
+	if len(spec.Labels) > 0 {
+		metadata["labels"] = spec.Labels
+	}
+	if len(spec.Annotations) > 0 {
+		metadata["annotations"] = spec.Annotations
+	}
+
+	templateMetadata := map[string]interface{}{}
+	if len(spec.PodLabels) > 0 {
+		templateMetadata["labels"] = spec.PodLabels
+	}
+	if len(spec.PodAnnotations) > 0 {
+		templateMetadata["annotations"] = spec.PodAnnotations
+	}
+
+	workloadSpec := map[string]interface{}{
+		"template": map[string]interface{}{
+			"metadata": templateMetadata,
+			"spec":     generatePodSpec(spec),
+		},
+	}
+	if spec.Replicas > 0 {
+		workloadSpec["replicas"] = spec.Replicas
+	}
+
+	return map[string]interface{}{
+		"metadata": metadata,
+		"spec":     workloadSpec,
+	}
+}
+
+// mergeValue 把期望值合并到原始YAML节点上
+// current 为原始节点解析后再生成的值，与 desired 相同的部分保留原始节点不变。
+func mergeValue(original, current, desired interface{}, schema *mergeSchema) interface{} {
+	if schema == nil {
+		// 标量值相同时保留原始写法（例如未加引号的数字）
+		if isScalar(original) && isScalar(desired) && fmt.Sprint(original) == fmt.Sprint(desired) {
+			return original
+		}
+		return desired
+	}
+
+	if schema.item != nil {
+		originalList, _ := original.([]interface{})
+		currentList, _ := current.([]interface{})
+		desiredList, _ := desired.([]interface{})
+		if schema.primary {
+			return mergePrimaryListItem(originalList, currentList, desiredList, schema.item)
+		}
+		return mergeKeyedList(originalList, currentList, desiredList, schema)
+	}
+
+	return mergeMap(original, current, desired, schema)
+}
+
+// mergeMap 合并映射节点，未被schema管理的键保持不变
+func mergeMap(original, current, desired interface{}, schema *mergeSchema) yaml.MapSlice {
+	result, _ := original.(yaml.MapSlice)
+	result = append(yaml.MapSlice{}, result...)
+	currentMap, _ := current.(map[string]interface{})
+	desiredMap, _ := desired.(map[string]interface{})
+
+	for _, key := range schemaKeys(schema, currentMap, desiredMap) {
+		currentValue, currentExists := currentMap[key]
+		desiredValue, desiredExists := desiredMap[key]
+		if currentExists == desiredExists && reflect.DeepEqual(currentValue, desiredValue) {
+			continue
+		}
+
+		fieldSchema := schema.fields[key]
+		originalValue, originalExists := mapSliceGet(result, key)
+
+		if !desiredExists {
+			// 子映射中可能还有未被管理的字段，只删除受管理的部分
+			if originalExists && fieldSchema != nil && fieldSchema.item == nil {
+				if remaining := mergeMap(originalValue, currentValue, nil, fieldSchema); len(remaining) > 0 {
+					result = mapSliceSet(result, key, remaining)
+					continue
+				}
+			}
+			result = mapSliceDelete(result, key)
+			continue
+		}
+
+		result = mapSliceSet(result, key, mergeValue(originalValue, currentValue, desiredValue, fieldSchema))
+		for _, conflict := range schema.conflicts[key] {
+			result = mapSliceDelete(result, conflict)
+		}
+	}
+
+	return result
+}
+
+// mergePrimaryListItem 只合并列表中的第一个元素（主容器），其余元素原样保留
+func mergePrimaryListItem(original, current, desired []interface{}, itemSchema *mergeSchema) []interface{} {
+	if len(original) == 0 || len(desired) == 0 {
+		return desired
+	}
+
+	var currentItem interface{}
+	if len(current) > 0 {
+		currentItem = current[0]
+	}
+
+	result := append([]interface{}{}, original...)
+	result[0] = mergeValue(original[0], currentItem, desired[0], itemSchema)
+	return result
+}
+
+// mergeKeyedList 按键合并对象列表，顺序以期望值为准
+func mergeKeyedList(original, current, desired []interface{}, schema *mergeSchema) []interface{} {
+	result := make([]interface{}, 0, len(desired))
+	for _, desiredItem := range desired {
+		key := listItemKey(desiredItem, schema.listKey)
+		originalItem := findListItem(original, schema.listKey, key)
+		currentItem := findListItem(current, schema.listKey, key)
+
+		switch {
+		case originalItem == nil:
+			result = append(result, desiredItem)
+		case currentItem != nil && reflect.DeepEqual(currentItem, desiredItem):
+			result = append(result, originalItem)
+		default:
+			result = append(result, mergeValue(originalItem, currentItem, desiredItem, schema.item))
+		}
+	}
+	return result
+}
+
+// schemaKeys 返回映射中需要比较的键（排序以保证新增字段的顺序稳定）
+func schemaKeys(schema *mergeSchema, current, desired map[string]interface{}) []string {
+	keySet := make(map[string]bool)
+	if schema.dynamic {
+		for key := range current {
+			keySet[key] = true
+		}
+		for key := range desired {
+			keySet[key] = true
+		}
+	}
+	for key := range schema.fields {
+		keySet[key] = true
+	}
+
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// listItemKey 获取列表元素用于匹配的键值
+func listItemKey(item interface{}, key string) string {
+	switch v := item.(type) {
+	case yaml.MapSlice:
+		if value, ok := mapSliceGet(v, key); ok {
+			return fmt.Sprint(value)
+		}
+	case map[string]interface{}:
+		if value, ok := v[key]; ok {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// findListItem 按键值查找列表元素
+func findListItem(items []interface{}, key, value string) interface{} {
+	for _, item := range items {
+		if listItemKey(item, key) == value {
+			return item
+		}
+	}
+	return nil
+}
+
+// normalizeValue 把生成器输出统一为 map[string]interface{} / []interface{} / 标量，便于比较
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = normalizeValue(item)
+		}
+		return result
+	case map[string]string:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = item
+		}
+		return result
+	case []map[string]interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeValue(item)
+		}
+		return result
+	case []string:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = item
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// isScalar 判断是否为YAML标量
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, bool, int, int32, int64, uint64, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// mapSliceGet 获取有序映射中的值
+func mapSliceGet(ms yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range ms {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+// mapSliceSet 设置有序映射中的值，新键追加到末尾
+func mapSliceSet(ms yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i, item := range ms {
+		if item.Key == key {
+			ms[i].Value = value
+			return ms
+		}
+	}
+	return append(ms, yaml.MapItem{Key: key, Value: value})
+}
+
+// mapSliceDelete 删除有序映射中的键
+func mapSliceDelete(ms yaml.MapSlice, key string) yaml.MapSlice {
+	result := ms[:0]
+	for _, item := range ms {
+		if item.Key != key {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// findWorkloadDocument 查找主工作负载所在的文档下标，未找到时返回-1
+func findWorkloadDocument(docs []string) int {
+	kinds := make([]string, len(docs))
+	for i, doc := range docs {
+		var resource struct {
+			Kind string `yaml:"kind"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &resource); err == nil {
+			kinds[i] = resource.Kind
+		}
+	}
+
+	return findWorkloadIndex(kinds)
+}
+
+// leadingComments 获取文档开头的注释行（重新序列化时其余注释无法保留）
+func leadingComments(doc string) string {
+	var comments []string
+	for _, line := range strings.Split(strings.TrimLeft(doc, "\n"), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			break
+		}
+		comments = append(comments, line+"\n")
+	}
+	return strings.Join(comments, "")
+}
+
+// splitYAMLDocuments 按 "---" 分隔行拆分多文档YAML，文档内容保持原样
+func splitYAMLDocuments(content string) []string {
+	var docs []string
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimRight(line, " \t\r") == "---" {
+			docs = append(docs, strings.Join(lines, "\n"))
+			lines = nil
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return append(docs, strings.Join(lines, "\n"))
+}
+
+// joinYAMLDocuments 使用 "---" 拼接YAML文档，空文档会被忽略
+func joinYAMLDocuments(docs []string) string {
+	var parts []string
+	for _, doc := range docs {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		parts = append(parts, strings.Trim(doc, "\n")+"\n")
+	}
+	return strings.Join(parts, "---\n")
+}
@@ -10,48 +10,72 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// workloadKinds 可被结构化编辑的工作负载类型（按优先级排列）
+var workloadKinds = []string{"Deployment", "StatefulSet"}
+
 // ParseYAMLToTemplateSpec 解析YAML到模板规格
+// 多文档YAML中第一个Deployment（没有Deployment时为StatefulSet）作为主工作负载被解析，
+// 其他资源记录在 ExtraResources 中。
 func ParseYAMLToTemplateSpec(yamlContent string) (*models.TemplateSpec, error) {
 	spec := &models.TemplateSpec{}
 
-	// 按文档分割YAML
-	documents := strings.Split(yamlContent, "\n---")
-	var deploymentSpec map[interface{}]interface{}
-
-	// 查找Deployment资源
-	for _, doc := range documents {
-		doc = strings.TrimSpace(doc)
-		if doc == "" {
+	var resources []map[interface{}]interface{}
+	for _, doc := range splitYAMLDocuments(yamlContent) {
+		if strings.TrimSpace(doc) == "" {
 			continue
 		}
 
 		var resource map[interface{}]interface{}
-		if err := yaml.Unmarshal([]byte(doc), &resource); err != nil {
+		if err := yaml.Unmarshal([]byte(doc), &resource); err != nil || resource == nil {
 			continue
 		}
+		resources = append(resources, resource)
+	}
+
+	// 查找主工作负载
+	kinds := make([]string, len(resources))
+	for i, resource := range resources {
+		kinds[i] = scalarString(resource["kind"])
+	}
+	workloadIndex := findWorkloadIndex(kinds)
+	if workloadIndex < 0 {
+		return spec, fmt.Errorf("no Deployment resource found in YAML")
+	}
+	workload := resources[workloadIndex]
 
-		if resource["kind"] == "Deployment" {
-			deploymentSpec = resource
-			break
+	for i, resource := range resources {
+		if i == workloadIndex {
+			continue
+		}
+		ref := models.ResourceRef{Kind: kinds[i]}
+		if metadata, ok := resource["metadata"].(map[interface{}]interface{}); ok {
+			ref.Name = scalarString(metadata["name"])
 		}
+		spec.ExtraResources = append(spec.ExtraResources, ref)
 	}
 
-	if deploymentSpec == nil {
-		return spec, fmt.Errorf("no Deployment resource found in YAML")
+	spec.WorkloadKind = scalarString(workload["kind"])
+
+	// 解析metadata
+	if metadata, ok := workload["metadata"].(map[interface{}]interface{}); ok {
+		spec.DeploymentName = scalarString(metadata["name"])
+		spec.Namespace = scalarString(metadata["namespace"])
+		spec.Labels = stringMap(metadata["labels"])
+		spec.Annotations = stringMap(metadata["annotations"])
 	}
 
-	// 解析spec.template.spec.containers
-	if specData, ok := deploymentSpec["spec"].(map[interface{}]interface{}); ok {
+	// 解析spec.template
+	if specData, ok := workload["spec"].(map[interface{}]interface{}); ok {
+		if replicas, ok := toInt32(specData["replicas"]); ok {
+			spec.Replicas = replicas
+		}
 		if templateData, ok := specData["template"].(map[interface{}]interface{}); ok {
+			if templateMetadata, ok := templateData["metadata"].(map[interface{}]interface{}); ok {
+				spec.PodLabels = stringMap(templateMetadata["labels"])
+				spec.PodAnnotations = stringMap(templateMetadata["annotations"])
+			}
 			if podSpecData, ok := templateData["spec"].(map[interface{}]interface{}); ok {
-				if containersData, ok := podSpecData["containers"].([]interface{}); ok && len(containersData) > 0 {
-					if containerData, ok := containersData[0].(map[interface{}]interface{}); ok {
-						// 解析容器规格
-						if err := parseContainerSpec(containerData, spec); err != nil {
-							return spec, err
-						}
-					}
-				}
+				parsePodSpec(podSpecData, spec)
 			}
 		}
 	}
@@ -59,237 +83,582 @@ func ParseYAMLToTemplateSpec(yamlContent string) (*models.TemplateSpec, error) {
 	return spec, nil
 }
 
-// parseContainerSpec 解析容器规格
-func parseContainerSpec(containerData map[interface{}]interface{}, spec *models.TemplateSpec) error {
-	// 解析镜像
-	if image, ok := containerData["image"].(string); ok {
-		spec.Image = image
+// findWorkloadIndex 根据资源类型列表查找主工作负载的下标，未找到时返回-1
+func findWorkloadIndex(kinds []string) int {
+	for _, workloadKind := range workloadKinds {
+		for i, kind := range kinds {
+			if kind == workloadKind {
+				return i
+			}
+		}
 	}
+	return -1
+}
 
-	// 解析环境变量
-	if envData, ok := containerData["env"].([]interface{}); ok {
-		var envVars models.EnvironmentVars
-		for _, envItem := range envData {
-			if envMap, ok := envItem.(map[interface{}]interface{}); ok {
-				if name, ok := envMap["name"].(string); ok {
-					var value string
-					if val, ok := envMap["value"].(string); ok {
-						value = val
-					}
-					envVars = append(envVars, models.EnvironmentVar{
-						Name:  name,
-						Value: value,
-					})
+// parsePodSpec 解析Pod规格
+func parsePodSpec(podSpecData map[interface{}]interface{}, spec *models.TemplateSpec) {
+	spec.RestartPolicy = scalarString(podSpecData["restartPolicy"])
+	spec.ServiceAccount = scalarString(podSpecData["serviceAccountName"])
+	spec.NodeSelector = stringMap(podSpecData["nodeSelector"])
+	spec.DNSPolicy = scalarString(podSpecData["dnsPolicy"])
+	if hostNetwork, ok := podSpecData["hostNetwork"].(bool); ok {
+		spec.HostNetwork = hostNetwork
+	}
+
+	// 解析容忍度
+	if tolerationsData, ok := podSpecData["tolerations"].([]interface{}); ok {
+		for _, item := range tolerationsData {
+			if tolerationMap, ok := item.(map[interface{}]interface{}); ok {
+				toleration := models.Toleration{
+					Key:      scalarString(tolerationMap["key"]),
+					Operator: scalarString(tolerationMap["operator"]),
+					Value:    scalarString(tolerationMap["value"]),
+					Effect:   scalarString(tolerationMap["effect"]),
+				}
+				if seconds, ok := toInt64(tolerationMap["tolerationSeconds"]); ok {
+					toleration.TolerationSeconds = &seconds
 				}
+				spec.Tolerations = append(spec.Tolerations, toleration)
 			}
 		}
-		spec.Env = envVars
 	}
 
-	// 解析命令
-	if commandData, ok := containerData["command"].([]interface{}); ok {
-		var commands []string
-		for _, cmd := range commandData {
-			if cmdStr, ok := cmd.(string); ok {
-				commands = append(commands, cmdStr)
+	// 解析亲和性
+	if affinityData, ok := podSpecData["affinity"].(map[interface{}]interface{}); ok {
+		spec.Affinity = parseAffinity(affinityData)
+	}
+
+	// 解析镜像拉取密钥
+	if secretsData, ok := podSpecData["imagePullSecrets"].([]interface{}); ok {
+		for _, item := range secretsData {
+			if secretMap, ok := item.(map[interface{}]interface{}); ok {
+				if name := scalarString(secretMap["name"]); name != "" {
+					spec.ImagePullSecrets = append(spec.ImagePullSecrets, name)
+				}
+			}
+		}
+	}
+
+	// 解析初始化容器
+	if initContainersData, ok := podSpecData["initContainers"].([]interface{}); ok {
+		for _, item := range initContainersData {
+			if containerData, ok := item.(map[interface{}]interface{}); ok {
+				spec.InitContainers = append(spec.InitContainers, parseContainer(containerData))
+			}
+		}
+	}
+
+	// 解析卷
+	if volumesData, ok := podSpecData["volumes"].([]interface{}); ok {
+		for _, item := range volumesData {
+			if volumeData, ok := item.(map[interface{}]interface{}); ok {
+				spec.Volumes = append(spec.Volumes, parseVolume(volumeData))
 			}
 		}
-		spec.Command = commands
 	}
 
-	// 解析参数
-	if argsData, ok := containerData["args"].([]interface{}); ok {
-		var args []string
-		for _, arg := range argsData {
-			if argStr, ok := arg.(string); ok {
-				args = append(args, argStr)
+	// 解析主容器（第一个容器），其余容器在结构化编辑时原样保留
+	if containersData, ok := podSpecData["containers"].([]interface{}); ok && len(containersData) > 0 {
+		if containerData, ok := containersData[0].(map[interface{}]interface{}); ok {
+			parseContainerSpec(containerData, spec)
+		}
+	}
+}
+
+// parseContainerSpec 解析主容器规格
+func parseContainerSpec(containerData map[interface{}]interface{}, spec *models.TemplateSpec) {
+	container := parseContainer(containerData)
+
+	spec.ContainerName = container.Name
+	spec.Image = container.Image
+	spec.ImagePullPolicy = container.ImagePullPolicy
+	spec.Env = container.Env
+	spec.Command = container.Command
+	spec.Args = container.Args
+	spec.Ports = container.Ports
+	spec.Resources = container.Resources
+	spec.VolumeMounts = container.VolumeMounts
+	spec.WorkingDir = container.WorkingDir
+	spec.LivenessProbe = container.LivenessProbe
+	spec.ReadinessProbe = container.ReadinessProbe
+	spec.StartupProbe = container.StartupProbe
+	spec.SecurityContext = container.SecurityContext
+}
+
+// parseContainer 解析容器定义
+func parseContainer(containerData map[interface{}]interface{}) models.Container {
+	container := models.Container{
+		Name:            scalarString(containerData["name"]),
+		Image:           scalarString(containerData["image"]),
+		ImagePullPolicy: scalarString(containerData["imagePullPolicy"]),
+		Command:         stringList(containerData["command"]),
+		Args:            stringList(containerData["args"]),
+		WorkingDir:      scalarString(containerData["workingDir"]),
+	}
+
+	// 解析环境变量（valueFrom类型的变量值为空，原始定义在结构化编辑时保留）
+	if envData, ok := containerData["env"].([]interface{}); ok {
+		for _, envItem := range envData {
+			if envMap, ok := envItem.(map[interface{}]interface{}); ok {
+				if name := scalarString(envMap["name"]); name != "" {
+					container.Env = append(container.Env, models.EnvironmentVar{
+						Name:  name,
+						Value: scalarString(envMap["value"]),
+					})
+				}
 			}
 		}
-		spec.Args = args
 	}
 
 	// 解析端口
 	if portsData, ok := containerData["ports"].([]interface{}); ok {
-		var ports []models.ContainerPort
 		for _, portItem := range portsData {
 			if portMap, ok := portItem.(map[interface{}]interface{}); ok {
-				port := models.ContainerPort{}
-				if containerPort, ok := portMap["containerPort"].(int); ok {
-					port.ContainerPort = int32(containerPort)
-				}
-				if name, ok := portMap["name"].(string); ok {
-					port.Name = name
+				port := models.ContainerPort{
+					Name:     scalarString(portMap["name"]),
+					Protocol: scalarString(portMap["protocol"]),
 				}
-				if protocol, ok := portMap["protocol"].(string); ok {
-					port.Protocol = protocol
+				if containerPort, ok := toInt32(portMap["containerPort"]); ok {
+					port.ContainerPort = containerPort
 				}
-				ports = append(ports, port)
+				container.Ports = append(container.Ports, port)
 			}
 		}
-		spec.Ports = ports
 	}
 
 	// 解析资源限制
 	if resourcesData, ok := containerData["resources"].(map[interface{}]interface{}); ok {
-		resources := models.ResourceLimits{}
 		if requestsData, ok := resourcesData["requests"].(map[interface{}]interface{}); ok {
-			if cpu, ok := requestsData["cpu"].(string); ok {
-				resources.Requests.CPU = cpu
-			}
-			if memory, ok := requestsData["memory"].(string); ok {
-				resources.Requests.Memory = memory
-			}
+			container.Resources.Requests.CPU = scalarString(requestsData["cpu"])
+			container.Resources.Requests.Memory = scalarString(requestsData["memory"])
 		}
 		if limitsData, ok := resourcesData["limits"].(map[interface{}]interface{}); ok {
-			if cpu, ok := limitsData["cpu"].(string); ok {
-				resources.Limits.CPU = cpu
-			}
-			if memory, ok := limitsData["memory"].(string); ok {
-				resources.Limits.Memory = memory
-			}
+			container.Resources.Limits.CPU = scalarString(limitsData["cpu"])
+			container.Resources.Limits.Memory = scalarString(limitsData["memory"])
 		}
-		spec.Resources = resources
 	}
 
 	// 解析卷挂载
 	if volumeMountsData, ok := containerData["volumeMounts"].([]interface{}); ok {
-		var volumeMounts []models.VolumeMount
 		for _, vmItem := range volumeMountsData {
 			if vmMap, ok := vmItem.(map[interface{}]interface{}); ok {
-				vm := models.VolumeMount{}
-				if name, ok := vmMap["name"].(string); ok {
-					vm.Name = name
-				}
-				if mountPath, ok := vmMap["mountPath"].(string); ok {
-					vm.MountPath = mountPath
-				}
-				if subPath, ok := vmMap["subPath"].(string); ok {
-					vm.SubPath = subPath
+				vm := models.VolumeMount{
+					Name:      scalarString(vmMap["name"]),
+					MountPath: scalarString(vmMap["mountPath"]),
+					SubPath:   scalarString(vmMap["subPath"]),
 				}
 				if readOnly, ok := vmMap["readOnly"].(bool); ok {
 					vm.ReadOnly = readOnly
 				}
-				volumeMounts = append(volumeMounts, vm)
+				container.VolumeMounts = append(container.VolumeMounts, vm)
 			}
 		}
-		spec.VolumeMounts = volumeMounts
 	}
 
-	// 解析工作目录
-	if workingDir, ok := containerData["workingDir"].(string); ok {
-		spec.WorkingDir = workingDir
+	// 解析探针
+	if probeData, ok := containerData["livenessProbe"].(map[interface{}]interface{}); ok {
+		container.LivenessProbe = parseProbe(probeData)
 	}
-
-	// 解析存活探针
-	if livenessProbeData, ok := containerData["livenessProbe"].(map[interface{}]interface{}); ok {
-		probe, err := parseProbe(livenessProbeData)
-		if err == nil {
-			spec.LivenessProbe = probe
-		}
+	if probeData, ok := containerData["readinessProbe"].(map[interface{}]interface{}); ok {
+		container.ReadinessProbe = parseProbe(probeData)
 	}
-
-	// 解析就绪探针
-	if readinessProbeData, ok := containerData["readinessProbe"].(map[interface{}]interface{}); ok {
-		probe, err := parseProbe(readinessProbeData)
-		if err == nil {
-			spec.ReadinessProbe = probe
-		}
+	if probeData, ok := containerData["startupProbe"].(map[interface{}]interface{}); ok {
+		container.StartupProbe = parseProbe(probeData)
 	}
 
 	// 解析安全上下文
 	if securityContextData, ok := containerData["securityContext"].(map[interface{}]interface{}); ok {
-		securityContext := &models.SecurityContext{}
-		if runAsUser, ok := securityContextData["runAsUser"].(int); ok {
-			runAsUser64 := int64(runAsUser)
-			securityContext.RunAsUser = &runAsUser64
-		}
-		if runAsNonRoot, ok := securityContextData["runAsNonRoot"].(bool); ok {
-			securityContext.RunAsNonRoot = &runAsNonRoot
-		}
-		if readOnlyRootFilesystem, ok := securityContextData["readOnlyRootFilesystem"].(bool); ok {
-			securityContext.ReadOnlyRootFilesystem = &readOnlyRootFilesystem
-		}
-		if allowPrivilegeEscalation, ok := securityContextData["allowPrivilegeEscalation"].(bool); ok {
-			securityContext.AllowPrivilegeEscalation = &allowPrivilegeEscalation
-		}
-		spec.SecurityContext = securityContext
+		container.SecurityContext = parseSecurityContext(securityContextData)
 	}
 
-	return nil
+	return container
 }
 
 // parseProbe 解析探针配置
-func parseProbe(probeData map[interface{}]interface{}) (*models.Probe, error) {
+func parseProbe(probeData map[interface{}]interface{}) *models.Probe {
 	probe := &models.Probe{}
 
-	if initialDelaySeconds, ok := probeData["initialDelaySeconds"].(int); ok {
-		probe.InitialDelaySeconds = int32(initialDelaySeconds)
+	if initialDelaySeconds, ok := toInt32(probeData["initialDelaySeconds"]); ok {
+		probe.InitialDelaySeconds = initialDelaySeconds
 	}
-	if periodSeconds, ok := probeData["periodSeconds"].(int); ok {
-		probe.PeriodSeconds = int32(periodSeconds)
+	if periodSeconds, ok := toInt32(probeData["periodSeconds"]); ok {
+		probe.PeriodSeconds = periodSeconds
 	}
-	if timeoutSeconds, ok := probeData["timeoutSeconds"].(int); ok {
-		probe.TimeoutSeconds = int32(timeoutSeconds)
+	if timeoutSeconds, ok := toInt32(probeData["timeoutSeconds"]); ok {
+		probe.TimeoutSeconds = timeoutSeconds
 	}
-	if successThreshold, ok := probeData["successThreshold"].(int); ok {
-		probe.SuccessThreshold = int32(successThreshold)
+	if successThreshold, ok := toInt32(probeData["successThreshold"]); ok {
+		probe.SuccessThreshold = successThreshold
 	}
-	if failureThreshold, ok := probeData["failureThreshold"].(int); ok {
-		probe.FailureThreshold = int32(failureThreshold)
+	if failureThreshold, ok := toInt32(probeData["failureThreshold"]); ok {
+		probe.FailureThreshold = failureThreshold
 	}
 
 	// 解析HTTP GET探针
 	if httpGetData, ok := probeData["httpGet"].(map[interface{}]interface{}); ok {
-		httpGet := &models.HTTPGetAction{}
-		if path, ok := httpGetData["path"].(string); ok {
-			httpGet.Path = path
-		}
-		if port, ok := httpGetData["port"].(int); ok {
-			httpGet.Port = intstr.FromInt(port)
-		} else if portStr, ok := httpGetData["port"].(string); ok {
-			httpGet.Port = intstr.FromString(portStr)
+		httpGet := &models.HTTPGetAction{
+			Path:   scalarString(httpGetData["path"]),
+			Port:   parseIntOrString(httpGetData["port"]),
+			Host:   scalarString(httpGetData["host"]),
+			Scheme: scalarString(httpGetData["scheme"]),
 		}
-		if host, ok := httpGetData["host"].(string); ok {
-			httpGet.Host = host
-		}
-		if scheme, ok := httpGetData["scheme"].(string); ok {
-			httpGet.Scheme = scheme
+		if headersData, ok := httpGetData["httpHeaders"].([]interface{}); ok {
+			for _, item := range headersData {
+				if headerMap, ok := item.(map[interface{}]interface{}); ok {
+					httpGet.HTTPHeaders = append(httpGet.HTTPHeaders, models.HTTPHeader{
+						Name:  scalarString(headerMap["name"]),
+						Value: scalarString(headerMap["value"]),
+					})
+				}
+			}
 		}
 		probe.HTTPGet = httpGet
 	}
 
 	// 解析TCP Socket探针
 	if tcpSocketData, ok := probeData["tcpSocket"].(map[interface{}]interface{}); ok {
-		tcpSocket := &models.TCPSocketAction{}
-		if port, ok := tcpSocketData["port"].(int); ok {
-			tcpSocket.Port = intstr.FromInt(port)
-		} else if portStr, ok := tcpSocketData["port"].(string); ok {
-			tcpSocket.Port = intstr.FromString(portStr)
-		}
-		if host, ok := tcpSocketData["host"].(string); ok {
-			tcpSocket.Host = host
+		probe.TCPSocket = &models.TCPSocketAction{
+			Port: parseIntOrString(tcpSocketData["port"]),
+			Host: scalarString(tcpSocketData["host"]),
 		}
-		probe.TCPSocket = tcpSocket
 	}
 
 	// 解析Exec探针
 	if execData, ok := probeData["exec"].(map[interface{}]interface{}); ok {
-		if commandData, ok := execData["command"].([]interface{}); ok {
-			var commands []string
-			for _, cmd := range commandData {
-				if cmdStr, ok := cmd.(string); ok {
-					commands = append(commands, cmdStr)
+		if commands := stringList(execData["command"]); len(commands) > 0 {
+			probe.Exec = &models.ExecAction{Command: commands}
+		}
+	}
+
+	return probe
+}
+
+// parseSecurityContext 解析容器安全上下文
+func parseSecurityContext(securityContextData map[interface{}]interface{}) *models.SecurityContext {
+	securityContext := &models.SecurityContext{}
+	if runAsUser, ok := toInt64(securityContextData["runAsUser"]); ok {
+		securityContext.RunAsUser = &runAsUser
+	}
+	if runAsGroup, ok := toInt64(securityContextData["runAsGroup"]); ok {
+		securityContext.RunAsGroup = &runAsGroup
+	}
+	if runAsNonRoot, ok := securityContextData["runAsNonRoot"].(bool); ok {
+		securityContext.RunAsNonRoot = &runAsNonRoot
+	}
+	if readOnlyRootFilesystem, ok := securityContextData["readOnlyRootFilesystem"].(bool); ok {
+		securityContext.ReadOnlyRootFilesystem = &readOnlyRootFilesystem
+	}
+	if allowPrivilegeEscalation, ok := securityContextData["allowPrivilegeEscalation"].(bool); ok {
+		securityContext.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+	}
+	if capabilitiesData, ok := securityContextData["capabilities"].(map[interface{}]interface{}); ok {
+		securityContext.Capabilities = &models.Capabilities{
+			Add:  stringList(capabilitiesData["add"]),
+			Drop: stringList(capabilitiesData["drop"]),
+		}
+	}
+	return securityContext
+}
+
+// parseVolume 解析卷定义
+func parseVolume(volumeData map[interface{}]interface{}) models.Volume {
+	volume := models.Volume{Name: scalarString(volumeData["name"])}
+	source := &models.VolumeSource{}
+
+	if emptyDirData, ok := volumeData["emptyDir"].(map[interface{}]interface{}); ok {
+		source.EmptyDir = &models.EmptyDirVolumeSource{
+			Medium:    scalarString(emptyDirData["medium"]),
+			SizeLimit: scalarString(emptyDirData["sizeLimit"]),
+		}
+	} else if _, ok := volumeData["emptyDir"]; ok {
+		// emptyDir: {} 以及 emptyDir: 空值
+		source.EmptyDir = &models.EmptyDirVolumeSource{}
+	}
+
+	if hostPathData, ok := volumeData["hostPath"].(map[interface{}]interface{}); ok {
+		source.HostPath = &models.HostPathVolumeSource{
+			Path: scalarString(hostPathData["path"]),
+			Type: scalarString(hostPathData["type"]),
+		}
+	}
+
+	if configMapData, ok := volumeData["configMap"].(map[interface{}]interface{}); ok {
+		configMap := &models.ConfigMapVolumeSource{
+			Items: parseKeyToPaths(configMapData["items"]),
+		}
+		configMap.Name = scalarString(configMapData["name"])
+		if defaultMode, ok := toInt32(configMapData["defaultMode"]); ok {
+			configMap.DefaultMode = &defaultMode
+		}
+		if optional, ok := configMapData["optional"].(bool); ok {
+			configMap.Optional = &optional
+		}
+		source.ConfigMap = configMap
+	}
+
+	if secretData, ok := volumeData["secret"].(map[interface{}]interface{}); ok {
+		secret := &models.SecretVolumeSource{
+			SecretName: scalarString(secretData["secretName"]),
+			Items:      parseKeyToPaths(secretData["items"]),
+		}
+		if defaultMode, ok := toInt32(secretData["defaultMode"]); ok {
+			secret.DefaultMode = &defaultMode
+		}
+		if optional, ok := secretData["optional"].(bool); ok {
+			secret.Optional = &optional
+		}
+		source.Secret = secret
+	}
+
+	if pvcData, ok := volumeData["persistentVolumeClaim"].(map[interface{}]interface{}); ok {
+		pvc := &models.PVCVolumeSource{ClaimName: scalarString(pvcData["claimName"])}
+		if readOnly, ok := pvcData["readOnly"].(bool); ok {
+			pvc.ReadOnly = readOnly
+		}
+		source.PVC = pvc
+	}
+
+	// 其他类型的卷源（csi、projected等）不做结构化解析
+	if source.EmptyDir != nil || source.HostPath != nil || source.ConfigMap != nil || source.Secret != nil || source.PVC != nil {
+		volume.VolumeSource = source
+	}
+
+	return volume
+}
+
+// parseKeyToPaths 解析ConfigMap/Secret卷的items
+func parseKeyToPaths(data interface{}) []models.KeyToPath {
+	itemsData, ok := data.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var items []models.KeyToPath
+	for _, item := range itemsData {
+		if itemMap, ok := item.(map[interface{}]interface{}); ok {
+			keyToPath := models.KeyToPath{
+				Key:  scalarString(itemMap["key"]),
+				Path: scalarString(itemMap["path"]),
+			}
+			if mode, ok := toInt32(itemMap["mode"]); ok {
+				keyToPath.Mode = &mode
+			}
+			items = append(items, keyToPath)
+		}
+	}
+	return items
+}
+
+// parseAffinity 解析亲和性
+func parseAffinity(affinityData map[interface{}]interface{}) *models.Affinity {
+	affinity := &models.Affinity{}
+
+	if nodeAffinityData, ok := affinityData["nodeAffinity"].(map[interface{}]interface{}); ok {
+		nodeAffinity := &models.NodeAffinity{}
+		if requiredData, ok := nodeAffinityData["requiredDuringSchedulingIgnoredDuringExecution"].(map[interface{}]interface{}); ok {
+			nodeSelector := &models.NodeSelector{}
+			if termsData, ok := requiredData["nodeSelectorTerms"].([]interface{}); ok {
+				for _, item := range termsData {
+					if termMap, ok := item.(map[interface{}]interface{}); ok {
+						nodeSelector.NodeSelectorTerms = append(nodeSelector.NodeSelectorTerms, parseNodeSelectorTerm(termMap))
+					}
 				}
 			}
-			probe.Exec = &models.ExecAction{Command: commands}
+			nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = nodeSelector
+		}
+		if preferredData, ok := nodeAffinityData["preferredDuringSchedulingIgnoredDuringExecution"].([]interface{}); ok {
+			for _, item := range preferredData {
+				if termMap, ok := item.(map[interface{}]interface{}); ok {
+					term := models.PreferredSchedulingTerm{}
+					if weight, ok := toInt32(termMap["weight"]); ok {
+						term.Weight = weight
+					}
+					if preferenceMap, ok := termMap["preference"].(map[interface{}]interface{}); ok {
+						term.Preference = parseNodeSelectorTerm(preferenceMap)
+					}
+					nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, term)
+				}
+			}
+		}
+		affinity.NodeAffinity = nodeAffinity
+	}
+
+	if podAffinityData, ok := affinityData["podAffinity"].(map[interface{}]interface{}); ok {
+		required, preferred := parsePodAffinityTerms(podAffinityData)
+		affinity.PodAffinity = &models.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution:  required,
+			PreferredDuringSchedulingIgnoredDuringExecution: preferred,
+		}
+	}
+
+	if podAntiAffinityData, ok := affinityData["podAntiAffinity"].(map[interface{}]interface{}); ok {
+		required, preferred := parsePodAffinityTerms(podAntiAffinityData)
+		affinity.PodAntiAffinity = &models.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution:  required,
+			PreferredDuringSchedulingIgnoredDuringExecution: preferred,
 		}
 	}
 
-	return probe, nil
+	return affinity
+}
+
+// parseNodeSelectorTerm 解析节点选择器条件
+func parseNodeSelectorTerm(termData map[interface{}]interface{}) models.NodeSelectorTerm {
+	term := models.NodeSelectorTerm{}
+	for _, expr := range parseRequirements(termData["matchExpressions"]) {
+		term.MatchExpressions = append(term.MatchExpressions, models.NodeSelectorRequirement(expr))
+	}
+	for _, field := range parseRequirements(termData["matchFields"]) {
+		term.MatchFields = append(term.MatchFields, models.NodeSelectorRequirement(field))
+	}
+	return term
+}
+
+// parsePodAffinityTerms 解析Pod（反）亲和性中的必需与首选条件
+func parsePodAffinityTerms(data map[interface{}]interface{}) ([]models.PodAffinityTerm, []models.WeightedPodAffinityTerm) {
+	var required []models.PodAffinityTerm
+	var preferred []models.WeightedPodAffinityTerm
+
+	if requiredData, ok := data["requiredDuringSchedulingIgnoredDuringExecution"].([]interface{}); ok {
+		for _, item := range requiredData {
+			if termMap, ok := item.(map[interface{}]interface{}); ok {
+				required = append(required, parsePodAffinityTerm(termMap))
+			}
+		}
+	}
+
+	if preferredData, ok := data["preferredDuringSchedulingIgnoredDuringExecution"].([]interface{}); ok {
+		for _, item := range preferredData {
+			if termMap, ok := item.(map[interface{}]interface{}); ok {
+				term := models.WeightedPodAffinityTerm{}
+				if weight, ok := toInt32(termMap["weight"]); ok {
+					term.Weight = weight
+				}
+				if podAffinityTermMap, ok := termMap["podAffinityTerm"].(map[interface{}]interface{}); ok {
+					term.PodAffinityTerm = parsePodAffinityTerm(podAffinityTermMap)
+				}
+				preferred = append(preferred, term)
+			}
+		}
+	}
+
+	return required, preferred
+}
+
+// parsePodAffinityTerm 解析Pod亲和性条件
+func parsePodAffinityTerm(termData map[interface{}]interface{}) models.PodAffinityTerm {
+	term := models.PodAffinityTerm{
+		TopologyKey: scalarString(termData["topologyKey"]),
+		Namespaces:  stringList(termData["namespaces"]),
+	}
+	if selectorData, ok := termData["labelSelector"].(map[interface{}]interface{}); ok {
+		term.LabelSelector = &models.LabelSelector{
+			MatchLabels:      stringMap(selectorData["matchLabels"]),
+			MatchExpressions: parseRequirements(selectorData["matchExpressions"]),
+		}
+	}
+	return term
+}
+
+// parseRequirements 解析 key/operator/values 形式的选择器要求
+func parseRequirements(data interface{}) []models.LabelSelectorRequirement {
+	itemsData, ok := data.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var requirements []models.LabelSelectorRequirement
+	for _, item := range itemsData {
+		if itemMap, ok := item.(map[interface{}]interface{}); ok {
+			requirements = append(requirements, models.LabelSelectorRequirement{
+				Key:      scalarString(itemMap["key"]),
+				Operator: scalarString(itemMap["operator"]),
+				Values:   stringList(itemMap["values"]),
+			})
+		}
+	}
+	return requirements
+}
+
+// scalarString 将YAML标量转换为字符串，非标量返回空字符串
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int, int64, float64, bool:
+		return fmt.Sprint(v)
+	default:
+		return ""
+	}
+}
+
+// stringList 将YAML序列转换为字符串列表
+func stringList(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var result []string
+	for _, item := range items {
+		result = append(result, scalarString(item))
+	}
+	return result
+}
+
+// stringMap 将YAML映射转换为字符串映射
+func stringMap(value interface{}) map[string]string {
+	items, ok := value.(map[interface{}]interface{})
+	if !ok || len(items) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(items))
+	for key, item := range items {
+		result[fmt.Sprint(key)] = scalarString(item)
+	}
+	return result
+}
+
+// toInt64 将YAML整数转换为int64
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// toInt32 将YAML整数转换为int32
+func toInt32(value interface{}) (int32, bool) {
+	v, ok := toInt64(value)
+	return int32(v), ok
+}
+
+// parseIntOrString 解析端口等可为数字或名称的字段
+func parseIntOrString(value interface{}) intstr.IntOrString {
+	if port, ok := toInt64(value); ok {
+		return intstr.FromInt(int(port))
+	}
+	if portStr, ok := value.(string); ok {
+		return intstr.FromString(portStr)
+	}
+	return intstr.IntOrString{}
 }
 
 // GenerateYAMLFromTemplateSpec 从模板规格重新生成YAML
-func GenerateYAMLFromTemplateSpec(spec *models.TemplateSpec) (string, error) {
+// baseYAML 为空时生成单个工作负载；否则把结构化字段合并回原始的多文档YAML，
+// TemplateSpec未建模的字段、其他容器以及其他资源保持不变。
+func GenerateYAMLFromTemplateSpec(spec *models.TemplateSpec, baseYAML string) (string, error) {
+	if strings.TrimSpace(baseYAML) != "" {
+		return mergeTemplateSpecIntoYAML(baseYAML, spec)
+	}
+
 	// 创建Deployment元数据
 	metadata := map[string]interface{}{
 		"name": getDeploymentName(spec),
@@ -343,10 +712,16 @@ func GenerateYAMLFromTemplateSpec(spec *models.TemplateSpec) (string, error) {
 		},
 	}
 
+	// StatefulSet 需要关联的 headless Service 名称
+	kind := getWorkloadKind(spec)
+	if kind == "StatefulSet" {
+		deploymentSpec["serviceName"] = getDeploymentName(spec)
+	}
+
 	// 创建完整的Deployment结构
 	deployment := map[string]interface{}{
 		"apiVersion": "apps/v1",
-		"kind":       "Deployment",
+		"kind":       kind,
 		"metadata":   metadata,
 		"spec":       deploymentSpec,
 	}
@@ -360,6 +735,14 @@ func GenerateYAMLFromTemplateSpec(spec *models.TemplateSpec) (string, error) {
 	return string(yamlData), nil
 }
 
+// getWorkloadKind 获取工作负载类型
+func getWorkloadKind(spec *models.TemplateSpec) string {
+	if spec.WorkloadKind == "StatefulSet" {
+		return spec.WorkloadKind
+	}
+	return "Deployment"
+}
+
 // getDeploymentName 获取Deployment名称
 func getDeploymentName(spec *models.TemplateSpec) string {
 	if spec.DeploymentName != "" {
@@ -1037,7 +1420,7 @@ func generateProbeSpec(probe *models.Probe) map[string]interface{} {
 			httpGet["path"] = probe.HTTPGet.Path
 		}
 		if probe.HTTPGet.Port != (intstr.IntOrString{}) {
-			httpGet["port"] = probe.HTTPGet.Port
+			httpGet["port"] = intOrStringValue(probe.HTTPGet.Port)
 		}
 		if probe.HTTPGet.Host != "" {
 			httpGet["host"] = probe.HTTPGet.Host
@@ -1045,13 +1428,23 @@ func generateProbeSpec(probe *models.Probe) map[string]interface{} {
 		if probe.HTTPGet.Scheme != "" {
 			httpGet["scheme"] = probe.HTTPGet.Scheme
 		}
+		if len(probe.HTTPGet.HTTPHeaders) > 0 {
+			headers := make([]map[string]interface{}, len(probe.HTTPGet.HTTPHeaders))
+			for i, header := range probe.HTTPGet.HTTPHeaders {
+				headers[i] = map[string]interface{}{
+					"name":  header.Name,
+					"value": header.Value,
+				}
+			}
+			httpGet["httpHeaders"] = headers
+		}
 		probeSpec["httpGet"] = httpGet
 	}
 
 	if probe.TCPSocket != nil {
 		tcpSocket := make(map[string]interface{})
 		if probe.TCPSocket.Port != (intstr.IntOrString{}) {
-			tcpSocket["port"] = probe.TCPSocket.Port
+			tcpSocket["port"] = intOrStringValue(probe.TCPSocket.Port)
 		}
 		if probe.TCPSocket.Host != "" {
 			tcpSocket["host"] = probe.TCPSocket.Host
@@ -1068,6 +1461,14 @@ func generateProbeSpec(probe *models.Probe) map[string]interface{} {
 	return probeSpec
 }
 
+// intOrStringValue 将IntOrString转换为可直接序列化为YAML的值
+func intOrStringValue(value intstr.IntOrString) interface{} {
+	if value.Type == intstr.String {
+		return value.StrVal
+	}
+	return value.IntVal
+}
+
 // generateSecurityContextSpec 生成安全上下文规格
 func generateSecurityContextSpec(sc *models.SecurityContext) map[string]interface{} {
 	securityContext := make(map[string]interface{})
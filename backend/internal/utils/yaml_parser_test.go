@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"url-manager-system/backend/internal/db/models"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// roundTripCases testdata/yaml_roundtrip 下的输入文件（不含扩展名）
+var roundTripCases = []string{"multi_resource", "statefulset", "placeholders"}
+
+// editTemplateSpec 模拟结构化编辑模式下的常见修改
+func editTemplateSpec(spec *models.TemplateSpec) {
+	spec.Image = "registry.example.com/app:2.0"
+	spec.Replicas = 3
+	spec.Env = append(spec.Env, models.EnvironmentVar{Name: "FEATURE_FLAG", Value: "on"})
+	spec.Resources.Limits.Memory = "512Mi"
+}
+
+func TestYAMLRoundTripGolden(t *testing.T) {
+	for _, name := range roundTripCases {
+		t.Run(name, func(t *testing.T) {
+			inputPath := filepath.Join("testdata", "yaml_roundtrip", name+".yaml")
+			input, err := os.ReadFile(inputPath)
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", inputPath, err)
+			}
+
+			spec, err := ParseYAMLToTemplateSpec(string(input))
+			if err != nil {
+				t.Fatalf("ParseYAMLToTemplateSpec() error = %v", err)
+			}
+
+			specJSON, err := json.MarshalIndent(spec, "", "  ")
+			if err != nil {
+				t.Fatalf("failed to marshal spec: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", "yaml_roundtrip", name+".spec.golden.json"), string(specJSON)+"\n")
+
+			// 未修改的规格经过API的JSON往返后重新生成，结果必须与原始YAML完全一致
+			var roundTripped models.TemplateSpec
+			if err := json.Unmarshal(specJSON, &roundTripped); err != nil {
+				t.Fatalf("failed to unmarshal spec: %v", err)
+			}
+			output, err := GenerateYAMLFromTemplateSpec(&roundTripped, string(input))
+			if err != nil {
+				t.Fatalf("GenerateYAMLFromTemplateSpec() error = %v", err)
+			}
+			if output != string(input) {
+				t.Errorf("parse→generate is not idempotent:\n%s", output)
+			}
+
+			// 结构化编辑后其他资源原样保留
+			editTemplateSpec(&roundTripped)
+			edited, err := GenerateYAMLFromTemplateSpec(&roundTripped, string(input))
+			if err != nil {
+				t.Fatalf("GenerateYAMLFromTemplateSpec() with edits error = %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", "yaml_roundtrip", name+".edited.golden.yaml"), edited)
+
+			inputDocs := splitYAMLDocuments(string(input))
+			workloadIndex := findWorkloadDocument(inputDocs)
+			for i, doc := range inputDocs {
+				if i == workloadIndex || strings.TrimSpace(doc) == "" {
+					continue
+				}
+				if !strings.Contains(edited, strings.Trim(doc, "\n")) {
+					t.Errorf("document %d was not preserved:\n%s", i, doc)
+				}
+			}
+
+			// 编辑结果再次解析后应与编辑后的规格一致，且再次生成保持不变
+			reparsed, err := ParseYAMLToTemplateSpec(edited)
+			if err != nil {
+				t.Fatalf("ParseYAMLToTemplateSpec() on edited YAML error = %v", err)
+			}
+			expectedJSON, _ := json.Marshal(roundTripped)
+			reparsedJSON, _ := json.Marshal(reparsed)
+			if string(expectedJSON) != string(reparsedJSON) {
+				t.Errorf("reparsed spec = %s, expected %s", reparsedJSON, expectedJSON)
+			}
+
+			regenerated, err := GenerateYAMLFromTemplateSpec(reparsed, edited)
+			if err != nil {
+				t.Fatalf("GenerateYAMLFromTemplateSpec() on edited YAML error = %v", err)
+			}
+			if regenerated != edited {
+				t.Errorf("edited YAML is not stable under parse→generate:\n%s", regenerated)
+			}
+		})
+	}
+}
+
+func TestGenerateYAMLFromTemplateSpecRemovesFields(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join("testdata", "yaml_roundtrip", "multi_resource.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read input: %v", err)
+	}
+
+	spec, err := ParseYAMLToTemplateSpec(string(input))
+	if err != nil {
+		t.Fatalf("ParseYAMLToTemplateSpec() error = %v", err)
+	}
+
+	// 删除就绪探针、CPU限制和一个卷挂载，并给valueFrom变量设置字面值
+	spec.ReadinessProbe = nil
+	spec.Resources.Limits.CPU = ""
+	spec.VolumeMounts = spec.VolumeMounts[:1]
+	for i := range spec.Env {
+		if spec.Env[i].Name == "DB_PASSWORD" {
+			spec.Env[i].Value = "plain"
+		}
+	}
+
+	output, err := GenerateYAMLFromTemplateSpec(spec, string(input))
+	if err != nil {
+		t.Fatalf("GenerateYAMLFromTemplateSpec() error = %v", err)
+	}
+
+	for _, unexpected := range []string{"readinessProbe", "cpu: 1\n", "/var/run/tokens", "secretKeyRef"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("output still contains %q:\n%s", unexpected, output)
+		}
+	}
+	for _, expected := range []string{"ephemeral-storage: 1Gi", "log-shipper", "projected:", "lifecycle:", "value: plain"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("output does not contain %q:\n%s", expected, output)
+		}
+	}
+}
+
+func TestGenerateYAMLFromTemplateSpecWithoutBase(t *testing.T) {
+	spec := &models.TemplateSpec{
+		DeploymentName: "web",
+		Image:          "nginx:1.25",
+		ReadinessProbe: &models.Probe{
+			HTTPGet: &models.HTTPGetAction{Path: "/", Port: parseIntOrString(80)},
+		},
+	}
+
+	output, err := GenerateYAMLFromTemplateSpec(spec, "")
+	if err != nil {
+		t.Fatalf("GenerateYAMLFromTemplateSpec() error = %v", err)
+	}
+
+	parsed, err := ParseYAMLToTemplateSpec(output)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTemplateSpec() error = %v", err)
+	}
+	if parsed.Image != spec.Image || parsed.DeploymentName != spec.DeploymentName {
+		t.Errorf("parsed spec = %+v", parsed)
+	}
+	if parsed.ReadinessProbe == nil || parsed.ReadinessProbe.HTTPGet == nil || parsed.ReadinessProbe.HTTPGet.Port.IntValue() != 80 {
+		t.Errorf("readiness probe was not round-tripped:\n%s", output)
+	}
+}
+
+// checkGolden 比较结果与golden文件，使用 -update 重新生成
+func checkGolden(t *testing.T, path, actual string) {
+	t.Helper()
+
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatalf("failed to update golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s: %v", path, err)
+	}
+	if string(expected) != actual {
+		t.Errorf("%s mismatch:\n--- expected\n%s\n--- actual\n%s", path, expected, actual)
+	}
+}
@@ -338,6 +338,7 @@ const ProjectDetail: React.FC = () => {
     creating: { variant: 'default' as const, text: '创建中', icon: RefreshCw },
     waiting: { variant: 'outline' as const, text: '等待中', icon: Clock },
     active: { variant: 'default' as const, text: '运行中', icon: CheckCircle },
+    degraded: { variant: 'outline' as const, text: '更新异常', icon: AlertCircle },
     deleting: { variant: 'destructive' as const, text: '删除中', icon: Trash2 },
     deleted: { variant: 'secondary' as const, text: '已删除', icon: AlertCircle },
     failed: { variant: 'destructive' as const, text: '失败', icon: AlertCircle },
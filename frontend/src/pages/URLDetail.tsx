@@ -8,6 +8,7 @@ import { useEditor } from '@/hooks/useEditor';
 import { Button } from '@/components/ui/button';
 import { Badge } from '@/components/ui/badge';
 import { Card, CardHeader, CardTitle, CardContent, CardDescription } from '@/components/ui/card';
+import { Alert, AlertTitle, AlertDescription } from '@/components/ui/alert';
 import { Separator } from '@/components/ui/separator';
 import { Spinner } from '@/components/ui/spinner';
 import { Tabs, TabsContent, TabsList, TabsTrigger } from '@/components/ui/tabs';
@@ -266,6 +267,7 @@ const URLDetail: React.FC = () => {
     creating: { variant: 'default' as const, text: '创建中', color: 'text-blue-600', icon: RefreshCw },
     waiting: { variant: 'outline' as const, text: '等待中', color: 'text-yellow-600', icon: Clock },
     active: { variant: 'default' as const, text: '运行中', color: 'text-green-600', icon: CheckCircle },
+    degraded: { variant: 'outline' as const, text: '更新异常', color: 'text-orange-600', icon: AlertCircle },
     deleting: { variant: 'destructive' as const, text: '删除中', color: 'text-red-600', icon: Trash2 },
     deleted: { variant: 'secondary' as const, text: '已删除', color: 'text-gray-600', icon: X },
     failed: { variant: 'destructive' as const, text: '失败', color: 'text-red-600', icon: AlertCircle },
@@ -347,7 +349,7 @@ const URLDetail: React.FC = () => {
           </Button>
           {!editor.editing ? (
             <>
-              {(url.status === 'draft' || url.status === 'failed' || url.status === 'active') && (
+              {(url.status === 'draft' || url.status === 'failed' || url.status === 'active' || url.status === 'degraded') && (
                 <Button
                   size="sm"
                   onClick={handleDeploy}
@@ -355,7 +357,7 @@ const URLDetail: React.FC = () => {
                   variant={url.status === 'active' ? 'outline' : 'default'}
                 >
                   <Rocket className="h-4 w-4" />
-                  {url.status === 'failed' ? '重新部署' : url.status === 'active' ? '重新部署' : '部署'}
+                  {url.status === 'draft' ? '部署' : '重新部署'}
                 </Button>
               )}
               <Button
@@ -420,6 +422,20 @@ const URLDetail: React.FC = () => {
         </div>
       </div>
 
+      {/* Failure diagnosis */}
+      {url.status === 'failed' && url.diagnosis && (
+        <Alert variant="destructive">
+          <AlertCircle className="h-4 w-4" />
+          <AlertTitle>部署失败：{url.diagnosis.code}</AlertTitle>
+          <AlertDescription>
+            <p>{url.diagnosis.hint}</p>
+            {url.diagnosis.reason && (
+              <p className="mt-1 font-mono text-xs break-all">{url.diagnosis.reason}</p>
+            )}
+          </AlertDescription>
+        </Alert>
+      )}
+
       {/* Main content */}
       <Tabs 
         defaultValue="overview" 
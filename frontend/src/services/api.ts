@@ -8,14 +8,38 @@ import type {
   UpdateURLRequest,
   ListProjectsResponse,
   ListURLsResponse,
+  Stack,
+  CreateStackRequest,
+  ListStacksResponse,
+  GitWebhook,
+  CreateGitWebhookRequest,
+  ListGitWebhooksResponse,
+  URLAlias,
+  CreateURLAliasRequest,
+  UpdateURLAliasRequest,
+  ListURLAliasesResponse,
+  CustomDomain,
+  CreateCustomDomainRequest,
+  ListCustomDomainsResponse,
+  TrafficSplit,
+  CreateTrafficSplitRequest,
+  UpdateTrafficSplitRequest,
+  ListTrafficSplitsResponse,
+  Operation,
+  DriftReport,
+  ListURLRevisionsResponse,
+  GCReport,
   PaginationParams,
   AppTemplate,
   CreateTemplateRequest,
   UpdateTemplateRequest,
   CreateURLFromTemplateRequest,
   ListTemplatesResponse,
+  ListTemplatesParams,
   TemplateVariablesResponse,
   TemplatePreviewResponse,
+  TemplateImportResult,
+  CatalogSyncResult,
   ContainerStatus,
   PodEvent,
   ContainerLog,
@@ -181,20 +205,22 @@ export class ApiService {
     return response.data;
   }
 
-  static async deleteURL(id: string): Promise<void> {
-    await apiClient.delete(`/urls/${id}`);
+  static async deleteURL(id: string): Promise<Operation> {
+    const response = await apiClient.delete(`/urls/${id}`);
+    return response.data;
   }
 
   static async updateURL(
     id: string,
     data: UpdateURLRequest
-  ): Promise<EphemeralURL> {
+  ): Promise<Operation> {
     const response = await apiClient.put(`/urls/${id}`, data);
     return response.data;
   }
 
-  static async deployURL(id: string): Promise<void> {
-    await apiClient.post(`/urls/${id}/deploy`);
+  static async deployURL(id: string): Promise<Operation> {
+    const response = await apiClient.post(`/urls/${id}/deploy`);
+    return response.data;
   }
 
   static async getURLContainerStatus(id: string): Promise<ContainerStatus[]> {
@@ -219,6 +245,146 @@ export class ApiService {
     return Array.isArray(data) ? data : [];
   }
 
+  static async getURLRevisions(id: string): Promise<ListURLRevisionsResponse> {
+    const response = await apiClient.get(`/urls/${id}/revisions`);
+    return response.data;
+  }
+
+  static async rollbackURL(id: string, revision: number): Promise<Operation> {
+    const response = await apiClient.post(`/urls/${id}/rollback`, { revision });
+    return response.data;
+  }
+
+  static async getURLDrift(id: string): Promise<DriftReport> {
+    const response = await apiClient.get(`/urls/${id}/drift`);
+    return response.data;
+  }
+
+  // 环境管理 API
+  static async getProjectStacks(
+    projectId: string,
+    params?: PaginationParams
+  ): Promise<ListStacksResponse> {
+    const response = await apiClient.get(
+      `/projects/${projectId}/stacks`,
+      { params }
+    );
+    return response.data;
+  }
+
+  static async createStack(
+    projectId: string,
+    data: CreateStackRequest
+  ): Promise<Stack> {
+    const response = await apiClient.post(
+      `/projects/${projectId}/stacks`,
+      data
+    );
+    return response.data;
+  }
+
+  static async getStack(id: string): Promise<Stack> {
+    const response = await apiClient.get(`/stacks/${id}`);
+    return response.data;
+  }
+
+  static async deleteStack(id: string): Promise<void> {
+    await apiClient.delete(`/stacks/${id}`);
+  }
+
+  // Git webhook 管理 API
+  static async getProjectGitWebhooks(projectId: string): Promise<ListGitWebhooksResponse> {
+    const response = await apiClient.get(`/projects/${projectId}/git-webhooks`);
+    return response.data;
+  }
+
+  static async createGitWebhook(
+    projectId: string,
+    data: CreateGitWebhookRequest
+  ): Promise<GitWebhook> {
+    const response = await apiClient.post(
+      `/projects/${projectId}/git-webhooks`,
+      data
+    );
+    return response.data;
+  }
+
+  static async deleteGitWebhook(id: string): Promise<void> {
+    await apiClient.delete(`/git-webhooks/${id}`);
+  }
+
+  // 别名管理 API
+  static async getProjectAliases(projectId: string): Promise<ListURLAliasesResponse> {
+    const response = await apiClient.get(`/projects/${projectId}/aliases`);
+    return response.data;
+  }
+
+  static async createAlias(projectId: string, data: CreateURLAliasRequest): Promise<URLAlias> {
+    const response = await apiClient.post(`/projects/${projectId}/aliases`, data);
+    return response.data;
+  }
+
+  static async updateAlias(id: string, data: UpdateURLAliasRequest): Promise<URLAlias> {
+    const response = await apiClient.put(`/aliases/${id}`, data);
+    return response.data;
+  }
+
+  static async deleteAlias(id: string): Promise<void> {
+    await apiClient.delete(`/aliases/${id}`);
+  }
+
+  // 自定义域名管理 API
+  static async getProjectDomains(projectId: string): Promise<ListCustomDomainsResponse> {
+    const response = await apiClient.get(`/projects/${projectId}/domains`);
+    return response.data;
+  }
+
+  static async createDomain(projectId: string, data: CreateCustomDomainRequest): Promise<CustomDomain> {
+    const response = await apiClient.post(`/projects/${projectId}/domains`, data);
+    return response.data;
+  }
+
+  static async verifyDomain(id: string): Promise<CustomDomain> {
+    const response = await apiClient.post(`/domains/${id}/verify`);
+    return response.data;
+  }
+
+  static async deleteDomain(id: string): Promise<void> {
+    await apiClient.delete(`/domains/${id}`);
+  }
+
+  // 流量切分管理 API
+  static async getProjectTrafficSplits(projectId: string): Promise<ListTrafficSplitsResponse> {
+    const response = await apiClient.get(`/projects/${projectId}/traffic-splits`);
+    return response.data;
+  }
+
+  static async createTrafficSplit(projectId: string, data: CreateTrafficSplitRequest): Promise<TrafficSplit> {
+    const response = await apiClient.post(`/projects/${projectId}/traffic-splits`, data);
+    return response.data;
+  }
+
+  static async updateTrafficSplit(id: string, data: UpdateTrafficSplitRequest): Promise<TrafficSplit> {
+    const response = await apiClient.put(`/traffic-splits/${id}`, data);
+    return response.data;
+  }
+
+  static async deleteTrafficSplit(id: string): Promise<void> {
+    await apiClient.delete(`/traffic-splits/${id}`);
+  }
+
+  // 异步操作 API
+  static async getOperation(id: string): Promise<Operation> {
+    const response = await apiClient.get(`/operations/${id}`);
+    return response.data;
+  }
+
+  // 孤儿资源回收 API（管理员）
+  static async runGC(dryRun = false): Promise<GCReport> {
+    const response = await apiClient.post('/admin/gc', null, { params: { dry_run: dryRun } });
+    return response.data;
+  }
+
   // 健康检查
   static async healthCheck(): Promise<{ status: string; service: string }> {
     const response = await apiClient.get('/health');
@@ -232,7 +398,7 @@ export class ApiService {
   }
 
   // 模版管理 API
-  static async getTemplates(params?: PaginationParams): Promise<ListTemplatesResponse> {
+  static async getTemplates(params?: ListTemplatesParams): Promise<ListTemplatesResponse> {
     const response = await apiClient.get('/templates', {
       params,
     });
@@ -265,9 +431,38 @@ export class ApiService {
 
   static async previewTemplate(
     id: string, 
-    variables?: Record<string, string>
+    variables?: Record<string, string>,
+    dryRun = false
   ): Promise<TemplatePreviewResponse> {
-    const response = await apiClient.post(`/templates/${id}/preview`, variables || {});
+    const response = await apiClient.post(`/templates/${id}/preview`, variables || {}, {
+      params: dryRun ? { dry_run: true } : undefined,
+    });
+    return response.data;
+  }
+
+  static async setTemplateFeatured(id: string, featured: boolean): Promise<AppTemplate> {
+    const response = await apiClient.put(`/templates/${id}/featured`, { featured });
+    return response.data;
+  }
+
+  static async exportTemplates(ids?: string[]): Promise<string> {
+    const response = await apiClient.get('/templates/export', {
+      params: ids && ids.length > 0 ? { ids: ids.join(',') } : undefined,
+      responseType: 'text',
+    });
+    return response.data;
+  }
+
+  static async importTemplates(bundle: string, overwrite = false): Promise<TemplateImportResult> {
+    const response = await apiClient.post('/templates/import', bundle, {
+      params: { overwrite },
+      headers: { 'Content-Type': 'application/x-yaml' },
+    });
+    return response.data;
+  }
+
+  static async syncTemplateCatalog(): Promise<CatalogSyncResult> {
+    const response = await apiClient.post('/templates/catalog/sync');
     return response.data;
   }
 
@@ -1,8 +1,25 @@
+export type RoutingMode = 'path' | 'subdomain';
+
+export type TLSMode = 'none' | 'secret' | 'cert-manager';
+
+export type DriftPolicy = 'report' | 'heal';
+
+export interface RetryPolicy {
+  max_attempts: number; // 0表示不自动重试
+  backoff_seconds: number; // 第一次重试前等待的秒数，之后每次翻倍
+  retryable_failures: FailureCode[];
+}
+
 export interface Project {
   id: string;
   user_id: string;
   name: string;
   description: string;
+  routing_mode: RoutingMode;
+  tls_mode: TLSMode;
+  tls_secret_name?: string;
+  drift_policy: DriftPolicy;
+  retry_policy: RetryPolicy;
   created_at: string;
   updated_at: string;
 }
@@ -78,6 +95,19 @@ export interface DeviceMapping {
   permissions?: string;
 }
 
+export type ProbeType = 'http' | 'tcp' | 'exec';
+
+export interface ContainerProbe {
+  type: ProbeType;
+  path?: string;
+  port?: number;
+  command?: string[];
+  initial_delay_seconds?: number;
+  period_seconds?: number;
+  timeout_seconds?: number;
+  failure_threshold?: number;
+}
+
 export interface ContainerConfig {
   container_name?: string;
   devices?: DeviceMapping[];
@@ -86,6 +116,9 @@ export interface ContainerConfig {
   working_dir?: string;
   tty?: boolean;
   stdin?: boolean;
+  readiness_probe?: ContainerProbe;
+  liveness_probe?: ContainerProbe;
+  startup_timeout_seconds?: number;
 }
 
 export interface ResourceRequests {
@@ -98,6 +131,22 @@ export interface ResourceLimits {
   limits: ResourceRequests;
 }
 
+export type FailureCode =
+  | 'quota_exceeded'
+  | 'unschedulable'
+  | 'image_pull_failed'
+  | 'config_error'
+  | 'oom_killed'
+  | 'crash_loop'
+  | 'probe_failed'
+  | 'unknown';
+
+export interface FailureDiagnosis {
+  code: FailureCode;
+  reason?: string;
+  hint: string;
+}
+
 export interface EphemeralURL {
   id: string;
   project_id: string;
@@ -108,17 +157,30 @@ export interface EphemeralURL {
   replicas: number;
   resources: ResourceLimits;
   container_config?: ContainerConfig;
-  status: 'draft' | 'creating' | 'waiting' | 'active' | 'deleting' | 'deleted' | 'failed';
+  status: 'draft' | 'creating' | 'waiting' | 'active' | 'degraded' | 'deleting' | 'deleted' | 'failed';
   ttl_seconds: number;
   k8s_deployment_name?: string;
   k8s_service_name?: string;
   k8s_secret_name?: string;
   error_message?: string;
+  diagnosis?: FailureDiagnosis; // failed状态的失败原因分类
+  retry_count: number; // 已自动重试的次数
+  next_retry_at?: string; // 下一次自动重试的时间
   logs?: LogEntry[];
   container_statuses?: ContainerStatus[];
   pod_events?: PodEvent[];
   container_logs?: ContainerLog[];
   ingress_host?: string;
+  stack_id?: string;
+  stack_service?: string;
+  git_repository?: string;
+  git_pr_number?: number;
+  git_branch?: string;
+  git_commit?: string;
+  track_tag_pattern?: string;
+  tls_secret_name?: string;
+  tls_ready: boolean;
+  url?: string; // 完整访问地址，证书就绪前为 http
   started_at?: string;
   expire_at: string;
   created_at: string;
@@ -130,6 +192,11 @@ export interface EphemeralURL {
 export interface CreateProjectRequest {
   name: string;
   description?: string;
+  routing_mode?: RoutingMode;
+  tls_mode?: TLSMode;
+  tls_secret_name?: string; // 更新时传空字符串清除
+  drift_policy?: DriftPolicy;
+  retry_policy?: Partial<RetryPolicy>;
 }
 
 export interface CreateURLRequest {
@@ -140,11 +207,13 @@ export interface CreateURLRequest {
   resources?: ResourceLimits;
   container_config?: ContainerConfig;
   ingress_host?: string;
+  track_tag_pattern?: string; // 跟踪的镜像标签规则，例如 v1.* 或 main-*
 }
 
 export interface CreateURLResponse {
   url: string;
   id: string;
+  operation_id?: string; // 创建Kubernetes资源的异步操作，Kubernetes不可用时为空
 }
 
 export interface UpdateURLRequest {
@@ -155,6 +224,7 @@ export interface UpdateURLRequest {
   resources?: ResourceLimits;
   container_config?: ContainerConfig;
   ingress_host?: string;
+  track_tag_pattern?: string; // 空字符串表示取消跟踪
 }
 
 export interface ListProjectsResponse {
@@ -167,6 +237,266 @@ export interface ListURLsResponse {
   total: number;
 }
 
+// 环境（多服务栈）相关类型
+export interface StackServiceSpec {
+  name: string;
+  template_id?: string;
+  image?: string;
+  env?: EnvironmentVar[];
+  replicas?: number;
+  resources?: ResourceLimits;
+  container_config?: ContainerConfig;
+  path?: string;
+  depends_on?: string[];
+}
+
+export type StackStatus = 'draft' | 'deploying' | 'active' | 'degraded' | 'deleting' | 'failed';
+
+export interface Stack {
+  id: string;
+  project_id: string;
+  user_id?: string;
+  name: string;
+  description: string;
+  services: StackServiceSpec[];
+  env?: EnvironmentVar[];
+  status: StackStatus;
+  ttl_seconds: number;
+  error_message?: string;
+  started_at?: string;
+  expire_at: string;
+  created_at: string;
+  updated_at: string;
+  members?: EphemeralURL[];
+}
+
+export interface CreateStackRequest {
+  name: string;
+  description?: string;
+  services: StackServiceSpec[];
+  env?: EnvironmentVar[];
+  ttl_seconds: number;
+}
+
+export interface ListStacksResponse {
+  stacks: Stack[];
+  total: number;
+}
+
+// Git webhook（PR预览）相关类型
+export type GitProvider = 'github' | 'gitlab';
+
+export interface GitWebhook {
+  id: string;
+  project_id: string;
+  template_id: string;
+  user_id?: string;
+  provider: GitProvider;
+  repository: string;
+  secret?: string; // 仅在创建时返回
+  image_repository: string;
+  image_tag_format: string;
+  ttl_seconds: number;
+  webhook_path: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreateGitWebhookRequest {
+  provider: GitProvider;
+  repository: string;
+  template_id: string;
+  image_repository: string;
+  image_tag_format?: string;
+  ttl_seconds?: number;
+  secret?: string;
+}
+
+export interface ListGitWebhooksResponse {
+  webhooks: GitWebhook[];
+  total: number;
+}
+
+// 别名相关类型
+export interface URLAlias {
+  id: string;
+  project_id: string;
+  name: string;
+  path: string;
+  url_id?: string; // 为空时指向兜底页面
+  user_id?: string;
+  url: string;
+  fallback: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreateURLAliasRequest {
+  name: string;
+  url_id?: string;
+}
+
+export interface UpdateURLAliasRequest {
+  url_id?: string | null;
+}
+
+export interface ListURLAliasesResponse {
+  aliases: URLAlias[];
+  total: number;
+}
+
+// 自定义域名相关类型
+export interface DomainChallenge {
+  type: 'TXT' | 'HTTP';
+  name: string; // TXT记录名称或验证文件地址
+  value: string;
+}
+
+export interface CustomDomain {
+  id: string;
+  project_id: string;
+  domain: string;
+  verification_method: 'dns' | 'http';
+  verification_token: string;
+  status: 'pending' | 'verified' | 'failed';
+  failure_count: number;
+  last_error?: string;
+  verified_at?: string;
+  last_checked_at?: string;
+  user_id?: string;
+  challenge?: DomainChallenge;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreateCustomDomainRequest {
+  domain: string;
+  verification_method?: 'dns' | 'http';
+}
+
+export interface ListCustomDomainsResponse {
+  domains: CustomDomain[];
+  total: number;
+}
+
+// 流量切分相关类型
+export interface TrafficSplit {
+  id: string;
+  project_id: string;
+  primary_url_id: string;
+  canary_url_id: string;
+  canary_weight: number; // 转发给金丝雀URL的流量百分比
+  primary_weight: number;
+  status: 'active' | 'reverted';
+  reverted_at?: string;
+  user_id?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreateTrafficSplitRequest {
+  primary_url_id: string;
+  canary_url_id: string;
+  canary_weight: number;
+}
+
+export interface UpdateTrafficSplitRequest {
+  canary_weight: number;
+}
+
+export interface ListTrafficSplitsResponse {
+  traffic_splits: TrafficSplit[];
+  total: number;
+}
+
+// 异步操作相关类型
+export interface Operation {
+  id: string;
+  type: 'create' | 'update' | 'delete' | 'deploy' | 'rollout' | 'rollback';
+  url_id: string;
+  project_id: string;
+  source: 'api' | 'cleanup' | 'webhook' | 'drift' | 'health' | 'retry'; // 发起操作的来源
+  status: 'pending' | 'running' | 'succeeded' | 'failed';
+  progress?: string;
+  result?: Record<string, unknown>;
+  error?: string;
+  attempts: number;
+  max_attempts: number;
+  next_run_at: string;
+  started_at?: string;
+  finished_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+// 配置版本相关类型
+export interface RevisionChange {
+  field: string;
+  from: string;
+  to: string;
+}
+
+export interface URLRevision {
+  id: string;
+  url_id: string;
+  revision: number;
+  image: string;
+  env: EnvironmentVar[];
+  replicas: number;
+  resources: ResourceLimits;
+  container_config: ContainerConfig;
+  source: 'create' | 'deploy' | 'rollout' | 'rollback';
+  operation_id?: string;
+  rollback_of?: number; // 回滚产生的版本对应的历史版本
+  changes: RevisionChange[]; // 与上一版本相比的变化
+  created_at: string;
+}
+
+export interface ListURLRevisionsResponse {
+  revisions: URLRevision[];
+  total: number;
+}
+
+// 配置漂移相关类型
+export interface DriftItem {
+  kind: string;
+  name: string;
+  field: string; // 资源缺失时为空
+  desired: string;
+  live: string;
+}
+
+export interface DriftReport {
+  url_id: string;
+  drift_policy: DriftPolicy;
+  drifted: boolean;
+  items: DriftItem[];
+  checked_at: string;
+}
+
+// 孤儿资源回收相关类型
+export interface GCOrphan {
+  kind: string;
+  name: string;
+  url_id?: string;
+  project?: string;
+  reason: string;
+  deleted: boolean;
+  error?: string;
+}
+
+export interface GCReport {
+  dry_run: boolean;
+  grace_period: string;
+  scanned: number;
+  in_grace: number; // 疑似孤儿但仍在宽限期内的资源数
+  orphans: GCOrphan[];
+  deleted: number;
+  failed: number;
+  started_at: string;
+  finished_at: string;
+}
+
 export interface ApiResponse<T> {
   data?: T;
   error?: string;
@@ -180,6 +510,7 @@ export interface PaginationParams {
 // 应用模版相关类型
 export interface TemplateSpec {
   // Deployment 级别配置
+  workload_kind?: 'Deployment' | 'StatefulSet';
   deployment_name?: string;
   namespace?: string;
   replicas?: number;
@@ -223,6 +554,14 @@ export interface TemplateSpec {
   // 其他配置
   image_pull_secrets?: string[];
   init_containers?: Container[];
+
+  // 模板中的其他资源（只读）
+  extra_resources?: ResourceRef[];
+}
+
+export interface ResourceRef {
+  kind: string;
+  name?: string;
 }
 
 export interface ContainerPort {
@@ -449,15 +788,68 @@ export interface AppTemplate {
   description: string;
   yaml_spec: string;
   parsed_spec: TemplateSpec;
+  parameters?: TemplateParameter[];
+  managed: boolean; // 由Git模版目录管理，只读
+  catalog_source?: string;
+  catalog_revision?: string;
+  visibility: TemplateVisibility;
+  project_id?: string;
+  category: string;
+  tags: string[];
+  featured: boolean;
+  usage_count: number;
+  last_used_at?: string;
   created_at: string;
   updated_at: string;
 }
 
+export type TemplateVisibility = 'private' | 'project' | 'organization';
+
+export interface ListTemplatesParams extends PaginationParams {
+  search?: string;
+  category?: string;
+  tags?: string; // 逗号分隔，需包含全部标签
+  visibility?: TemplateVisibility;
+  template_type?: string;
+  project_id?: string;
+  featured?: boolean;
+  sort?: 'popular' | 'name' | 'recent';
+}
+
+export interface TemplateParameter {
+  name: string;
+  description?: string;
+  default?: string;
+  required?: boolean;
+}
+
+export interface TemplateImportResult {
+  created: string[];
+  updated: string[];
+  skipped: string[];
+  errors: string[];
+}
+
+export interface CatalogSyncResult {
+  revision: string;
+  created: string[];
+  updated: string[];
+  unchanged: string[];
+  removed: string[];
+  errors: string[];
+  synced_at: string;
+}
+
 export interface CreateTemplateRequest {
   name: string;
   description?: string;
   yaml_spec: string;
   parsed_spec?: TemplateSpec;
+  parameters?: TemplateParameter[];
+  visibility?: TemplateVisibility;
+  project_id?: string;
+  category?: string;
+  tags?: string[];
 }
 
 export interface UpdateTemplateRequest {
@@ -465,6 +857,11 @@ export interface UpdateTemplateRequest {
   description?: string;
   yaml_spec?: string;
   parsed_spec?: TemplateSpec;
+  parameters?: TemplateParameter[];
+  visibility?: TemplateVisibility;
+  project_id?: string;
+  category?: string;
+  tags?: string[];
 }
 
 export interface CreateURLFromTemplateRequest {
@@ -486,6 +883,32 @@ export interface TemplateVariablesResponse {
 export interface TemplatePreviewResponse {
   processed_yaml: string;
   variables: Record<string, string>;
+  dry_run?: TemplateDryRunResult; // 仅 dry_run=true 时返回
+}
+
+export type DryRunStatus = 'valid' | 'invalid' | 'rejected' | 'conflict' | 'error';
+
+export interface DryRunCause {
+  field?: string;
+  reason?: string;
+  message: string;
+}
+
+export interface DryRunDocumentResult {
+  index: number;
+  api_version?: string;
+  kind?: string;
+  name?: string;
+  namespace?: string;
+  status: DryRunStatus;
+  message?: string;
+  causes?: DryRunCause[];
+  object?: Record<string, unknown>;
+}
+
+export interface TemplateDryRunResult {
+  valid: boolean;
+  documents: DryRunDocumentResult[];
 }
 
 // 认证相关类型